@@ -0,0 +1,114 @@
+// Command beacon-sim simulates the lecturer's device of a BEACON attendance session: it fetches the
+// rotating identifier from the backend and "broadcasts" it by printing it every rotation. Given a
+// student token it also plays a phone that observes the identifier and checks in with a simulated
+// signal strength, so the beacon flow can be tried without Bluetooth hardware.
+//
+//	go run ./cmd/beacon-sim -session 42 -token $LECTURER_TOKEN -student-token $STUDENT_TOKEN -device $DEVICE_ID -rssi -70
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/delpresence/backend/internal/models"
+)
+
+func main() {
+	api := flag.String("api", "http://localhost:8080/api", "Base URL of the backend API")
+	group := flag.String("group", "lecturer", "Route group of the broadcasting user: lecturer, assistant or admin")
+	sessionID := flag.Uint("session", 0, "ID of the active BEACON session")
+	token := flag.String("token", "", "Access token of the broadcasting user")
+	studentToken := flag.String("student-token", "", "Access token of a student who checks in with each identifier (optional)")
+	device := flag.String("device", "", "Registered device ID of the student, sent as X-Device-ID")
+	rssi := flag.Int("rssi", -60, "Simulated signal strength in dBm reported by the student")
+	once := flag.Bool("once", false, "Broadcast a single identifier and exit")
+	flag.Parse()
+
+	if *sessionID == 0 || *token == "" {
+		log.Fatal("-session and -token are required")
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	for {
+		var beacon models.AttendanceBeaconResponse
+		url := fmt.Sprintf("%s/%s/attendance/sessions/%d/beacon", *api, *group, *sessionID)
+		if err := call(client, http.MethodGet, url, *token, "", nil, &beacon); err != nil {
+			log.Fatalf("Failed to fetch beacon identifier: %v", err)
+		}
+		log.Printf("Broadcasting service %s identifier %s until %s",
+			beacon.ServiceUUID, beacon.Identifier, beacon.ExpiresAt.Format("15:04:05"))
+
+		if *studentToken != "" {
+			submission := map[string]interface{}{
+				"session_id": *sessionID,
+				"identifier": beacon.Identifier,
+				"rssi":       *rssi,
+			}
+			err := call(client, http.MethodPost, *api+"/student/attendance/beacon-submit", *studentToken, *device, submission, nil)
+			if err != nil {
+				log.Printf("Student check-in rejected: %v", err)
+			} else {
+				log.Printf("Student checked in at %d dBm", *rssi)
+			}
+		}
+
+		if *once {
+			return
+		}
+		time.Sleep(time.Until(beacon.ExpiresAt) + 500*time.Millisecond)
+	}
+}
+
+// call sends a JSON request and decodes the response into out. Lecturer endpoints return bare JSON
+// while the other groups wrap it in {"status", "message", "data"}, so both shapes are accepted.
+func call(client *http.Client, method, url, token, deviceID string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	if deviceID != "" {
+		req.Header.Set("X-Device-ID", deviceID)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(raw))
+	}
+	if out == nil {
+		return nil
+	}
+
+	var wrapped struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(raw, &wrapped); err == nil && len(wrapped.Data) > 0 {
+		raw = wrapped.Data
+	}
+	return json.Unmarshal(raw, out)
+}
@@ -9,6 +9,8 @@ import (
 	"github.com/delpresence/backend/internal/database"
 	"github.com/delpresence/backend/internal/handlers"
 	"github.com/delpresence/backend/internal/middleware"
+	"github.com/delpresence/backend/internal/realtime"
+	"github.com/delpresence/backend/internal/services"
 	"github.com/delpresence/backend/internal/utils"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -31,6 +33,32 @@ func main() {
 	// Initialize auth service (includes both user and student repositories)
 	auth.Initialize()
 
+	// Initialize real-time event broker (fans out across replicas via PostgreSQL LISTEN/NOTIFY)
+	realtime.Initialize()
+
+	// Start delivering queued webhook events (safe to run on every replica)
+	services.NewWebhookService().StartDeliveryWorker()
+
+	// Start the email notification scheduler (emails are deduplicated across replicas)
+	if utils.GetEnvAsBool("NOTIFICATION_SCHEDULER_ENABLED", true) {
+		services.NewNotificationService().StartScheduler()
+	}
+
+	// Remind students shortly before an auto-closing session ends (pushes are deduplicated across replicas)
+	services.NewPushService().StartReminderWorker()
+
+	// Render queued report exports in the background (jobs are claimed with SKIP LOCKED)
+	services.NewReportJobService().StartWorker()
+
+	// Close sessions that were reopened for a few minutes once their window ends
+	services.NewAttendanceService().StartReopenCloser()
+
+	// Open and close self-opening campus events such as dormitory roll calls
+	services.NewCampusEventService().StartWindowWorker()
+
+	// Flag suspected proxy attendance for review (flags are deduplicated across replicas)
+	services.NewAttendanceFraudService().StartAnalysisWorker()
+
 	// Create admin user
 	err = auth.CreateAdminUser()
 	if err != nil {
@@ -44,7 +72,7 @@ func main() {
 	config := cors.DefaultConfig()
 	config.AllowOrigins = []string{"*"}
 	config.AllowCredentials = true
-	config.AllowHeaders = append(config.AllowHeaders, "Authorization", "Content-Type")
+	config.AllowHeaders = append(config.AllowHeaders, "Authorization", "Content-Type", "X-Device-ID")
 	config.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
 	router.Use(cors.New(config))
 
@@ -65,12 +93,31 @@ func main() {
 	buildingHandler := handlers.NewBuildingHandler()
 	roomHandler := handlers.NewRoomHandler()
 	academicYearHandler := handlers.NewAcademicYearHandler()
+	academicYearRolloverHandler := handlers.NewAcademicYearRolloverHandler()
 	courseHandler := handlers.NewCourseHandler()
 	studentGroupHandler := handlers.NewStudentGroupHandler()
 	lecturerAssignmentHandler := handlers.NewLecturerAssignmentHandler()
 	teachingAssistantAssignmentHandler := handlers.NewTeachingAssistantAssignmentHandler()
 	courseScheduleHandler := handlers.NewCourseScheduleHandler()
 	attendanceHandler := handlers.NewAttendanceHandler()
+	assistantAttendanceHandler := handlers.NewActorAttendanceHandler(services.AttendanceActorAssistant)
+	adminAttendanceHandler := handlers.NewActorAttendanceHandler(services.AttendanceActorAdmin)
+	attendanceEventHandler := handlers.NewAttendanceEventHandler()
+	campusEventHandler := handlers.NewCampusEventHandler()
+	lecturerTeachingHandler := handlers.NewLecturerTeachingHandler()
+	assistantActivityHandler := handlers.NewTeachingAssistantActivityHandler()
+	webhookHandler := handlers.NewWebhookHandler()
+	notificationHandler := handlers.NewNotificationHandler()
+	offlineAttendanceHandler := handlers.NewOfflineAttendanceHandler(services.AttendanceActorLecturer)
+	assistantOfflineAttendanceHandler := handlers.NewOfflineAttendanceHandler(services.AttendanceActorAssistant)
+	studentDeviceHandler := handlers.NewStudentDeviceHandler()
+	attendanceFraudHandler := handlers.NewAttendanceFraudHandler()
+	attendanceStatisticsHandler := handlers.NewAttendanceStatisticsHandler()
+	attendanceAnalyticsHandler := handlers.NewAttendanceAnalyticsHandler()
+	attendanceRecapHandler := handlers.NewAttendanceRecapHandler()
+	attendanceMatrixHandler := handlers.NewAttendanceMatrixHandler()
+	attendanceSheetHandler := handlers.NewAttendanceSheetHandler()
+	reportJobHandler := handlers.NewReportJobHandler()
 
 	// Protected routes
 	authRequired := router.Group("/api")
@@ -104,6 +151,12 @@ func main() {
 			adminRoutes.GET("/students/by-user-id/:user_id", studentHandler.GetStudentByUserID)
 			adminRoutes.POST("/students/sync", studentHandler.SyncStudents)
 
+			// Admin access to academic advisors and per-student attendance recaps
+			adminRoutes.GET("/advisors", attendanceRecapHandler.GetAdvisors)
+			adminRoutes.PUT("/students/:id/advisor", attendanceRecapHandler.AssignAdvisor)
+			adminRoutes.DELETE("/students/:id/advisor", attendanceRecapHandler.RemoveAdvisor)
+			adminRoutes.GET("/students/:id/attendance-recap", attendanceRecapHandler.GetStudentRecap)
+
 			// Admin access to faculty data
 			adminRoutes.GET("/faculties", facultyHandler.GetAllFaculties)
 			adminRoutes.GET("/faculties/:id", facultyHandler.GetFacultyByID)
@@ -139,6 +192,11 @@ func main() {
 			adminRoutes.PUT("/academic-years/:id", academicYearHandler.UpdateAcademicYear)
 			adminRoutes.DELETE("/academic-years/:id", academicYearHandler.DeleteAcademicYear)
 
+			// Copying a previous academic year's courses, groups, assignments and schedules
+			adminRoutes.POST("/academic-years/:id/rollover/preview", academicYearRolloverHandler.PreviewRollover)
+			adminRoutes.POST("/academic-years/:id/rollover", academicYearRolloverHandler.CommitRollover)
+			adminRoutes.GET("/academic-years/:id/rollovers", academicYearRolloverHandler.GetRollovers)
+
 			// Admin access to course data
 			adminRoutes.GET("/courses", courseHandler.GetAllCourses)
 			adminRoutes.GET("/courses/:id", courseHandler.GetCourseByID)
@@ -187,6 +245,76 @@ func main() {
 
 			// New endpoint to get lecturer for a course - use a more specific path to avoid conflict
 			adminRoutes.GET("/course-lecturers/course/:course_id", courseScheduleHandler.GetLecturerForCourse)
+
+			// Admin management of outbound webhooks for other campus systems
+			adminRoutes.GET("/webhooks/event-types", webhookHandler.GetEventTypes)
+			adminRoutes.GET("/webhooks/dead-letters", webhookHandler.GetDeadLetters)
+			adminRoutes.POST("/webhooks/deliveries/:id/redeliver", webhookHandler.RedeliverDelivery)
+			adminRoutes.GET("/webhooks", webhookHandler.GetAllEndpoints)
+			adminRoutes.GET("/webhooks/:id", webhookHandler.GetEndpointByID)
+			adminRoutes.POST("/webhooks", webhookHandler.CreateEndpoint)
+			adminRoutes.PUT("/webhooks/:id", webhookHandler.UpdateEndpoint)
+			adminRoutes.DELETE("/webhooks/:id", webhookHandler.DeleteEndpoint)
+			adminRoutes.GET("/webhooks/:id/deliveries", webhookHandler.GetEndpointDeliveries)
+
+			// Attendance statistics across all schedules, grouped by session, schedule, course, student or group
+			adminRoutes.GET("/attendance/statistics", attendanceStatisticsHandler.GetAdminBreakdown)
+
+			// Attendance sessions of every schedule, limited by the admin attendance policy
+			registerAttendanceSessionRoutes(adminRoutes, adminAttendanceHandler)
+
+			// Lecture journals and course lesson plans, limited by the admin attendance policy
+			registerLectureJournalRoutes(adminRoutes, handlers.NewLectureJournalHandler(services.AttendanceActorAdmin))
+
+			// Campus events of every organizer
+			registerCampusEventRoutes(adminRoutes, campusEventHandler)
+			registerRollCallRoutes(adminRoutes, campusEventHandler)
+
+			// Admin attendance overrides; each requires a reason and is recorded in the audit trail
+			attendanceOverrideHandler := handlers.NewAttendanceOverrideHandler()
+			adminRoutes.GET("/attendance/overrides", attendanceOverrideHandler.GetOverrides)
+			adminRoutes.POST("/attendance/overrides/sessions", attendanceOverrideHandler.CreateRetroactiveSession)
+			adminRoutes.PUT("/attendance/overrides/sessions/:id/reopen", attendanceOverrideHandler.ReopenSession)
+			adminRoutes.PUT("/attendance/overrides/sessions/:id/close", attendanceOverrideHandler.CloseSession)
+			adminRoutes.PUT("/attendance/overrides/sessions/:id/cancel", attendanceOverrideHandler.CancelSession)
+			adminRoutes.PUT("/attendance/overrides/sessions/:id/students/:studentId", attendanceOverrideHandler.SetStudentStatus)
+			adminRoutes.POST("/attendance/overrides/excuse", attendanceOverrideHandler.ExcuseGroup)
+
+			// Devices students check in with; device changes beyond the semester limit wait here
+			adminRoutes.GET("/attendance-devices", studentDeviceHandler.GetDeviceRequests)
+			adminRoutes.PUT("/attendance-devices/:id/review", studentDeviceHandler.ReviewDeviceRequest)
+
+			// Run the proxy-attendance analysis now instead of waiting for the background job
+			adminRoutes.POST("/attendance/fraud-analysis", attendanceFraudHandler.RunAnalysis)
+
+			// Semester attendance matrix of a schedule or of all schedules of a course
+			adminRoutes.GET("/attendance/matrix", attendanceMatrixHandler.ExportMatrix)
+
+			// Printable attendance sheets (daftar hadir)
+			adminRoutes.GET("/schedules/:id/attendance-sheet", attendanceSheetHandler.GetPlannedSheet)
+			adminRoutes.GET("/attendance/sessions/:id/sheet", attendanceSheetHandler.GetSessionSheet)
+
+			// Background report exports, downloaded through signed links once rendered
+			adminRoutes.POST("/reports/jobs", reportJobHandler.CreateJob)
+			adminRoutes.GET("/reports/jobs", reportJobHandler.GetMyJobs)
+			adminRoutes.GET("/reports/jobs/:id", reportJobHandler.GetJob)
+			adminRoutes.GET("/reports/jobs/:id/events", reportJobHandler.StreamJobEvents)
+
+			// Attendance analytics per academic year (defaults to the active one)
+			adminRoutes.GET("/analytics/attendance/summary", attendanceAnalyticsHandler.GetOverview)
+			adminRoutes.GET("/analytics/attendance/by/:dimension", attendanceAnalyticsHandler.GetBreakdown)
+			adminRoutes.GET("/analytics/attendance/weekly-trend", attendanceAnalyticsHandler.GetWeeklyTrend)
+			adminRoutes.GET("/analytics/attendance/late-arrivals", attendanceAnalyticsHandler.GetLateArrivals)
+			adminRoutes.GET("/analytics/attendance/planned-vs-opened", attendanceAnalyticsHandler.GetPlannedCoverage)
+			adminRoutes.GET("/analytics/attendance/verification-methods", attendanceAnalyticsHandler.GetVerificationMethods)
+
+			// Lecturer teaching records for honorarium calculation
+			adminRoutes.GET("/reports/lecturer-teaching", lecturerTeachingHandler.GetReport)
+			adminRoutes.GET("/reports/assistant-activity", assistantActivityHandler.GetReport)
+
+			// Admin inspection of sent email notifications and manual job runs
+			adminRoutes.GET("/notifications/logs", notificationHandler.GetLogs)
+			adminRoutes.POST("/notifications/jobs/:job/run", notificationHandler.RunJob)
 		}
 
 		// Lecturer routes - add lecturer-specific endpoints
@@ -206,17 +334,41 @@ func main() {
 			lecturerRoutes.GET("/academic-years", academicYearHandler.GetAllAcademicYears)
 
 			// Attendance management routes for lecturers
-			lecturerRoutes.POST("/attendance/sessions", attendanceHandler.CreateAttendanceSession)
-			lecturerRoutes.GET("/attendance/sessions/active", attendanceHandler.GetActiveAttendanceSessions)
-			lecturerRoutes.GET("/attendance/sessions", attendanceHandler.GetAttendanceSessions)
-			lecturerRoutes.GET("/attendance/sessions/:id", attendanceHandler.GetAttendanceSessionDetails)
-			lecturerRoutes.PUT("/attendance/sessions/:id/close", attendanceHandler.CloseAttendanceSession)
-			lecturerRoutes.PUT("/attendance/sessions/:id/cancel", attendanceHandler.CancelAttendanceSession)
-			lecturerRoutes.GET("/attendance/sessions/:id/students", attendanceHandler.GetStudentAttendances)
-			lecturerRoutes.PUT("/attendance/sessions/:id/students/:studentId", attendanceHandler.MarkStudentAttendance)
-			lecturerRoutes.GET("/attendance/statistics/course/:courseScheduleId", attendanceHandler.GetAttendanceStatistics)
-			lecturerRoutes.GET("/attendance/qrcode/:id", attendanceHandler.GetQRCode)
-			lecturerRoutes.GET("/attendance/sessions/:id/report", attendanceHandler.DownloadAttendanceReport)
+			registerAttendanceSessionRoutes(lecturerRoutes, attendanceHandler)
+			registerLectureJournalRoutes(lecturerRoutes, handlers.NewLectureJournalHandler(services.AttendanceActorLecturer))
+			lecturerRoutes.GET("/attendance/statistics", attendanceStatisticsHandler.GetLecturerBreakdown)
+			lecturerRoutes.GET("/attendance/matrix", attendanceMatrixHandler.ExportMatrix)
+			lecturerRoutes.GET("/teaching-record", lecturerTeachingHandler.GetMyReport)
+
+			// Campus events organized by the lecturer
+			registerCampusEventRoutes(lecturerRoutes, campusEventHandler)
+
+			// Activity of the teaching assistants the lecturer assigned
+			lecturerRoutes.GET("/reports/assistant-activity", assistantActivityHandler.GetReport)
+			lecturerRoutes.GET("/schedules/:id/attendance-sheet", attendanceSheetHandler.GetPlannedSheet)
+			lecturerRoutes.GET("/attendance/sessions/:id/sheet", attendanceSheetHandler.GetSessionSheet)
+
+			// Background report exports, downloaded through signed links once rendered
+			lecturerRoutes.POST("/reports/jobs", reportJobHandler.CreateJob)
+			lecturerRoutes.GET("/reports/jobs", reportJobHandler.GetMyJobs)
+			lecturerRoutes.GET("/reports/jobs/:id", reportJobHandler.GetJob)
+			lecturerRoutes.GET("/reports/jobs/:id/events", reportJobHandler.StreamJobEvents)
+
+			// Academic advisors see their advisees and their attendance recaps
+			lecturerRoutes.GET("/advisees", attendanceRecapHandler.GetMyAdvisees)
+			lecturerRoutes.GET("/advisees/:id/attendance-recap", attendanceRecapHandler.GetStudentRecap)
+			lecturerRoutes.GET("/attendance/sessions/:id/events", attendanceEventHandler.StreamSessionEvents)
+			lecturerRoutes.GET("/attendance/sessions/:id/offline-checkins", offlineAttendanceHandler.GetSessionOfflineCheckIns)
+			lecturerRoutes.PUT("/attendance/offline-checkins/:id/review", offlineAttendanceHandler.ReviewOfflineCheckIn)
+
+			// Email notification preferences
+			lecturerRoutes.GET("/notifications/preferences", notificationHandler.GetMyPreferences)
+			lecturerRoutes.PUT("/notifications/preferences", notificationHandler.UpdateMyPreferences)
+
+			// Push notification device registration for the mobile app
+			lecturerRoutes.GET("/devices", notificationHandler.GetMyDevices)
+			lecturerRoutes.POST("/devices", notificationHandler.RegisterDevice)
+			lecturerRoutes.DELETE("/devices", notificationHandler.UnregisterDevice)
 
 			// Teaching assistant management endpoints for lecturers
 			lecturerRoutes.GET("/ta-assignments", teachingAssistantAssignmentHandler.GetMyTeachingAssistantAssignments)
@@ -233,6 +385,10 @@ func main() {
 			// Teaching assistant can view their assigned courses
 			employeeRoutes.GET("/assigned-courses", teachingAssistantAssignmentHandler.GetAssignmentsByTeachingAssistant)
 
+			// Campus events organized by the employee, and dormitory roll calls for dormitory staff
+			registerCampusEventRoutes(employeeRoutes, campusEventHandler)
+			registerRollCallRoutes(employeeRoutes, campusEventHandler)
+
 			// Other employee-specific routes can be added here
 		}
 
@@ -246,19 +402,30 @@ func main() {
 			// Get academic years (needed for filtering courses and schedules)
 			assistantRoutes.GET("/academic-years", academicYearHandler.GetAllAcademicYears)
 
-			// Register teaching assistant attendance handler
-			teachingAssistantAttendanceHandler := handlers.NewTeachingAssistantAttendanceHandler()
-
-			// Attendance management routes for assistants - full capabilities like lecturers
-			assistantRoutes.POST("/attendance/sessions", teachingAssistantAttendanceHandler.CreateAttendanceSession)
-			assistantRoutes.GET("/attendance/sessions/active", teachingAssistantAttendanceHandler.GetActiveAttendanceSessions)
-			assistantRoutes.GET("/attendance/sessions", teachingAssistantAttendanceHandler.GetAttendanceSessions)
-			assistantRoutes.GET("/attendance/sessions/:id", teachingAssistantAttendanceHandler.GetAttendanceSessionDetails)
-			assistantRoutes.PUT("/attendance/sessions/:id/close", teachingAssistantAttendanceHandler.CloseAttendanceSession)
-			assistantRoutes.GET("/attendance/sessions/:id/students", teachingAssistantAttendanceHandler.GetStudentAttendances)
-			assistantRoutes.PUT("/attendance/sessions/:id/students/:studentId", teachingAssistantAttendanceHandler.MarkStudentAttendance)
-			assistantRoutes.GET("/attendance/qrcode/:id", teachingAssistantAttendanceHandler.GetQRCode)
-			assistantRoutes.GET("/attendance/sessions/:id/report", teachingAssistantAttendanceHandler.DownloadAttendanceReport)
+			// Attendance management routes for assistants, limited by the assistant attendance policy
+			registerAttendanceSessionRoutes(assistantRoutes, assistantAttendanceHandler)
+			registerLectureJournalRoutes(assistantRoutes, handlers.NewLectureJournalHandler(services.AttendanceActorAssistant))
+			assistantRoutes.GET("/attendance/matrix", attendanceMatrixHandler.ExportMatrix)
+			assistantRoutes.GET("/schedules/:id/attendance-sheet", attendanceSheetHandler.GetPlannedSheet)
+			assistantRoutes.GET("/attendance/sessions/:id/sheet", attendanceSheetHandler.GetSessionSheet)
+
+			// Background report exports, downloaded through signed links once rendered
+			assistantRoutes.POST("/reports/jobs", reportJobHandler.CreateJob)
+			assistantRoutes.GET("/reports/jobs", reportJobHandler.GetMyJobs)
+			assistantRoutes.GET("/reports/jobs/:id", reportJobHandler.GetJob)
+			assistantRoutes.GET("/reports/jobs/:id/events", reportJobHandler.StreamJobEvents)
+			assistantRoutes.GET("/attendance/sessions/:id/events", attendanceEventHandler.StreamSessionEvents)
+			assistantRoutes.GET("/attendance/sessions/:id/offline-checkins", assistantOfflineAttendanceHandler.GetSessionOfflineCheckIns)
+			assistantRoutes.PUT("/attendance/offline-checkins/:id/review", assistantOfflineAttendanceHandler.ReviewOfflineCheckIn)
+
+			// Email notification preferences
+			assistantRoutes.GET("/notifications/preferences", notificationHandler.GetMyPreferences)
+			assistantRoutes.PUT("/notifications/preferences", notificationHandler.UpdateMyPreferences)
+
+			// Push notification device registration for the mobile app
+			assistantRoutes.GET("/devices", notificationHandler.GetMyDevices)
+			assistantRoutes.POST("/devices", notificationHandler.RegisterDevice)
+			assistantRoutes.DELETE("/devices", notificationHandler.UnregisterDevice)
 		}
 
 		// Student routes
@@ -277,11 +444,53 @@ func main() {
 			studentAttendanceHandler := handlers.NewStudentAttendanceHandler()
 			studentRoutes.GET("/attendance/active-sessions", studentAttendanceHandler.GetActiveAttendanceSessions)
 
+			// The device the student checks in with; check-ins from other devices are rejected
+			studentRoutes.GET("/attendance-device", studentDeviceHandler.GetMyDevice)
+			studentRoutes.POST("/attendance-device", studentDeviceHandler.RegisterMyDevice)
+			requireDevice := studentDeviceHandler.RequireRegisteredDevice
+
 			// Add new endpoint for QR code attendance submission
-			studentRoutes.POST("/attendance/qr-submit", studentAttendanceHandler.SubmitQRAttendance)
+			studentRoutes.POST("/attendance/qr-submit", requireDevice, studentAttendanceHandler.SubmitQRAttendance)
+
+			// Check in to a PIN session with the code the lecturer reads aloud
+			studentRoutes.POST("/attendance/pin-submit", requireDevice, studentAttendanceHandler.SubmitPINAttendance)
+
+			// Check in to a BEACON session with the identifier observed from the lecturer's device
+			studentRoutes.POST("/attendance/beacon-submit", requireDevice, studentAttendanceHandler.SubmitBeaconAttendance)
+
+			// Offline check-ins captured without connectivity and submitted later
+			studentRoutes.POST("/attendance/offline-key", requireDevice, offlineAttendanceHandler.IssueSigningKey)
+			studentRoutes.POST("/attendance/offline-batch", requireDevice, offlineAttendanceHandler.SubmitOfflineBatch)
 
 			// Add new endpoint for attendance history
 			studentRoutes.GET("/attendance/history", studentAttendanceHandler.GetAttendanceHistory)
+
+			// Own attendance statistics, grouped by course by default
+			studentRoutes.GET("/attendance/statistics", attendanceStatisticsHandler.GetStudentBreakdown)
+
+			// Student's attendance recap across all courses of an academic year
+			studentRoutes.GET("/attendance/recap", attendanceRecapHandler.GetMyRecap)
+
+			// Real-time stream of session openings and closings for the student's groups
+			studentRoutes.GET("/attendance/events", attendanceEventHandler.StreamStudentEvents)
+
+			// Lecture journals awaiting the class representative's co-signature
+			studentLectureJournalHandler := handlers.NewStudentLectureJournalHandler()
+			studentRoutes.GET("/journals/pending", studentLectureJournalHandler.GetPendingCosigns)
+			studentRoutes.POST("/journals/:id/cosign", studentLectureJournalHandler.CosignJournal)
+
+			// Campus events of the student's audience and QR check-in
+			studentRoutes.GET("/events", campusEventHandler.GetStudentEvents)
+			studentRoutes.POST("/events/:id/check-in", requireDevice, campusEventHandler.CheckIn)
+
+			// Email notification preferences
+			studentRoutes.GET("/notifications/preferences", notificationHandler.GetMyPreferences)
+			studentRoutes.PUT("/notifications/preferences", notificationHandler.UpdateMyPreferences)
+
+			// Push notification device registration for the mobile app
+			studentRoutes.GET("/devices", notificationHandler.GetMyDevices)
+			studentRoutes.POST("/devices", notificationHandler.RegisterDevice)
+			studentRoutes.DELETE("/devices", notificationHandler.UnregisterDevice)
 		}
 	}
 
@@ -290,6 +499,8 @@ func main() {
 
 	// Add public endpoints
 	router.GET("/api/students/by-user-id/:user_id", studentHandler.GetStudentByUserID)
+	router.GET("/api/attendance-sheets/verify", attendanceSheetHandler.VerifySheet)
+	router.GET("/api/reports/jobs/:id/download", reportJobHandler.DownloadArtifact)
 
 	log.Printf("Server running on port %s", port)
 	err = router.Run(":" + port)
@@ -298,3 +509,68 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// registerAttendanceSessionRoutes registers the attendance session API of one actor. Every actor
+// gets the same routes; its attendance policy decides which of them it may use.
+func registerAttendanceSessionRoutes(routes *gin.RouterGroup, h *handlers.AttendanceHandler) {
+	routes.GET("/attendance/policy", h.GetPolicy)
+	routes.POST("/attendance/sessions", h.CreateAttendanceSession)
+	routes.GET("/attendance/sessions/active", h.GetActiveAttendanceSessions)
+	routes.GET("/attendance/sessions", h.GetAttendanceSessions)
+	routes.GET("/attendance/sessions/:id", h.GetAttendanceSessionDetails)
+	routes.PUT("/attendance/sessions/:id/close", h.CloseAttendanceSession)
+	routes.PUT("/attendance/sessions/:id/cancel", h.CancelAttendanceSession)
+	routes.PUT("/attendance/sessions/:id/reopen", h.ReopenAttendanceSession)
+	routes.GET("/attendance/sessions/:id/history", h.GetAttendanceSessionHistory)
+	routes.GET("/attendance/sessions/:id/students", h.GetStudentAttendances)
+	routes.PUT("/attendance/sessions/:id/students", h.BulkMarkStudentAttendance)
+	routes.PUT("/attendance/sessions/:id/students/:studentId", h.MarkStudentAttendance)
+	routes.GET("/attendance/sessions/:id/report", h.DownloadAttendanceReport)
+	routes.GET("/attendance/statistics/course/:courseScheduleId", h.GetAttendanceStatistics)
+	routes.GET("/attendance/qrcode/:id", h.GetQRCode)
+	routes.GET("/attendance/sessions/:id/pin", h.GetPIN)
+	routes.GET("/attendance/sessions/:id/beacon", h.GetBeacon)
+	routes.GET("/attendance/fraud-flags", h.GetFraudFlags)
+	routes.GET("/attendance/sessions/:id/fraud-flags", h.GetSessionFraudFlags)
+	routes.PUT("/attendance/fraud-flags/:id/review", h.ReviewFraudFlag)
+}
+
+// registerLectureJournalRoutes registers the lecture journal and lesson plan API of one actor; what
+// each actor may do is decided by its attendance policy
+func registerLectureJournalRoutes(routes *gin.RouterGroup, h *handlers.LectureJournalHandler) {
+	routes.GET("/journals", h.GetJournals)
+	routes.POST("/journals", h.CreateJournal)
+	routes.GET("/journals/:id", h.GetJournal)
+	routes.PUT("/journals/:id", h.UpdateJournal)
+	routes.PUT("/journals/:id/sign", h.SignJournal)
+	routes.POST("/journals/:id/attachments", h.AddAttachment)
+	routes.GET("/journals/:id/attachments/:attachmentId", h.DownloadAttachment)
+	routes.DELETE("/journals/:id/attachments/:attachmentId", h.DeleteAttachment)
+	routes.GET("/courses/:id/lesson-plan", h.GetLessonPlan)
+	routes.PUT("/courses/:id/lesson-plan", h.ReplaceLessonPlan)
+	routes.GET("/courses/:id/lesson-plan/coverage", h.GetLessonPlanCoverage)
+}
+
+// registerCampusEventRoutes registers the campus event API of an organizer role; admins manage every
+// event and other organizers the events they created
+func registerCampusEventRoutes(routes *gin.RouterGroup, h *handlers.CampusEventHandler) {
+	routes.GET("/events", h.GetEvents)
+	routes.POST("/events", h.CreateEvent)
+	routes.GET("/events/:id", h.GetEvent)
+	routes.PUT("/events/:id", h.UpdateEvent)
+	routes.PUT("/events/:id/open", h.OpenCheckIn)
+	routes.PUT("/events/:id/close", h.CloseCheckIn)
+	routes.PUT("/events/:id/cancel", h.CancelEvent)
+	routes.GET("/events/:id/qrcode", h.GetQRCode)
+	routes.GET("/events/:id/attendances", h.GetAttendances)
+	routes.PUT("/events/:id/attendances/:studentId", h.MarkAttendance)
+	routes.GET("/events/:id/report", h.DownloadReport)
+}
+
+// registerRollCallRoutes registers the dormitory roll call API; roll calls themselves are campus
+// events managed through the event routes
+func registerRollCallRoutes(routes *gin.RouterGroup, h *handlers.CampusEventHandler) {
+	routes.GET("/roll-calls/dormitories", h.GetDormitories)
+	routes.POST("/roll-calls", h.ScheduleRollCalls)
+	routes.GET("/roll-calls/absences", h.GetRollCallAbsences)
+}
@@ -19,13 +19,6 @@ var DB *gorm.DB
 func Initialize() {
 	var err error
 
-	// Get database connection details from environment variables
-	host := os.Getenv("DB_HOST")
-	port := os.Getenv("DB_PORT")
-	user := os.Getenv("DB_USER")
-	password := os.Getenv("DB_PASSWORD")
-	dbname := os.Getenv("DB_NAME")
-
 	// Configure GORM logger
 	newLogger := logger.New(
 		log.New(os.Stdout, "\r\n", log.LstdFlags), // io writer
@@ -38,12 +31,8 @@ func Initialize() {
 		},
 	)
 
-	// Create connection string
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable TimeZone=UTC",
-		host, port, user, password, dbname)
-
 	// Connect to database
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
+	DB, err = gorm.Open(postgres.Open(DSN()), &gorm.Config{
 		Logger:                                   newLogger,
 		DisableForeignKeyConstraintWhenMigrating: true, // Disable foreign key checks during migrations
 	})
@@ -230,9 +219,99 @@ func Initialize() {
 	}
 	log.Println("StudentFace table migrated successfully")
 
+	// Migrate the webhook models for outbound event delivery
+	err = DB.AutoMigrate(&models.WebhookEndpoint{}, &models.WebhookDelivery{})
+	if err != nil {
+		log.Fatalf("Error auto-migrating Webhook models: %v\n", err)
+	}
+	log.Println("Webhook tables migrated successfully")
+
+	// Migrate the notification models for user preferences, the send log and push device tokens
+	err = DB.AutoMigrate(&models.NotificationPreference{}, &models.NotificationLog{}, &models.DeviceToken{})
+	if err != nil {
+		log.Fatalf("Error auto-migrating Notification models: %v\n", err)
+	}
+	log.Println("Notification tables migrated successfully")
+
+	// Migrate the offline check-in model for attendance captured without connectivity
+	err = DB.AutoMigrate(&models.OfflineCheckIn{})
+	if err != nil {
+		log.Fatalf("Error auto-migrating OfflineCheckIn model: %v\n", err)
+	}
+	log.Println("OfflineCheckIn table migrated successfully")
+
+	// Migrate the academic advisor model used for per-student attendance recaps
+	err = DB.AutoMigrate(&models.AcademicAdvisor{})
+	if err != nil {
+		log.Fatalf("Error auto-migrating AcademicAdvisor model: %v\n", err)
+	}
+	log.Println("AcademicAdvisor table migrated successfully")
+
+	// Migrate the report job model for background report exports
+	err = DB.AutoMigrate(&models.ReportJob{})
+	if err != nil {
+		log.Fatalf("Error auto-migrating ReportJob model: %v\n", err)
+	}
+	log.Println("ReportJob table migrated successfully")
+
+	// Migrate the attendance override model, the audit trail of admin attendance changes
+	err = DB.AutoMigrate(&models.AttendanceOverride{})
+	if err != nil {
+		log.Fatalf("Error auto-migrating AttendanceOverride model: %v\n", err)
+	}
+	log.Println("AttendanceOverride table migrated successfully")
+
+	// Migrate the lesson plan (RPS) and lecture journal (BAP) models
+	err = DB.AutoMigrate(&models.LessonPlanTopic{}, &models.LectureJournal{}, &models.LectureJournalAttachment{})
+	if err != nil {
+		log.Fatalf("Error auto-migrating lecture journal models: %v\n", err)
+	}
+	log.Println("Lecture journal tables migrated successfully")
+
+	// Migrate the campus event models for attendance outside courses
+	err = DB.AutoMigrate(&models.CampusEvent{}, &models.CampusEventParticipant{}, &models.CampusEventAttendance{})
+	if err != nil {
+		log.Fatalf("Error auto-migrating campus event models: %v\n", err)
+	}
+	log.Println("Campus event tables migrated successfully")
+
+	// Migrate the wrong-code attempts of PIN sessions
+	err = DB.AutoMigrate(&models.AttendancePINAttempt{})
+	if err != nil {
+		log.Fatalf("Error auto-migrating AttendancePINAttempt model: %v\n", err)
+	}
+	log.Println("AttendancePINAttempt table migrated successfully")
+
+	// Migrate the devices students check in with and the flags of suspected proxy attendance
+	err = DB.AutoMigrate(&models.StudentDevice{}, &models.AttendanceFraudFlag{})
+	if err != nil {
+		log.Fatalf("Error auto-migrating device binding models: %v\n", err)
+	}
+	log.Println("Device binding and fraud flag tables migrated successfully")
+
+	// Migrate the academic year rollovers and the copies they made
+	err = DB.AutoMigrate(&models.AcademicYearRollover{}, &models.AcademicYearRolloverMapping{})
+	if err != nil {
+		log.Fatalf("Error auto-migrating academic year rollover models: %v\n", err)
+	}
+	log.Println("Academic year rollover tables migrated successfully")
+
 	log.Println("Database schema migrated successfully")
 }
 
+// DSN builds the PostgreSQL connection string from environment variables
+func DSN() string {
+	// Get database connection details from environment variables
+	host := os.Getenv("DB_HOST")
+	port := os.Getenv("DB_PORT")
+	user := os.Getenv("DB_USER")
+	password := os.Getenv("DB_PASSWORD")
+	dbname := os.Getenv("DB_NAME")
+
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable TimeZone=UTC",
+		host, port, user, password, dbname)
+}
+
 // Close closes the database connection
 func Close() {
 	if DB != nil {
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/delpresence/backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// AcademicYearRolloverHandler handles copying a previous academic year's setup into a new one
+type AcademicYearRolloverHandler struct {
+	service *services.AcademicYearRolloverService
+}
+
+// NewAcademicYearRolloverHandler creates a new academic year rollover handler
+func NewAcademicYearRolloverHandler() *AcademicYearRolloverHandler {
+	return &AcademicYearRolloverHandler{
+		service: services.NewAcademicYearRolloverService(),
+	}
+}
+
+// PreviewRollover lists what a rollover into the academic year would create, find already copied,
+// skip or conflict with, without changing anything
+func (h *AcademicYearRolloverHandler) PreviewRollover(c *gin.Context) {
+	options, ok := bindRolloverOptions(c)
+	if !ok {
+		return
+	}
+
+	preview, err := h.service.Preview(options)
+	if err != nil {
+		c.JSON(rolloverErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Rollover preview generated successfully",
+		"data":    preview,
+	})
+}
+
+// CommitRollover performs a rollover into the academic year. Conflicts are answered with 409 and
+// the preview listing them.
+func (h *AcademicYearRolloverHandler) CommitRollover(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	options, ok := bindRolloverOptions(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.Commit(options, userID)
+	if err != nil {
+		if errors.Is(err, services.ErrRolloverConflicts) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "data": result})
+			return
+		}
+		c.JSON(rolloverErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Rollover committed successfully",
+		"data":    result,
+	})
+}
+
+// GetRollovers lists the rollovers committed into the academic year
+func (h *AcademicYearRolloverHandler) GetRollovers(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid academic year ID"})
+		return
+	}
+
+	rollovers, err := h.service.ListRollovers(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Rollovers retrieved successfully",
+		"data":    rollovers,
+	})
+}
+
+// bindRolloverOptions reads the rollover options from the request, with the target academic year
+// from the URL
+func bindRolloverOptions(c *gin.Context) (services.RolloverOptions, bool) {
+	var options services.RolloverOptions
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid academic year ID"})
+		return options, false
+	}
+	if err := c.ShouldBindJSON(&options); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return options, false
+	}
+	options.TargetAcademicYearID = uint(id)
+	return options, true
+}
+
+// rolloverErrorStatus maps rollover errors to a status code
+func rolloverErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidRollover):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrRolloverConflicts):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/delpresence/backend/internal/models"
+	"github.com/delpresence/backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// AttendanceAnalyticsHandler handles admin attendance analytics requests
+type AttendanceAnalyticsHandler struct {
+	service *services.AttendanceAnalyticsService
+}
+
+// NewAttendanceAnalyticsHandler creates a new attendance analytics handler
+func NewAttendanceAnalyticsHandler() *AttendanceAnalyticsHandler {
+	return &AttendanceAnalyticsHandler{
+		service: services.NewAttendanceAnalyticsService(),
+	}
+}
+
+// GetOverview returns the headline attendance figures of an academic year
+func (h *AttendanceAnalyticsHandler) GetOverview(c *gin.Context) {
+	academicYear, ok := h.resolveAcademicYear(c)
+	if !ok {
+		return
+	}
+
+	overview, err := h.service.GetOverview(academicYear)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Attendance overview retrieved successfully",
+		"data":    overview,
+	})
+}
+
+// GetBreakdown returns the attendance rate of an academic year grouped by a dimension
+func (h *AttendanceAnalyticsHandler) GetBreakdown(c *gin.Context) {
+	breakdown, err := services.ParseAttendanceBreakdown(c.Param("dimension"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	academicYear, ok := h.resolveAcademicYear(c)
+	if !ok {
+		return
+	}
+
+	items, err := h.service.GetBreakdown(academicYear, breakdown)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Attendance breakdown retrieved successfully",
+		"data": gin.H{
+			"academic_year": academicYear,
+			"group_by":      breakdown,
+			"items":         items,
+		},
+	})
+}
+
+// GetWeeklyTrend returns attendance per week of an academic year
+func (h *AttendanceAnalyticsHandler) GetWeeklyTrend(c *gin.Context) {
+	academicYear, ok := h.resolveAcademicYear(c)
+	if !ok {
+		return
+	}
+
+	points, err := h.service.GetWeeklyTrend(academicYear)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Weekly attendance trend retrieved successfully",
+		"data": gin.H{
+			"academic_year": academicYear,
+			"weeks":         points,
+		},
+	})
+}
+
+// GetLateArrivals returns how late students arrive when they are marked late
+func (h *AttendanceAnalyticsHandler) GetLateArrivals(c *gin.Context) {
+	academicYear, ok := h.resolveAcademicYear(c)
+	if !ok {
+		return
+	}
+
+	buckets, err := h.service.GetLateArrivalDistribution(academicYear)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Late arrival distribution retrieved successfully",
+		"data": gin.H{
+			"academic_year": academicYear,
+			"buckets":       buckets,
+		},
+	})
+}
+
+// GetPlannedCoverage returns how many planned meetings never had a session opened
+func (h *AttendanceAnalyticsHandler) GetPlannedCoverage(c *gin.Context) {
+	academicYear, ok := h.resolveAcademicYear(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.service.GetPlannedCoverage(academicYear)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Planned session coverage retrieved successfully",
+		"data": gin.H{
+			"academic_year": academicYear,
+			"coverage":      report,
+		},
+	})
+}
+
+// GetVerificationMethods returns the mix of verification methods used for check-ins
+func (h *AttendanceAnalyticsHandler) GetVerificationMethods(c *gin.Context) {
+	academicYear, ok := h.resolveAcademicYear(c)
+	if !ok {
+		return
+	}
+
+	methods, err := h.service.GetVerificationMix(academicYear)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Verification method mix retrieved successfully",
+		"data": gin.H{
+			"academic_year": academicYear,
+			"methods":       methods,
+		},
+	})
+}
+
+// resolveAcademicYear reads academic_year_id from the query, defaulting to the active academic year
+func (h *AttendanceAnalyticsHandler) resolveAcademicYear(c *gin.Context) (*models.AcademicYear, bool) {
+	academicYearID, ok := parseAcademicYearQuery(c)
+	if !ok {
+		return nil, false
+	}
+
+	academicYear, err := h.service.ResolveAcademicYear(academicYearID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	return academicYear, true
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/delpresence/backend/internal/realtime"
+	"github.com/delpresence/backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// heartbeatInterval keeps idle event streams open through proxies and load balancers
+const heartbeatInterval = 25 * time.Second
+
+// AttendanceEventHandler streams real-time attendance events over Server-Sent Events
+type AttendanceEventHandler struct {
+	attendanceService *services.AttendanceService
+}
+
+// NewAttendanceEventHandler creates a new attendance event handler
+func NewAttendanceEventHandler() *AttendanceEventHandler {
+	return &AttendanceEventHandler{
+		attendanceService: services.NewAttendanceService(),
+	}
+}
+
+// StreamSessionEvents streams check-ins, manual status changes and lifecycle events of a session
+// to the lecturer or teaching assistant running it
+func (h *AttendanceEventHandler) StreamSessionEvents(c *gin.Context) {
+	// Extract lecturer or assistant ID from authenticated user
+	userID := c.MustGet("userID").(uint)
+
+	// Extract session ID from URL
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	sessionService, err := services.NewAttendanceSessionServiceForRole(c.MustGet("role").(string))
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Verify access and get the current state so the client can render before the first event
+	session, err := sessionService.GetSession(uint(sessionID), userID)
+	if err != nil {
+		c.JSON(sessionErrorStatus(err, http.StatusNotFound), gin.H{"error": err.Error()})
+		return
+	}
+
+	sub, err := realtime.Subscribe(realtime.SessionTopic(uint(sessionID)))
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	defer sub.Close()
+
+	streamEvents(c, sub, "snapshot", session)
+}
+
+// StreamStudentEvents streams session lifecycle events for all groups of the authenticated student,
+// so the mobile app learns immediately when a session opens
+func (h *AttendanceEventHandler) StreamStudentEvents(c *gin.Context) {
+	// Extract student ID from the authenticated user
+	userID := c.MustGet("userID").(uint)
+
+	groupIDs, err := h.attendanceService.GetStudentGroupIDsByExternalID(userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"status": "error",
+			"error":  fmt.Sprintf("Failed to resolve student groups: %v", err),
+		})
+		return
+	}
+
+	topics := make([]string, 0, len(groupIDs))
+	for _, groupID := range groupIDs {
+		topics = append(topics, realtime.StudentGroupTopic(groupID))
+	}
+
+	sub, err := realtime.Subscribe(topics...)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status": "error",
+			"error":  err.Error(),
+		})
+		return
+	}
+	defer sub.Close()
+
+	streamEvents(c, sub, "subscribed", gin.H{"student_group_ids": groupIDs})
+}
+
+// streamEvents writes an initial event followed by every message of the subscription
+// until the client disconnects
+func streamEvents(c *gin.Context, sub *realtime.Subscription, initialEvent string, initialData interface{}) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Disable response buffering in nginx
+
+	c.SSEvent(initialEvent, initialData)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case msg, ok := <-sub.Messages():
+			if !ok {
+				return false
+			}
+			c.SSEvent(msg.Event, msg.Data)
+			return true
+		case <-heartbeat.C:
+			c.SSEvent("ping", gin.H{"time": services.GetIndonesiaTime()})
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
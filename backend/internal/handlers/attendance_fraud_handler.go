@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/delpresence/backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// AttendanceFraudHandler handles the proxy-attendance analysis
+type AttendanceFraudHandler struct {
+	service *services.AttendanceFraudService
+}
+
+// NewAttendanceFraudHandler creates a new attendance fraud handler
+func NewAttendanceFraudHandler() *AttendanceFraudHandler {
+	return &AttendanceFraudHandler{
+		service: services.NewAttendanceFraudService(),
+	}
+}
+
+// RunAnalysis analyzes the check-ins of the last hours (default 24, at most 720) right away
+// instead of waiting for the background job
+func (h *AttendanceFraudHandler) RunAnalysis(c *gin.Context) {
+	hours := 24
+	if value := c.Query("hours"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 720 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "hours must be between 1 and 720"})
+			return
+		}
+		hours = parsed
+	}
+
+	created, err := h.service.AnalyzeSince(services.GetIndonesiaTime().Add(-time.Duration(hours) * time.Hour))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Attendance fraud analysis completed",
+		"data":    gin.H{"hours": hours, "flags_created": created},
+	})
+}
@@ -1,34 +1,55 @@
 package handlers
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"image/png"
 	"net/http"
-	"regexp"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/delpresence/backend/internal/models"
+	"github.com/delpresence/backend/internal/qrcode"
 	"github.com/delpresence/backend/internal/services"
 	"github.com/gin-gonic/gin"
-	"github.com/tealeg/xlsx/v3"
+	"gorm.io/gorm"
 )
 
-// AttendanceHandler handles attendance-related API requests
+// AttendanceHandler handles the attendance session API of one actor (lecturer, teaching
+// assistant, admin, ...). What the actor may do comes from its services.AttendanceActorPolicy.
 type AttendanceHandler struct {
-	attendanceService *services.AttendanceService
+	service *services.AttendanceSessionService
+
+	// envelope wraps responses as {"status", "data"}. The lecturer API predates the envelope
+	// and keeps its unwrapped responses for existing clients.
+	envelope bool
 }
 
-// NewAttendanceHandler creates a new attendance handler
+// NewAttendanceHandler creates the attendance handler of the lecturer API
 func NewAttendanceHandler() *AttendanceHandler {
 	return &AttendanceHandler{
-		attendanceService: services.NewAttendanceService(),
+		service: services.NewAttendanceSessionService(services.AttendanceActorLecturer),
+	}
+}
+
+// NewActorAttendanceHandler creates the attendance handler of any other actor
+func NewActorAttendanceHandler(actor services.AttendanceActor) *AttendanceHandler {
+	return &AttendanceHandler{
+		service:  services.NewAttendanceSessionService(actor),
+		envelope: true,
 	}
 }
 
+// GetPolicy returns what the authenticated actor may do with attendance sessions,
+// so clients can hide actions that would be refused
+func (h *AttendanceHandler) GetPolicy(c *gin.Context) {
+	h.respond(c, h.service.Policy())
+}
+
 // CreateAttendanceSession creates a new attendance session for a course schedule
 func (h *AttendanceHandler) CreateAttendanceSession(c *gin.Context) {
-	// Extract lecturer ID from authenticated user
+	// Extract user ID from authenticated user
 	userID := c.MustGet("userID").(uint)
 
 	// Parse request
@@ -40,14 +61,14 @@ func (h *AttendanceHandler) CreateAttendanceSession(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		h.respondError(c, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
 	// Convert date string to time.Time
 	date, err := time.Parse("2006-01-02", req.Date)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format, use YYYY-MM-DD"})
+		h.respondError(c, http.StatusBadRequest, "Invalid date format, use YYYY-MM-DD")
 		return
 	}
 
@@ -62,54 +83,51 @@ func (h *AttendanceHandler) CreateAttendanceSession(c *gin.Context) {
 		attendanceType = models.AttendanceTypeManual
 	case "BOTH":
 		attendanceType = models.AttendanceTypeBoth
+	case "PIN":
+		attendanceType = models.AttendanceTypePIN
+	case "BEACON":
+		attendanceType = models.AttendanceTypeBeacon
 	default:
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attendance type"})
+		h.respondError(c, http.StatusBadRequest, "Invalid attendance type")
 		return
 	}
 
 	// Create the session
-	session, err := h.attendanceService.CreateAttendanceSession(userID, req.CourseScheduleID, date, attendanceType, req.Settings)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// Get the response format
-	response, err := h.attendanceService.GetSessionDetails(session.ID, userID)
+	session, err := h.service.OpenSession(userID, req.CourseScheduleID, date, attendanceType, req.Settings)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Session created but error retrieving details"})
+		h.respondError(c, sessionErrorStatus(err, http.StatusBadRequest), err.Error())
 		return
 	}
 
 	// Return the session details
-	c.JSON(http.StatusOK, response)
+	h.respond(c, session)
 }
 
-// GetActiveAttendanceSessions gets all active attendance sessions for the authenticated lecturer
+// GetActiveAttendanceSessions gets all active attendance sessions visible to the authenticated user
 func (h *AttendanceHandler) GetActiveAttendanceSessions(c *gin.Context) {
-	// Extract lecturer ID from authenticated user
+	// Extract user ID from authenticated user
 	userID := c.MustGet("userID").(uint)
 
 	// Get active sessions
-	sessions, err := h.attendanceService.GetActiveSessionsForUser(userID)
+	sessions, err := h.service.ListActiveSessions(userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(sessionErrorStatus(err, http.StatusInternalServerError), gin.H{
 			"status":  "error",
 			"message": "Failed to get active sessions",
 		})
 		return
 	}
 
-	// Return sessions
+	// Return sessions, always wrapped since both original APIs wrapped this endpoint
 	c.JSON(http.StatusOK, gin.H{
 		"status": "success",
 		"data":   sessions,
 	})
 }
 
-// GetAttendanceSessions gets attendance sessions for the authenticated lecturer within a date range
+// GetAttendanceSessions gets attendance sessions visible to the authenticated user within a date range
 func (h *AttendanceHandler) GetAttendanceSessions(c *gin.Context) {
-	// Extract lecturer ID from authenticated user
+	// Extract user ID from authenticated user
 	userID := c.MustGet("userID").(uint)
 
 	// Parse query parameters
@@ -127,13 +145,13 @@ func (h *AttendanceHandler) GetAttendanceSessions(c *gin.Context) {
 	// Parse dates
 	startDate, err := time.Parse("2006-01-02", startDateStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date format, use YYYY-MM-DD"})
+		h.respondError(c, http.StatusBadRequest, "Invalid start_date format, use YYYY-MM-DD")
 		return
 	}
 
 	endDate, err := time.Parse("2006-01-02", endDateStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date format, use YYYY-MM-DD"})
+		h.respondError(c, http.StatusBadRequest, "Invalid end_date format, use YYYY-MM-DD")
 		return
 	}
 
@@ -141,121 +159,184 @@ func (h *AttendanceHandler) GetAttendanceSessions(c *gin.Context) {
 	endDate = endDate.Add(24*time.Hour - time.Second)
 
 	// Get sessions
-	sessions, err := h.attendanceService.GetSessionsByDateRange(userID, startDate, endDate)
+	sessions, err := h.service.ListSessions(userID, startDate, endDate)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.respondError(c, sessionErrorStatus(err, http.StatusInternalServerError), err.Error())
 		return
 	}
 
 	// Return sessions
-	c.JSON(http.StatusOK, sessions)
+	h.respond(c, sessions)
 }
 
 // GetAttendanceSessionDetails gets detailed information for a specific attendance session
 func (h *AttendanceHandler) GetAttendanceSessionDetails(c *gin.Context) {
-	// Extract lecturer ID from authenticated user
 	userID := c.MustGet("userID").(uint)
 
-	// Extract session ID from URL
-	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+	sessionID, ok := h.parseSessionID(c)
+	if !ok {
 		return
 	}
 
 	// Get session details
-	session, err := h.attendanceService.GetSessionDetails(uint(sessionID), userID)
+	session, err := h.service.GetSession(sessionID, userID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		h.respondError(c, sessionErrorStatus(err, http.StatusNotFound), err.Error())
 		return
 	}
 
 	// Return session details
-	c.JSON(http.StatusOK, session)
+	h.respond(c, session)
 }
 
 // CloseAttendanceSession closes an active attendance session
 func (h *AttendanceHandler) CloseAttendanceSession(c *gin.Context) {
-	// Extract lecturer ID from authenticated user
 	userID := c.MustGet("userID").(uint)
 
-	// Extract session ID from URL
-	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+	sessionID, ok := h.parseSessionID(c)
+	if !ok {
 		return
 	}
 
 	// Close the session
-	if err := h.attendanceService.CloseAttendanceSession(uint(sessionID), userID); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := h.service.CloseSession(sessionID, userID); err != nil {
+		h.respondError(c, sessionErrorStatus(err, http.StatusBadRequest), err.Error())
 		return
 	}
 
 	// Return success response
-	c.JSON(http.StatusOK, gin.H{"message": "Attendance session closed successfully"})
+	h.respondMessage(c, "Attendance session closed successfully")
 }
 
 // CancelAttendanceSession cancels an active attendance session
 func (h *AttendanceHandler) CancelAttendanceSession(c *gin.Context) {
-	// Extract lecturer ID from authenticated user
 	userID := c.MustGet("userID").(uint)
 
-	// Extract session ID from URL
-	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+	sessionID, ok := h.parseSessionID(c)
+	if !ok {
 		return
 	}
 
 	// Cancel the session
-	if err := h.attendanceService.CancelAttendanceSession(uint(sessionID), userID); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := h.service.CancelSession(sessionID, userID); err != nil {
+		h.respondError(c, sessionErrorStatus(err, http.StatusBadRequest), err.Error())
 		return
 	}
 
 	// Return success response
-	c.JSON(http.StatusOK, gin.H{"message": "Attendance session canceled successfully"})
+	h.respondMessage(c, "Attendance session canceled successfully")
+}
+
+// BulkMarkStudentAttendance marks many students of a session in one transaction
+func (h *AttendanceHandler) BulkMarkStudentAttendance(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	sessionID, ok := h.parseSessionID(c)
+	if !ok {
+		return
+	}
+
+	var input services.BulkMarkInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		h.respondError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	result, err := h.service.BulkMarkStudentAttendance(sessionID, userID, input)
+	if errors.Is(err, services.ErrBulkMarkRejected) {
+		// Return the row results so the client can show which rows to fix
+		if h.envelope {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"status": "error", "message": err.Error(), "data": result})
+		} else {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "result": result})
+		}
+		return
+	}
+	if err != nil {
+		h.respondError(c, sessionErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+
+	h.respond(c, result)
+}
+
+// ReopenAttendanceSession reopens a closed session for a few minutes
+func (h *AttendanceHandler) ReopenAttendanceSession(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	sessionID, ok := h.parseSessionID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Minutes int    `json:"minutes"`
+		Reason  string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	session, err := h.service.ReopenSession(sessionID, userID, req.Minutes, req.Reason)
+	if err != nil {
+		h.respondError(c, sessionErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+
+	h.respond(c, session)
+}
+
+// GetAttendanceSessionHistory lists the corrections, reopenings and admin overrides of a session
+func (h *AttendanceHandler) GetAttendanceSessionHistory(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	sessionID, ok := h.parseSessionID(c)
+	if !ok {
+		return
+	}
+
+	history, err := h.service.GetSessionHistory(sessionID, userID)
+	if err != nil {
+		h.respondError(c, sessionErrorStatus(err, http.StatusInternalServerError), err.Error())
+		return
+	}
+
+	h.respond(c, history)
 }
 
 // GetStudentAttendances gets all student attendance records for a session
 func (h *AttendanceHandler) GetStudentAttendances(c *gin.Context) {
-	// Extract lecturer ID from authenticated user
 	userID := c.MustGet("userID").(uint)
 
-	// Extract session ID from URL
-	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+	sessionID, ok := h.parseSessionID(c)
+	if !ok {
 		return
 	}
 
 	// Get student attendances
-	attendances, err := h.attendanceService.GetStudentAttendances(uint(sessionID), userID)
+	attendances, err := h.service.GetStudentAttendances(sessionID, userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.respondError(c, sessionErrorStatus(err, http.StatusInternalServerError), err.Error())
 		return
 	}
 
 	// Return student attendances
-	c.JSON(http.StatusOK, attendances)
+	h.respond(c, attendances)
 }
 
 // MarkStudentAttendance marks a student's attendance for a session
 func (h *AttendanceHandler) MarkStudentAttendance(c *gin.Context) {
-	// Extract lecturer ID from authenticated user
 	userID := c.MustGet("userID").(uint)
 
-	// Extract session ID and student ID from URL
-	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+	sessionID, ok := h.parseSessionID(c)
+	if !ok {
 		return
 	}
 
 	studentID, err := strconv.ParseUint(c.Param("studentId"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student ID"})
+		h.respondError(c, http.StatusBadRequest, "Invalid student ID")
 		return
 	}
 
@@ -264,10 +345,11 @@ func (h *AttendanceHandler) MarkStudentAttendance(c *gin.Context) {
 		Status             string `json:"status"`
 		Notes              string `json:"notes"`
 		VerificationMethod string `json:"verification_method"`
+		Reason             string `json:"reason"` // Required to correct a closed session
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		h.respondError(c, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
@@ -283,363 +365,257 @@ func (h *AttendanceHandler) MarkStudentAttendance(c *gin.Context) {
 	case "EXCUSED":
 		status = models.StudentAttendanceStatusExcused
 	default:
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attendance status"})
+		h.respondError(c, http.StatusBadRequest, "Invalid attendance status")
 		return
 	}
 
 	// Mark student attendance
-	if err := h.attendanceService.MarkStudentAttendance(uint(sessionID), uint(studentID), status, req.VerificationMethod, req.Notes, &userID); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := h.service.MarkStudentAttendance(sessionID, uint(studentID), status, req.VerificationMethod, req.Notes, req.Reason, userID); err != nil {
+		h.respondError(c, sessionErrorStatus(err, http.StatusBadRequest), err.Error())
 		return
 	}
 
 	// Return success response
-	c.JSON(http.StatusOK, gin.H{"message": "Student attendance marked successfully"})
+	h.respondMessage(c, "Student attendance marked successfully")
 }
 
 // GetAttendanceStatistics gets attendance statistics for a course schedule
 func (h *AttendanceHandler) GetAttendanceStatistics(c *gin.Context) {
-	// Extract lecturer ID from authenticated user
 	userID := c.MustGet("userID").(uint)
 
 	// Extract course schedule ID from URL
 	courseScheduleID, err := strconv.ParseUint(c.Param("courseScheduleId"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course schedule ID"})
+		h.respondError(c, http.StatusBadRequest, "Invalid course schedule ID")
+		return
+	}
+
+	// Optional date range and breakdown filters
+	filter, err := parseStatsFilter(c)
+	if err != nil {
+		h.respondError(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
 	// Get attendance statistics
-	stats, err := h.attendanceService.GetAttendanceStatistics(uint(courseScheduleID), userID)
+	stats, err := h.service.GetScheduleStatistics(uint(courseScheduleID), userID, filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.respondError(c, sessionErrorStatus(err, http.StatusInternalServerError), err.Error())
 		return
 	}
 
 	// Return statistics
-	c.JSON(http.StatusOK, stats)
+	h.respond(c, stats)
 }
 
-// GetQRCode generates a QR code for an attendance session
+// GetQRCode returns the check-in QR code of a session as a PNG image, or its payload
+// with format=json for clients that render the code themselves
 func (h *AttendanceHandler) GetQRCode(c *gin.Context) {
-	// Extract lecturer ID from authenticated user
 	userID := c.MustGet("userID").(uint)
 
-	// Extract session ID from URL
-	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	sessionID, ok := h.parseSessionID(c)
+	if !ok {
+		return
+	}
+
+	payload, err := h.service.GetQRCodePayload(sessionID, userID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		h.respondError(c, sessionErrorStatus(err, http.StatusBadRequest), err.Error())
 		return
 	}
 
-	// Get session details to verify ownership and retrieve QR code data
-	session, err := h.attendanceService.GetSessionDetails(uint(sessionID), userID)
+	if c.Query("format") == "json" {
+		h.respond(c, gin.H{"qr_code_data": payload})
+		return
+	}
+
+	code, err := qrcode.Encode(payload)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		h.respondError(c, http.StatusInternalServerError, "Failed to generate QR code")
 		return
 	}
 
-	// Simple text representation for demo purposes
-	// In a real implementation, this would generate an actual QR code image
-	c.Header("Content-Type", "text/plain")
-	c.String(http.StatusOK, "QR Code for Session %d\nCourse: %s - %s\nTime: %s",
-		session.ID, session.CourseCode, session.CourseName, session.StartTime)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, code.Image(8)); err != nil {
+		h.respondError(c, http.StatusInternalServerError, "Failed to generate QR code")
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "image/png", buf.Bytes())
 }
 
-// DownloadAttendanceReport downloads attendance report as Excel file for a specific session
-func (h *AttendanceHandler) DownloadAttendanceReport(c *gin.Context) {
-	// Extract lecturer ID from authenticated user
+// GetPIN returns the current code of a PIN session for the lecturer to read aloud
+func (h *AttendanceHandler) GetPIN(c *gin.Context) {
 	userID := c.MustGet("userID").(uint)
 
-	// Extract session ID from URL
-	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+	sessionID, ok := h.parseSessionID(c)
+	if !ok {
 		return
 	}
 
-	// Get session details
-	session, err := h.attendanceService.GetSessionDetails(uint(sessionID), userID)
+	pin, err := h.service.GetCurrentPIN(sessionID, userID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		h.respondError(c, sessionErrorStatus(err, http.StatusBadRequest), err.Error())
 		return
 	}
 
-	// Get all student attendances for this session
-	attendances, err := h.attendanceService.GetStudentAttendances(uint(sessionID), userID)
+	c.Header("Cache-Control", "no-store")
+	h.respond(c, pin)
+}
+
+// GetBeacon returns the identifier the lecturer's device should broadcast for a BEACON session
+func (h *AttendanceHandler) GetBeacon(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	sessionID, ok := h.parseSessionID(c)
+	if !ok {
+		return
+	}
+
+	beacon, err := h.service.GetCurrentBeacon(sessionID, userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve attendance data"})
+		h.respondError(c, sessionErrorStatus(err, http.StatusBadRequest), err.Error())
 		return
 	}
 
-	// Create Excel file
-	file := xlsx.NewFile()
+	c.Header("Cache-Control", "no-store")
+	h.respond(c, beacon)
+}
+
+// GetFraudFlags lists the fraud flags of every session the user has access to, open flags unless
+// another status is asked for
+func (h *AttendanceHandler) GetFraudFlags(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
 
-	// Create main summary sheet
-	summarySheet, err := file.AddSheet("Summary")
+	flags, err := h.service.ListFraudQueue(userID, c.Query("status"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create Excel sheet"})
-		return
-	}
-
-	// Add title and styling
-	titleRow := summarySheet.AddRow()
-	titleCell := titleRow.AddCell()
-	titleCell.Value = fmt.Sprintf("LAPORAN PRESENSI MAHASISWA")
-	titleStyle := xlsx.NewStyle()
-	titleStyle.Font.Bold = true
-	titleStyle.Font.Size = 16
-	titleCell.SetStyle(titleStyle)
-
-	// Add course information
-	summarySheet.AddRow() // Empty row for spacing
-
-	courseRow := summarySheet.AddRow()
-	courseNameLabel := courseRow.AddCell()
-	courseNameLabel.Value = "Mata Kuliah"
-	courseNameValue := courseRow.AddCell()
-	courseNameValue.Value = fmt.Sprintf("%s - %s", session.CourseCode, session.CourseName)
-
-	dateRow := summarySheet.AddRow()
-	dateLabel := dateRow.AddCell()
-	dateLabel.Value = "Tanggal"
-	dateValue := dateRow.AddCell()
-	dateValue.Value = session.Date
-
-	timeRow := summarySheet.AddRow()
-	timeLabel := timeRow.AddCell()
-	timeLabel.Value = "Waktu"
-	timeValue := timeRow.AddCell()
-	timeValue.Value = fmt.Sprintf("%s - %s", session.StartTime, session.EndTime)
-
-	roomRow := summarySheet.AddRow()
-	roomLabel := roomRow.AddCell()
-	roomLabel.Value = "Ruangan"
-	roomValue := roomRow.AddCell()
-	roomValue.Value = session.Room
-
-	// Add statistics
-	summarySheet.AddRow() // Empty row for spacing
-
-	statsRow := summarySheet.AddRow()
-	statsLabel := statsRow.AddCell()
-	statsLabel.Value = "Statistik Kehadiran"
-	statsStyle := xlsx.NewStyle()
-	statsStyle.Font.Bold = true
-	statsLabel.SetStyle(statsStyle)
-
-	totalRow := summarySheet.AddRow()
-	totalLabel := totalRow.AddCell()
-	totalLabel.Value = "Total Mahasiswa"
-	totalValue := totalRow.AddCell()
-	totalValue.Value = fmt.Sprintf("%d", session.TotalStudents)
-
-	presentRow := summarySheet.AddRow()
-	presentLabel := presentRow.AddCell()
-	presentLabel.Value = "Hadir"
-	presentValue := presentRow.AddCell()
-	presentValue.Value = fmt.Sprintf("%d", session.AttendedCount)
-
-	lateRow := summarySheet.AddRow()
-	lateLabel := lateRow.AddCell()
-	lateLabel.Value = "Terlambat"
-	lateValue := lateRow.AddCell()
-	lateValue.Value = fmt.Sprintf("%d", session.LateCount)
-
-	absentRow := summarySheet.AddRow()
-	absentLabel := absentRow.AddCell()
-	absentLabel.Value = "Tidak Hadir"
-	absentValue := absentRow.AddCell()
-	absentValue.Value = fmt.Sprintf("%d", session.AbsentCount)
-
-	excusedRow := summarySheet.AddRow()
-	excusedLabel := excusedRow.AddCell()
-	excusedLabel.Value = "Izin"
-	excusedValue := excusedRow.AddCell()
-	excusedValue.Value = fmt.Sprintf("%d", session.ExcusedCount)
-
-	percentRow := summarySheet.AddRow()
-	percentLabel := percentRow.AddCell()
-	percentLabel.Value = "Persentase Kehadiran"
-	percentValue := percentRow.AddCell()
-
-	// Calculate attendance percentage
-	totalStudents := session.TotalStudents
-	if totalStudents > 0 {
-		attendancePercent := float64(session.AttendedCount+session.LateCount) / float64(totalStudents) * 100
-		percentValue.Value = fmt.Sprintf("%.2f%%", attendancePercent)
-	} else {
-		percentValue.Value = "N/A"
-	}
-
-	// Create detailed attendance sheet
-	detailSheet, err := file.AddSheet("Daftar Hadir")
+		h.respondError(c, sessionErrorStatus(err, http.StatusInternalServerError), err.Error())
+		return
+	}
+
+	h.respond(c, flags)
+}
+
+// GetSessionFraudFlags lists the fraud flags of a session, optionally filtered by status
+func (h *AttendanceHandler) GetSessionFraudFlags(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	sessionID, ok := h.parseSessionID(c)
+	if !ok {
+		return
+	}
+
+	flags, err := h.service.ListFraudFlags(sessionID, userID, c.Query("status"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create detail sheet"})
-		return
-	}
-
-	// Add table header with styling
-	headerRow := detailSheet.AddRow()
-
-	headerStyle := xlsx.NewStyle()
-	headerStyle.Font.Bold = true
-	headerStyle.Fill.PatternType = "solid"
-	headerStyle.Fill.BgColor = "C6E0B4" // Light green background
-	headerStyle.Alignment.Horizontal = "center"
-	headerStyle.Border.Left = "thin"
-	headerStyle.Border.Right = "thin"
-	headerStyle.Border.Top = "thin"
-	headerStyle.Border.Bottom = "thin"
-
-	headerCells := []string{"No", "NIM", "Nama Mahasiswa", "Status", "Waktu Presensi", "Metode Verifikasi", "Keterangan"}
-	for _, header := range headerCells {
-		cell := headerRow.AddCell()
-		cell.Value = header
-		cell.SetStyle(headerStyle)
-	}
-
-	// Add data rows
-	for i, attendance := range attendances {
-		row := detailSheet.AddRow()
-
-		// Style for data cells
-		dataStyle := xlsx.NewStyle()
-		dataStyle.Border.Left = "thin"
-		dataStyle.Border.Right = "thin"
-		dataStyle.Border.Top = "thin"
-		dataStyle.Border.Bottom = "thin"
-
-		// Status-specific styling
-		statusStyle := xlsx.NewStyle()
-		statusStyle.Border.Left = "thin"
-		statusStyle.Border.Right = "thin"
-		statusStyle.Border.Top = "thin"
-		statusStyle.Border.Bottom = "thin"
-		statusStyle.Font.Bold = true
-		statusStyle.Alignment.Horizontal = "center"
-
-		// Set row background color based on status
-		switch attendance.Status {
-		case "PRESENT":
-			statusStyle.Fill.PatternType = "solid"
-			statusStyle.Fill.BgColor = "C6EFCE" // Light green
-			statusStyle.Font.Color = "006100"   // Dark green
-		case "LATE":
-			statusStyle.Fill.PatternType = "solid"
-			statusStyle.Fill.BgColor = "FFEB9C" // Light yellow
-			statusStyle.Font.Color = "9C5700"   // Dark orange
-		case "ABSENT":
-			statusStyle.Fill.PatternType = "solid"
-			statusStyle.Fill.BgColor = "FFC7CE" // Light red
-			statusStyle.Font.Color = "9C0006"   // Dark red
-		case "EXCUSED":
-			statusStyle.Fill.PatternType = "solid"
-			statusStyle.Fill.BgColor = "DDEBF7" // Light blue
-			statusStyle.Font.Color = "2F75B5"   // Dark blue
-		}
+		h.respondError(c, sessionErrorStatus(err, http.StatusInternalServerError), err.Error())
+		return
+	}
 
-		// Add data cells
-		numCell := row.AddCell()
-		numCell.SetInt(i + 1)
-		numCell.SetStyle(dataStyle)
-
-		nimCell := row.AddCell()
-		nimCell.Value = attendance.StudentNIM
-		nimCell.SetStyle(dataStyle)
-
-		nameCell := row.AddCell()
-		nameCell.Value = attendance.StudentName
-		nameCell.SetStyle(dataStyle)
-
-		statusCell := row.AddCell()
-
-		// Translate status to Indonesian
-		statusText := ""
-		switch attendance.Status {
-		case "PRESENT":
-			statusText = "Hadir"
-		case "LATE":
-			statusText = "Terlambat"
-		case "ABSENT":
-			statusText = "Tidak Hadir"
-		case "EXCUSED":
-			statusText = "Izin"
-		default:
-			statusText = attendance.Status
-		}
+	h.respond(c, flags)
+}
 
-		statusCell.Value = statusText
-		statusCell.SetStyle(statusStyle)
-
-		timeCell := row.AddCell()
-		timeCell.Value = attendance.CheckInTime
-		timeCell.SetStyle(dataStyle)
-
-		methodCell := row.AddCell()
-
-		// Translate verification method to Indonesian
-		methodText := ""
-		switch attendance.VerificationMethod {
-		case "QR_CODE":
-			methodText = "Kode QR"
-		case "FACE_RECOGNITION":
-			methodText = "Pengenalan Wajah"
-		case "MANUAL":
-			methodText = "Manual"
-		default:
-			methodText = attendance.VerificationMethod
-		}
+// ReviewFraudFlag confirms or dismisses a fraud flag; confirming marks the student absent
+func (h *AttendanceHandler) ReviewFraudFlag(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
 
-		methodCell.Value = methodText
-		methodCell.SetStyle(dataStyle)
+	flagID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		h.respondError(c, http.StatusBadRequest, "Invalid flag ID")
+		return
+	}
 
-		noteCell := row.AddCell()
-		noteCell.Value = attendance.Notes
-		noteCell.SetStyle(dataStyle)
+	var req struct {
+		Confirm *bool  `json:"confirm" binding:"required"`
+		Notes   string `json:"notes"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondError(c, http.StatusBadRequest, "Invalid request body")
+		return
 	}
 
-	// Auto-size columns for better readability
-	detailSheet.SetColWidth(1, 1, 5)  // No
-	detailSheet.SetColWidth(2, 2, 15) // NIM
-	detailSheet.SetColWidth(3, 3, 30) // Name
-	detailSheet.SetColWidth(4, 4, 15) // Status
-	detailSheet.SetColWidth(5, 5, 20) // Check-in time
-	detailSheet.SetColWidth(6, 6, 20) // Verification method
-	detailSheet.SetColWidth(7, 7, 30) // Notes
+	flag, err := h.service.ReviewFraudFlag(uint(flagID), userID, *req.Confirm, req.Notes)
+	if err != nil {
+		h.respondError(c, sessionErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
 
-	// Set filename with course code, course name and date
-	filename := fmt.Sprintf("Presensi_%s_%s_%s.xlsx",
-		session.CourseCode,
-		formatFilename(session.CourseName),
-		formatFilename(session.Date))
+	h.respond(c, flag)
+}
+
+// DownloadAttendanceReport downloads attendance report as Excel file for a specific session
+func (h *AttendanceHandler) DownloadAttendanceReport(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	// Extract session ID from URL
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	content, filename, err := h.service.RenderSessionReport(uint(sessionID), userID)
+	if err != nil {
+		c.JSON(sessionErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
 
 	// Set response headers
 	c.Header("Content-Description", "File Transfer")
 	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
-	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	c.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", content)
+}
 
-	// Write file to response
-	err = file.Write(c.Writer)
+// parseSessionID reads the session ID from the URL, responding with an error if it is invalid
+func (h *AttendanceHandler) parseSessionID(c *gin.Context) (uint, bool) {
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate Excel file"})
-		return
+		h.respondError(c, http.StatusBadRequest, "Invalid session ID")
+		return 0, false
 	}
+	return uint(sessionID), true
 }
 
-// Helper function to format strings for filenames
-func formatFilename(s string) string {
-	// Replace spaces with underscores
-	s = strings.ReplaceAll(s, " ", "_")
+// respond writes a successful response with data
+func (h *AttendanceHandler) respond(c *gin.Context, data interface{}) {
+	if h.envelope {
+		c.JSON(http.StatusOK, gin.H{"status": "success", "data": data})
+		return
+	}
+	c.JSON(http.StatusOK, data)
+}
 
-	// Replace slashes with hyphens
-	s = strings.ReplaceAll(s, "/", "-")
-	s = strings.ReplaceAll(s, "\\", "-")
+// respondMessage writes a successful response with a message
+func (h *AttendanceHandler) respondMessage(c *gin.Context, message string) {
+	if h.envelope {
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": message})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": message})
+}
 
-	// Remove other special characters
-	reg := regexp.MustCompile(`[^\w\-]`)
-	s = reg.ReplaceAllString(s, "")
+// respondError writes an error response
+func (h *AttendanceHandler) respondError(c *gin.Context, code int, message string) {
+	if h.envelope {
+		c.JSON(code, gin.H{"status": "error", "message": message})
+		return
+	}
+	c.JSON(code, gin.H{"error": message})
+}
 
-	// Convert to lowercase for consistency
-	return strings.ToLower(s)
+// sessionErrorStatus maps attendance session errors to a status code, using fallback for
+// errors that are specific to the operation
+func sessionErrorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, services.ErrAttendanceActionNotAllowed), errors.Is(err, services.ErrSessionAccessDenied),
+		errors.Is(err, services.ErrCourseAccessDenied), errors.Is(err, services.ErrOutsideMeetingRange),
+		errors.Is(err, services.ErrEditWindowClosed):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrInvalidOverride), errors.Is(err, services.ErrOverrideReasonRequired),
+		errors.Is(err, services.ErrInvalidGeofence), errors.Is(err, services.ErrInvalidQRRotation):
+		return http.StatusBadRequest
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	}
+	return fallback
 }
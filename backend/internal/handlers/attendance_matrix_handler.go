@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/delpresence/backend/internal/models"
+	"github.com/delpresence/backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// AttendanceMatrixHandler handles semester attendance matrix exports
+type AttendanceMatrixHandler struct {
+	service *services.AttendanceMatrixService
+}
+
+// NewAttendanceMatrixHandler creates a new attendance matrix handler
+func NewAttendanceMatrixHandler() *AttendanceMatrixHandler {
+	return &AttendanceMatrixHandler{
+		service: services.NewAttendanceMatrixService(),
+	}
+}
+
+// ExportMatrix exports the attendance matrix of a course schedule (course_schedule_id) or of all
+// schedules of a course in an academic year (course_id and optional academic_year_id) as xlsx, csv,
+// a printable pdf recap or json
+func (h *AttendanceMatrixHandler) ExportMatrix(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	role := c.MustGet("role").(string)
+
+	format := c.DefaultQuery("format", "xlsx")
+	if format != "xlsx" && format != "csv" && format != "pdf" && format != "json" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be xlsx, csv, pdf or json"})
+		return
+	}
+
+	var matrices []models.AttendanceMatrix
+	var err error
+	if value := c.Query("course_schedule_id"); value != "" {
+		scheduleID, parseErr := strconv.ParseUint(value, 10, 32)
+		if parseErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course_schedule_id"})
+			return
+		}
+		var matrix *models.AttendanceMatrix
+		matrix, err = h.service.GetScheduleMatrix(uint(scheduleID), role, userID)
+		if err == nil {
+			matrices = []models.AttendanceMatrix{*matrix}
+		}
+	} else if value := c.Query("course_id"); value != "" {
+		courseID, parseErr := strconv.ParseUint(value, 10, 32)
+		if parseErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course_id"})
+			return
+		}
+		academicYearID, ok := parseAcademicYearQuery(c)
+		if !ok {
+			return
+		}
+		matrices, err = h.service.GetCourseMatrices(uint(courseID), academicYearID, role, userID)
+	} else {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "course_schedule_id or course_id is required"})
+		return
+	}
+
+	if err != nil {
+		if errors.Is(err, services.ErrScheduleForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	if format == "json" {
+		c.JSON(http.StatusOK, gin.H{
+			"status":  "success",
+			"message": "Attendance matrix retrieved successfully",
+			"data":    matrices,
+		})
+		return
+	}
+
+	var content []byte
+	contentType := "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	switch format {
+	case "csv":
+		content, err = services.RenderMatrixCSV(matrices)
+		contentType = "text/csv; charset=utf-8"
+	case "pdf":
+		content, err = services.RenderMatrixPDF(matrices)
+		contentType = "application/pdf"
+	default:
+		content, err = services.RenderMatrixXLSX(matrices)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate attendance matrix"})
+		return
+	}
+
+	c.Header("Content-Description", "File Transfer")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", services.MatrixFilename(matrices, format)))
+	c.Data(http.StatusOK, contentType, content)
+}
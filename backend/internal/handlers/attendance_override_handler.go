@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/delpresence/backend/internal/models"
+	"github.com/delpresence/backend/internal/repositories"
+	"github.com/delpresence/backend/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AttendanceOverrideHandler handles admin changes to attendance sessions and records
+type AttendanceOverrideHandler struct {
+	service *services.AttendanceOverrideService
+}
+
+// NewAttendanceOverrideHandler creates a new attendance override handler
+func NewAttendanceOverrideHandler() *AttendanceOverrideHandler {
+	return &AttendanceOverrideHandler{
+		service: services.NewAttendanceOverrideService(),
+	}
+}
+
+// overrideReasonRequest is the request body of overrides that only need a reason
+type overrideReasonRequest struct {
+	Reason string `json:"reason"`
+}
+
+// GetOverrides lists the audit trail of admin overrides and lecturer corrections
+func (h *AttendanceOverrideHandler) GetOverrides(c *gin.Context) {
+	filter := repositories.AttendanceOverrideFilter{
+		Action: c.Query("action"),
+		Limit:  200,
+	}
+
+	for param, target := range map[string]*uint{
+		"session_id":      &filter.SessionID,
+		"student_id":      &filter.StudentID,
+		"performed_by_id": &filter.PerformedByID,
+	} {
+		if value := c.Query(param); value != "" {
+			id, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param})
+				return
+			}
+			*target = uint(id)
+		}
+	}
+
+	if value := c.Query("start_date"); value != "" {
+		startDate, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date format. Use YYYY-MM-DD"})
+			return
+		}
+		filter.StartDate = &startDate
+	}
+	if value := c.Query("end_date"); value != "" {
+		endDate, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date format. Use YYYY-MM-DD"})
+			return
+		}
+		endDate = endDate.AddDate(0, 0, 1)
+		filter.EndDate = &endDate
+	}
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 || limit > 1000 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 1000"})
+			return
+		}
+		filter.Limit = limit
+	}
+
+	overrides, err := h.service.ListOverrides(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve attendance overrides"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Attendance overrides retrieved successfully",
+		"data":    overrides,
+	})
+}
+
+// CreateRetroactiveSession records a closed session for a meeting that was not opened in the app
+func (h *AttendanceOverrideHandler) CreateRetroactiveSession(c *gin.Context) {
+	var input services.RetroactiveSessionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	session, err := h.service.CreateRetroactiveSession(input, c.MustGet("userID").(uint))
+	if err != nil {
+		c.JSON(overrideErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": "Attendance session created successfully",
+		"data":    session,
+	})
+}
+
+// ReopenSession makes a closed session active again
+func (h *AttendanceOverrideHandler) ReopenSession(c *gin.Context) {
+	h.changeSession(c, h.service.ReopenSession, "Attendance session reopened successfully")
+}
+
+// CloseSession closes an active session
+func (h *AttendanceOverrideHandler) CloseSession(c *gin.Context) {
+	h.changeSession(c, h.service.CloseSession, "Attendance session closed successfully")
+}
+
+// CancelSession cancels an active or closed session
+func (h *AttendanceOverrideHandler) CancelSession(c *gin.Context) {
+	h.changeSession(c, h.service.CancelSession, "Attendance session canceled successfully")
+}
+
+// SetStudentStatus corrects a student's status in a session
+func (h *AttendanceOverrideHandler) SetStudentStatus(c *gin.Context) {
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+	studentID, err := strconv.ParseUint(c.Param("studentId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student ID"})
+		return
+	}
+
+	var req struct {
+		Status string `json:"status" binding:"required"`
+		Reason string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	status := models.StudentAttendanceStatus(req.Status)
+	switch status {
+	case models.StudentAttendanceStatusPresent, models.StudentAttendanceStatusLate,
+		models.StudentAttendanceStatusAbsent, models.StudentAttendanceStatusExcused:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attendance status"})
+		return
+	}
+
+	attendance, err := h.service.SetStudentStatus(uint(sessionID), uint(studentID), status, req.Reason, c.MustGet("userID").(uint))
+	if err != nil {
+		c.JSON(overrideErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Student attendance updated successfully",
+		"data":    attendance,
+	})
+}
+
+// ExcuseGroup excuses the absences of a student group between two dates
+func (h *AttendanceOverrideHandler) ExcuseGroup(c *gin.Context) {
+	var input services.GroupExcuseInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	result, err := h.service.ExcuseGroup(input, c.MustGet("userID").(uint))
+	if err != nil {
+		c.JSON(overrideErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Student group excused successfully",
+		"data":    result,
+	})
+}
+
+// changeSession applies a session status override that takes only a reason
+func (h *AttendanceOverrideHandler) changeSession(c *gin.Context, change func(sessionID uint, reason string, adminID uint) (*models.AttendanceSessionResponse, error), message string) {
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	var req overrideReasonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	session, err := change(uint(sessionID), req.Reason, c.MustGet("userID").(uint))
+	if err != nil {
+		c.JSON(overrideErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": message,
+		"data":    session,
+	})
+}
+
+// overrideErrorStatus maps an override error to an HTTP status
+func overrideErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrOverrideReasonRequired), errors.Is(err, services.ErrInvalidOverride):
+		return http.StatusBadRequest
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/delpresence/backend/internal/models"
+	"github.com/delpresence/backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// AttendanceRecapHandler handles per-student attendance recaps and academic advisor assignments
+type AttendanceRecapHandler struct {
+	service *services.AttendanceRecapService
+}
+
+// NewAttendanceRecapHandler creates a new attendance recap handler
+func NewAttendanceRecapHandler() *AttendanceRecapHandler {
+	return &AttendanceRecapHandler{
+		service: services.NewAttendanceRecapService(),
+	}
+}
+
+// GetMyRecap returns the authenticated student's own recap
+func (h *AttendanceRecapHandler) GetMyRecap(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	academicYearID, ok := parseAcademicYearQuery(c)
+	if !ok {
+		return
+	}
+
+	recap, err := h.service.GetRecapByStudentUserID(userID, academicYearID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
+	respondWithRecap(c, recap)
+}
+
+// GetStudentRecap returns the recap of a student for an admin or the student's academic advisor
+func (h *AttendanceRecapHandler) GetStudentRecap(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	role := c.MustGet("role").(string)
+
+	studentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student ID"})
+		return
+	}
+
+	academicYearID, ok := parseAcademicYearQuery(c)
+	if !ok {
+		return
+	}
+
+	recap, err := h.service.GetRecapForUser(uint(studentID), role, userID, academicYearID)
+	if err != nil {
+		if errors.Is(err, services.ErrRecapForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	respondWithRecap(c, recap)
+}
+
+// GetMyAdvisees lists the students the authenticated lecturer advises
+func (h *AttendanceRecapHandler) GetMyAdvisees(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	advisors, err := h.service.ListAdvisors(int(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Advisees retrieved successfully",
+		"data":    advisors,
+	})
+}
+
+// GetAdvisors lists academic advisor assignments, optionally filtered by lecturer_user_id
+func (h *AttendanceRecapHandler) GetAdvisors(c *gin.Context) {
+	lecturerUserID, _ := strconv.Atoi(c.Query("lecturer_user_id"))
+
+	advisors, err := h.service.ListAdvisors(lecturerUserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Academic advisors retrieved successfully",
+		"data":    advisors,
+	})
+}
+
+// assignAdvisorRequest is the body of an advisor assignment
+type assignAdvisorRequest struct {
+	LecturerUserID int `json:"lecturer_user_id" binding:"required"`
+}
+
+// AssignAdvisor sets the academic advisor of a student
+func (h *AttendanceRecapHandler) AssignAdvisor(c *gin.Context) {
+	studentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student ID"})
+		return
+	}
+
+	var req assignAdvisorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	advisor, err := h.service.AssignAdvisor(uint(studentID), req.LecturerUserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Academic advisor assigned successfully",
+		"data":    advisor,
+	})
+}
+
+// RemoveAdvisor removes the academic advisor of a student
+func (h *AttendanceRecapHandler) RemoveAdvisor(c *gin.Context) {
+	studentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student ID"})
+		return
+	}
+
+	if err := h.service.RemoveAdvisor(uint(studentID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Academic advisor removed successfully",
+	})
+}
+
+// respondWithRecap writes a recap as JSON, or as a download when format is xlsx or pdf
+func respondWithRecap(c *gin.Context, recap *models.StudentAttendanceRecap) {
+	var (
+		content     []byte
+		err         error
+		contentType string
+	)
+
+	format := c.DefaultQuery("format", "json")
+	switch format {
+	case "json":
+		c.JSON(http.StatusOK, gin.H{
+			"status":  "success",
+			"message": "Attendance recap retrieved successfully",
+			"data":    recap,
+		})
+		return
+	case "xlsx":
+		content, err = services.RenderRecapXLSX(recap)
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case "pdf":
+		content, err = services.RenderRecapPDF(recap)
+		contentType = "application/pdf"
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json, xlsx or pdf"})
+		return
+	}
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate recap file"})
+		return
+	}
+
+	c.Header("Content-Description", "File Transfer")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", services.RecapFilename(recap, format)))
+	c.Data(http.StatusOK, contentType, content)
+}
+
+// parseAcademicYearQuery reads the optional academic_year_id query parameter
+func parseAcademicYearQuery(c *gin.Context) (uint, bool) {
+	value := c.Query("academic_year_id")
+	if value == "" {
+		return 0, true
+	}
+
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid academic_year_id"})
+		return 0, false
+	}
+	return uint(id), true
+}
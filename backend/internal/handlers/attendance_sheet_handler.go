@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/delpresence/backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// AttendanceSheetHandler handles printable attendance sheets (daftar hadir)
+type AttendanceSheetHandler struct {
+	service *services.AttendanceSheetService
+}
+
+// NewAttendanceSheetHandler creates a new attendance sheet handler
+func NewAttendanceSheetHandler() *AttendanceSheetHandler {
+	return &AttendanceSheetHandler{
+		service: services.NewAttendanceSheetService(),
+	}
+}
+
+// GetPlannedSheet downloads a blank attendance sheet for a schedule's meeting on date (default today)
+func (h *AttendanceSheetHandler) GetPlannedSheet(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	role := c.MustGet("role").(string)
+
+	scheduleID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule ID"})
+		return
+	}
+
+	date := services.GetIndonesiaTime()
+	if value := c.Query("date"); value != "" {
+		date, err = time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
+			return
+		}
+	}
+
+	content, filename, err := h.service.PlannedSheetPDF(uint(scheduleID), date, role, userID)
+	if err != nil {
+		respondWithSheetError(c, err)
+		return
+	}
+
+	sendPDF(c, filename, content)
+}
+
+// GetSessionSheet downloads the filled attendance sheet of a closed session
+func (h *AttendanceSheetHandler) GetSessionSheet(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	role := c.MustGet("role").(string)
+
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	content, filename, err := h.service.SessionSheetPDF(uint(sessionID), role, userID)
+	if err != nil {
+		respondWithSheetError(c, err)
+		return
+	}
+
+	sendPDF(c, filename, content)
+}
+
+// VerifySheet resolves the verification QR code printed on an attendance sheet. It is public
+// so that anyone holding a paper sheet can check it against the session record.
+func (h *AttendanceSheetHandler) VerifySheet(c *gin.Context) {
+	verification, err := h.service.VerifySheet(c.Query("ref"), c.Query("sig"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Attendance sheet verified",
+		"data":    verification,
+	})
+}
+
+// respondWithSheetError maps sheet generation errors to responses
+func respondWithSheetError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrScheduleForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
+
+// sendPDF writes a PDF file download
+func sendPDF(c *gin.Context, filename string, content []byte) {
+	c.Header("Content-Description", "File Transfer")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	c.Data(http.StatusOK, "application/pdf", content)
+}
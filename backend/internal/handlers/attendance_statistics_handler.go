@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/delpresence/backend/internal/repositories"
+	"github.com/delpresence/backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// AttendanceStatisticsHandler handles attendance breakdown requests
+type AttendanceStatisticsHandler struct {
+	service *services.AttendanceStatisticsService
+}
+
+// NewAttendanceStatisticsHandler creates a new attendance statistics handler
+func NewAttendanceStatisticsHandler() *AttendanceStatisticsHandler {
+	return &AttendanceStatisticsHandler{
+		service: services.NewAttendanceStatisticsService(),
+	}
+}
+
+// GetLecturerBreakdown returns attendance statistics over the authenticated lecturer's schedules
+func (h *AttendanceStatisticsHandler) GetLecturerBreakdown(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	h.respondWithBreakdown(c, func(filter *repositories.AttendanceStatsFilter) {
+		filter.LecturerUserID = userID
+	})
+}
+
+// GetStudentBreakdown returns the authenticated student's own attendance statistics
+func (h *AttendanceStatisticsHandler) GetStudentBreakdown(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	h.respondWithBreakdown(c, func(filter *repositories.AttendanceStatsFilter) {
+		filter.StudentUserID = userID
+	})
+}
+
+// GetAdminBreakdown returns attendance statistics across all schedules
+func (h *AttendanceStatisticsHandler) GetAdminBreakdown(c *gin.Context) {
+	h.respondWithBreakdown(c, func(filter *repositories.AttendanceStatsFilter) {})
+}
+
+// respondWithBreakdown parses the filter, applies the caller's scope and writes the breakdown
+func (h *AttendanceStatisticsHandler) respondWithBreakdown(c *gin.Context, scope func(filter *repositories.AttendanceStatsFilter)) {
+	breakdown, err := services.ParseAttendanceBreakdown(c.DefaultQuery("group_by", "course"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter, err := parseStatsFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	scope(&filter)
+
+	items, totals, err := h.service.GetBreakdown(breakdown, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Attendance statistics retrieved successfully",
+		"data": gin.H{
+			"group_by":        breakdown,
+			"totals":          totals,
+			"attendance_rate": totals.AttendedRate(),
+			"items":           items,
+		},
+	})
+}
+
+// parseStatsFilter reads the optional statistics filters from the query string:
+// start_date, end_date (YYYY-MM-DD), course_schedule_id, course_id, student_group_id,
+// session_id, academic_year_id (comma-separated IDs are accepted) and closed_only
+func parseStatsFilter(c *gin.Context) (repositories.AttendanceStatsFilter, error) {
+	var filter repositories.AttendanceStatsFilter
+
+	if value := c.Query("start_date"); value != "" {
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return filter, errors.New("Invalid start_date format, use YYYY-MM-DD")
+		}
+		filter.StartDate = &date
+	}
+
+	if value := c.Query("end_date"); value != "" {
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return filter, errors.New("Invalid end_date format, use YYYY-MM-DD")
+		}
+		filter.EndDate = &date
+	}
+
+	var err error
+	if filter.CourseScheduleIDs, err = parseIDList(c.Query("course_schedule_id")); err != nil {
+		return filter, errors.New("Invalid course_schedule_id")
+	}
+	if filter.CourseIDs, err = parseIDList(c.Query("course_id")); err != nil {
+		return filter, errors.New("Invalid course_id")
+	}
+	if filter.StudentGroupIDs, err = parseIDList(c.Query("student_group_id")); err != nil {
+		return filter, errors.New("Invalid student_group_id")
+	}
+	if filter.SessionIDs, err = parseIDList(c.Query("session_id")); err != nil {
+		return filter, errors.New("Invalid session_id")
+	}
+
+	if value := c.Query("academic_year_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return filter, errors.New("Invalid academic_year_id")
+		}
+		filter.AcademicYearID = uint(id)
+	}
+
+	filter.ClosedOnly = c.Query("closed_only") == "true"
+
+	return filter, nil
+}
+
+// parseIDList parses a comma-separated list of IDs
+func parseIDList(value string) ([]uint, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	var ids []uint
+	for _, part := range strings.Split(value, ",") {
+		id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids, nil
+}
@@ -0,0 +1,454 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image/png"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/delpresence/backend/internal/models"
+	"github.com/delpresence/backend/internal/qrcode"
+	"github.com/delpresence/backend/internal/repositories"
+	"github.com/delpresence/backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// CampusEventHandler handles campus events outside courses, such as general lectures and seminars
+type CampusEventHandler struct {
+	service *services.CampusEventService
+}
+
+// NewCampusEventHandler creates a new campus event handler
+func NewCampusEventHandler() *CampusEventHandler {
+	return &CampusEventHandler{
+		service: services.NewCampusEventService(),
+	}
+}
+
+// GetEvents lists the events the user manages, optionally between from and to dates (YYYY-MM-DD)
+// and by status
+func (h *CampusEventHandler) GetEvents(c *gin.Context) {
+	filter := repositories.CampusEventFilter{
+		Status: models.CampusEventStatus(strings.ToUpper(c.Query("status"))),
+	}
+	if value := c.Query("from"); value != "" {
+		from, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from format. Use YYYY-MM-DD"})
+			return
+		}
+		filter.From = &from
+	}
+	if value := c.Query("to"); value != "" {
+		to, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to format. Use YYYY-MM-DD"})
+			return
+		}
+		to = to.AddDate(0, 0, 1)
+		filter.To = &to
+	}
+
+	events, err := h.service.ListEvents(c.MustGet("userID").(uint), c.GetString("role"), filter)
+	if err != nil {
+		c.JSON(campusEventErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Campus events retrieved successfully",
+		"data":    events,
+	})
+}
+
+// CreateEvent creates a campus event
+func (h *CampusEventHandler) CreateEvent(c *gin.Context) {
+	var input services.CampusEventInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	event, err := h.service.CreateEvent(input, c.MustGet("userID").(uint), c.GetString("role"))
+	if err != nil {
+		c.JSON(campusEventErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": "Campus event created successfully",
+		"data":    event,
+	})
+}
+
+// GetEvent returns a campus event
+func (h *CampusEventHandler) GetEvent(c *gin.Context) {
+	eventID, ok := parseCampusEventID(c)
+	if !ok {
+		return
+	}
+
+	event, err := h.service.GetEvent(eventID, c.MustGet("userID").(uint), c.GetString("role"))
+	if err != nil {
+		c.JSON(campusEventErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Campus event retrieved successfully",
+		"data":    event,
+	})
+}
+
+// UpdateEvent replaces the details of a campus event
+func (h *CampusEventHandler) UpdateEvent(c *gin.Context) {
+	eventID, ok := parseCampusEventID(c)
+	if !ok {
+		return
+	}
+
+	var input services.CampusEventInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	event, err := h.service.UpdateEvent(eventID, input, c.MustGet("userID").(uint), c.GetString("role"))
+	if err != nil {
+		c.JSON(campusEventErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Campus event updated successfully",
+		"data":    event,
+	})
+}
+
+// OpenCheckIn opens check-in for a campus event
+func (h *CampusEventHandler) OpenCheckIn(c *gin.Context) {
+	eventID, ok := parseCampusEventID(c)
+	if !ok {
+		return
+	}
+
+	event, err := h.service.OpenCheckIn(eventID, c.MustGet("userID").(uint), c.GetString("role"))
+	if err != nil {
+		c.JSON(campusEventErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Campus event check-in opened successfully",
+		"data":    event,
+	})
+}
+
+// CloseCheckIn closes check-in for a campus event
+func (h *CampusEventHandler) CloseCheckIn(c *gin.Context) {
+	eventID, ok := parseCampusEventID(c)
+	if !ok {
+		return
+	}
+
+	event, err := h.service.CloseCheckIn(eventID, c.MustGet("userID").(uint), c.GetString("role"))
+	if err != nil {
+		c.JSON(campusEventErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Campus event check-in closed successfully",
+		"data":    event,
+	})
+}
+
+// CancelEvent cancels a campus event
+func (h *CampusEventHandler) CancelEvent(c *gin.Context) {
+	eventID, ok := parseCampusEventID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.CancelEvent(eventID, c.MustGet("userID").(uint), c.GetString("role")); err != nil {
+		c.JSON(campusEventErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Campus event canceled successfully",
+	})
+}
+
+// GetQRCode returns the check-in QR code of a campus event as a PNG image, or its payload with
+// format=json
+func (h *CampusEventHandler) GetQRCode(c *gin.Context) {
+	eventID, ok := parseCampusEventID(c)
+	if !ok {
+		return
+	}
+
+	payload, err := h.service.GetQRCodePayload(eventID, c.MustGet("userID").(uint), c.GetString("role"))
+	if err != nil {
+		c.JSON(campusEventErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.Query("format") == "json" {
+		c.JSON(http.StatusOK, gin.H{
+			"status":  "success",
+			"message": "QR code retrieved successfully",
+			"data":    gin.H{"qr_code_data": payload},
+		})
+		return
+	}
+
+	code, err := qrcode.Encode(payload)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate QR code"})
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, code.Image(8)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate QR code"})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "image/png", buf.Bytes())
+}
+
+// GetAttendances lists the attendance records of a campus event; status=ABSENT lists the absentees
+func (h *CampusEventHandler) GetAttendances(c *gin.Context) {
+	eventID, ok := parseCampusEventID(c)
+	if !ok {
+		return
+	}
+
+	status := models.StudentAttendanceStatus(strings.ToUpper(c.Query("status")))
+	attendances, err := h.service.ListAttendances(eventID, status, c.MustGet("userID").(uint), c.GetString("role"))
+	if err != nil {
+		c.JSON(campusEventErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Campus event attendances retrieved successfully",
+		"data":    attendances,
+	})
+}
+
+// MarkAttendance records a student's attendance of a campus event by hand
+func (h *CampusEventHandler) MarkAttendance(c *gin.Context) {
+	eventID, ok := parseCampusEventID(c)
+	if !ok {
+		return
+	}
+	studentID, err := strconv.ParseUint(c.Param("studentId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student ID"})
+		return
+	}
+
+	var req struct {
+		Status string `json:"status" binding:"required"`
+		Notes  string `json:"notes"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	status := models.StudentAttendanceStatus(strings.ToUpper(req.Status))
+	err = h.service.MarkAttendance(eventID, uint(studentID), status, req.Notes, c.MustGet("userID").(uint), c.GetString("role"))
+	if err != nil {
+		c.JSON(campusEventErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Attendance marked successfully",
+	})
+}
+
+// DownloadReport downloads the attendance report of a campus event as an Excel file
+func (h *CampusEventHandler) DownloadReport(c *gin.Context) {
+	eventID, ok := parseCampusEventID(c)
+	if !ok {
+		return
+	}
+
+	content, filename, err := h.service.RenderReport(eventID, c.MustGet("userID").(uint), c.GetString("role"))
+	if err != nil {
+		c.JSON(campusEventErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Description", "File Transfer")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	c.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", content)
+}
+
+// GetStudentEvents lists the upcoming and ongoing events of the authenticated student's audience
+func (h *CampusEventHandler) GetStudentEvents(c *gin.Context) {
+	events, err := h.service.ListStudentEvents(c.MustGet("userID").(uint))
+	if err != nil {
+		c.JSON(campusEventErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Campus events retrieved successfully",
+		"data":    events,
+	})
+}
+
+// CheckIn records the authenticated student's QR check-in to a campus event
+func (h *CampusEventHandler) CheckIn(c *gin.Context) {
+	eventID, ok := parseCampusEventID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		QRCodeData string `json:"qr_code_data" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	attendance, err := h.service.CheckIn(c.MustGet("userID").(uint), eventID, req.QRCodeData)
+	if err != nil {
+		c.JSON(campusEventErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Checked in successfully",
+		"data":    attendance,
+	})
+}
+
+// ScheduleRollCalls schedules the nightly roll calls of a dormitory for a period
+func (h *CampusEventHandler) ScheduleRollCalls(c *gin.Context) {
+	var input services.RollCallScheduleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	events, err := h.service.ScheduleRollCalls(input, c.MustGet("userID").(uint), c.GetString("role"))
+	if err != nil {
+		c.JSON(campusEventErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": fmt.Sprintf("%d roll calls scheduled", len(events)),
+		"data":    events,
+	})
+}
+
+// GetDormitories lists the dormitories of synced students
+func (h *CampusEventHandler) GetDormitories(c *gin.Context) {
+	dormitories, err := h.service.ListDormitories()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Dormitories retrieved successfully",
+		"data":    dormitories,
+	})
+}
+
+// GetRollCallAbsences reports the students of a dormitory who repeatedly missed roll calls between
+// from and to (YYYY-MM-DD); format=xlsx downloads it
+func (h *CampusEventHandler) GetRollCallAbsences(c *gin.Context) {
+	from, err := time.Parse("2006-01-02", c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from format. Use YYYY-MM-DD"})
+		return
+	}
+	to, err := time.Parse("2006-01-02", c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to format. Use YYYY-MM-DD"})
+		return
+	}
+	minAbsences := 2
+	if value := c.Query("min_absences"); value != "" {
+		minAbsences, err = strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid min_absences"})
+			return
+		}
+	}
+
+	report, err := h.service.GetRollCallAbsenceReport(c.Query("dormitory"), from, to, minAbsences)
+	if err != nil {
+		c.JSON(campusEventErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.Query("format") != "xlsx" {
+		c.JSON(http.StatusOK, gin.H{
+			"status":  "success",
+			"message": "Roll call absences retrieved successfully",
+			"data":    report,
+		})
+		return
+	}
+
+	content, err := services.RenderRollCallAbsenceXLSX(report)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate roll call absence report"})
+		return
+	}
+
+	c.Header("Content-Description", "File Transfer")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", services.RollCallAbsenceFilename(report)))
+	c.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", content)
+}
+
+// parseCampusEventID parses the event ID path parameter, responding with 400 when it is invalid
+func parseCampusEventID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// campusEventErrorStatus maps a campus event error to an HTTP status
+func campusEventErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidCampusEvent):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrCampusEventAccessDenied), errors.Is(err, services.ErrNotInEventAudience):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrCampusEventNotOpen), errors.Is(err, services.ErrAlreadyCheckedIn):
+		return http.StatusConflict
+	}
+	return sessionErrorStatus(err, http.StatusInternalServerError)
+}
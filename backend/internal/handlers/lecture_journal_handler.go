@@ -0,0 +1,393 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/delpresence/backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// LectureJournalHandler handles lecture journals (Berita Acara Perkuliahan) and lesson plans
+type LectureJournalHandler struct {
+	service *services.LectureJournalService
+}
+
+// NewLectureJournalHandler creates a lecture journal handler for an attendance actor
+func NewLectureJournalHandler(actor services.AttendanceActor) *LectureJournalHandler {
+	return &LectureJournalHandler{
+		service: services.NewLectureJournalService(actor),
+	}
+}
+
+// NewStudentLectureJournalHandler creates a lecture journal handler for class representatives
+func NewStudentLectureJournalHandler() *LectureJournalHandler {
+	return &LectureJournalHandler{
+		service: services.NewStudentLectureJournalService(),
+	}
+}
+
+// GetJournals lists the lecture journals of a course schedule
+func (h *LectureJournalHandler) GetJournals(c *gin.Context) {
+	scheduleID, err := strconv.ParseUint(c.Query("course_schedule_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "course_schedule_id is required"})
+		return
+	}
+
+	journals, err := h.service.ListJournals(uint(scheduleID), c.MustGet("userID").(uint))
+	if err != nil {
+		c.JSON(journalErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Lecture journals retrieved successfully",
+		"data":    journals,
+	})
+}
+
+// CreateJournal fills in the lecture journal of a meeting
+func (h *LectureJournalHandler) CreateJournal(c *gin.Context) {
+	var input services.LectureJournalInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	journal, err := h.service.CreateJournal(input, c.MustGet("userID").(uint))
+	if err != nil {
+		c.JSON(journalErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": "Lecture journal created successfully",
+		"data":    journal,
+	})
+}
+
+// GetJournal returns a lecture journal
+func (h *LectureJournalHandler) GetJournal(c *gin.Context) {
+	journalID, ok := parseJournalID(c)
+	if !ok {
+		return
+	}
+
+	journal, err := h.service.GetJournal(journalID, c.MustGet("userID").(uint))
+	if err != nil {
+		c.JSON(journalErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Lecture journal retrieved successfully",
+		"data":    journal,
+	})
+}
+
+// UpdateJournal replaces the contents of a draft lecture journal
+func (h *LectureJournalHandler) UpdateJournal(c *gin.Context) {
+	journalID, ok := parseJournalID(c)
+	if !ok {
+		return
+	}
+
+	var input services.LectureJournalInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	journal, err := h.service.UpdateJournal(journalID, input, c.MustGet("userID").(uint))
+	if err != nil {
+		c.JSON(journalErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Lecture journal updated successfully",
+		"data":    journal,
+	})
+}
+
+// SignJournal signs a draft lecture journal
+func (h *LectureJournalHandler) SignJournal(c *gin.Context) {
+	journalID, ok := parseJournalID(c)
+	if !ok {
+		return
+	}
+
+	journal, err := h.service.SignJournal(journalID, c.MustGet("userID").(uint))
+	if err != nil {
+		c.JSON(journalErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Lecture journal signed successfully",
+		"data":    journal,
+	})
+}
+
+// AddAttachment attaches a material to a draft lecture journal: a file uploaded as multipart form
+// field "file", or a link sent as JSON
+func (h *LectureJournalHandler) AddAttachment(c *gin.Context) {
+	journalID, ok := parseJournalID(c)
+	if !ok {
+		return
+	}
+	userID := c.MustGet("userID").(uint)
+
+	if c.ContentType() == "multipart/form-data" {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.service.MaxUploadSize()+1<<20)
+		header, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+			return
+		}
+		file, err := header.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read uploaded file"})
+			return
+		}
+		defer file.Close()
+
+		attachment, err := h.service.AddFileAttachment(journalID, c.PostForm("title"), header.Filename,
+			header.Header.Get("Content-Type"), header.Size, file, userID)
+		if err != nil {
+			c.JSON(journalErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"status":  "success",
+			"message": "Attachment uploaded successfully",
+			"data":    attachment,
+		})
+		return
+	}
+
+	var req struct {
+		Title string `json:"title"`
+		URL   string `json:"url" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	attachment, err := h.service.AddLinkAttachment(journalID, req.Title, req.URL, userID)
+	if err != nil {
+		c.JSON(journalErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": "Attachment added successfully",
+		"data":    attachment,
+	})
+}
+
+// DownloadAttachment downloads an uploaded attachment of a lecture journal
+func (h *LectureJournalHandler) DownloadAttachment(c *gin.Context) {
+	journalID, ok := parseJournalID(c)
+	if !ok {
+		return
+	}
+	attachmentID, err := strconv.ParseUint(c.Param("attachmentId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attachment ID"})
+		return
+	}
+
+	attachment, file, err := h.service.OpenAttachment(journalID, uint(attachmentID), c.MustGet("userID").(uint))
+	if err != nil {
+		c.JSON(journalErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", attachment.FileName))
+	c.DataFromReader(http.StatusOK, attachment.FileSize, attachment.ContentType, file, nil)
+}
+
+// DeleteAttachment removes an attachment from a draft lecture journal
+func (h *LectureJournalHandler) DeleteAttachment(c *gin.Context) {
+	journalID, ok := parseJournalID(c)
+	if !ok {
+		return
+	}
+	attachmentID, err := strconv.ParseUint(c.Param("attachmentId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attachment ID"})
+		return
+	}
+
+	if err := h.service.DeleteAttachment(journalID, uint(attachmentID), c.MustGet("userID").(uint)); err != nil {
+		c.JSON(journalErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Attachment deleted successfully",
+	})
+}
+
+// GetLessonPlan returns the lesson plan (RPS) of a course
+func (h *LectureJournalHandler) GetLessonPlan(c *gin.Context) {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+		return
+	}
+
+	topics, err := h.service.GetLessonPlan(uint(courseID), c.MustGet("userID").(uint))
+	if err != nil {
+		c.JSON(journalErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Lesson plan retrieved successfully",
+		"data":    topics,
+	})
+}
+
+// ReplaceLessonPlan replaces the lesson plan (RPS) of a course
+func (h *LectureJournalHandler) ReplaceLessonPlan(c *gin.Context) {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+		return
+	}
+
+	var req struct {
+		Topics []services.LessonPlanTopicInput `json:"topics"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	topics, err := h.service.ReplaceLessonPlan(uint(courseID), req.Topics, c.MustGet("userID").(uint))
+	if err != nil {
+		c.JSON(journalErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Lesson plan updated successfully",
+		"data":    topics,
+	})
+}
+
+// GetLessonPlanCoverage compares a course's lesson plan with what each class delivered, as JSON
+// or as an xlsx download with format=xlsx
+func (h *LectureJournalHandler) GetLessonPlanCoverage(c *gin.Context) {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "xlsx" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or xlsx"})
+		return
+	}
+
+	coverage, err := h.service.GetLessonPlanCoverage(uint(courseID), c.MustGet("userID").(uint))
+	if err != nil {
+		c.JSON(journalErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	if format == "json" {
+		c.JSON(http.StatusOK, gin.H{
+			"status":  "success",
+			"message": "Lesson plan coverage retrieved successfully",
+			"data":    coverage,
+		})
+		return
+	}
+
+	content, err := services.RenderLessonPlanCoverageXLSX(coverage)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate report"})
+		return
+	}
+
+	c.Header("Content-Description", "File Transfer")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", services.LessonPlanCoverageFilename(coverage)))
+	c.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", content)
+}
+
+// GetPendingCosigns lists the lecture journals the authenticated class representative has yet to co-sign
+func (h *LectureJournalHandler) GetPendingCosigns(c *gin.Context) {
+	journals, err := h.service.ListPendingCosigns(c.MustGet("userID").(uint))
+	if err != nil {
+		c.JSON(journalErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Lecture journals retrieved successfully",
+		"data":    journals,
+	})
+}
+
+// CosignJournal co-signs a signed lecture journal as its class representative
+func (h *LectureJournalHandler) CosignJournal(c *gin.Context) {
+	journalID, ok := parseJournalID(c)
+	if !ok {
+		return
+	}
+
+	journal, err := h.service.CosignJournal(journalID, c.MustGet("userID").(uint))
+	if err != nil {
+		c.JSON(journalErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Lecture journal co-signed successfully",
+		"data":    journal,
+	})
+}
+
+// parseJournalID parses the journal ID path parameter, responding with 400 when it is invalid
+func parseJournalID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid journal ID"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// journalErrorStatus maps a lecture journal error to an HTTP status
+func journalErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidLectureJournal):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrLectureJournalExists), errors.Is(err, services.ErrLectureJournalLocked):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrAttachmentTooLarge):
+		return http.StatusRequestEntityTooLarge
+	}
+	return sessionErrorStatus(err, http.StatusInternalServerError)
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/delpresence/backend/internal/models"
 	"github.com/delpresence/backend/internal/repositories"
@@ -24,9 +25,13 @@ func NewLecturerAssignmentHandler() *LecturerAssignmentHandler {
 // CreateLecturerAssignment creates a new lecturer assignment
 func (h *LecturerAssignmentHandler) CreateLecturerAssignment(c *gin.Context) {
 	var input struct {
-		UserID         int  `json:"user_id" binding:"required"`
-		CourseID       uint `json:"course_id" binding:"required"`
-		AcademicYearID uint `json:"academic_year_id"`
+		UserID           int    `json:"user_id" binding:"required"`
+		CourseID         uint   `json:"course_id" binding:"required"`
+		AcademicYearID   uint   `json:"academic_year_id"`
+		Role             string `json:"role"`
+		CourseScheduleID *uint  `json:"course_schedule_id"`
+		MeetingFrom      *int   `json:"meeting_from"`
+		MeetingTo        *int   `json:"meeting_to"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -75,10 +80,29 @@ func (h *LecturerAssignmentHandler) CreateLecturerAssignment(c *gin.Context) {
 		return
 	}
 
-	if len(existingAssignments) > 0 {
-		c.JSON(http.StatusConflict, gin.H{
+	// The first lecturer of a course coordinates it; later ones join its teaching team
+	role := models.LecturerAssignmentRole(strings.ToUpper(input.Role))
+	if role == "" {
+		role = models.LecturerAssignmentCoordinator
+		if len(existingAssignments) > 0 {
+			role = models.LecturerAssignmentMember
+		}
+	}
+	if role == models.LecturerAssignmentCoordinator {
+		for _, existing := range existingAssignments {
+			if existing.Role == models.LecturerAssignmentCoordinator {
+				c.JSON(http.StatusConflict, gin.H{
+					"status":  "error",
+					"message": "Mata kuliah ini sudah memiliki dosen koordinator. Tugaskan dosen sebagai anggota tim pengajar atau ubah koordinator yang ada.",
+				})
+				return
+			}
+		}
+	}
+	if message := validateTeachingTeamScope(role, input.CourseID, input.AcademicYearID, input.CourseScheduleID, input.MeetingFrom, input.MeetingTo); message != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
 			"status":  "error",
-			"message": "Tidak dapat menugaskan dosen: Satu mata kuliah hanya dapat ditugaskan kepada satu dosen. Hapus penugasan dosen yang ada terlebih dahulu.",
+			"message": message,
 		})
 		return
 	}
@@ -128,9 +152,13 @@ func (h *LecturerAssignmentHandler) CreateLecturerAssignment(c *gin.Context) {
 
 	// Create the assignment with the proper user_id
 	assignment := models.LecturerAssignment{
-		UserID:         actualUserID,
-		CourseID:       input.CourseID,
-		AcademicYearID: input.AcademicYearID,
+		UserID:           actualUserID,
+		CourseID:         input.CourseID,
+		AcademicYearID:   input.AcademicYearID,
+		Role:             role,
+		CourseScheduleID: input.CourseScheduleID,
+		MeetingFrom:      input.MeetingFrom,
+		MeetingTo:        input.MeetingTo,
 	}
 
 	err = h.repo.Create(&assignment)
@@ -264,13 +292,18 @@ func (h *LecturerAssignmentHandler) UpdateLecturerAssignment(c *gin.Context) {
 	// Store the original values for later comparison
 	originalUserID := existingAssignment.UserID
 	originalCourseID := existingAssignment.CourseID
+	originalRole := existingAssignment.Role
 	// Note: We're storing original values only for fields we need to compare
 
-	// Parse input data
+	// Parse input data. A class or meeting bound of 0 removes that limit.
 	var input struct {
-		UserID         int  `json:"user_id"`
-		CourseID       uint `json:"course_id"`
-		AcademicYearID uint `json:"academic_year_id"`
+		UserID           int    `json:"user_id"`
+		CourseID         uint   `json:"course_id"`
+		AcademicYearID   uint   `json:"academic_year_id"`
+		Role             string `json:"role"`
+		CourseScheduleID *uint  `json:"course_schedule_id"`
+		MeetingFrom      *int   `json:"meeting_from"`
+		MeetingTo        *int   `json:"meeting_to"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -380,6 +413,54 @@ func (h *LecturerAssignmentHandler) UpdateLecturerAssignment(c *gin.Context) {
 		existingAssignment.AcademicYearID = input.AcademicYearID
 	}
 
+	// Apply team teaching changes
+	if input.Role != "" {
+		existingAssignment.Role = models.LecturerAssignmentRole(strings.ToUpper(input.Role))
+	}
+	if input.CourseScheduleID != nil {
+		existingAssignment.CourseScheduleID = input.CourseScheduleID
+		if *input.CourseScheduleID == 0 {
+			existingAssignment.CourseScheduleID = nil
+		}
+	}
+	if input.MeetingFrom != nil {
+		existingAssignment.MeetingFrom = input.MeetingFrom
+		if *input.MeetingFrom == 0 {
+			existingAssignment.MeetingFrom = nil
+		}
+	}
+	if input.MeetingTo != nil {
+		existingAssignment.MeetingTo = input.MeetingTo
+		if *input.MeetingTo == 0 {
+			existingAssignment.MeetingTo = nil
+		}
+	}
+	if existingAssignment.Role == models.LecturerAssignmentCoordinator {
+		coordinatorExists, err := h.repo.CoordinatorExists(existingAssignment.CourseID, existingAssignment.AcademicYearID, existingAssignment.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"status":  "error",
+				"message": "Failed to check for conflicts: " + err.Error(),
+			})
+			return
+		}
+		if coordinatorExists {
+			c.JSON(http.StatusConflict, gin.H{
+				"status":  "error",
+				"message": "Mata kuliah ini sudah memiliki dosen koordinator",
+			})
+			return
+		}
+	}
+	if message := validateTeachingTeamScope(existingAssignment.Role, existingAssignment.CourseID, existingAssignment.AcademicYearID,
+		existingAssignment.CourseScheduleID, existingAssignment.MeetingFrom, existingAssignment.MeetingTo); message != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": message,
+		})
+		return
+	}
+
 	// Update the assignment
 	err = h.repo.Update(&existingAssignment)
 	if err != nil {
@@ -390,8 +471,10 @@ func (h *LecturerAssignmentHandler) UpdateLecturerAssignment(c *gin.Context) {
 		return
 	}
 
-	// If the lecturer has changed, update related course schedules
-	if existingAssignment.UserID != originalUserID {
+	// If the coordinator has changed, update related course schedules. Team members teach alongside
+	// the coordinator and leave the schedules' lecturer as it is.
+	if existingAssignment.Role == models.LecturerAssignmentCoordinator &&
+		(existingAssignment.UserID != originalUserID || originalRole != models.LecturerAssignmentCoordinator) {
 		// Update all schedules for this course to use the new lecturer, filtered by academic year
 		scheduleRepo := repositories.NewCourseScheduleRepository()
 		updateErr := scheduleRepo.UpdateSchedulesForCourseInAcademicYear(
@@ -518,13 +601,16 @@ func (h *LecturerAssignmentHandler) DeleteLecturerAssignment(c *gin.Context) {
 		return
 	}
 	
-	// Count other assignments excluding the one being deleted
+	// Count other assignments excluding the one being deleted; the first of them (a coordinator
+	// before team members) takes over the course's schedules
 	var otherAssignmentCount int = 0
 	var alternativeLecturerID int = 0
+	var alternativeAssignment models.LecturerAssignment
 	for _, otherAssignment := range assignments {
 		if otherAssignment.ID != uint(id) {
 			otherAssignmentCount++
 			alternativeLecturerID = otherAssignment.UserID
+			alternativeAssignment = otherAssignment
 			break
 		}
 	}
@@ -559,9 +645,23 @@ func (h *LecturerAssignmentHandler) DeleteLecturerAssignment(c *gin.Context) {
 		return
 	}
 	
+	// When the coordinator leaves a teaching team, the next lecturer becomes coordinator and teaches
+	// every class and meeting of the course
+	if assignment.Role == models.LecturerAssignmentCoordinator && alternativeAssignment.ID != 0 &&
+		alternativeAssignment.Role != models.LecturerAssignmentCoordinator {
+		alternativeAssignment.Role = models.LecturerAssignmentCoordinator
+		alternativeAssignment.CourseScheduleID = nil
+		alternativeAssignment.MeetingFrom = nil
+		alternativeAssignment.MeetingTo = nil
+		if err := h.repo.Update(&alternativeAssignment); err != nil {
+			fmt.Printf("Warning: Failed to promote lecturer_id=%d to coordinator after deletion: %v\n",
+				alternativeLecturerID, err)
+		}
+	}
+
 	// After deleting the assignment, update course schedules if needed
 	// If there are other assignments for this course, update schedules to use one of those lecturers
-	if otherAssignmentCount > 0 && alternativeLecturerID != 0 {
+	if assignment.Role == models.LecturerAssignmentCoordinator && otherAssignmentCount > 0 && alternativeLecturerID != 0 {
 		// Check if there are any schedules with this lecturer
 		lecturerSchedules, err := scheduleRepo.GetByLecturerAndAcademicYear(uint(userID), academicYearID)
 		if err == nil && len(lecturerSchedules) > 0 {
@@ -841,4 +941,35 @@ func (h *LecturerAssignmentHandler) GetMyAssignments(c *gin.Context) {
 		"status": "success",
 		"data":   assignments,
 	})
-} 
\ No newline at end of file
+} 
+
+// validateTeachingTeamScope validates the role of an assignment and the class and meeting range it
+// is limited to, returning an error message or an empty string. Only team members can be limited;
+// the coordinator teaches every class of the course.
+func validateTeachingTeamScope(role models.LecturerAssignmentRole, courseID, academicYearID uint, courseScheduleID *uint, meetingFrom, meetingTo *int) string {
+	switch role {
+	case models.LecturerAssignmentCoordinator:
+		if courseScheduleID != nil || meetingFrom != nil || meetingTo != nil {
+			return "Dosen koordinator mengajar seluruh kelas dan pertemuan; hanya anggota tim pengajar yang dapat dibatasi"
+		}
+		return ""
+	case models.LecturerAssignmentMember:
+	default:
+		return "Peran dosen tidak valid: gunakan COORDINATOR atau MEMBER"
+	}
+
+	if meetingFrom != nil && *meetingFrom < 1 || meetingTo != nil && *meetingTo < 1 {
+		return "Nomor pertemuan harus dimulai dari 1"
+	}
+	if meetingFrom != nil && meetingTo != nil && *meetingTo < *meetingFrom {
+		return "Pertemuan akhir tidak boleh sebelum pertemuan awal"
+	}
+
+	if courseScheduleID != nil {
+		schedule, err := repositories.NewCourseScheduleRepository().GetByID(*courseScheduleID)
+		if err != nil || schedule.CourseID != courseID || schedule.AcademicYearID != academicYearID {
+			return "Jadwal kelas tidak ditemukan untuk mata kuliah dan tahun akademik ini"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/delpresence/backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// LecturerTeachingHandler handles lecturer teaching record reports
+type LecturerTeachingHandler struct {
+	service *services.LecturerTeachingService
+}
+
+// NewLecturerTeachingHandler creates a new lecturer teaching handler
+func NewLecturerTeachingHandler() *LecturerTeachingHandler {
+	return &LecturerTeachingHandler{
+		service: services.NewLecturerTeachingService(),
+	}
+}
+
+// GetReport returns the teaching record of all lecturers in an academic year, or of one with
+// lecturer_user_id, as json or as an xlsx download with format=xlsx
+func (h *LecturerTeachingHandler) GetReport(c *gin.Context) {
+	var lecturerUserID uint
+	if value := c.Query("lecturer_user_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lecturer_user_id"})
+			return
+		}
+		lecturerUserID = uint(id)
+	}
+	h.respondReport(c, lecturerUserID)
+}
+
+// GetMyReport returns the authenticated lecturer's own teaching record
+func (h *LecturerTeachingHandler) GetMyReport(c *gin.Context) {
+	h.respondReport(c, c.MustGet("userID").(uint))
+}
+
+// respondReport builds and writes a teaching report in the requested format
+func (h *LecturerTeachingHandler) respondReport(c *gin.Context, lecturerUserID uint) {
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "xlsx" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or xlsx"})
+		return
+	}
+
+	academicYearID, ok := parseAcademicYearQuery(c)
+	if !ok {
+		return
+	}
+	academicYear, err := h.service.ResolveAcademicYear(academicYearID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.service.GetReport(academicYear, lecturerUserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build teaching report"})
+		return
+	}
+
+	if format == "json" {
+		c.JSON(http.StatusOK, gin.H{
+			"status":  "success",
+			"message": "Teaching report retrieved successfully",
+			"data":    report,
+		})
+		return
+	}
+
+	content, err := services.RenderLecturerTeachingXLSX(report)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate report"})
+		return
+	}
+
+	c.Header("Content-Description", "File Transfer")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", services.LecturerTeachingFilename(report)))
+	c.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", content)
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/delpresence/backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationHandler handles notification preference, device registration and administration requests
+type NotificationHandler struct {
+	service     *services.NotificationService
+	pushService *services.PushService
+}
+
+// NewNotificationHandler creates a new notification handler
+func NewNotificationHandler() *NotificationHandler {
+	return &NotificationHandler{
+		service:     services.NewNotificationService(),
+		pushService: services.NewPushService(),
+	}
+}
+
+// GetMyPreferences returns the authenticated user's notification preferences
+func (h *NotificationHandler) GetMyPreferences(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	preference, err := h.service.GetPreference(int(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Notification preferences retrieved successfully",
+		"data":    preference,
+	})
+}
+
+// UpdateMyPreferences updates the authenticated user's notification preferences
+func (h *NotificationHandler) UpdateMyPreferences(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	var req services.NotificationPreferenceUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	preference, err := h.service.UpdatePreference(int(userID), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Notification preferences updated successfully",
+		"data":    preference,
+	})
+}
+
+// deviceTokenRequest is the request body for registering or removing a push device token
+type deviceTokenRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Platform string `json:"platform"`
+}
+
+// GetMyDevices returns the authenticated user's registered push devices
+func (h *NotificationHandler) GetMyDevices(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	devices, err := h.pushService.ListDevices(int(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Devices retrieved successfully",
+		"data":    devices,
+	})
+}
+
+// RegisterDevice registers a push device token for the authenticated user
+func (h *NotificationHandler) RegisterDevice(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	role, _ := c.Get("role")
+	roleStr, _ := role.(string)
+
+	var req deviceTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	device, err := h.pushService.RegisterDevice(int(userID), roleStr, req.Token, req.Platform)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Device registered successfully",
+		"data":    device,
+	})
+}
+
+// UnregisterDevice removes a push device token of the authenticated user
+func (h *NotificationHandler) UnregisterDevice(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	var req deviceTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if err := h.pushService.UnregisterDevice(int(userID), req.Token); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Device unregistered successfully",
+	})
+}
+
+// GetLogs returns the most recent notification logs, optionally filtered by kind
+func (h *NotificationHandler) GetLogs(c *gin.Context) {
+	logs, err := h.service.ListLogs(c.Query("kind"), queryLimit(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Notification logs retrieved successfully",
+		"data":    logs,
+	})
+}
+
+// RunJob runs a notification job immediately. Emails already sent for the
+// same period are not sent again.
+func (h *NotificationHandler) RunJob(c *gin.Context) {
+	sent, err := h.service.RunJob(c.Param("job"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Notification job completed successfully",
+		"data":    gin.H{"sent": sent},
+	})
+}
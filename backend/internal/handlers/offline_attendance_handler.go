@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/delpresence/backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// OfflineAttendanceHandler handles check-ins captured by the mobile app while offline
+type OfflineAttendanceHandler struct {
+	service *services.OfflineAttendanceService
+}
+
+// NewOfflineAttendanceHandler creates a new offline attendance handler. The actor's attendance
+// policy authorizes reviews of flagged check-ins.
+func NewOfflineAttendanceHandler(actor services.AttendanceActor) *OfflineAttendanceHandler {
+	return &OfflineAttendanceHandler{
+		service: services.NewOfflineAttendanceService(actor),
+	}
+}
+
+// IssueSigningKey returns the key the app uses to sign offline check-ins on this device.
+// The app should fetch it while online and keep it in secure storage.
+func (h *OfflineAttendanceHandler) IssueSigningKey(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	var req struct {
+		DeviceID string `json:"device_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status": "error",
+			"error":  "Invalid request format",
+		})
+		return
+	}
+
+	// With device binding, keys are only issued to the student's registered device
+	if deviceID := c.GetString("deviceID"); deviceID != "" && req.DeviceID != deviceID {
+		c.JSON(http.StatusForbidden, gin.H{
+			"status": "error",
+			"error":  services.ErrDeviceNotRegistered.Error(),
+		})
+		return
+	}
+
+	key, err := h.service.IssueSigningKey(userID, req.DeviceID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status": "error",
+			"error":  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data": gin.H{
+			"device_id":      req.DeviceID,
+			"key":            key,
+			"algorithm":      "HMAC-SHA256",
+			"signing_string": "client_id|session_id|qr_data|scanned_at|device_id",
+		},
+	})
+}
+
+// SubmitOfflineBatch validates a batch of offline check-ins and returns the outcome of each
+func (h *OfflineAttendanceHandler) SubmitOfflineBatch(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	var req struct {
+		Submissions []services.OfflineCheckInSubmission `json:"submissions" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status": "error",
+			"error":  "Invalid request format",
+		})
+		return
+	}
+
+	results, err := h.service.SubmitBatch(userID, c.GetString("deviceID"), req.Submissions)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status": "error",
+			"error":  err.Error(),
+			"data":   results,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   results,
+	})
+}
+
+// GetSessionOfflineCheckIns lists the offline check-ins of a session, optionally filtered by outcome
+func (h *OfflineAttendanceHandler) GetSessionOfflineCheckIns(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	checkIns, err := h.service.ListSubmissions(uint(sessionID), userID, c.Query("outcome"))
+	if err != nil {
+		c.JSON(sessionErrorStatus(err, http.StatusForbidden), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Offline check-ins retrieved successfully",
+		"data":    checkIns,
+	})
+}
+
+// ReviewOfflineCheckIn accepts or rejects a flagged offline check-in
+func (h *OfflineAttendanceHandler) ReviewOfflineCheckIn(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	checkInID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid check-in ID"})
+		return
+	}
+
+	var req struct {
+		Accept *bool  `json:"accept" binding:"required"`
+		Notes  string `json:"notes"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	checkIn, err := h.service.ReviewSubmission(uint(checkInID), userID, *req.Accept, req.Notes)
+	if err != nil {
+		c.JSON(sessionErrorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Offline check-in reviewed successfully",
+		"data":    checkIn,
+	})
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/delpresence/backend/internal/models"
+	"github.com/delpresence/backend/internal/realtime"
+	"github.com/delpresence/backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// ReportJobHandler handles background report jobs and the download of their files
+type ReportJobHandler struct {
+	service *services.ReportJobService
+}
+
+// NewReportJobHandler creates a new report job handler
+func NewReportJobHandler() *ReportJobHandler {
+	return &ReportJobHandler{
+		service: services.NewReportJobService(),
+	}
+}
+
+// reportJobRequest is the request body for queuing a report job
+type reportJobRequest struct {
+	Type   models.ReportJobType `json:"type" binding:"required"`
+	Format string               `json:"format"`
+	models.ReportJobParams
+}
+
+// CreateJob queues a report job for the authenticated user
+func (h *ReportJobHandler) CreateJob(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	role := c.MustGet("role").(string)
+
+	var req reportJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	job, err := h.service.Enqueue(req.Type, req.Format, req.ReportJobParams, role, userID)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidReportJob) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"status":  "success",
+		"message": "Report job queued successfully",
+		"data":    job,
+	})
+}
+
+// GetMyJobs lists the most recent report jobs of the authenticated user
+func (h *ReportJobHandler) GetMyJobs(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	role := c.MustGet("role").(string)
+
+	jobs, err := h.service.ListJobs(role, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve report jobs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Report jobs retrieved successfully",
+		"data":    jobs,
+	})
+}
+
+// GetJob returns the status of a report job, with a download link once it is completed
+func (h *ReportJobHandler) GetJob(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	role := c.MustGet("role").(string)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
+		return
+	}
+
+	job, err := h.service.GetJob(uint(id), role, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Report job retrieved successfully",
+		"data":    job,
+	})
+}
+
+// StreamJobEvents streams status updates of a report job over Server-Sent Events, starting
+// with its current state
+func (h *ReportJobHandler) StreamJobEvents(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	role := c.MustGet("role").(string)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
+		return
+	}
+
+	// Subscribe before reading the job, so an update between the two is not missed
+	sub, err := realtime.Subscribe(realtime.ReportJobTopic(uint(id)))
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	defer sub.Close()
+
+	job, err := h.service.GetJob(uint(id), role, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	streamEvents(c, sub, "snapshot", job)
+}
+
+// DownloadArtifact serves the file of a completed report job. The link is signed and
+// time-limited, so it works without an Authorization header.
+func (h *ReportJobHandler) DownloadArtifact(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
+		return
+	}
+
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid download link"})
+		return
+	}
+
+	job, file, err := h.service.OpenArtifact(uint(id), expires, c.Query("sig"))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidDownloadLink):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrReportArtifactUnavailable):
+			c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	defer file.Close()
+
+	c.Header("Content-Description", "File Transfer")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", job.FileName))
+	c.DataFromReader(http.StatusOK, job.FileSize, job.ContentType, file, nil)
+}
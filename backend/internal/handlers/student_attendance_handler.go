@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/delpresence/backend/internal/database"
 	"github.com/delpresence/backend/internal/models"
@@ -123,11 +125,13 @@ func (h *StudentAttendanceHandler) SubmitQRAttendance(c *gin.Context) {
 
 	// Parse request body
 	var req struct {
-		SessionID          uint   `json:"session_id" binding:"required"`
-		ScheduleID         uint   `json:"schedule_id"` // Optional, used for verification
-		VerificationMethod string `json:"verification_method" binding:"required"`
-		QRData             string `json:"qr_data"`
-		Timestamp          string `json:"timestamp"`
+		SessionID          uint     `json:"session_id" binding:"required"`
+		ScheduleID         uint     `json:"schedule_id"` // Optional, used for verification
+		VerificationMethod string   `json:"verification_method" binding:"required"`
+		QRData             string   `json:"qr_data"`
+		Timestamp          string   `json:"timestamp"`
+		Latitude           *float64 `json:"latitude"`
+		Longitude          *float64 `json:"longitude"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -185,6 +189,7 @@ func (h *StudentAttendanceHandler) SubmitQRAttendance(c *gin.Context) {
 		userID,
 		models.StudentAttendanceStatusPresent,
 		req.QRData,
+		services.CheckInMetadata{DeviceID: c.GetString("deviceID"), Latitude: req.Latitude, Longitude: req.Longitude},
 	)
 
 	if err != nil {
@@ -202,6 +207,88 @@ func (h *StudentAttendanceHandler) SubmitQRAttendance(c *gin.Context) {
 	})
 }
 
+// SubmitPINAttendance handles a check-in to a PIN session with the code the lecturer reads aloud,
+// plus the device location when the session is geofenced
+func (h *StudentAttendanceHandler) SubmitPINAttendance(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	var req struct {
+		SessionID uint     `json:"session_id" binding:"required"`
+		PIN       string   `json:"pin" binding:"required"`
+		Latitude  *float64 `json:"latitude"`
+		Longitude *float64 `json:"longitude"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status": "error",
+			"error":  "Invalid request format",
+		})
+		return
+	}
+
+	err := h.attendanceService.SubmitPINAttendance(req.SessionID, userID, strings.TrimSpace(req.PIN),
+		services.CheckInMetadata{DeviceID: c.GetString("deviceID"), Latitude: req.Latitude, Longitude: req.Longitude})
+	if err != nil {
+		status := http.StatusBadRequest
+		switch {
+		case errors.Is(err, services.ErrPINAttemptsExceeded):
+			status = http.StatusTooManyRequests
+		case errors.Is(err, services.ErrOutsideGeofence):
+			status = http.StatusForbidden
+		}
+		c.JSON(status, gin.H{
+			"status": "error",
+			"error":  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Attendance recorded successfully",
+	})
+}
+
+// SubmitBeaconAttendance handles a check-in to a BEACON session with the identifier the phone
+// observed from the lecturer's device and its signal strength
+func (h *StudentAttendanceHandler) SubmitBeaconAttendance(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	var req struct {
+		SessionID  uint     `json:"session_id" binding:"required"`
+		Identifier string   `json:"identifier" binding:"required"`
+		RSSI       *int     `json:"rssi" binding:"required"`
+		Latitude   *float64 `json:"latitude"`
+		Longitude  *float64 `json:"longitude"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status": "error",
+			"error":  "Invalid request format",
+		})
+		return
+	}
+
+	err := h.attendanceService.SubmitBeaconAttendance(req.SessionID, userID, req.Identifier, *req.RSSI,
+		services.CheckInMetadata{DeviceID: c.GetString("deviceID"), Latitude: req.Latitude, Longitude: req.Longitude})
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, services.ErrBeaconTooFar) {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, gin.H{
+			"status": "error",
+			"error":  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Attendance recorded successfully",
+	})
+}
+
 // GetAttendanceHistory retrieves the attendance history for a student
 func (h *StudentAttendanceHandler) GetAttendanceHistory(c *gin.Context) {
 	// Extract student ID from the authenticated user
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/delpresence/backend/internal/models"
+	"github.com/delpresence/backend/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// deviceIDHeader is the header the app sends its device ID in with every check-in
+const deviceIDHeader = "X-Device-ID"
+
+// StudentDeviceHandler handles the devices students check in with
+type StudentDeviceHandler struct {
+	service *services.StudentDeviceService
+}
+
+// NewStudentDeviceHandler creates a new student device handler
+func NewStudentDeviceHandler() *StudentDeviceHandler {
+	return &StudentDeviceHandler{
+		service: services.NewStudentDeviceService(),
+	}
+}
+
+// RequireRegisteredDevice rejects student check-ins that do not come from the student's registered
+// device. The device ID is stored in the context as "deviceID" for the check-in handlers.
+func (h *StudentDeviceHandler) RequireRegisteredDevice(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	deviceID := c.GetHeader(deviceIDHeader)
+
+	if err := h.service.VerifyDevice(userID, deviceID); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrDeviceRequired) || errors.Is(err, services.ErrDeviceNotRegistered) {
+			status = http.StatusForbidden
+		}
+		c.AbortWithStatusJSON(status, gin.H{
+			"status": "error",
+			"error":  err.Error(),
+		})
+		return
+	}
+
+	c.Set("deviceID", deviceID)
+	c.Next()
+}
+
+// GetMyDevice returns the student's registered device, pending change and changes left this semester
+func (h *StudentDeviceHandler) GetMyDevice(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	overview, err := h.service.GetOverview(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status": "error",
+			"error":  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   overview,
+	})
+}
+
+// RegisterMyDevice registers the device the student checks in with. A change beyond the semester's
+// limit is stored as pending and answered with 202 Accepted.
+func (h *StudentDeviceHandler) RegisterMyDevice(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	var req struct {
+		DeviceID   string `json:"device_id" binding:"required"`
+		DeviceName string `json:"device_name"`
+		Reason     string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status": "error",
+			"error":  "Invalid request format",
+		})
+		return
+	}
+
+	device, err := h.service.RegisterDevice(userID, req.DeviceID, req.DeviceName, req.Reason)
+	if err != nil {
+		c.JSON(deviceErrorStatus(err), gin.H{
+			"status": "error",
+			"error":  err.Error(),
+		})
+		return
+	}
+
+	if device.Status == models.StudentDevicePending {
+		c.JSON(http.StatusAccepted, gin.H{
+			"status":  "success",
+			"message": "Device change is waiting for admin approval",
+			"data":    device,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Device registered successfully",
+		"data":    device,
+	})
+}
+
+// GetDeviceRequests lists device registrations, filtered by status (e.g. PENDING) and student_id
+func (h *StudentDeviceHandler) GetDeviceRequests(c *gin.Context) {
+	var studentID uint
+	if value := c.Query("student_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student ID"})
+			return
+		}
+		studentID = uint(id)
+	}
+
+	devices, err := h.service.ListRequests(c.Query("status"), studentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Device registrations retrieved successfully",
+		"data":    devices,
+	})
+}
+
+// ReviewDeviceRequest approves or rejects a pending device change
+func (h *StudentDeviceHandler) ReviewDeviceRequest(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid device ID"})
+		return
+	}
+
+	var req struct {
+		Approve *bool  `json:"approve" binding:"required"`
+		Notes   string `json:"notes"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	device, err := h.service.ReviewRequest(uint(id), userID, *req.Approve, req.Notes)
+	if err != nil {
+		c.JSON(deviceErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Device change reviewed successfully",
+		"data":    device,
+	})
+}
+
+// deviceErrorStatus maps device binding errors to a status code
+func deviceErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidDevice):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrDeviceTaken), errors.Is(err, services.ErrDeviceChangePending):
+		return http.StatusConflict
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusBadRequest
+	}
+}
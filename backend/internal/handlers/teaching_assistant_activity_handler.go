@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/delpresence/backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// TeachingAssistantActivityHandler handles teaching assistant workload reports
+type TeachingAssistantActivityHandler struct {
+	service *services.TeachingAssistantActivityService
+}
+
+// NewTeachingAssistantActivityHandler creates a new teaching assistant activity handler
+func NewTeachingAssistantActivityHandler() *TeachingAssistantActivityHandler {
+	return &TeachingAssistantActivityHandler{
+		service: services.NewTeachingAssistantActivityService(),
+	}
+}
+
+// GetReport returns the activity of teaching assistants in an academic year, optionally for one
+// assistant_user_id or course_id, as json or as an xlsx download with format=xlsx
+func (h *TeachingAssistantActivityHandler) GetReport(c *gin.Context) {
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "xlsx" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or xlsx"})
+		return
+	}
+
+	var filter services.TeachingAssistantActivityFilter
+	for param, target := range map[string]*uint{
+		"assistant_user_id": &filter.AssistantUserID,
+		"course_id":         &filter.CourseID,
+	} {
+		if value := c.Query(param); value != "" {
+			id, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param})
+				return
+			}
+			*target = uint(id)
+		}
+	}
+
+	academicYearID, ok := parseAcademicYearQuery(c)
+	if !ok {
+		return
+	}
+	academicYear, err := h.service.ResolveAcademicYear(academicYearID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.service.GetReport(academicYear, c.MustGet("role").(string), c.MustGet("userID").(uint), filter)
+	if err != nil {
+		if errors.Is(err, services.ErrAssistantActivityForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build teaching assistant activity report"})
+		return
+	}
+
+	if format == "json" {
+		c.JSON(http.StatusOK, gin.H{
+			"status":  "success",
+			"message": "Teaching assistant activity retrieved successfully",
+			"data":    report,
+		})
+		return
+	}
+
+	content, err := services.RenderTeachingAssistantActivityXLSX(report)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate report"})
+		return
+	}
+
+	c.Header("Content-Description", "File Transfer")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", services.TeachingAssistantActivityFilename(report)))
+	c.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", content)
+}
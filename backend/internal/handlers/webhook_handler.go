@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/delpresence/backend/internal/models"
+	"github.com/delpresence/backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookHandler handles admin requests for managing outbound webhooks
+type WebhookHandler struct {
+	service *services.WebhookService
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler() *WebhookHandler {
+	return &WebhookHandler{
+		service: services.NewWebhookService(),
+	}
+}
+
+// webhookEndpointRequest is the request body for creating or updating an endpoint
+type webhookEndpointRequest struct {
+	Name       string   `json:"name" binding:"required"`
+	URL        string   `json:"url" binding:"required"`
+	Secret     string   `json:"secret"`
+	EventTypes []string `json:"event_types"`
+	Active     *bool    `json:"active"`
+}
+
+// GetEventTypes returns the event types endpoints can subscribe to
+func (h *WebhookHandler) GetEventTypes(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Webhook event types retrieved successfully",
+		"data":    models.WebhookEventTypes,
+	})
+}
+
+// GetAllEndpoints returns all webhook endpoints
+func (h *WebhookHandler) GetAllEndpoints(c *gin.Context) {
+	endpoints, err := h.service.ListEndpoints()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Webhook endpoints retrieved successfully",
+		"data":    endpoints,
+	})
+}
+
+// GetEndpointByID returns a webhook endpoint by ID
+func (h *WebhookHandler) GetEndpointByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	endpoint, err := h.service.GetEndpoint(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Webhook endpoint retrieved successfully",
+		"data":    endpoint,
+	})
+}
+
+// CreateEndpoint registers a new webhook endpoint. The signing secret is only returned here.
+func (h *WebhookHandler) CreateEndpoint(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	var req webhookEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	endpoint, err := h.service.CreateEndpoint(req.Name, req.URL, req.Secret, req.EventTypes, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": "Webhook endpoint created successfully",
+		"data":    endpoint,
+	})
+}
+
+// UpdateEndpoint updates a webhook endpoint
+func (h *WebhookHandler) UpdateEndpoint(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var req webhookEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+
+	endpoint, err := h.service.UpdateEndpoint(uint(id), req.Name, req.URL, req.EventTypes, active)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Webhook endpoint updated successfully",
+		"data":    endpoint,
+	})
+}
+
+// DeleteEndpoint deletes a webhook endpoint
+func (h *WebhookHandler) DeleteEndpoint(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	if err := h.service.DeleteEndpoint(uint(id)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Webhook endpoint deleted successfully",
+	})
+}
+
+// GetEndpointDeliveries returns the most recent deliveries for an endpoint
+func (h *WebhookHandler) GetEndpointDeliveries(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	deliveries, err := h.service.ListDeliveries(uint(id), queryLimit(c))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Webhook deliveries retrieved successfully",
+		"data":    deliveries,
+	})
+}
+
+// GetDeadLetters returns deliveries that exhausted their retries
+func (h *WebhookHandler) GetDeadLetters(c *gin.Context) {
+	deliveries, err := h.service.ListDeadLetters(queryLimit(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Dead-letter deliveries retrieved successfully",
+		"data":    deliveries,
+	})
+}
+
+// RedeliverDelivery queues a delivered or dead delivery to be sent again. A delivered one is
+// queued as a new delivery, so the response carries the new delivery's ID.
+func (h *WebhookHandler) RedeliverDelivery(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	delivery, err := h.service.Redeliver(uint(id))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Webhook delivery queued for redelivery",
+		"data":    delivery,
+	})
+}
+
+// queryLimit parses the optional "limit" query parameter, defaulting to 100
+func queryLimit(c *gin.Context) int {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 || limit > 500 {
+		return 100
+	}
+	return limit
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// AcademicAdvisor assigns a lecturer as the academic advisor (dosen wali) of a student
+type AcademicAdvisor struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	StudentID      uint      `json:"student_id" gorm:"uniqueIndex;not null"` // Internal students.id
+	Student        *Student  `json:"student,omitempty" gorm:"foreignKey:StudentID"`
+	LecturerUserID int       `json:"lecturer_user_id" gorm:"index;not null"` // External user ID of the lecturer
+	Lecturer       *Lecturer `json:"lecturer,omitempty" gorm:"-"`            // Dynamically loaded
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName returns the table name for the AcademicAdvisor model
+func (AcademicAdvisor) TableName() string {
+	return "academic_advisors"
+}
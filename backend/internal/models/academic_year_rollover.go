@@ -0,0 +1,104 @@
+package models
+
+import (
+	"time"
+)
+
+// RolloverEntity is a kind of record an academic year rollover clones
+type RolloverEntity string
+
+const (
+	RolloverEntityCourse              RolloverEntity = "COURSE"
+	RolloverEntityStudentGroup        RolloverEntity = "STUDENT_GROUP"
+	RolloverEntityCourseSchedule      RolloverEntity = "COURSE_SCHEDULE"
+	RolloverEntityLecturerAssignment  RolloverEntity = "LECTURER_ASSIGNMENT"
+	RolloverEntityAssistantAssignment RolloverEntity = "ASSISTANT_ASSIGNMENT"
+)
+
+// RolloverAction is what a rollover does with one source record
+type RolloverAction string
+
+const (
+	RolloverActionCreate   RolloverAction = "CREATE"   // A copy is created in the target year
+	RolloverActionExists   RolloverAction = "EXISTS"   // A copy already exists, e.g. from an earlier run
+	RolloverActionSkip     RolloverAction = "SKIP"     // Not copied, see the reason
+	RolloverActionConflict RolloverAction = "CONFLICT" // Would clash with the target year; blocks the commit unless skipped
+)
+
+// RolloverGroupMode is how a rollover treats the student groups of the copied schedules
+type RolloverGroupMode string
+
+const (
+	RolloverGroupsReuse            RolloverGroupMode = "REUSE"              // Copied schedules keep their groups
+	RolloverGroupsClone            RolloverGroupMode = "CLONE"              // Groups are copied without members
+	RolloverGroupsCloneWithMembers RolloverGroupMode = "CLONE_WITH_MEMBERS" // Groups are copied with their members
+)
+
+// AcademicYearRollover is a committed copy of one academic year's courses, groups, assignments and
+// schedules into another
+type AcademicYearRollover struct {
+	ID                   uint      `json:"id" gorm:"primaryKey"`
+	SourceAcademicYearID uint      `json:"source_academic_year_id" gorm:"not null;index"`
+	TargetAcademicYearID uint      `json:"target_academic_year_id" gorm:"not null;index"`
+	Options              string    `json:"options" gorm:"type:text"` // The request as JSON
+	Created              int       `json:"created"`
+	Existing             int       `json:"existing"`
+	Skipped              int       `json:"skipped"`
+	CreatedByID          uint      `json:"created_by_id"`
+	CreatedAt            time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// AcademicYearRolloverMapping links a source record to its copy in the target year, so running
+// the same rollover again leaves existing copies alone
+type AcademicYearRolloverMapping struct {
+	ID                   uint           `json:"id" gorm:"primaryKey"`
+	SourceAcademicYearID uint           `json:"source_academic_year_id" gorm:"not null;uniqueIndex:idx_rollover_mappings_source"`
+	TargetAcademicYearID uint           `json:"target_academic_year_id" gorm:"not null;uniqueIndex:idx_rollover_mappings_source"`
+	Entity               RolloverEntity `json:"entity" gorm:"type:varchar(30);not null;uniqueIndex:idx_rollover_mappings_source"`
+	SourceID             uint           `json:"source_id" gorm:"not null;uniqueIndex:idx_rollover_mappings_source"`
+	TargetID             uint           `json:"target_id" gorm:"not null"`
+	RolloverID           uint           `json:"rollover_id" gorm:"not null;index"`
+	CreatedAt            time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt            time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// RolloverItem is one source record in a rollover preview
+type RolloverItem struct {
+	SourceID uint           `json:"source_id"`
+	TargetID *uint          `json:"target_id,omitempty"` // The existing copy, or the new one once committed
+	Label    string         `json:"label"`
+	Action   RolloverAction `json:"action"`
+	Reason   string         `json:"reason,omitempty"`
+}
+
+// RolloverSummary counts the items of a rollover preview by action
+type RolloverSummary struct {
+	Create   int `json:"create"`
+	Exists   int `json:"exists"`
+	Skip     int `json:"skip"`
+	Conflict int `json:"conflict"`
+}
+
+// RolloverPreview lists what a rollover does, or did once committed
+type RolloverPreview struct {
+	SourceAcademicYearID uint            `json:"source_academic_year_id"`
+	TargetAcademicYearID uint            `json:"target_academic_year_id"`
+	Courses              []RolloverItem  `json:"courses"`
+	StudentGroups        []RolloverItem  `json:"student_groups"`
+	Schedules            []RolloverItem  `json:"schedules"`
+	LecturerAssignments  []RolloverItem  `json:"lecturer_assignments"`
+	AssistantAssignments []RolloverItem  `json:"assistant_assignments"`
+	Summary              RolloverSummary `json:"summary"`
+	Committed            bool            `json:"committed"`
+	RolloverID           *uint           `json:"rollover_id,omitempty"`
+}
+
+// TableName returns the table name for the AcademicYearRollover model
+func (AcademicYearRollover) TableName() string {
+	return "academic_year_rollovers"
+}
+
+// TableName returns the table name for the AcademicYearRolloverMapping model
+func (AcademicYearRolloverMapping) TableName() string {
+	return "academic_year_rollover_mappings"
+}
@@ -14,6 +14,8 @@ const (
 	AttendanceTypeFaceRecognition AttendanceType = "FACE_RECOGNITION"
 	AttendanceTypeManual          AttendanceType = "MANUAL"
 	AttendanceTypeBoth            AttendanceType = "BOTH"
+	AttendanceTypePIN             AttendanceType = "PIN"    // Rotating numeric code read aloud by the lecturer
+	AttendanceTypeBeacon          AttendanceType = "BEACON" // Rotating Bluetooth identifier broadcast by the lecturer's device
 )
 
 // AttendanceStatus represents the status of an attendance session
@@ -35,28 +37,44 @@ const (
 	StudentAttendanceStatusExcused StudentAttendanceStatus = "EXCUSED"
 )
 
+// Sources of a student's attendance history entries
+const (
+	AttendanceHistorySourceCourse = "COURSE"
+	AttendanceHistorySourceEvent  = "EVENT"
+)
+
 // AttendanceSession represents an attendance session for a course schedule
 type AttendanceSession struct {
-	ID               uint             `json:"id" gorm:"primaryKey"`
-	CourseScheduleID uint             `json:"course_schedule_id" gorm:"not null;index"`
-	CourseSchedule   CourseSchedule   `json:"course_schedule,omitempty" gorm:"foreignKey:CourseScheduleID"`
-	LecturerID       uint             `json:"lecturer_id" gorm:"not null;index"`
-	Lecturer         Lecturer         `json:"lecturer,omitempty" gorm:"foreignKey:LecturerID"`
-	CreatorRole      string           `json:"creator_role" gorm:"type:varchar(20);default:'LECTURER'"` // 'LECTURER' or 'ASSISTANT'
-	Date             time.Time        `json:"date" gorm:"not null"`
-	StartTime        time.Time        `json:"start_time" gorm:"not null"`
-	EndTime          *time.Time       `json:"end_time"`
-	Type             AttendanceType   `json:"type" gorm:"not null;type:varchar(20)"`
-	Status           AttendanceStatus `json:"status" gorm:"not null;type:varchar(20)"`
-	AutoClose        bool             `json:"auto_close" gorm:"default:true"`
-	Duration         int              `json:"duration" gorm:"default:15"` // in minutes
-	AllowLate        bool             `json:"allow_late" gorm:"default:true"`
-	LateThreshold    int              `json:"late_threshold" gorm:"default:10"` // in minutes
-	Notes            string           `json:"notes" gorm:"type:text"`
-	QRCodeData       string           `json:"qr_code_data,omitempty" gorm:"type:text"`
-	CreatedAt        time.Time        `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt        time.Time        `json:"updated_at" gorm:"autoUpdateTime"`
-	DeletedAt        gorm.DeletedAt   `json:"deleted_at,omitempty" gorm:"index"`
+	ID                uint             `json:"id" gorm:"primaryKey"`
+	CourseScheduleID  uint             `json:"course_schedule_id" gorm:"not null;index"`
+	CourseSchedule    CourseSchedule   `json:"course_schedule,omitempty" gorm:"foreignKey:CourseScheduleID"`
+	LecturerID        uint             `json:"lecturer_id" gorm:"not null;index"`
+	Lecturer          Lecturer         `json:"lecturer,omitempty" gorm:"foreignKey:LecturerID"`
+	CreatorRole       string           `json:"creator_role" gorm:"type:varchar(20);default:'LECTURER'"` // 'LECTURER', 'ASSISTANT' or the attendance actor that opened it
+	Date              time.Time        `json:"date" gorm:"not null"`
+	StartTime         time.Time        `json:"start_time" gorm:"not null"`
+	EndTime           *time.Time       `json:"end_time"`
+	ClosedByID        *uint            `json:"closed_by_id"`                           // User who last closed the session by hand
+	ClosedByRole      string           `json:"closed_by_role" gorm:"type:varchar(20)"` // Recorded like CreatorRole
+	Type              AttendanceType   `json:"type" gorm:"not null;type:varchar(20)"`
+	Status            AttendanceStatus `json:"status" gorm:"not null;type:varchar(20)"`
+	AutoClose         bool             `json:"auto_close" gorm:"default:true"`
+	Duration          int              `json:"duration" gorm:"default:15"` // in minutes
+	AllowLate         bool             `json:"allow_late" gorm:"default:true"`
+	LateThreshold     int              `json:"late_threshold" gorm:"default:10"` // in minutes
+	Notes             string           `json:"notes" gorm:"type:text"`
+	QRCodeData        string           `json:"qr_code_data,omitempty" gorm:"type:text"`
+	QRRotationSeconds int              `json:"qr_rotation_seconds"`       // When positive, the QR code changes this often
+	PINSecret         string           `json:"-" gorm:"type:varchar(64)"` // Seed of the rotating code of PIN sessions
+	BeaconSecret      string           `json:"-" gorm:"type:varchar(64)"` // Seed of the rotating identifier of BEACON sessions
+	GeofenceLatitude  *float64         `json:"geofence_latitude"`
+	GeofenceLongitude *float64         `json:"geofence_longitude"`
+	GeofenceRadius    int              `json:"geofence_radius"` // Meters; PIN check-ins must be this close to the geofence center
+	ReopenedUntil     *time.Time       `json:"reopened_until"`  // A reopened session closes itself at this time
+	FirstClosedAt     *time.Time       `json:"first_closed_at"` // When the session first closed; reopening does not move it
+	CreatedAt         time.Time        `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time        `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt         gorm.DeletedAt   `json:"deleted_at,omitempty" gorm:"index"`
 }
 
 // StudentAttendance represents a student's attendance record for a session
@@ -69,8 +87,12 @@ type StudentAttendance struct {
 	Status              StudentAttendanceStatus `json:"status" gorm:"not null;type:varchar(20)"`
 	CheckInTime         *time.Time              `json:"check_in_time"`
 	Notes               string                  `json:"notes" gorm:"type:text"`
-	VerificationMethod  string                  `json:"verification_method" gorm:"type:varchar(50)"` // e.g., "QR_CODE", "FACE_RECOGNITION", "MANUAL"
-	VerifiedByID        *uint                   `json:"verified_by_id"`                              // ID of the lecturer or assistant who verified manually
+	VerificationMethod  string                  `json:"verification_method" gorm:"type:varchar(50)"`        // e.g., "QR_CODE", "FACE_RECOGNITION", "MANUAL"
+	VerifiedByID        *uint                   `json:"verified_by_id"`                                     // ID of the lecturer or assistant who verified manually
+	DeviceID            string                  `json:"device_id,omitempty" gorm:"type:varchar(128);index"` // Registered device the student checked in with
+	Latitude            *float64                `json:"latitude,omitempty"`                                 // Location the student reported when checking in
+	Longitude           *float64                `json:"longitude,omitempty"`
+	CodeExpiredAt       *time.Time              `json:"code_expired_at,omitempty"` // When the rotating code the student used was replaced, if it already was
 	CreatedAt           time.Time               `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt           time.Time               `json:"updated_at" gorm:"autoUpdateTime"`
 	DeletedAt           gorm.DeletedAt          `json:"deleted_at,omitempty" gorm:"index"`
@@ -88,31 +110,33 @@ func (StudentAttendance) TableName() string {
 
 // AttendanceSessionResponse represents a response for an attendance session
 type AttendanceSessionResponse struct {
-	ID                uint      `json:"id"`
-	CourseScheduleID  uint      `json:"course_schedule_id"`
-	CourseCode        string    `json:"course_code"`
-	CourseName        string    `json:"course_name"`
-	Room              string    `json:"room"`
-	Date              string    `json:"date"`
-	StartTime         string    `json:"start_time"`
-	EndTime           string    `json:"end_time,omitempty"`
-	ScheduleStartTime string    `json:"schedule_start_time"`
-	ScheduleEndTime   string    `json:"schedule_end_time"`
-	Type              string    `json:"type"`
-	Status            string    `json:"status"`
-	CreatorRole       string    `json:"creator_role"`
-	AutoClose         bool      `json:"auto_close"`
-	Duration          int       `json:"duration"`
-	AllowLate         bool      `json:"allow_late"`
-	LateThreshold     int       `json:"late_threshold"`
-	Notes             string    `json:"notes"`
-	QRCodeURL         string    `json:"qr_code_url,omitempty"`
-	TotalStudents     int       `json:"total_students"`
-	AttendedCount     int       `json:"attended_count"`
-	LateCount         int       `json:"late_count"`
-	AbsentCount       int       `json:"absent_count"`
-	ExcusedCount      int       `json:"excused_count"`
-	CreatedAt         time.Time `json:"created_at"`
+	ID                uint       `json:"id"`
+	CourseScheduleID  uint       `json:"course_schedule_id"`
+	CourseCode        string     `json:"course_code"`
+	CourseName        string     `json:"course_name"`
+	Room              string     `json:"room"`
+	Date              string     `json:"date"`
+	StartTime         string     `json:"start_time"`
+	EndTime           string     `json:"end_time,omitempty"`
+	ScheduleStartTime string     `json:"schedule_start_time"`
+	ScheduleEndTime   string     `json:"schedule_end_time"`
+	Type              string     `json:"type"`
+	Status            string     `json:"status"`
+	CreatorRole       string     `json:"creator_role"`
+	AutoClose         bool       `json:"auto_close"`
+	Duration          int        `json:"duration"`
+	AllowLate         bool       `json:"allow_late"`
+	LateThreshold     int        `json:"late_threshold"`
+	Notes             string     `json:"notes"`
+	QRCodeURL         string     `json:"qr_code_url,omitempty"`
+	TotalStudents     int        `json:"total_students"`
+	AttendedCount     int        `json:"attended_count"`
+	LateCount         int        `json:"late_count"`
+	AbsentCount       int        `json:"absent_count"`
+	ExcusedCount      int        `json:"excused_count"`
+	ReopenedUntil     *time.Time `json:"reopened_until,omitempty"` // Set while a reopened session is active
+	EditableUntil     *time.Time `json:"editable_until,omitempty"` // End of the correction window of a closed session
+	CreatedAt         time.Time  `json:"created_at"`
 }
 
 // StudentAttendanceResponse represents a response for a student's attendance
@@ -131,6 +155,8 @@ type StudentAttendanceResponse struct {
 // StudentAttendanceHistoryResponse represents detailed attendance history for the mobile app
 type StudentAttendanceHistoryResponse struct {
 	ID                 uint   `json:"id"`
+	Source             string `json:"source"`                    // "COURSE" or "EVENT"
+	CampusEventID      *uint  `json:"campus_event_id,omitempty"` // Set for campus event records
 	Date               string `json:"date"`
 	CourseCode         string `json:"course_code"`
 	CourseName         string `json:"course_name"`
@@ -142,11 +168,19 @@ type StudentAttendanceHistoryResponse struct {
 
 // AttendanceStatistics represents statistics for attendance sessions
 type AttendanceStatistics struct {
-	TotalSessions     int `json:"total_sessions"`
-	TotalStudents     int `json:"total_students"`
-	TotalAttendance   int `json:"total_attendance"`
-	TotalLate         int `json:"total_late"`
-	TotalAbsent       int `json:"total_absent"`
-	TotalExcused      int `json:"total_excused"`
-	AverageAttendance int `json:"average_attendance"` // Percentage
+	TotalSessions     int     `json:"total_sessions"`
+	TotalStudents     int     `json:"total_students"`
+	TotalAttendance   int     `json:"total_attendance"`
+	TotalLate         int     `json:"total_late"`
+	TotalAbsent       int     `json:"total_absent"`
+	TotalExcused      int     `json:"total_excused"`
+	AverageAttendance int     `json:"average_attendance"` // Percentage
+	ActiveSessions    int     `json:"active_sessions"`
+	ClosedSessions    int     `json:"closed_sessions"`
+	CanceledSessions  int     `json:"canceled_sessions"`
+	TotalRecords      int     `json:"total_records"`
+	AttendanceRate    float64 `json:"attendance_rate"` // Present and late over all records, as a percentage
+
+	BySession []AttendanceBreakdownItem `json:"by_session"`
+	ByStudent []AttendanceBreakdownItem `json:"by_student"`
 }
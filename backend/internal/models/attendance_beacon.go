@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// AttendanceBeaconResponse is what the lecturer's device broadcasts for a BEACON session until
+// ExpiresAt, when it fetches the next identifier
+type AttendanceBeaconResponse struct {
+	SessionID       uint      `json:"session_id"`
+	ServiceUUID     string    `json:"service_uuid"` // Bluetooth service the app scans for
+	Identifier      string    `json:"identifier"`   // Hex service data to advertise
+	ExpiresAt       time.Time `json:"expires_at"`
+	RotationSeconds int       `json:"rotation_seconds"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// AttendanceEventType represents the kind of real-time attendance event
+type AttendanceEventType string
+
+const (
+	AttendanceEventSessionOpened        AttendanceEventType = "SESSION_OPENED"
+	AttendanceEventSessionClosed        AttendanceEventType = "SESSION_CLOSED"
+	AttendanceEventSessionCanceled      AttendanceEventType = "SESSION_CANCELED"
+	AttendanceEventStudentCheckedIn     AttendanceEventType = "STUDENT_CHECKED_IN"
+	AttendanceEventStudentStatusChanged AttendanceEventType = "STUDENT_STATUS_CHANGED"
+)
+
+// AttendanceEvent is the payload pushed to clients subscribed to an attendance session or student group
+type AttendanceEvent struct {
+	Type               AttendanceEventType `json:"type"`
+	SessionID          uint                `json:"session_id"`
+	CourseScheduleID   uint                `json:"course_schedule_id"`
+	StudentGroupID     uint                `json:"student_group_id,omitempty"`
+	SessionStatus      string              `json:"session_status,omitempty"`
+	CourseCode         string              `json:"course_code,omitempty"`
+	CourseName         string              `json:"course_name,omitempty"`
+	StudentID          uint                `json:"student_id,omitempty"` // External user ID, same as StudentAttendanceResponse
+	StudentNIM         string              `json:"student_nim,omitempty"`
+	StudentName        string              `json:"student_name,omitempty"`
+	Status             string              `json:"status,omitempty"`
+	CheckInTime        string              `json:"check_in_time,omitempty"`
+	VerificationMethod string              `json:"verification_method,omitempty"`
+	OccurredAt         time.Time           `json:"occurred_at"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+)
+
+// AttendanceFraudRule is a pattern of proxy attendance the fraud analysis looks for
+type AttendanceFraudRule string
+
+const (
+	FraudRuleSharedDevice       AttendanceFraudRule = "SHARED_DEVICE"       // One device checked in several students
+	FraudRuleIdenticalTimestamp AttendanceFraudRule = "IDENTICAL_TIMESTAMP" // Students checked in at the same instant
+	FraudRuleIdenticalLocation  AttendanceFraudRule = "IDENTICAL_LOCATION"  // Students reported the exact same coordinates
+	FraudRuleImpossibleTravel   AttendanceFraudRule = "IMPOSSIBLE_TRAVEL"   // Too far from the student's previous check-in for the time between them
+	FraudRuleStaleCode          AttendanceFraudRule = "STALE_CODE"          // Checked in with a QR, PIN or beacon code that had already rotated
+)
+
+// AttendanceFraudFlagStatus is the review state of a fraud flag
+type AttendanceFraudFlagStatus string
+
+const (
+	FraudFlagOpen      AttendanceFraudFlagStatus = "OPEN"
+	FraudFlagConfirmed AttendanceFraudFlagStatus = "CONFIRMED" // The check-in was proxy attendance and is now absent
+	FraudFlagDismissed AttendanceFraudFlagStatus = "DISMISSED"
+)
+
+// AttendanceFraudFlag is a suspicious check-in found by the fraud analysis, waiting for the
+// lecturer or assistant of the session. A check-in is flagged at most once per rule.
+type AttendanceFraudFlag struct {
+	ID                  uint                      `json:"id" gorm:"primaryKey"`
+	StudentAttendanceID uint                      `json:"student_attendance_id" gorm:"not null;uniqueIndex:idx_fraud_flags_attendance_rule"`
+	Rule                AttendanceFraudRule       `json:"rule" gorm:"type:varchar(30);not null;uniqueIndex:idx_fraud_flags_attendance_rule"`
+	AttendanceSessionID uint                      `json:"attendance_session_id" gorm:"not null;index"`
+	StudentID           uint                      `json:"student_id" gorm:"not null;index"`
+	Student             Student                   `json:"student,omitempty" gorm:"foreignKey:StudentID"`
+	Details             string                    `json:"details" gorm:"type:text"`
+	Status              AttendanceFraudFlagStatus `json:"status" gorm:"type:varchar(20);not null;index"`
+	ReviewedByID        *uint                     `json:"reviewed_by_id"`
+	ReviewedAt          *time.Time                `json:"reviewed_at"`
+	ReviewNotes         string                    `json:"review_notes" gorm:"type:text"`
+	CreatedAt           time.Time                 `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt           time.Time                 `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName returns the table name for the AttendanceFraudFlag model
+func (AttendanceFraudFlag) TableName() string {
+	return "attendance_fraud_flags"
+}
@@ -0,0 +1,57 @@
+package models
+
+// AttendanceMatrixMeeting is one column of an attendance matrix
+type AttendanceMatrixMeeting struct {
+	Number    int    `json:"number"`
+	SessionID uint   `json:"session_id"`
+	Date      string `json:"date"`
+	StartTime string `json:"start_time"`
+	Status    string `json:"status"`
+}
+
+// AttendanceMatrixRow is one student's line in an attendance matrix. Codes holds one status code
+// per meeting: H (hadir), T (terlambat), A (alpa), I (izin) or "-" when the student has no record.
+type AttendanceMatrixRow struct {
+	StudentID      uint     `json:"student_id"`
+	NIM            string   `json:"nim"`
+	FullName       string   `json:"full_name"`
+	Codes          []string `json:"codes"`
+	Present        int      `json:"present"`
+	Late           int      `json:"late"`
+	Absent         int      `json:"absent"`
+	Excused        int      `json:"excused"`
+	AttendanceRate float64  `json:"attendance_rate"`
+}
+
+// AttendanceMatrix is the semester attendance of one course schedule, students by meetings
+type AttendanceMatrix struct {
+	CourseScheduleID uint                      `json:"course_schedule_id"`
+	CourseID         uint                      `json:"course_id"`
+	CourseCode       string                    `json:"course_code"`
+	CourseName       string                    `json:"course_name"`
+	StudentGroupName string                    `json:"student_group_name"`
+	LecturerName     string                    `json:"lecturer_name"`
+	Day              string                    `json:"day"`
+	StartTime        string                    `json:"start_time"`
+	EndTime          string                    `json:"end_time"`
+	AcademicYearName string                    `json:"academic_year_name"`
+	Semester         string                    `json:"semester"`
+	Meetings         []AttendanceMatrixMeeting `json:"meetings"`
+	Rows             []AttendanceMatrixRow     `json:"rows"`
+}
+
+// AttendanceStatusCode returns the single-letter code of a student attendance status used in matrices
+func AttendanceStatusCode(status StudentAttendanceStatus) string {
+	switch status {
+	case StudentAttendanceStatusPresent:
+		return "H"
+	case StudentAttendanceStatusLate:
+		return "T"
+	case StudentAttendanceStatusAbsent:
+		return "A"
+	case StudentAttendanceStatusExcused:
+		return "I"
+	default:
+		return "-"
+	}
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+)
+
+// AttendanceOverrideAction is a change made to attendance outside the normal session flow
+type AttendanceOverrideAction string
+
+const (
+	AttendanceOverrideCreateSession AttendanceOverrideAction = "CREATE_SESSION" // Session created retroactively
+	AttendanceOverrideReopenSession AttendanceOverrideAction = "REOPEN_SESSION"
+	AttendanceOverrideCloseSession  AttendanceOverrideAction = "CLOSE_SESSION"
+	AttendanceOverrideCancelSession AttendanceOverrideAction = "CANCEL_SESSION"
+	AttendanceOverrideSetStatus     AttendanceOverrideAction = "SET_STATUS"   // A student's status corrected
+	AttendanceOverrideExcuseGroup   AttendanceOverrideAction = "EXCUSE_GROUP" // A student excused as part of a group
+)
+
+// AttendanceOverride records one change to a session or to a student's attendance in it, made by
+// an admin or by a lecturer correcting a closed session. Rows are never updated or deleted, so they
+// form the audit trail and the correction history of each session.
+type AttendanceOverride struct {
+	ID                  uint                     `json:"id" gorm:"primaryKey"`
+	Action              AttendanceOverrideAction `json:"action" gorm:"type:varchar(20);not null;index"`
+	AttendanceSessionID uint                     `json:"attendance_session_id" gorm:"not null;index"`
+	StudentID           *uint                    `json:"student_id" gorm:"index"` // Set for changes to a student's attendance
+	Student             *Student                 `json:"student,omitempty" gorm:"foreignKey:StudentID"`
+	PreviousValue       string                   `json:"previous_value" gorm:"type:varchar(20)"` // Session or student status before the change
+	NewValue            string                   `json:"new_value" gorm:"type:varchar(20)"`
+	Reason              string                   `json:"reason" gorm:"type:text;not null"`
+	StudentGroupID      *uint                    `json:"student_group_id"` // Group of an EXCUSE_GROUP change
+	PerformedByID       uint                     `json:"performed_by_id" gorm:"not null;index;comment:External user ID from campus system"`
+	PerformedByRole     string                   `json:"performed_by_role" gorm:"type:varchar(20)"` // Attendance actor, e.g. "admin" or "lecturer"
+	CreatedAt           time.Time                `json:"created_at" gorm:"autoCreateTime;index"`
+}
+
+// TableName returns the table name for the AttendanceOverride model
+func (AttendanceOverride) TableName() string {
+	return "attendance_overrides"
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// AttendancePINAttempt is a wrong code a student submitted to a PIN session; recent attempts
+// limit how often a student may guess
+type AttendancePINAttempt struct {
+	ID                  uint      `json:"id" gorm:"primaryKey"`
+	AttendanceSessionID uint      `json:"attendance_session_id" gorm:"not null;index:idx_pin_attempt_session_student"`
+	StudentID           uint      `json:"student_id" gorm:"not null;index:idx_pin_attempt_session_student"`
+	CreatedAt           time.Time `json:"created_at" gorm:"autoCreateTime;index"`
+}
+
+// AttendancePINResponse is the current code of a PIN session as shown to the lecturer
+type AttendancePINResponse struct {
+	SessionID       uint      `json:"session_id"`
+	PIN             string    `json:"pin"`
+	ExpiresAt       time.Time `json:"expires_at"`
+	RotationSeconds int       `json:"rotation_seconds"`
+}
+
+// TableName returns the table name for the AttendancePINAttempt model
+func (AttendancePINAttempt) TableName() string {
+	return "attendance_pin_attempts"
+}
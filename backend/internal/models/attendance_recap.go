@@ -0,0 +1,42 @@
+package models
+
+// CourseAttendanceRecap is a student's attendance in one course schedule over an academic year
+type CourseAttendanceRecap struct {
+	CourseScheduleID uint    `json:"course_schedule_id"`
+	CourseID         uint    `json:"course_id"`
+	CourseCode       string  `json:"course_code"`
+	CourseName       string  `json:"course_name"`
+	Credits          int     `json:"credits"`
+	LecturerName     string  `json:"lecturer_name"`
+	StudentGroupName string  `json:"student_group_name"`
+	MeetingsHeld     int     `json:"meetings_held"` // Closed sessions the student was expected to attend
+	Present          int     `json:"present"`
+	Late             int     `json:"late"`
+	Absent           int     `json:"absent"`
+	Excused          int     `json:"excused"`
+	AttendanceRate   float64 `json:"attendance_rate"`
+	Eligible         bool    `json:"eligible"`
+}
+
+// StudentAttendanceRecap is a student's attendance across all courses of an academic year,
+// with the eligibility verdict for each course
+type StudentAttendanceRecap struct {
+	StudentID        uint                    `json:"student_id"`
+	StudentUserID    int                     `json:"student_user_id"`
+	NIM              string                  `json:"nim"`
+	FullName         string                  `json:"full_name"`
+	StudyProgram     string                  `json:"study_program"`
+	YearEnrolled     int                     `json:"year_enrolled"`
+	AdvisorName      string                  `json:"advisor_name,omitempty"`
+	AdvisorNIP       string                  `json:"advisor_nip,omitempty"`
+	AcademicYear     *AcademicYear           `json:"academic_year"`
+	ThresholdPercent float64                 `json:"threshold_percent"`
+	Courses          []CourseAttendanceRecap `json:"courses"`
+	MeetingsHeld     int                     `json:"meetings_held"`
+	Present          int                     `json:"present"`
+	Late             int                     `json:"late"`
+	Absent           int                     `json:"absent"`
+	Excused          int                     `json:"excused"`
+	AttendanceRate   float64                 `json:"attendance_rate"`
+	IneligibleCount  int                     `json:"ineligible_count"`
+}
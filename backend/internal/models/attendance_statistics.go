@@ -0,0 +1,95 @@
+package models
+
+// AttendanceBreakdown is the dimension attendance statistics are grouped by
+type AttendanceBreakdown string
+
+const (
+	AttendanceBreakdownSession  AttendanceBreakdown = "session"
+	AttendanceBreakdownSchedule AttendanceBreakdown = "schedule"
+	AttendanceBreakdownCourse   AttendanceBreakdown = "course"
+	AttendanceBreakdownStudent  AttendanceBreakdown = "student"
+	AttendanceBreakdownGroup    AttendanceBreakdown = "group"
+
+	AttendanceBreakdownFaculty      AttendanceBreakdown = "faculty"
+	AttendanceBreakdownStudyProgram AttendanceBreakdown = "study_program"
+	AttendanceBreakdownLecturer     AttendanceBreakdown = "lecturer"
+	AttendanceBreakdownDayOfWeek    AttendanceBreakdown = "day_of_week"
+	AttendanceBreakdownTimeSlot     AttendanceBreakdown = "time_slot"
+)
+
+// AttendanceStatusCounts holds the number of attendance records in each status
+type AttendanceStatusCounts struct {
+	Present int `json:"present"`
+	Late    int `json:"late"`
+	Absent  int `json:"absent"`
+	Excused int `json:"excused"`
+	Total   int `json:"total"`
+}
+
+// AttendedRate returns present and late records over all records, as a percentage
+func (c AttendanceStatusCounts) AttendedRate() float64 {
+	if c.Total == 0 {
+		return 0
+	}
+	return float64(c.Present+c.Late) * 100 / float64(c.Total)
+}
+
+// AttendanceBreakdownItem is one row of attendance statistics grouped by a dimension.
+// Code and Name describe the row, e.g. the NIM and name of a student or the code and name of a course.
+type AttendanceBreakdownItem struct {
+	ID             uint    `json:"id"`
+	Code           string  `json:"code"`
+	Name           string  `json:"name"`
+	Date           string  `json:"date,omitempty"` // Only set for sessions
+	Sessions       int     `json:"sessions"`
+	Present        int     `json:"present"`
+	Late           int     `json:"late"`
+	Absent         int     `json:"absent"`
+	Excused        int     `json:"excused"`
+	Total          int     `json:"total"`
+	AttendanceRate float64 `json:"attendance_rate"`
+}
+
+// AttendanceTrendPoint holds the attendance counts of one week
+type AttendanceTrendPoint struct {
+	Week           int     `json:"week"` // Week number within the academic year, starting at 1
+	WeekStart      string  `json:"week_start"`
+	Sessions       int     `json:"sessions"`
+	Present        int     `json:"present"`
+	Late           int     `json:"late"`
+	Absent         int     `json:"absent"`
+	Excused        int     `json:"excused"`
+	Total          int     `json:"total"`
+	AttendanceRate float64 `json:"attendance_rate"`
+}
+
+// LateArrivalBucket counts late check-ins that arrived within a range of minutes after the session started
+type LateArrivalBucket struct {
+	Label      string  `json:"label"`
+	MinMinutes int     `json:"min_minutes"`
+	MaxMinutes int     `json:"max_minutes,omitempty"` // Zero for the open-ended last bucket
+	Count      int     `json:"count"`
+	Share      float64 `json:"share"` // Percentage of all late check-ins
+}
+
+// VerificationMethodShare counts check-ins recorded with one verification method
+type VerificationMethodShare struct {
+	Method string  `json:"method"`
+	Count  int     `json:"count"`
+	Share  float64 `json:"share"` // Percentage of all check-ins
+}
+
+// PlannedSessionCoverage compares the meetings a schedule should have had with the sessions opened
+type PlannedSessionCoverage struct {
+	CourseScheduleID uint    `json:"course_schedule_id"`
+	CourseCode       string  `json:"course_code"`
+	CourseName       string  `json:"course_name"`
+	LecturerUserID   uint    `json:"lecturer_user_id"`
+	Day              string  `json:"day"`
+	StartTime        string  `json:"start_time"`
+	Planned          int     `json:"planned"`
+	Opened           int     `json:"opened"` // Planned dates with a session
+	NeverOpened      int     `json:"never_opened"`
+	Unplanned        int     `json:"unplanned"` // Sessions on dates the schedule does not meet
+	NeverOpenedShare float64 `json:"never_opened_share"`
+}
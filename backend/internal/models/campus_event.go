@@ -0,0 +1,164 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CampusEventCategory is the kind of a campus event
+type CampusEventCategory string
+
+const (
+	CampusEventGeneralLecture CampusEventCategory = "GENERAL_LECTURE" // Kuliah umum
+	CampusEventSeminar        CampusEventCategory = "SEMINAR"
+	CampusEventAssembly       CampusEventCategory = "ASSEMBLY" // e.g. dormitory assemblies
+	CampusEventCeremony       CampusEventCategory = "CEREMONY"
+	CampusEventRollCall       CampusEventCategory = "ROLL_CALL" // Nightly dormitory roll call (apel malam)
+	CampusEventOther          CampusEventCategory = "OTHER"
+)
+
+// CampusEventAudience is who is expected to attend a campus event
+type CampusEventAudience string
+
+const (
+	CampusEventAudienceAll          CampusEventAudience = "ALL_STUDENTS"
+	CampusEventAudienceStudyProgram CampusEventAudience = "STUDY_PROGRAM"
+	CampusEventAudienceIntakeYear   CampusEventAudience = "INTAKE_YEAR"
+	CampusEventAudienceGroup        CampusEventAudience = "STUDENT_GROUP"
+	CampusEventAudienceList         CampusEventAudience = "LIST"
+	CampusEventAudienceDormitory    CampusEventAudience = "DORMITORY" // Students whose synced dormitory matches
+)
+
+// CampusEventStatus is the state of a campus event's attendance
+type CampusEventStatus string
+
+const (
+	CampusEventScheduled CampusEventStatus = "SCHEDULED"
+	CampusEventActive    CampusEventStatus = "ACTIVE" // Check-in is open
+	CampusEventClosed    CampusEventStatus = "CLOSED"
+	CampusEventCanceled  CampusEventStatus = "CANCELED"
+)
+
+// CampusEvent is an activity outside courses that takes student attendance, such as a general
+// lecture, seminar, assembly or ceremony
+type CampusEvent struct {
+	ID          uint                `json:"id" gorm:"primaryKey"`
+	Title       string              `json:"title" gorm:"type:varchar(200);not null"`
+	Description string              `json:"description" gorm:"type:text"`
+	Category    CampusEventCategory `json:"category" gorm:"type:varchar(20);not null;index"`
+	StartAt     time.Time           `json:"start_at" gorm:"not null;index"`
+	EndAt       time.Time           `json:"end_at" gorm:"not null"`
+	RoomID      *uint               `json:"room_id"`
+	Room        *Room               `json:"room,omitempty" gorm:"foreignKey:RoomID"`
+	Location    string              `json:"location" gorm:"type:varchar(200)"` // Free text when the event is not held in a room
+
+	// Organizer is the unit holding the event; OrganizerID is the user who manages it
+	Organizer     string `json:"organizer" gorm:"type:varchar(200)"`
+	OrganizerID   uint   `json:"organizer_id" gorm:"not null;index"`
+	OrganizerRole string `json:"organizer_role" gorm:"type:varchar(20)"`
+
+	// Target audience; only the field matching AudienceType is used
+	AudienceType           CampusEventAudience `json:"audience_type" gorm:"type:varchar(20);not null"`
+	AudienceStudyProgramID *int                `json:"audience_study_program_id"` // Campus study program ID as synced on students
+	AudienceIntakeYear     *int                `json:"audience_intake_year"`
+	AudienceStudentGroupID *uint               `json:"audience_student_group_id"`
+	AudienceDormitory      *string             `json:"audience_dormitory" gorm:"type:varchar(50);index"`
+
+	// Check-in settings, as for course attendance sessions
+	AttendanceType AttendanceType    `json:"attendance_type" gorm:"type:varchar(20);not null"`
+	AllowLate      bool              `json:"allow_late" gorm:"default:true"`
+	LateThreshold  int               `json:"late_threshold" gorm:"default:10"` // Minutes after StartAt
+	AutoOpenClose  bool              `json:"auto_open_close"`                  // Check-in opens at StartAt and closes at EndAt by itself
+	Status         CampusEventStatus `json:"status" gorm:"type:varchar(20);not null;index"`
+	QRCodeData     string            `json:"-" gorm:"type:text"`
+	OpenedAt       *time.Time        `json:"opened_at"`
+	ClosedAt       *time.Time        `json:"closed_at"`
+
+	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// CampusEventParticipant is a student invited to an event whose audience is an explicit list
+type CampusEventParticipant struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	CampusEventID uint      `json:"campus_event_id" gorm:"not null;uniqueIndex:idx_campus_event_participant"`
+	StudentID     uint      `json:"student_id" gorm:"not null;uniqueIndex:idx_campus_event_participant"`
+	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// CampusEventAttendance is a student's attendance record for a campus event. Records are created
+// as ABSENT for the whole audience when check-in opens.
+type CampusEventAttendance struct {
+	ID                 uint                    `json:"id" gorm:"primaryKey"`
+	CampusEventID      uint                    `json:"campus_event_id" gorm:"not null;uniqueIndex:idx_campus_event_attendance"`
+	CampusEvent        CampusEvent             `json:"campus_event,omitempty" gorm:"foreignKey:CampusEventID"`
+	StudentID          uint                    `json:"student_id" gorm:"not null;uniqueIndex:idx_campus_event_attendance;index"`
+	Student            Student                 `json:"student,omitempty" gorm:"foreignKey:StudentID"`
+	Status             StudentAttendanceStatus `json:"status" gorm:"not null;type:varchar(20)"`
+	CheckInTime        *time.Time              `json:"check_in_time"`
+	VerificationMethod string                  `json:"verification_method" gorm:"type:varchar(50)"`
+	VerifiedByID       *uint                   `json:"verified_by_id"`
+	Notes              string                  `json:"notes" gorm:"type:text"`
+	CreatedAt          time.Time               `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt          time.Time               `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// CampusEventResponse is a campus event with its attendance counts
+type CampusEventResponse struct {
+	CampusEvent
+	RoomName      string `json:"room_name,omitempty"`
+	QRCodeURL     string `json:"qr_code_url,omitempty"`
+	TotalStudents int    `json:"total_students"`
+	AttendedCount int    `json:"attended_count"`
+	LateCount     int    `json:"late_count"`
+	AbsentCount   int    `json:"absent_count"`
+	ExcusedCount  int    `json:"excused_count"`
+}
+
+// DormitorySummary is a dormitory with the number of students living in it
+type DormitorySummary struct {
+	Dormitory     string `json:"dormitory"`
+	TotalStudents int    `json:"total_students"`
+}
+
+// RollCallAbsence is a student's roll call attendance in a dormitory over a period
+type RollCallAbsence struct {
+	StudentID      uint     `json:"student_id"`
+	StudentName    string   `json:"student_name"`
+	StudentNIM     string   `json:"student_nim"`
+	Dormitory      string   `json:"dormitory"`
+	RollCalls      int      `json:"roll_calls"`
+	PresentCount   int      `json:"present_count"`
+	LateCount      int      `json:"late_count"`
+	AbsentCount    int      `json:"absent_count"`
+	ExcusedCount   int      `json:"excused_count"`
+	AbsenceDates   []string `json:"absence_dates"`
+	AttendanceRate float64  `json:"attendance_rate"`
+}
+
+// RollCallAbsenceReport lists the students of a dormitory who missed roll calls in a period
+type RollCallAbsenceReport struct {
+	Dormitory   string            `json:"dormitory"`
+	From        string            `json:"from"`
+	To          string            `json:"to"`
+	RollCalls   int               `json:"roll_calls"`
+	MinAbsences int               `json:"min_absences"`
+	Students    []RollCallAbsence `json:"students"`
+}
+
+// TableName returns the table name for the CampusEvent model
+func (CampusEvent) TableName() string {
+	return "campus_events"
+}
+
+// TableName returns the table name for the CampusEventParticipant model
+func (CampusEventParticipant) TableName() string {
+	return "campus_event_participants"
+}
+
+// TableName returns the table name for the CampusEventAttendance model
+func (CampusEventAttendance) TableName() string {
+	return "campus_event_attendances"
+}
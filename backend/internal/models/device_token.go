@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+// DeviceToken is a push notification token registered by a mobile app installation
+type DeviceToken struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	UserID     int       `json:"user_id" gorm:"not null;index;comment:External user ID from campus system"`
+	Role       string    `json:"role" gorm:"type:varchar(30)"`
+	Token      string    `json:"token" gorm:"type:varchar(512);not null;uniqueIndex"`
+	Platform   string    `json:"platform" gorm:"type:varchar(20)"` // 'android', 'ios' or 'web'
+	LastSeenAt time.Time `json:"last_seen_at"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName returns the table name for the DeviceToken model
+func (DeviceToken) TableName() string {
+	return "device_tokens"
+}
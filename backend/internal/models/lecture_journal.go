@@ -0,0 +1,117 @@
+package models
+
+import (
+	"time"
+)
+
+// LessonPlanTopic is one planned meeting of a course's semester lesson plan (Rencana
+// Pembelajaran Semester, RPS)
+type LessonPlanTopic struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	CourseID       uint      `json:"course_id" gorm:"not null;uniqueIndex:idx_lesson_plan_topics_meeting"`
+	MeetingNumber  int       `json:"meeting_number" gorm:"not null;uniqueIndex:idx_lesson_plan_topics_meeting"`
+	Topic          string    `json:"topic" gorm:"type:text;not null"`
+	SubTopics      []string  `json:"sub_topics" gorm:"type:text;serializer:json"`
+	TeachingMethod string    `json:"teaching_method" gorm:"type:varchar(100)"` // Planned method, e.g. "Ceramah", "Diskusi", "Praktikum"
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName returns the table name for the LessonPlanTopic model
+func (LessonPlanTopic) TableName() string {
+	return "lesson_plan_topics"
+}
+
+// LectureJournalStatus is the sign-off state of a lecture journal
+type LectureJournalStatus string
+
+const (
+	LectureJournalDraft  LectureJournalStatus = "DRAFT"
+	LectureJournalSigned LectureJournalStatus = "SIGNED" // Confirmed by the lecturer or assistant; no longer editable
+)
+
+// LectureJournal is the lecture journal (Berita Acara Perkuliahan, BAP) of one meeting of a
+// schedule: what was taught and how. It belongs to the meeting's attendance session, or to a
+// planned meeting date when no session was opened.
+type LectureJournal struct {
+	ID                    uint                       `json:"id" gorm:"primaryKey"`
+	CourseScheduleID      uint                       `json:"course_schedule_id" gorm:"not null;uniqueIndex:idx_lecture_journals_meeting"`
+	CourseSchedule        CourseSchedule             `json:"-" gorm:"foreignKey:CourseScheduleID"`
+	MeetingDate           time.Time                  `json:"meeting_date" gorm:"type:date;not null;uniqueIndex:idx_lecture_journals_meeting"`
+	AttendanceSessionID   *uint                      `json:"attendance_session_id" gorm:"uniqueIndex"`
+	MeetingNumber         int                        `json:"meeting_number"`
+	LessonPlanTopicID     *uint                      `json:"lesson_plan_topic_id"` // Planned RPS topic of the meeting
+	LessonPlanTopic       *LessonPlanTopic           `json:"lesson_plan_topic,omitempty" gorm:"foreignKey:LessonPlanTopicID"`
+	Topic                 string                     `json:"topic" gorm:"type:text;not null"` // Topic actually taught
+	SubTopics             []string                   `json:"sub_topics" gorm:"type:text;serializer:json"`
+	TeachingMethod        string                     `json:"teaching_method" gorm:"type:varchar(100)"`
+	Notes                 string                     `json:"notes" gorm:"type:text"`
+	Status                LectureJournalStatus       `json:"status" gorm:"type:varchar(20);not null;default:'DRAFT'"`
+	CreatedByID           uint                       `json:"created_by_id" gorm:"not null;comment:External user ID from campus system"`
+	ClassRepresentativeID *uint                      `json:"class_representative_id"` // Student asked to co-sign
+	ClassRepresentative   *Student                   `json:"class_representative,omitempty" gorm:"foreignKey:ClassRepresentativeID"`
+	SignedByID            *uint                      `json:"signed_by_id"`
+	SignedByRole          string                     `json:"signed_by_role" gorm:"type:varchar(20)"`
+	SignedAt              *time.Time                 `json:"signed_at"`
+	CosignedAt            *time.Time                 `json:"cosigned_at"` // Co-signed by the class representative
+	Attachments           []LectureJournalAttachment `json:"attachments" gorm:"foreignKey:LectureJournalID"`
+	CreatedAt             time.Time                  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt             time.Time                  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName returns the table name for the LectureJournal model
+func (LectureJournal) TableName() string {
+	return "lecture_journals"
+}
+
+// LectureJournalAttachment is a material link or an uploaded file of a lecture journal
+type LectureJournalAttachment struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	LectureJournalID uint      `json:"lecture_journal_id" gorm:"not null;index"`
+	Title            string    `json:"title" gorm:"type:varchar(255)"`
+	URL              string    `json:"url" gorm:"type:text"` // Set for links
+	FileName         string    `json:"file_name" gorm:"type:varchar(255)"`
+	ContentType      string    `json:"content_type" gorm:"type:varchar(100)"`
+	StoragePath      string    `json:"-" gorm:"type:text"` // Set for uploaded files
+	FileSize         int64     `json:"file_size"`
+	UploadedByID     uint      `json:"uploaded_by_id"`
+	CreatedAt        time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName returns the table name for the LectureJournalAttachment model
+func (LectureJournalAttachment) TableName() string {
+	return "lecture_journal_attachments"
+}
+
+// LessonPlanCoverage compares the planned RPS topics of a course with the topics delivered by
+// each of its schedules
+type LessonPlanCoverage struct {
+	CourseID        uint                     `json:"course_id"`
+	CourseCode      string                   `json:"course_code"`
+	CourseName      string                   `json:"course_name"`
+	PlannedMeetings int                      `json:"planned_meetings"`
+	Schedules       []LessonPlanScheduleItem `json:"schedules"`
+}
+
+// LessonPlanScheduleItem is the coverage of the lesson plan by one schedule (class)
+type LessonPlanScheduleItem struct {
+	CourseScheduleID uint                      `json:"course_schedule_id"`
+	StudentGroup     string                    `json:"student_group"`
+	LecturerName     string                    `json:"lecturer_name"`
+	JournalsFilled   int                       `json:"journals_filled"`
+	JournalsSigned   int                       `json:"journals_signed"`
+	TopicsDelivered  int                       `json:"topics_delivered"` // Planned topics with a journal
+	CoverageRate     float64                   `json:"coverage_rate"`    // Delivered over planned topics, as a percentage
+	Topics           []LessonPlanTopicDelivery `json:"topics"`
+	Unplanned        []LessonPlanTopicDelivery `json:"unplanned"` // Journals not linked to a planned topic
+}
+
+// LessonPlanTopicDelivery pairs a planned topic with the journals that delivered it
+type LessonPlanTopicDelivery struct {
+	MeetingNumber  int      `json:"meeting_number"`
+	PlannedTopic   string   `json:"planned_topic"`
+	Delivered      bool     `json:"delivered"`
+	DeliveredDates []string `json:"delivered_dates"`
+	ActualTopics   []string `json:"actual_topics"`
+	Signed         bool     `json:"signed"` // Every delivering journal is signed
+}
@@ -5,6 +5,14 @@ import (
 	"gorm.io/gorm"
 )
 
+// LecturerAssignmentRole is the role of a lecturer in a course's teaching team
+type LecturerAssignmentRole string
+
+const (
+	LecturerAssignmentCoordinator LecturerAssignmentRole = "COORDINATOR"
+	LecturerAssignmentMember      LecturerAssignmentRole = "MEMBER"
+)
+
 // LecturerAssignment represents the assignment of a lecturer to a course
 type LecturerAssignment struct {
 	// Primary key
@@ -18,6 +26,13 @@ type LecturerAssignment struct {
 	CourseID       uint           `json:"course_id" gorm:"index:idx_lecturer_assignment_course_id"`
 	Course         Course         `json:"course" gorm:"foreignKey:CourseID"`
 	
+	// Team teaching: the coordinator is the lecturer of the course's schedules; members teach
+	// alongside, optionally for a single class and a range of meetings
+	Role             LecturerAssignmentRole `json:"role" gorm:"type:varchar(20);default:'COORDINATOR'"`
+	CourseScheduleID *uint                  `json:"course_schedule_id"`
+	MeetingFrom      *int                   `json:"meeting_from"`
+	MeetingTo        *int                   `json:"meeting_to"`
+	
 	// Academic year details
 	AcademicYearID uint           `json:"academic_year_id" gorm:"index:idx_lecturer_assignment_academic_year_id"`
 	AcademicYear   AcademicYear   `json:"academic_year" gorm:"foreignKey:AcademicYearID"`
@@ -46,12 +61,29 @@ type LecturerAssignmentResponse struct {
 	CourseCode      string    `json:"course_code"`
 	CourseSemester  int       `json:"course_semester"`
 	
+	// Team teaching details
+	Role             LecturerAssignmentRole `json:"role"`
+	CourseScheduleID *uint                  `json:"course_schedule_id,omitempty"`
+	MeetingFrom      *int                   `json:"meeting_from,omitempty"`
+	MeetingTo        *int                   `json:"meeting_to,omitempty"`
+	
 	// Academic year details
 	AcademicYearID      uint   `json:"academic_year_id"`
 	AcademicYearName    string `json:"academic_year_name"`
 	AcademicYearSemester string `json:"academic_year_semester"`
 }
 
+// CoversMeeting reports whether a meeting number falls within the assignment's meeting range
+func (a LecturerAssignment) CoversMeeting(meeting int) bool {
+	if a.MeetingFrom != nil && meeting < *a.MeetingFrom {
+		return false
+	}
+	if a.MeetingTo != nil && meeting > *a.MeetingTo {
+		return false
+	}
+	return true
+}
+
 // TableName specifies the table name for lecturer assignments
 func (LecturerAssignment) TableName() string {
 	return "lecturer_assignments"
@@ -0,0 +1,54 @@
+package models
+
+// LecturerTeachingReport is the teaching record of lecturers over an academic year, used by HR
+// for honorarium calculation
+type LecturerTeachingReport struct {
+	AcademicYearID   uint                     `json:"academic_year_id"`
+	AcademicYearName string                   `json:"academic_year_name"`
+	Semester         string                   `json:"semester"`
+	Until            string                   `json:"until"`          // Planned meetings are counted up to this date
+	LateThreshold    int                      `json:"late_threshold"` // Minutes after the scheduled start a session counts as opened late
+	Lecturers        []LecturerTeachingRecord `json:"lecturers"`
+}
+
+// LecturerTeachingRecord totals the teaching of one lecturer over the schedules they teach
+type LecturerTeachingRecord struct {
+	LecturerUserID         uint                       `json:"lecturer_user_id"`
+	LecturerName           string                     `json:"lecturer_name"`
+	NIP                    string                     `json:"nip"`
+	Planned                int                        `json:"planned"` // Meetings the schedules should have had
+	Taught                 int                        `json:"taught"`  // Meetings held, by the lecturer or an assistant
+	TaughtByLecturer       int                        `json:"taught_by_lecturer"`
+	TaughtByAssistant      int                        `json:"taught_by_assistant"`   // Meetings whose sessions were all opened by an assistant
+	Missed                 int                        `json:"missed"`                // Planned meetings without a session
+	LateOpened             int                        `json:"late_opened"`           // Meetings opened more than the late threshold after the scheduled start
+	AverageDelayMinutes    float64                    `json:"average_delay_minutes"` // Average delay of opened meetings, early openings counting as zero
+	TeachingRate           float64                    `json:"teaching_rate"`         // Meetings taught by the lecturer over planned meetings, as a percentage
+	CreditHoursPlanned     int                        `json:"credit_hours_planned"`  // Planned meetings weighted by course credits (SKS)
+	CreditHoursTaught      int                        `json:"credit_hours_taught"`   // Meetings taught by the lecturer weighted by course credits
+	CreditHoursByAssistant int                        `json:"credit_hours_by_assistant"`
+	Schedules              []LecturerScheduleTeaching `json:"schedules"`
+}
+
+// LecturerScheduleTeaching is the teaching record of one schedule
+type LecturerScheduleTeaching struct {
+	CourseScheduleID       uint    `json:"course_schedule_id"`
+	CourseCode             string  `json:"course_code"`
+	CourseName             string  `json:"course_name"`
+	Credits                int     `json:"credits"`
+	StudentGroup           string  `json:"student_group"`
+	Day                    string  `json:"day"`
+	StartTime              string  `json:"start_time"`
+	Planned                int     `json:"planned"`
+	Taught                 int     `json:"taught"`
+	TaughtByLecturer       int     `json:"taught_by_lecturer"`
+	TaughtByAssistant      int     `json:"taught_by_assistant"`
+	Unplanned              int     `json:"unplanned"` // Meetings held on dates the schedule does not meet
+	Missed                 int     `json:"missed"`
+	LateOpened             int     `json:"late_opened"`
+	AverageDelayMinutes    float64 `json:"average_delay_minutes"`
+	MaxDelayMinutes        int     `json:"max_delay_minutes"`
+	CreditHoursPlanned     int     `json:"credit_hours_planned"`
+	CreditHoursTaught      int     `json:"credit_hours_taught"`
+	CreditHoursByAssistant int     `json:"credit_hours_by_assistant"`
+}
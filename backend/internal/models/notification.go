@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+)
+
+// NotificationKind identifies a kind of notification sent to users
+type NotificationKind string
+
+const (
+	NotificationKindAbsenceSummary   NotificationKind = "ABSENCE_SUMMARY"
+	NotificationKindThresholdWarning NotificationKind = "THRESHOLD_WARNING"
+	NotificationKindLecturerDigest   NotificationKind = "LECTURER_DIGEST"
+	NotificationKindSessionOpened    NotificationKind = "SESSION_OPENED"
+	NotificationKindSessionClosing   NotificationKind = "SESSION_CLOSING"
+)
+
+// NotificationChannel identifies how a notification is delivered
+type NotificationChannel string
+
+const (
+	NotificationChannelEmail NotificationChannel = "EMAIL"
+	NotificationChannelPush  NotificationChannel = "PUSH"
+)
+
+// NotificationLogStatus represents the outcome of sending a notification
+type NotificationLogStatus string
+
+const (
+	NotificationLogStatusPending NotificationLogStatus = "PENDING"
+	NotificationLogStatusSent    NotificationLogStatus = "SENT"
+	NotificationLogStatusFailed  NotificationLogStatus = "FAILED"
+	NotificationLogStatusSkipped NotificationLogStatus = "SKIPPED"
+)
+
+// NotificationPreference stores a user's language and opt-out choices.
+// Users without a row receive every notification in Indonesian.
+type NotificationPreference struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	UserID           int       `json:"user_id" gorm:"not null;uniqueIndex;comment:External user ID from campus system"`
+	Language         string    `json:"language" gorm:"type:varchar(5);not null;default:'id'"` // 'id' or 'en'
+	EmailEnabled     bool      `json:"email_enabled" gorm:"not null;default:true"`
+	AbsenceSummary   bool      `json:"absence_summary" gorm:"not null;default:true"`
+	ThresholdWarning bool      `json:"threshold_warning" gorm:"not null;default:true"`
+	LecturerDigest   bool      `json:"lecturer_digest" gorm:"not null;default:true"`
+	PushEnabled      bool      `json:"push_enabled" gorm:"not null;default:true"`
+	CreatedAt        time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt        time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// NotificationLog records every notification sent. The unique index on
+// (user_id, kind, dedupe_key) ensures a notification is only sent once,
+// even when several server instances run the scheduler.
+type NotificationLog struct {
+	ID        uint                  `json:"id" gorm:"primaryKey"`
+	UserID    int                   `json:"user_id" gorm:"not null;uniqueIndex:idx_notification_logs_dedupe"`
+	Kind      NotificationKind      `json:"kind" gorm:"type:varchar(30);not null;uniqueIndex:idx_notification_logs_dedupe"`
+	DedupeKey string                `json:"dedupe_key" gorm:"type:varchar(100);not null;uniqueIndex:idx_notification_logs_dedupe"`
+	Channel   NotificationChannel   `json:"channel" gorm:"type:varchar(20);not null"`
+	Recipient string                `json:"recipient" gorm:"type:varchar(255)"`
+	Subject   string                `json:"subject" gorm:"type:varchar(255)"`
+	Status    NotificationLogStatus `json:"status" gorm:"type:varchar(20);not null;index"`
+	Error     string                `json:"error,omitempty" gorm:"type:text"`
+	CreatedAt time.Time             `json:"created_at" gorm:"autoCreateTime;index"`
+	UpdatedAt time.Time             `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName returns the table name for the NotificationPreference model
+func (NotificationPreference) TableName() string {
+	return "notification_preferences"
+}
+
+// TableName returns the table name for the NotificationLog model
+func (NotificationLog) TableName() string {
+	return "notification_logs"
+}
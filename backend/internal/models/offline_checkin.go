@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+)
+
+// OfflineCheckInOutcome is the result of validating an offline check-in
+type OfflineCheckInOutcome string
+
+const (
+	OfflineCheckInAccepted OfflineCheckInOutcome = "ACCEPTED"
+	OfflineCheckInRejected OfflineCheckInOutcome = "REJECTED"
+	OfflineCheckInFlagged  OfflineCheckInOutcome = "FLAGGED" // Waiting for a lecturer or assistant to review
+)
+
+// OfflineCheckIn is a QR check-in captured by the mobile app without connectivity and
+// submitted later. ClientID is generated by the app, so resubmitting a batch is idempotent.
+type OfflineCheckIn struct {
+	ID                  uint                  `json:"id" gorm:"primaryKey"`
+	ClientID            string                `json:"client_id" gorm:"type:varchar(64);not null;uniqueIndex:idx_offline_check_ins_client"`
+	UserID              int                   `json:"user_id" gorm:"not null;uniqueIndex:idx_offline_check_ins_client;comment:External user ID from campus system"`
+	StudentID           uint                  `json:"student_id" gorm:"not null;index"`
+	Student             Student               `json:"student,omitempty" gorm:"foreignKey:StudentID"`
+	AttendanceSessionID uint                  `json:"attendance_session_id" gorm:"not null;index"`
+	DeviceID            string                `json:"device_id" gorm:"type:varchar(128)"`
+	QRData              string                `json:"-" gorm:"type:text"`
+	ScannedAt           time.Time             `json:"scanned_at"`
+	ReceivedAt          time.Time             `json:"received_at"`
+	Outcome             OfflineCheckInOutcome `json:"outcome" gorm:"type:varchar(20);not null;index"`
+	Reason              string                `json:"reason" gorm:"type:text"`
+	AttendanceStatus    string                `json:"attendance_status" gorm:"type:varchar(20)"` // Status recorded when accepted
+	ReviewedByID        *uint                 `json:"reviewed_by_id"`
+	ReviewedAt          *time.Time            `json:"reviewed_at"`
+	CreatedAt           time.Time             `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt           time.Time             `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName returns the table name for the OfflineCheckIn model
+func (OfflineCheckIn) TableName() string {
+	return "offline_check_ins"
+}
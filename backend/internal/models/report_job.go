@@ -0,0 +1,69 @@
+package models
+
+import "time"
+
+// ReportJobType identifies the report rendered by a job
+type ReportJobType string
+
+const (
+	ReportJobSessionReport    ReportJobType = "SESSION_REPORT"
+	ReportJobAttendanceMatrix ReportJobType = "ATTENDANCE_MATRIX"
+	ReportJobStudentRecap     ReportJobType = "STUDENT_RECAP"
+)
+
+// ReportJobStatus represents the state of a report job in the queue
+type ReportJobStatus string
+
+const (
+	ReportJobStatusPending   ReportJobStatus = "PENDING"
+	ReportJobStatusRunning   ReportJobStatus = "RUNNING"
+	ReportJobStatusCompleted ReportJobStatus = "COMPLETED"
+	ReportJobStatusFailed    ReportJobStatus = "FAILED"
+	ReportJobStatusExpired   ReportJobStatus = "EXPIRED" // The artifact was removed by the retention policy
+)
+
+// ReportJob is a report export rendered in the background. The rendered file is kept
+// in local storage until ExpiresAt and downloaded through a signed, time-limited link.
+type ReportJob struct {
+	ID              uint            `json:"id" gorm:"primaryKey"`
+	Type            ReportJobType   `json:"type" gorm:"type:varchar(30);not null"`
+	Format          string          `json:"format" gorm:"type:varchar(10);not null"`
+	Params          string          `json:"-" gorm:"type:text"` // JSON encoded ReportJobParams
+	Status          ReportJobStatus `json:"status" gorm:"type:varchar(20);not null;index:idx_report_jobs_status_available"`
+	RequestedByID   uint            `json:"requested_by_id" gorm:"not null;index"` // External user ID
+	RequestedByRole string          `json:"requested_by_role" gorm:"type:varchar(30);not null"`
+	Attempts        int             `json:"attempts" gorm:"default:0"`
+	AvailableAt     time.Time       `json:"-" gorm:"index:idx_report_jobs_status_available"` // Claim lease of running jobs
+	FileName        string          `json:"file_name" gorm:"type:varchar(255)"`
+	ContentType     string          `json:"content_type" gorm:"type:varchar(100)"`
+	StoragePath     string          `json:"-" gorm:"type:text"`
+	FileSize        int64           `json:"file_size"`
+	Error           string          `json:"error,omitempty" gorm:"type:text"`
+	StartedAt       *time.Time      `json:"started_at"`
+	CompletedAt     *time.Time      `json:"completed_at"`
+	ExpiresAt       *time.Time      `json:"expires_at" gorm:"index"`
+	CreatedAt       time.Time       `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName returns the table name for the ReportJob model
+func (ReportJob) TableName() string {
+	return "report_jobs"
+}
+
+// ReportJobParams holds the parameters of a report job. Only the fields used by the job type are set.
+type ReportJobParams struct {
+	SessionID        uint `json:"session_id,omitempty"`
+	CourseScheduleID uint `json:"course_schedule_id,omitempty"`
+	CourseID         uint `json:"course_id,omitempty"`
+	StudentID        uint `json:"student_id,omitempty"`
+	AcademicYearID   uint `json:"academic_year_id,omitempty"`
+}
+
+// ReportJobResponse represents a report job returned to the user who requested it
+type ReportJobResponse struct {
+	ReportJob
+	Params            ReportJobParams `json:"params"`
+	DownloadURL       string          `json:"download_url,omitempty"`
+	DownloadExpiresAt *time.Time      `json:"download_expires_at,omitempty"`
+}
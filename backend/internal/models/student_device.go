@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+)
+
+// StudentDeviceStatus is the state of a phone a student registered for checking in
+type StudentDeviceStatus string
+
+const (
+	StudentDeviceActive   StudentDeviceStatus = "ACTIVE"   // The device the student checks in with
+	StudentDevicePending  StudentDeviceStatus = "PENDING"  // A device change waiting for an admin
+	StudentDeviceRevoked  StudentDeviceStatus = "REVOKED"  // Replaced by a later device
+	StudentDeviceRejected StudentDeviceStatus = "REJECTED" // A device change an admin turned down
+)
+
+// StudentDevice binds a student's check-ins to one phone. A student has at most one ACTIVE device;
+// changing it counts against a per-semester limit, beyond which an admin must approve the change.
+type StudentDevice struct {
+	ID             uint                `json:"id" gorm:"primaryKey"`
+	UserID         int                 `json:"user_id" gorm:"not null;index;comment:External user ID from campus system"`
+	StudentID      uint                `json:"student_id" gorm:"not null;index;uniqueIndex:idx_student_devices_active_student,where:status = 'ACTIVE'"`
+	Student        Student             `json:"student,omitempty" gorm:"foreignKey:StudentID"`
+	DeviceID       string              `json:"device_id" gorm:"type:varchar(128);not null;index;uniqueIndex:idx_student_devices_active_device,where:status = 'ACTIVE'"`
+	DeviceName     string              `json:"device_name" gorm:"type:varchar(100)"`
+	Status         StudentDeviceStatus `json:"status" gorm:"type:varchar(20);not null;index"`
+	AcademicYearID *uint               `json:"academic_year_id" gorm:"index"` // Semester the device was requested in
+	IsChange       bool                `json:"is_change"`                     // Replaces an earlier device of the student
+	Reason         string              `json:"reason" gorm:"type:text"`       // Why the student changes devices
+	ActivatedAt    *time.Time          `json:"activated_at"`
+	RevokedAt      *time.Time          `json:"revoked_at"`
+	ReviewedByID   *uint               `json:"reviewed_by_id"`
+	ReviewedAt     *time.Time          `json:"reviewed_at"`
+	ReviewNotes    string              `json:"review_notes" gorm:"type:text"`
+	CreatedAt      time.Time           `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time           `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// StudentDeviceOverview is a student's device binding as shown in the app
+type StudentDeviceOverview struct {
+	Active         *StudentDevice `json:"active"`
+	Pending        *StudentDevice `json:"pending"`
+	AcademicYearID *uint          `json:"academic_year_id"`
+	ChangesUsed    int            `json:"changes_used"`    // Device changes made this semester
+	ChangesAllowed int            `json:"changes_allowed"` // Changes allowed without admin approval
+}
+
+// TableName returns the table name for the StudentDevice model
+func (StudentDevice) TableName() string {
+	return "student_devices"
+}
@@ -0,0 +1,38 @@
+package models
+
+// TeachingAssistantActivityReport lists what teaching assistants did in the courses they are
+// assigned to over an academic year; assistants are paid per session
+type TeachingAssistantActivityReport struct {
+	AcademicYearID   uint                        `json:"academic_year_id"`
+	AcademicYearName string                      `json:"academic_year_name"`
+	Semester         string                      `json:"semester"`
+	Assistants       []TeachingAssistantActivity `json:"assistants"`
+}
+
+// TeachingAssistantActivity totals the activity of one teaching assistant over their assignments
+type TeachingAssistantActivity struct {
+	AssistantUserID uint                              `json:"assistant_user_id"`
+	AssistantName   string                            `json:"assistant_name"`
+	SessionsCreated int                               `json:"sessions_created"`
+	SessionsClosed  int                               `json:"sessions_closed"`
+	ManualMarks     int                               `json:"manual_marks"`
+	HoursCovered    float64                           `json:"hours_covered"`
+	Courses         []TeachingAssistantCourseActivity `json:"courses"`
+}
+
+// TeachingAssistantCourseActivity is the activity of a teaching assistant in one assigned course
+type TeachingAssistantCourseActivity struct {
+	AssignmentID        uint    `json:"assignment_id"`
+	CourseID            uint    `json:"course_id"`
+	CourseCode          string  `json:"course_code"`
+	CourseName          string  `json:"course_name"`
+	AssignedByID        uint    `json:"assigned_by_id"`
+	SessionsCreated     int     `json:"sessions_created"`      // Sessions the assistant opened
+	SessionsClosed      int     `json:"sessions_closed"`       // Sessions the assistant closed, including ones opened by others
+	ManualMarks         int     `json:"manual_marks"`          // Student records the assistant marked by hand
+	HoursCovered        float64 `json:"hours_covered"`         // Scheduled hours of the sessions the assistant opened
+	CourseSessions      int     `json:"course_sessions"`       // All sessions of the course
+	LecturerRunSessions int     `json:"lecturer_run_sessions"` // Sessions opened by the schedule's lecturer
+	AssistantShare      float64 `json:"assistant_share"`       // Sessions the assistant opened over all sessions, as a percentage
+	AssistantToLecturer float64 `json:"assistant_to_lecturer"` // Sessions the assistant opened per lecturer-run session; 0 without lecturer-run sessions
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Schedule event types emitted to webhooks in addition to the attendance event types
+const (
+	WebhookEventScheduleCreated = "SCHEDULE_CREATED"
+	WebhookEventScheduleUpdated = "SCHEDULE_UPDATED"
+	WebhookEventScheduleDeleted = "SCHEDULE_DELETED"
+)
+
+// WebhookEventTypes lists every event type an endpoint can subscribe to
+var WebhookEventTypes = []string{
+	string(AttendanceEventSessionOpened),
+	string(AttendanceEventSessionClosed),
+	string(AttendanceEventSessionCanceled),
+	string(AttendanceEventStudentCheckedIn),
+	string(AttendanceEventStudentStatusChanged),
+	WebhookEventScheduleCreated,
+	WebhookEventScheduleUpdated,
+	WebhookEventScheduleDeleted,
+}
+
+// WebhookDeliveryStatus represents the state of a webhook delivery in the queue
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "PENDING"
+	WebhookDeliveryStatusDelivered WebhookDeliveryStatus = "DELIVERED"
+	WebhookDeliveryStatusDead      WebhookDeliveryStatus = "DEAD"
+)
+
+// WebhookEndpoint is an external URL registered by an admin to receive events
+type WebhookEndpoint struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	Name        string         `json:"name" gorm:"type:varchar(100);not null"`
+	URL         string         `json:"url" gorm:"type:text;not null"`
+	Secret      string         `json:"-" gorm:"type:varchar(128);not null"`
+	EventTypes  string         `json:"-" gorm:"type:text"` // Comma-separated, empty means all events
+	Active      bool           `json:"active" gorm:"default:true"`
+	CreatedByID uint           `json:"created_by_id"`
+	CreatedAt   time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// TableName returns the table name for the WebhookEndpoint model
+func (WebhookEndpoint) TableName() string {
+	return "webhook_endpoints"
+}
+
+// WebhookDelivery is a single event queued for delivery to one endpoint
+type WebhookDelivery struct {
+	ID             uint                  `json:"id" gorm:"primaryKey"`
+	EndpointID     uint                  `json:"endpoint_id" gorm:"not null;index"`
+	Endpoint       WebhookEndpoint       `json:"-" gorm:"foreignKey:EndpointID"`
+	EventID        string                `json:"event_id" gorm:"type:varchar(64);not null;index"`
+	EventType      string                `json:"event_type" gorm:"type:varchar(50);not null"`
+	Payload        string                `json:"payload" gorm:"type:text;not null"`
+	Status         WebhookDeliveryStatus `json:"status" gorm:"type:varchar(20);not null;index:idx_webhook_deliveries_status_next_attempt"`
+	Attempts       int                   `json:"attempts" gorm:"default:0"`
+	NextAttemptAt  time.Time             `json:"next_attempt_at" gorm:"index:idx_webhook_deliveries_status_next_attempt"`
+	LastStatusCode int                   `json:"last_status_code"`
+	LastError      string                `json:"last_error" gorm:"type:text"`
+	DeliveredAt    *time.Time            `json:"delivered_at"`
+	CreatedAt      time.Time             `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time             `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName returns the table name for the WebhookDelivery model
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// WebhookEndpointResponse represents a webhook endpoint returned to admins
+type WebhookEndpointResponse struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	URL         string    `json:"url"`
+	Secret      string    `json:"secret,omitempty"` // Only returned when the endpoint is created
+	EventTypes  []string  `json:"event_types"`
+	Active      bool      `json:"active"`
+	CreatedByID uint      `json:"created_by_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// WebhookPayload is the signed JSON body posted to webhook endpoints
+type WebhookPayload struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
@@ -0,0 +1,144 @@
+package pdf
+
+import "strings"
+
+// Column describes one column of a table
+type Column struct {
+	Header string
+	Width  float64
+	Align  Align
+}
+
+// TableStyle sets the font size and row height of a table, and optional backgrounds for data rows or cells
+type TableStyle struct {
+	FontSize  float64
+	RowHeight float64
+	RowFill   func(row int) *Color         // Background of a data row, nil for none
+	CellFill  func(row, column int) *Color // Background of a data cell, overriding RowFill; nil for none
+}
+
+// DefaultTableStyle is a 9pt table with 16pt rows
+var DefaultTableStyle = TableStyle{FontSize: 9, RowHeight: 16}
+
+// ContentWidth returns the width between the left and right margins
+func (d *Document) ContentWidth() float64 {
+	return d.Width - 2*d.Margin
+}
+
+// EnsureSpace starts a new page when less than height is left above the bottom margin,
+// and reports whether it did
+func (d *Document) EnsureSpace(height float64) bool {
+	d.ensurePage()
+	if d.Y+height <= d.Height-d.Margin {
+		return false
+	}
+	d.AddPage()
+	return true
+}
+
+// Space moves the layout cursor down
+func (d *Document) Space(height float64) {
+	d.Y += height
+}
+
+// Heading draws a bold line of text aligned across the content width
+func (d *Document) Heading(text string, size float64, align Align) {
+	d.EnsureSpace(size * 1.4)
+	d.TextInBox(d.Margin, d.Y+size, d.ContentWidth(), align, Bold, size, text)
+	d.Y += size * 1.4
+}
+
+// Paragraph draws text wrapped to the content width
+func (d *Document) Paragraph(text string, font Font, size float64) {
+	for _, line := range WrapText(font, size, text, d.ContentWidth()) {
+		d.EnsureSpace(size * 1.4)
+		d.Text(d.Margin, d.Y+size, font, size, line)
+		d.Y += size * 1.4
+	}
+}
+
+// KeyValue draws a "label : value" line with the values aligned at labelWidth
+func (d *Document) KeyValue(label, value string, labelWidth, size float64) {
+	d.EnsureSpace(size * 1.5)
+	d.Text(d.Margin, d.Y+size, Regular, size, label)
+	d.Text(d.Margin+labelWidth, d.Y+size, Regular, size, ": ")
+	d.TextInBox(d.Margin+labelWidth+TextWidth(Regular, size, ": "), d.Y+size,
+		d.ContentWidth()-labelWidth, AlignLeft, Bold, size, value)
+	d.Y += size * 1.5
+}
+
+// Table draws a bordered table at the layout cursor, continuing on new pages
+// with the header repeated when it runs past the bottom margin
+func (d *Document) Table(columns []Column, rows [][]string, style TableStyle) {
+	if style.FontSize == 0 {
+		style = DefaultTableStyle
+	}
+	padding := 3.0
+	baseline := (style.RowHeight + style.FontSize*0.7) / 2
+
+	drawRow := func(cells []string, font Font, fill func(column int) *Color) {
+		x := d.Margin
+		for i, column := range columns {
+			if color := fill(i); color != nil {
+				d.FillRect(x, d.Y, column.Width, style.RowHeight, *color)
+			}
+			d.Rect(x, d.Y, column.Width, style.RowHeight, 0.5)
+			if i < len(cells) {
+				d.TextInBox(x+padding, d.Y+baseline, column.Width-2*padding, column.Align, font, style.FontSize, cells[i])
+			}
+			x += column.Width
+		}
+		d.Y += style.RowHeight
+	}
+
+	headers := make([]string, len(columns))
+	for i, column := range columns {
+		headers[i] = column.Header
+	}
+	headerFill := func(int) *Color {
+		fill := LightGray
+		return &fill
+	}
+
+	d.EnsureSpace(style.RowHeight * 2)
+	drawRow(headers, Bold, headerFill)
+	for i, row := range rows {
+		if d.EnsureSpace(style.RowHeight) {
+			drawRow(headers, Bold, headerFill)
+		}
+		drawRow(row, Regular, func(column int) *Color {
+			if style.CellFill != nil {
+				if fill := style.CellFill(i, column); fill != nil {
+					return fill
+				}
+			}
+			if style.RowFill != nil {
+				return style.RowFill(i)
+			}
+			return nil
+		})
+	}
+}
+
+// WrapText splits text into lines no wider than width, breaking between words
+func WrapText(font Font, size float64, text string, width float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		words := strings.Fields(paragraph)
+		if len(words) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+		line := words[0]
+		for _, word := range words[1:] {
+			if TextWidth(font, size, line+" "+word) > width {
+				lines = append(lines, line)
+				line = word
+				continue
+			}
+			line += " " + word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,277 @@
+// Package pdf writes simple A4 documents with text, lines and filled rectangles
+// using the standard PDF fonts, so reports need no external tools or font files.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+)
+
+// A4 page size in points, portrait
+const (
+	A4Width  = 595.28
+	A4Height = 841.89
+)
+
+// Align is the horizontal alignment of text within a box
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignCenter
+	AlignRight
+)
+
+// Color is an RGB color with components between 0 and 1
+type Color struct {
+	R, G, B float64
+}
+
+// Common colors
+var (
+	Black     = Color{0, 0, 0}
+	White     = Color{1, 1, 1}
+	LightGray = Color{0.9, 0.9, 0.9}
+)
+
+// Hex parses a color written as RRGGBB, returning black when it is malformed
+func Hex(value string) Color {
+	if len(value) != 6 {
+		return Black
+	}
+	rgb, err := strconv.ParseUint(value, 16, 32)
+	if err != nil {
+		return Black
+	}
+	return Color{
+		R: float64(rgb>>16&0xFF) / 255,
+		G: float64(rgb>>8&0xFF) / 255,
+		B: float64(rgb&0xFF) / 255,
+	}
+}
+
+// Document is a PDF being written. Coordinates passed to its drawing methods are in
+// points measured from the top-left corner of the page.
+type Document struct {
+	Title  string
+	Width  float64 // Page width in points
+	Height float64 // Page height in points
+	Margin float64 // Page margin used by the layout helpers
+	Y      float64 // Layout cursor, the top of the next line drawn by the layout helpers
+
+	pages []*bytes.Buffer
+	page  *bytes.Buffer
+
+	// Footer, when set, is called after each page is finished to draw page furniture
+	Footer func(d *Document, pageNumber, pageCount int)
+}
+
+// New creates an empty A4 portrait document with 40pt margins
+func New(title string) *Document {
+	return &Document{Title: title, Width: A4Width, Height: A4Height, Margin: 40}
+}
+
+// NewLandscape creates an empty A4 landscape document with 30pt margins
+func NewLandscape(title string) *Document {
+	return &Document{Title: title, Width: A4Height, Height: A4Width, Margin: 30}
+}
+
+// AddPage starts a new page and moves the layout cursor to its top margin
+func (d *Document) AddPage() {
+	d.page = &bytes.Buffer{}
+	d.pages = append(d.pages, d.page)
+	d.Y = d.Margin
+}
+
+// PageCount returns the number of pages added so far
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+// Text draws text with its baseline at (x, y)
+func (d *Document) Text(x, y float64, font Font, size float64, text string) {
+	d.ensurePage()
+	fmt.Fprintf(d.page, "BT /%s %s Tf %s %s Td (%s) Tj ET\n",
+		font.resourceName(), num(size), num(x), num(d.Height-y), escape(encodeWinAnsi(text)))
+}
+
+// TextColor draws text like Text in the given color
+func (d *Document) TextColor(x, y float64, font Font, size float64, color Color, text string) {
+	d.ensurePage()
+	fmt.Fprintf(d.page, "%s %s %s rg\n", num(color.R), num(color.G), num(color.B))
+	d.Text(x, y, font, size, text)
+	d.page.WriteString("0 0 0 rg\n")
+}
+
+// TextInBox draws text aligned within a box of the given width starting at x,
+// shortening it with "..." when it does not fit
+func (d *Document) TextInBox(x, y, width float64, align Align, font Font, size float64, text string) {
+	text = Truncate(font, size, text, width)
+	switch align {
+	case AlignCenter:
+		x += (width - TextWidth(font, size, text)) / 2
+	case AlignRight:
+		x += width - TextWidth(font, size, text)
+	}
+	d.Text(x, y, font, size, text)
+}
+
+// Line draws a straight line
+func (d *Document) Line(x1, y1, x2, y2, lineWidth float64) {
+	d.ensurePage()
+	fmt.Fprintf(d.page, "%s w %s %s m %s %s l S\n",
+		num(lineWidth), num(x1), num(d.Height-y1), num(x2), num(d.Height-y2))
+}
+
+// Rect draws the outline of a rectangle whose top-left corner is (x, y)
+func (d *Document) Rect(x, y, width, height, lineWidth float64) {
+	d.ensurePage()
+	fmt.Fprintf(d.page, "%s w %s %s %s %s re S\n",
+		num(lineWidth), num(x), num(d.Height-y-height), num(width), num(height))
+}
+
+// FillRect fills a rectangle whose top-left corner is (x, y)
+func (d *Document) FillRect(x, y, width, height float64, color Color) {
+	d.ensurePage()
+	fmt.Fprintf(d.page, "%s %s %s rg %s %s %s %s re f 0 0 0 rg\n",
+		num(color.R), num(color.G), num(color.B),
+		num(x), num(d.Height-y-height), num(width), num(height))
+}
+
+// Write finishes the document and writes it to w
+func (d *Document) Write(w io.Writer) error {
+	d.ensurePage()
+	if d.Footer != nil {
+		current := d.page
+		for i, page := range d.pages {
+			d.page = page
+			d.Footer(d, i+1, len(d.pages))
+		}
+		d.page = current
+	}
+
+	out := &bytes.Buffer{}
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+
+	// Objects 1-4 are fixed; each page then takes a page object and a content stream
+	kids := &bytes.Buffer{}
+	for i := range d.pages {
+		fmt.Fprintf(kids, "%d 0 R ", 5+i*2)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", kids.String(), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			num(d.Width), num(d.Height), 6+i*2))
+
+		compressed := &bytes.Buffer{}
+		zw := zlib.NewWriter(compressed)
+		if _, err := zw.Write(page.Bytes()); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream",
+			compressed.Len(), compressed.String()))
+	}
+
+	object(fmt.Sprintf("<< /Title (%s) /Producer (DelPresence) /CreationDate (D:%s) >>",
+		escape(encodeWinAnsi(d.Title)), time.Now().UTC().Format("20060102150405Z")))
+	info := len(offsets)
+
+	xref := out.Len()
+	fmt.Fprintf(out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(out, "trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(offsets)+1, info, xref)
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// Bytes finishes the document and returns its contents
+func (d *Document) Bytes() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := d.Write(buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Truncate shortens text with "..." so that it fits in the given width
+func Truncate(font Font, size float64, text string, width float64) string {
+	if TextWidth(font, size, text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		candidate := string(runes) + "..."
+		if TextWidth(font, size, candidate) <= width {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// ensurePage adds the first page when drawing starts on an empty document
+func (d *Document) ensurePage() {
+	if d.page == nil {
+		d.AddPage()
+	}
+}
+
+// escape escapes the characters that are special inside PDF string literals
+func escape(text []byte) string {
+	buf := &bytes.Buffer{}
+	for _, b := range text {
+		switch b {
+		case '\\', '(', ')':
+			buf.WriteByte('\\')
+		}
+		buf.WriteByte(b)
+	}
+	return buf.String()
+}
+
+// num formats a number with at most two decimals
+func num(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
+
+// Modules draws a square grid of dark modules (such as a QR code) with its top-left corner at (x, y),
+// merging horizontal runs so the page stays small
+func (d *Document) Modules(x, y, moduleSize float64, size int, dark func(col, row int) bool) {
+	for row := 0; row < size; row++ {
+		for col := 0; col < size; {
+			if !dark(col, row) {
+				col++
+				continue
+			}
+			start := col
+			for col < size && dark(col, row) {
+				col++
+			}
+			d.FillRect(x+float64(start)*moduleSize, y+float64(row)*moduleSize,
+				float64(col-start)*moduleSize, moduleSize, Black)
+		}
+	}
+}
@@ -0,0 +1,423 @@
+// Package qrcode encodes short texts such as URLs as QR codes (byte mode, error correction
+// level M, versions 1 to 10), so documents can carry scannable links without external tools.
+package qrcode
+
+import "errors"
+
+// ErrTooLong is returned when the text does not fit in the largest supported version
+var ErrTooLong = errors.New("qrcode: text too long")
+
+// versionInfo describes the error correction blocks of a version at level M
+type versionInfo struct {
+	ecPerBlock   int
+	group1Blocks int
+	group1Data   int
+	group2Blocks int
+	group2Data   int
+	alignments   []int
+}
+
+// versions lists versions 1 to 10 at error correction level M
+var versions = [...]versionInfo{
+	{10, 1, 16, 0, 0, nil},
+	{16, 1, 28, 0, 0, []int{6, 18}},
+	{26, 1, 44, 0, 0, []int{6, 22}},
+	{18, 2, 32, 0, 0, []int{6, 26}},
+	{24, 2, 43, 0, 0, []int{6, 30}},
+	{16, 4, 27, 0, 0, []int{6, 34}},
+	{18, 4, 31, 0, 0, []int{6, 22, 38}},
+	{22, 2, 38, 2, 39, []int{6, 24, 42}},
+	{22, 3, 36, 2, 37, []int{6, 26, 46}},
+	{26, 4, 43, 1, 44, []int{6, 28, 50}},
+}
+
+// dataCodewords returns the number of data codewords of the version
+func (v versionInfo) dataCodewords() int {
+	return v.group1Blocks*v.group1Data + v.group2Blocks*v.group2Data
+}
+
+// Code is an encoded QR code symbol
+type Code struct {
+	Size     int // Number of modules per side, excluding the quiet zone
+	modules  [][]bool
+	function [][]bool
+}
+
+// Dark reports whether the module at column x and row y is dark
+func (c *Code) Dark(x, y int) bool {
+	return c.modules[y][x]
+}
+
+// Encode encodes text as a QR code using the smallest version that fits
+func Encode(text string) (*Code, error) {
+	data := []byte(text)
+
+	version := 0
+	for i, info := range versions {
+		countBits := 8
+		if i+1 >= 10 {
+			countBits = 16
+		}
+		if 4+countBits+len(data)*8 <= info.dataCodewords()*8 {
+			version = i + 1
+			break
+		}
+	}
+	if version == 0 {
+		return nil, ErrTooLong
+	}
+	info := versions[version-1]
+
+	codewords := interleave(info, encodeData(data, version, info.dataCodewords()))
+
+	size := 17 + 4*version
+	code := &Code{Size: size, modules: newGrid(size), function: newGrid(size)}
+	code.drawFunctionPatterns(version, info)
+	code.drawCodewords(codewords)
+
+	// Keep the mask with the lowest penalty
+	bestMask, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		code.applyMask(mask)
+		code.drawFormatBits(mask)
+		penalty := code.penalty()
+		if bestPenalty < 0 || penalty < bestPenalty {
+			bestMask, bestPenalty = mask, penalty
+		}
+		code.applyMask(mask) // Masking twice undoes it
+	}
+	code.applyMask(bestMask)
+	code.drawFormatBits(bestMask)
+
+	return code, nil
+}
+
+// encodeData builds the data codewords: byte mode header, data, terminator and padding
+func encodeData(data []byte, version, capacity int) []byte {
+	bits := &bitBuffer{}
+	bits.append(0x4, 4)
+	if version >= 10 {
+		bits.append(len(data), 16)
+	} else {
+		bits.append(len(data), 8)
+	}
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+
+	capacityBits := capacity * 8
+	terminator := capacityBits - len(*bits)
+	if terminator > 4 {
+		terminator = 4
+	}
+	bits.append(0, terminator)
+	bits.append(0, (8-len(*bits)%8)%8)
+
+	result := bits.bytes()
+	for pad := 0; len(result) < capacity; pad++ {
+		if pad%2 == 0 {
+			result = append(result, 0xEC)
+		} else {
+			result = append(result, 0x11)
+		}
+	}
+	return result
+}
+
+// interleave splits data into blocks, adds error correction and interleaves the codewords
+func interleave(info versionInfo, data []byte) []byte {
+	divisor := reedSolomonDivisor(info.ecPerBlock)
+
+	var dataBlocks, ecBlocks [][]byte
+	offset := 0
+	addBlocks := func(count, length int) {
+		for i := 0; i < count; i++ {
+			block := data[offset : offset+length]
+			offset += length
+			dataBlocks = append(dataBlocks, block)
+			ecBlocks = append(ecBlocks, reedSolomonRemainder(block, divisor))
+		}
+	}
+	addBlocks(info.group1Blocks, info.group1Data)
+	addBlocks(info.group2Blocks, info.group2Data)
+
+	longest := info.group1Data
+	if info.group2Data > longest {
+		longest = info.group2Data
+	}
+
+	var result []byte
+	for i := 0; i < longest; i++ {
+		for _, block := range dataBlocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+	for i := 0; i < info.ecPerBlock; i++ {
+		for _, block := range ecBlocks {
+			result = append(result, block[i])
+		}
+	}
+	return result
+}
+
+// drawFunctionPatterns draws finder, timing and alignment patterns and reserves the format and version areas
+func (c *Code) drawFunctionPatterns(version int, info versionInfo) {
+	size := c.Size
+
+	for i := 0; i < size; i++ {
+		c.setFunction(6, i, i%2 == 0)
+		c.setFunction(i, 6, i%2 == 0)
+	}
+
+	c.drawFinder(3, 3)
+	c.drawFinder(size-4, 3)
+	c.drawFinder(3, size-4)
+
+	last := len(info.alignments) - 1
+	for i, x := range info.alignments {
+		for j, y := range info.alignments {
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					c.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+
+	// Reserve the format areas; the real bits are drawn once the mask is chosen
+	c.drawFormatBits(0)
+
+	if version >= 7 {
+		remainder := version
+		for i := 0; i < 12; i++ {
+			remainder = (remainder << 1) ^ ((remainder >> 11) * 0x1F25)
+		}
+		bits := version<<12 | remainder
+		for i := 0; i < 18; i++ {
+			dark := (bits>>i)&1 != 0
+			a, b := size-11+i%3, i/3
+			c.setFunction(a, b, dark)
+			c.setFunction(b, a, dark)
+		}
+	}
+}
+
+// drawFinder draws a finder pattern with its separator centered at (x, y)
+func (c *Code) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || xx >= c.Size || yy < 0 || yy >= c.Size {
+				continue
+			}
+			distance := max(abs(dx), abs(dy))
+			c.setFunction(xx, yy, distance != 2 && distance != 4)
+		}
+	}
+}
+
+// drawFormatBits draws both copies of the format information for level M and the mask
+func (c *Code) drawFormatBits(mask int) {
+	data := 0<<3 | mask // Level M is 00
+	remainder := data
+	for i := 0; i < 10; i++ {
+		remainder = (remainder << 1) ^ ((remainder >> 9) * 0x537)
+	}
+	bits := (data<<10 | remainder) ^ 0x5412
+	bit := func(i int) bool { return (bits>>i)&1 != 0 }
+
+	for i := 0; i <= 5; i++ {
+		c.setFunction(8, i, bit(i))
+	}
+	c.setFunction(8, 7, bit(6))
+	c.setFunction(8, 8, bit(7))
+	c.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		c.setFunction(14-i, 8, bit(i))
+	}
+
+	size := c.Size
+	for i := 0; i < 8; i++ {
+		c.setFunction(size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		c.setFunction(8, size-15+i, bit(i))
+	}
+	c.setFunction(8, size-8, true) // Dark module
+}
+
+// drawCodewords places the codeword bits in the zigzag order over the non-function modules
+func (c *Code) drawCodewords(codewords []byte) {
+	size := c.Size
+	i := 0
+	for right := size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				upward := (right+1)&2 == 0
+				y := vert
+				if upward {
+					y = size - 1 - vert
+				}
+				if !c.function[y][x] && i < len(codewords)*8 {
+					c.modules[y][x] = (codewords[i>>3]>>(7-uint(i&7)))&1 != 0
+					i++
+				}
+			}
+		}
+	}
+}
+
+// applyMask inverts the non-function modules selected by a mask pattern
+func (c *Code) applyMask(mask int) {
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.function[y][x] {
+				continue
+			}
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert {
+				c.modules[y][x] = !c.modules[y][x]
+			}
+		}
+	}
+}
+
+// penalty scores a masked symbol; lower scores are easier to scan
+func (c *Code) penalty() int {
+	size := c.Size
+	score := 0
+
+	// Runs of five or more same-colored modules in rows and columns
+	for _, horizontal := range []bool{true, false} {
+		for a := 0; a < size; a++ {
+			run := 0
+			var previous bool
+			for b := 0; b < size; b++ {
+				dark := c.at(a, b, horizontal)
+				if b > 0 && dark == previous {
+					run++
+				} else {
+					if run >= 5 {
+						score += run - 2
+					}
+					run = 1
+				}
+				previous = dark
+			}
+			if run >= 5 {
+				score += run - 2
+			}
+		}
+	}
+
+	// 2x2 blocks of the same color
+	for y := 0; y < size-1; y++ {
+		for x := 0; x < size-1; x++ {
+			dark := c.modules[y][x]
+			if dark == c.modules[y][x+1] && dark == c.modules[y+1][x] && dark == c.modules[y+1][x+1] {
+				score += 3
+			}
+		}
+	}
+
+	// Finder-like 1:1:3:1:1 patterns with four light modules on one side
+	pattern := []bool{true, false, true, true, true, false, true}
+	for _, horizontal := range []bool{true, false} {
+		for a := 0; a < size; a++ {
+			for b := 0; b+7 <= size; b++ {
+				matches := true
+				for k, dark := range pattern {
+					if c.at(a, b+k, horizontal) != dark {
+						matches = false
+						break
+					}
+				}
+				if matches && (c.lightRun(a, b-4, b, horizontal) || c.lightRun(a, b+7, b+11, horizontal)) {
+					score += 40
+				}
+			}
+		}
+	}
+
+	// Balance of dark and light modules
+	dark := 0
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if c.modules[y][x] {
+				dark++
+			}
+		}
+	}
+	total := size * size
+	deviation := abs(dark*20-total*10) / total
+	score += deviation * 10
+
+	return score
+}
+
+// at returns the module at line a, position b, reading rows when horizontal and columns otherwise
+func (c *Code) at(a, b int, horizontal bool) bool {
+	if horizontal {
+		return c.modules[a][b]
+	}
+	return c.modules[b][a]
+}
+
+// lightRun reports whether positions from..to-1 of a line are light, counting the quiet zone as light
+func (c *Code) lightRun(a, from, to int, horizontal bool) bool {
+	for b := from; b < to; b++ {
+		if b >= 0 && b < c.Size && c.at(a, b, horizontal) {
+			return false
+		}
+	}
+	return true
+}
+
+// setFunction sets a module that belongs to a function pattern
+func (c *Code) setFunction(x, y int, dark bool) {
+	c.modules[y][x] = dark
+	c.function[y][x] = true
+}
+
+// newGrid allocates a size x size grid of modules
+func newGrid(size int) [][]bool {
+	grid := make([][]bool, size)
+	for i := range grid {
+		grid[i] = make([]bool, size)
+	}
+	return grid
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
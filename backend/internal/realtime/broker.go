@@ -0,0 +1,248 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/delpresence/backend/internal/database"
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+)
+
+const (
+	// notifyChannel is the PostgreSQL channel shared by all backend replicas
+	notifyChannel = "delpresence_events"
+
+	// maxNotifyPayload stays below PostgreSQL's 8000 byte NOTIFY payload limit
+	maxNotifyPayload = 7900
+
+	// subscriberBuffer is the number of messages queued per subscriber before dropping
+	subscriberBuffer = 64
+)
+
+// Message is a single event delivered to subscribers of a topic
+type Message struct {
+	Topic string          `json:"topic"`
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// Subscription receives messages published to one or more topics
+type Subscription struct {
+	broker *Broker
+	topics []string
+	ch     chan Message
+	once   sync.Once
+}
+
+// Messages returns the channel on which messages are delivered
+func (s *Subscription) Messages() <-chan Message {
+	return s.ch
+}
+
+// Close unsubscribes from all topics and closes the message channel
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.broker.unsubscribe(s)
+		close(s.ch)
+	})
+}
+
+// Broker fans out published messages to local subscribers. When PostgreSQL
+// LISTEN/NOTIFY is available every message goes through the database, so that
+// subscribers connected to any backend replica receive it.
+type Broker struct {
+	db          *gorm.DB
+	dsn         string
+	mutex       sync.RWMutex
+	subscribers map[string]map[*Subscription]struct{}
+	listening   atomic.Bool
+}
+
+// defaultBroker is the process-wide broker used by the package-level helpers
+var defaultBroker *Broker
+
+// NewBroker creates a broker that publishes through the given database connection
+func NewBroker(db *gorm.DB, dsn string) *Broker {
+	return &Broker{
+		db:          db,
+		dsn:         dsn,
+		subscribers: make(map[string]map[*Subscription]struct{}),
+	}
+}
+
+// Initialize creates the default broker and starts listening for notifications from other replicas
+func Initialize() {
+	defaultBroker = NewBroker(database.GetDB(), database.DSN())
+	go defaultBroker.Listen(context.Background())
+}
+
+// Publish publishes an event on the default broker. It is a no-op if the broker is not initialized.
+func Publish(topic, event string, data interface{}) error {
+	if defaultBroker == nil {
+		return nil
+	}
+	return defaultBroker.Publish(topic, event, data)
+}
+
+// Subscribe subscribes to the given topics on the default broker
+func Subscribe(topics ...string) (*Subscription, error) {
+	if defaultBroker == nil {
+		return nil, fmt.Errorf("realtime broker is not initialized")
+	}
+	return defaultBroker.Subscribe(topics...), nil
+}
+
+// SessionTopic returns the topic for events of a single attendance session
+func SessionTopic(sessionID uint) string {
+	return fmt.Sprintf("attendance_session:%d", sessionID)
+}
+
+// StudentGroupTopic returns the topic for session events relevant to a student group
+func StudentGroupTopic(studentGroupID uint) string {
+	return fmt.Sprintf("student_group:%d", studentGroupID)
+}
+
+// ReportJobTopic returns the topic for status updates of a background report job
+func ReportJobTopic(jobID uint) string {
+	return fmt.Sprintf("report_job:%d", jobID)
+}
+
+// Subscribe registers a new subscription for the given topics
+func (b *Broker) Subscribe(topics ...string) *Subscription {
+	sub := &Subscription{
+		broker: b,
+		topics: topics,
+		ch:     make(chan Message, subscriberBuffer),
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for _, topic := range topics {
+		if b.subscribers[topic] == nil {
+			b.subscribers[topic] = make(map[*Subscription]struct{})
+		}
+		b.subscribers[topic][sub] = struct{}{}
+	}
+
+	return sub
+}
+
+// unsubscribe removes a subscription from all of its topics
+func (b *Broker) unsubscribe(sub *Subscription) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for _, topic := range sub.topics {
+		delete(b.subscribers[topic], sub)
+		if len(b.subscribers[topic]) == 0 {
+			delete(b.subscribers, topic)
+		}
+	}
+}
+
+// Publish sends an event to all subscribers of a topic across replicas
+func (b *Broker) Publish(topic, event string, data interface{}) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode event data: %w", err)
+	}
+
+	msg := Message{Topic: topic, Event: event, Data: raw}
+
+	// Without a listener the database round-trip would never come back, so deliver locally
+	if !b.listening.Load() {
+		b.deliver(msg)
+		return nil
+	}
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	if len(payload) > maxNotifyPayload {
+		log.Printf("Realtime event %s on %s exceeds NOTIFY payload limit, delivering locally only", event, topic)
+		b.deliver(msg)
+		return nil
+	}
+
+	return b.db.Exec("SELECT pg_notify(?, ?)", notifyChannel, string(payload)).Error
+}
+
+// deliver hands a message to every local subscriber of its topic
+func (b *Broker) deliver(msg Message) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	for sub := range b.subscribers[msg.Topic] {
+		select {
+		case sub.ch <- msg:
+		default:
+			// Slow consumers must not block the broker, they can resync from the REST endpoints
+			log.Printf("Realtime subscriber buffer full, dropping %s event on %s", msg.Event, msg.Topic)
+		}
+	}
+}
+
+// Listen keeps a dedicated connection listening for notifications until the context is canceled
+func (b *Broker) Listen(ctx context.Context) {
+	backoff := time.Second
+
+	for {
+		connected, err := b.listenOnce(ctx)
+		b.listening.Store(false)
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		if connected {
+			backoff = time.Second
+		}
+
+		log.Printf("Realtime listener stopped: %v, reconnecting in %s", err, backoff)
+		time.Sleep(backoff)
+
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
+// listenOnce listens on a single connection and reports whether LISTEN succeeded
+func (b *Broker) listenOnce(ctx context.Context) (bool, error) {
+	conn, err := pgx.Connect(ctx, b.dsn)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+notifyChannel); err != nil {
+		return false, err
+	}
+
+	b.listening.Store(true)
+	log.Printf("Realtime listener subscribed to channel %s", notifyChannel)
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return true, err
+		}
+
+		var msg Message
+		if err := json.Unmarshal([]byte(notification.Payload), &msg); err != nil {
+			log.Printf("Realtime listener received invalid payload: %v", err)
+			continue
+		}
+
+		b.deliver(msg)
+	}
+}
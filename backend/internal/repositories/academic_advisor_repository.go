@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"github.com/delpresence/backend/internal/database"
+	"github.com/delpresence/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AcademicAdvisorRepository handles database operations for academic advisors
+type AcademicAdvisorRepository struct {
+	db *gorm.DB
+}
+
+// NewAcademicAdvisorRepository creates a new academic advisor repository
+func NewAcademicAdvisorRepository() *AcademicAdvisorRepository {
+	return &AcademicAdvisorRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Assign sets the advisor of a student, replacing any previous advisor
+func (r *AcademicAdvisorRepository) Assign(advisor *models.AcademicAdvisor) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "student_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"lecturer_user_id", "updated_at"}),
+	}).Create(advisor).Error
+}
+
+// DeleteByStudentID removes the advisor of a student
+func (r *AcademicAdvisorRepository) DeleteByStudentID(studentID uint) error {
+	return r.db.Where("student_id = ?", studentID).Delete(&models.AcademicAdvisor{}).Error
+}
+
+// FindByStudentID returns the advisor assignment of a student, or nil when there is none
+func (r *AcademicAdvisorRepository) FindByStudentID(studentID uint) (*models.AcademicAdvisor, error) {
+	var advisor models.AcademicAdvisor
+	err := r.db.Where("student_id = ?", studentID).First(&advisor).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &advisor, nil
+}
+
+// IsAdvisor reports whether the lecturer is the advisor of the student
+func (r *AcademicAdvisorRepository) IsAdvisor(lecturerUserID int, studentID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.AcademicAdvisor{}).
+		Where("lecturer_user_id = ? AND student_id = ?", lecturerUserID, studentID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ListAll returns advisor assignments with their students, optionally for one lecturer
+func (r *AcademicAdvisorRepository) ListAll(lecturerUserID int) ([]models.AcademicAdvisor, error) {
+	var advisors []models.AcademicAdvisor
+	query := r.db.Preload("Student").Order("student_id")
+	if lecturerUserID > 0 {
+		query = query.Where("lecturer_user_id = ?", lecturerUserID)
+	}
+	err := query.Find(&advisors).Error
+	return advisors, err
+}
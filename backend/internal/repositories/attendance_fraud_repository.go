@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/delpresence/backend/internal/database"
+	"github.com/delpresence/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AttendanceFraudRepository handles database operations for suspected proxy attendance
+type AttendanceFraudRepository struct {
+	db *gorm.DB
+}
+
+// NewAttendanceFraudRepository creates a new attendance fraud repository
+func NewAttendanceFraudRepository() *AttendanceFraudRepository {
+	return &AttendanceFraudRepository{
+		db: database.GetDB(),
+	}
+}
+
+// ListSelfCheckInsSince lists the check-ins students made themselves since a time, oldest first.
+// Attendance marked by a lecturer or assistant is left out.
+func (r *AttendanceFraudRepository) ListSelfCheckInsSince(since time.Time) ([]models.StudentAttendance, error) {
+	var attendances []models.StudentAttendance
+	err := r.db.Where("check_in_time >= ? AND verified_by_id IS NULL", since).
+		Where("status IN ?", []models.StudentAttendanceStatus{models.StudentAttendanceStatusPresent, models.StudentAttendanceStatusLate}).
+		Order("check_in_time ASC").
+		Find(&attendances).Error
+	return attendances, err
+}
+
+// CreateFlags stores new flags, skipping check-ins already flagged for the same rule, and returns
+// how many were stored
+func (r *AttendanceFraudRepository) CreateFlags(flags []models.AttendanceFraudFlag) (int64, error) {
+	if len(flags) == 0 {
+		return 0, nil
+	}
+	res := r.db.Omit("Student").Clauses(clause.OnConflict{DoNothing: true}).Create(&flags)
+	return res.RowsAffected, res.Error
+}
+
+// GetByID retrieves a flag by ID
+func (r *AttendanceFraudRepository) GetByID(id uint) (*models.AttendanceFraudFlag, error) {
+	var flag models.AttendanceFraudFlag
+	err := r.db.Preload("Student").First(&flag, id).Error
+	return &flag, err
+}
+
+// ListBySession lists the flags of a session, optionally filtered by status
+func (r *AttendanceFraudRepository) ListBySession(sessionID uint, status string) ([]models.AttendanceFraudFlag, error) {
+	var flags []models.AttendanceFraudFlag
+	query := r.db.Preload("Student").Where("attendance_session_id = ?", sessionID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("created_at DESC").Find(&flags).Error
+	return flags, err
+}
+
+// ListByStatus lists the flags with a status across all sessions, newest first
+func (r *AttendanceFraudRepository) ListByStatus(status string) ([]models.AttendanceFraudFlag, error) {
+	var flags []models.AttendanceFraudFlag
+	query := r.db.Preload("Student")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("created_at DESC").Find(&flags).Error
+	return flags, err
+}
+
+// ResolveOpenFlags sets the status of every open flag of a check-in, returning the number resolved
+func (r *AttendanceFraudRepository) ResolveOpenFlags(attendanceID uint, status models.AttendanceFraudFlagStatus, reviewerID uint, notes string, at time.Time) (int64, error) {
+	res := r.db.Model(&models.AttendanceFraudFlag{}).
+		Where("student_attendance_id = ? AND status = ?", attendanceID, models.FraudFlagOpen).
+		Updates(map[string]interface{}{
+			"status":         status,
+			"reviewed_by_id": reviewerID,
+			"reviewed_at":    at,
+			"review_notes":   notes,
+		})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/delpresence/backend/internal/database"
+	"github.com/delpresence/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// AttendanceOverrideRepository handles database operations for the admin override audit trail
+type AttendanceOverrideRepository struct {
+	db *gorm.DB
+}
+
+// NewAttendanceOverrideRepository creates a new attendance override repository
+func NewAttendanceOverrideRepository() *AttendanceOverrideRepository {
+	return &AttendanceOverrideRepository{
+		db: database.GetDB(),
+	}
+}
+
+// AttendanceOverrideFilter narrows the audit trail; zero values match everything
+type AttendanceOverrideFilter struct {
+	SessionID     uint
+	StudentID     uint
+	PerformedByID uint
+	Action        string
+	StartDate     *time.Time
+	EndDate       *time.Time
+	Limit         int
+}
+
+// List lists overrides matching the filter, most recent first
+func (r *AttendanceOverrideRepository) List(filter AttendanceOverrideFilter) ([]models.AttendanceOverride, error) {
+	query := r.db.Preload("Student")
+	if filter.SessionID > 0 {
+		query = query.Where("attendance_session_id = ?", filter.SessionID)
+	}
+	if filter.StudentID > 0 {
+		query = query.Where("student_id = ?", filter.StudentID)
+	}
+	if filter.PerformedByID > 0 {
+		query = query.Where("performed_by_id = ?", filter.PerformedByID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.StartDate != nil {
+		query = query.Where("created_at >= ?", *filter.StartDate)
+	}
+	if filter.EndDate != nil {
+		query = query.Where("created_at < ?", *filter.EndDate)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var overrides []models.AttendanceOverride
+	err := query.Order("created_at DESC, id DESC").Find(&overrides).Error
+	return overrides, err
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/delpresence/backend/internal/database"
 	"github.com/delpresence/backend/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // AttendanceRepository handles database operations for attendance
@@ -58,6 +59,43 @@ func (r *AttendanceRepository) ListSessionsByDateRange(lecturerID uint, startDat
 	return sessions, err
 }
 
+// SessionListFilter selects the sessions visible to a user: sessions they created, sessions
+// of the given schedules, or every session when All is set
+type SessionListFilter struct {
+	CreatorID   uint
+	ScheduleIDs []uint
+	All         bool
+	ActiveOnly  bool
+	StartDate   *time.Time
+	EndDate     *time.Time
+}
+
+// ListSessions lists attendance sessions matching the filter, most recent first
+func (r *AttendanceRepository) ListSessions(filter SessionListFilter) ([]models.AttendanceSession, error) {
+	query := r.db.Preload("CourseSchedule").Preload("CourseSchedule.Course").Preload("CourseSchedule.Room")
+
+	if !filter.All {
+		switch {
+		case len(filter.ScheduleIDs) > 0:
+			query = query.Where("(lecturer_id = ? OR course_schedule_id IN ?)", filter.CreatorID, filter.ScheduleIDs)
+		case filter.CreatorID > 0:
+			query = query.Where("lecturer_id = ?", filter.CreatorID)
+		default:
+			return []models.AttendanceSession{}, nil
+		}
+	}
+	if filter.ActiveOnly {
+		query = query.Where("status = ?", models.AttendanceStatusActive)
+	}
+	if filter.StartDate != nil && filter.EndDate != nil {
+		query = query.Where("date BETWEEN ? AND ?", filter.StartDate.Format("2006-01-02"), filter.EndDate.Format("2006-01-02"))
+	}
+
+	var sessions []models.AttendanceSession
+	err := query.Order("date DESC, start_time DESC").Find(&sessions).Error
+	return sessions, err
+}
+
 // ListSessionsByCourseSchedule lists attendance sessions for a specific course schedule
 func (r *AttendanceRepository) ListSessionsByCourseSchedule(courseScheduleID uint) ([]models.AttendanceSession, error) {
 	var sessions []models.AttendanceSession
@@ -122,80 +160,6 @@ func (r *AttendanceRepository) ListStudentAttendancesByStatus(sessionID uint, st
 	return attendances, err
 }
 
-// GetAttendanceStats gets attendance statistics for a course schedule
-func (r *AttendanceRepository) GetAttendanceStats(courseScheduleID uint) (*models.AttendanceStatistics, error) {
-	var stats models.AttendanceStatistics
-
-	// Get total sessions
-	var totalSessions int64
-	if err := r.db.Model(&models.AttendanceSession{}).
-		Where("course_schedule_id = ?", courseScheduleID).
-		Count(&totalSessions).Error; err != nil {
-		return nil, err
-	}
-	stats.TotalSessions = int(totalSessions)
-
-	// Get total students for this course schedule
-	var courseSchedule models.CourseSchedule
-	if err := r.db.Where("id = ?", courseScheduleID).First(&courseSchedule).Error; err != nil {
-		return nil, err
-	}
-	stats.TotalStudents = courseSchedule.Enrolled
-
-	// Count attendance by status
-	sessions, err := r.ListSessionsByCourseSchedule(courseScheduleID)
-	if err != nil {
-		return nil, err
-	}
-
-	for _, session := range sessions {
-		var presentCount int64
-		err := r.db.Model(&models.StudentAttendance{}).
-			Where("attendance_session_id = ? AND status = ?", session.ID, models.StudentAttendanceStatusPresent).
-			Count(&presentCount).Error
-		if err != nil {
-			return nil, err
-		}
-		stats.TotalAttendance += int(presentCount)
-
-		var lateCount int64
-		err = r.db.Model(&models.StudentAttendance{}).
-			Where("attendance_session_id = ? AND status = ?", session.ID, models.StudentAttendanceStatusLate).
-			Count(&lateCount).Error
-		if err != nil {
-			return nil, err
-		}
-		stats.TotalLate += int(lateCount)
-
-		var absentCount int64
-		err = r.db.Model(&models.StudentAttendance{}).
-			Where("attendance_session_id = ? AND status = ?", session.ID, models.StudentAttendanceStatusAbsent).
-			Count(&absentCount).Error
-		if err != nil {
-			return nil, err
-		}
-		stats.TotalAbsent += int(absentCount)
-
-		var excusedCount int64
-		err = r.db.Model(&models.StudentAttendance{}).
-			Where("attendance_session_id = ? AND status = ?", session.ID, models.StudentAttendanceStatusExcused).
-			Count(&excusedCount).Error
-		if err != nil {
-			return nil, err
-		}
-		stats.TotalExcused += int(excusedCount)
-	}
-
-	// Calculate average attendance percentage
-	if stats.TotalSessions > 0 && stats.TotalStudents > 0 {
-		totalPossibleAttendances := stats.TotalSessions * stats.TotalStudents
-		totalPresent := stats.TotalAttendance + stats.TotalLate
-		stats.AverageAttendance = (totalPresent * 100) / totalPossibleAttendances
-	}
-
-	return &stats, nil
-}
-
 // ListActiveSessionsBySchedules gets all active attendance sessions for given course schedule IDs
 func (r *AttendanceRepository) ListActiveSessionsBySchedules(scheduleIDs []uint) ([]models.AttendanceSession, error) {
 	// Handle empty schedule IDs
@@ -233,3 +197,95 @@ func (r *AttendanceRepository) ListActiveSessionsBySchedules(scheduleIDs []uint)
 
 	return sessions, nil
 }
+
+// SessionExistsForScheduleOnDate reports whether a schedule has a session that was not canceled on a date
+func (r *AttendanceRepository) SessionExistsForScheduleOnDate(courseScheduleID uint, date time.Time) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.AttendanceSession{}).
+		Where("course_schedule_id = ? AND date = ? AND status <> ?", courseScheduleID, date.Format("2006-01-02"), models.AttendanceStatusCanceled).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// CountMeetingsBefore counts the dates before a date on which a schedule held a session that was
+// not canceled
+func (r *AttendanceRepository) CountMeetingsBefore(courseScheduleID uint, date time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.AttendanceSession{}).
+		Where("course_schedule_id = ? AND date < ? AND status <> ?", courseScheduleID, date.Format("2006-01-02"), models.AttendanceStatusCanceled).
+		Distinct("date").
+		Count(&count).Error
+	return count, err
+}
+
+// ListAbsencesInDateRange lists the ABSENT records of the students in sessions held between two dates
+// that were not canceled, optionally limited to one schedule
+func (r *AttendanceRepository) ListAbsencesInDateRange(studentIDs []uint, startDate, endDate time.Time, courseScheduleID uint) ([]models.StudentAttendance, error) {
+	var attendances []models.StudentAttendance
+	if len(studentIDs) == 0 {
+		return attendances, nil
+	}
+
+	query := r.db.Joins("JOIN attendance_sessions ON attendance_sessions.id = student_attendances.attendance_session_id AND attendance_sessions.deleted_at IS NULL").
+		Where("student_attendances.student_id IN ?", studentIDs).
+		Where("student_attendances.status = ?", models.StudentAttendanceStatusAbsent).
+		Where("attendance_sessions.status <> ?", models.AttendanceStatusCanceled).
+		Where("attendance_sessions.date BETWEEN ? AND ?", startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	if courseScheduleID > 0 {
+		query = query.Where("attendance_sessions.course_schedule_id = ?", courseScheduleID)
+	}
+
+	err := query.Preload("Student").Preload("AttendanceSession").Preload("AttendanceSession.CourseSchedule").
+		Order("attendance_sessions.date, student_attendances.id").
+		Find(&attendances).Error
+	return attendances, err
+}
+
+// ListExpiredReopenedSessions lists reopened sessions that are still active after their reopen window
+func (r *AttendanceRepository) ListExpiredReopenedSessions(now time.Time) ([]models.AttendanceSession, error) {
+	var sessions []models.AttendanceSession
+	err := r.db.Preload("CourseSchedule").Preload("CourseSchedule.Course").
+		Where("status = ? AND reopened_until IS NOT NULL AND reopened_until <= ?", models.AttendanceStatusActive, now).
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// CloseReopenedSession closes a reopened session at the end of its reopen window. It reports false
+// when the session was already closed, e.g. by another replica.
+func (r *AttendanceRepository) CloseReopenedSession(id uint, endTime time.Time) (bool, error) {
+	result := r.db.Model(&models.AttendanceSession{}).
+		Where("id = ? AND status = ? AND reopened_until IS NOT NULL", id, models.AttendanceStatusActive).
+		Updates(map[string]interface{}{
+			"status":          models.AttendanceStatusClosed,
+			"end_time":        endTime,
+			"reopened_until":  nil,
+			"first_closed_at": gorm.Expr("COALESCE(first_closed_at, ?)", endTime),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// CheckPINAttempt counts the wrong codes a student submitted to a PIN session since a time and,
+// when the submitted code is wrong and the count is below the limit, records it. The student's row
+// is locked for the count and insert, so concurrent submissions cannot slip past the limit. It
+// returns the count from before this submission.
+func (r *AttendanceRepository) CheckPINAttempt(sessionID, studentID uint, since time.Time, limit int, wrong bool) (int64, error) {
+	var count int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var student models.Student
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&student, studentID).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.AttendancePINAttempt{}).
+			Where("attendance_session_id = ? AND student_id = ? AND created_at >= ?", sessionID, studentID, since).
+			Count(&count).Error; err != nil {
+			return err
+		}
+
+		if !wrong || count >= int64(limit) {
+			return nil
+		}
+		return tx.Create(&models.AttendancePINAttempt{AttendanceSessionID: sessionID, StudentID: studentID}).Error
+	})
+	return count, err
+}
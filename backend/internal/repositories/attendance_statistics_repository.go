@@ -0,0 +1,564 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/delpresence/backend/internal/database"
+	"github.com/delpresence/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// AttendanceStatsFilter restricts the sessions and records attendance statistics are computed over.
+// Zero values are ignored. Canceled sessions are always excluded from attendance counts.
+type AttendanceStatsFilter struct {
+	SessionIDs        []uint
+	CourseScheduleIDs []uint
+	CourseIDs         []uint
+	StudentGroupIDs   []uint
+	AcademicYearID    uint
+	LecturerUserID    uint // Schedules taught by this lecturer (external user ID)
+	StudentUserID     uint // Records of this student (external user ID)
+	StartDate         *time.Time
+	EndDate           *time.Time
+	ClosedOnly        bool // Only count sessions that were closed
+}
+
+// breakdownColumns holds the SQL expressions that identify and describe a breakdown row,
+// and any joins they need beyond the base records query
+type breakdownColumns struct {
+	id    string
+	code  string
+	name  string
+	date  string
+	group string
+	joins []string
+}
+
+// attendanceBreakdownColumns maps each breakdown to its SQL expressions
+var attendanceBreakdownColumns = map[models.AttendanceBreakdown]breakdownColumns{
+	models.AttendanceBreakdownSession: {
+		id: "s.id", code: "c.code", name: "c.name", date: "TO_CHAR(s.date, 'YYYY-MM-DD')",
+		group: "s.id, c.code, c.name, s.date",
+	},
+	models.AttendanceBreakdownSchedule: {
+		id: "cs.id", code: "c.code", name: "CONCAT(c.name, ' - ', cs.day, ' ', cs.start_time)", date: "''",
+		group: "cs.id, c.code, c.name, cs.day, cs.start_time",
+	},
+	models.AttendanceBreakdownCourse: {
+		id: "c.id", code: "c.code", name: "c.name", date: "''",
+		group: "c.id, c.code, c.name",
+	},
+	models.AttendanceBreakdownStudent: {
+		id: "st.id", code: "st.nim", name: "st.full_name", date: "''",
+		group: "st.id, st.nim, st.full_name",
+	},
+	models.AttendanceBreakdownGroup: {
+		id: "cs.student_group_id", code: "''", name: "COALESCE(sg.name, '')", date: "''",
+		group: "cs.student_group_id, sg.name",
+	},
+	models.AttendanceBreakdownFaculty: {
+		id: "c.faculty_id", code: "COALESCE(f.code, '')", name: "COALESCE(f.name, '')", date: "''",
+		group: "c.faculty_id, f.code, f.name",
+		joins: []string{"LEFT JOIN faculties f ON f.id = c.faculty_id"},
+	},
+	models.AttendanceBreakdownStudyProgram: {
+		id: "c.department_id", code: "COALESCE(sp.code, '')", name: "COALESCE(sp.name, '')", date: "''",
+		group: "c.department_id, sp.code, sp.name",
+		joins: []string{"LEFT JOIN study_programs sp ON sp.id = c.department_id"},
+	},
+	models.AttendanceBreakdownLecturer: {
+		id: "cs.lecturer_id", code: "COALESCE(l.n_ip, '')", name: "COALESCE(l.full_name, '')", date: "''",
+		group: "cs.lecturer_id, l.n_ip, l.full_name",
+		joins: []string{`LEFT JOIN LATERAL (
+			SELECT n_ip, full_name FROM lecturers
+			WHERE lecturers.user_id = cs.lecturer_id AND lecturers.deleted_at IS NULL
+			ORDER BY lecturers.id LIMIT 1
+		) l ON TRUE`},
+	},
+	models.AttendanceBreakdownDayOfWeek: {
+		id: `CASE LOWER(cs.day) WHEN 'senin' THEN 1 WHEN 'selasa' THEN 2 WHEN 'rabu' THEN 3
+			WHEN 'kamis' THEN 4 WHEN 'jumat' THEN 5 WHEN 'sabtu' THEN 6 ELSE 7 END`,
+		code: "''", name: "INITCAP(cs.day)", date: "''",
+		group: "LOWER(cs.day), INITCAP(cs.day)",
+	},
+	models.AttendanceBreakdownTimeSlot: {
+		id: "CAST(SPLIT_PART(cs.start_time, ':', 1) AS INTEGER)", code: "cs.start_time",
+		name: "CONCAT(cs.start_time, '-', cs.end_time)", date: "''",
+		group: "cs.start_time, cs.end_time",
+	},
+}
+
+// AttendanceStatisticsRepository computes attendance statistics with aggregate queries
+type AttendanceStatisticsRepository struct {
+	db *gorm.DB
+}
+
+// NewAttendanceStatisticsRepository creates a new attendance statistics repository
+func NewAttendanceStatisticsRepository() *AttendanceStatisticsRepository {
+	return &AttendanceStatisticsRepository{
+		db: database.GetDB(),
+	}
+}
+
+// GetBreakdown counts attendance records by status grouped by the given dimension, in one query
+func (r *AttendanceStatisticsRepository) GetBreakdown(breakdown models.AttendanceBreakdown, filter AttendanceStatsFilter) ([]models.AttendanceBreakdownItem, error) {
+	columns, ok := attendanceBreakdownColumns[breakdown]
+	if !ok {
+		return nil, fmt.Errorf("unknown breakdown: %s", breakdown)
+	}
+
+	query := r.recordsQuery(filter)
+	for _, join := range columns.joins {
+		query = query.Joins(join)
+	}
+
+	var items []models.AttendanceBreakdownItem
+	err := query.
+		Select(fmt.Sprintf(`%s AS id, %s AS code, %s AS name, %s AS date,
+			COUNT(DISTINCT s.id) AS sessions,
+			COUNT(*) FILTER (WHERE sa.status = ?) AS present,
+			COUNT(*) FILTER (WHERE sa.status = ?) AS late,
+			COUNT(*) FILTER (WHERE sa.status = ?) AS absent,
+			COUNT(*) FILTER (WHERE sa.status = ?) AS excused,
+			COUNT(*) AS total`, columns.id, columns.code, columns.name, columns.date),
+			models.StudentAttendanceStatusPresent, models.StudentAttendanceStatusLate,
+			models.StudentAttendanceStatusAbsent, models.StudentAttendanceStatusExcused).
+		Group(columns.group).
+		Order("code, id").
+		Scan(&items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range items {
+		items[i].AttendanceRate = models.AttendanceStatusCounts{
+			Present: items[i].Present,
+			Late:    items[i].Late,
+			Total:   items[i].Total,
+		}.AttendedRate()
+	}
+	return items, nil
+}
+
+// GetWeeklyTrend counts attendance records by status for each week, in one query
+func (r *AttendanceStatisticsRepository) GetWeeklyTrend(filter AttendanceStatsFilter) ([]models.AttendanceTrendPoint, error) {
+	var points []models.AttendanceTrendPoint
+	err := r.recordsQuery(filter).
+		Select(`TO_CHAR(DATE_TRUNC('week', s.date), 'YYYY-MM-DD') AS week_start,
+			COUNT(DISTINCT s.id) AS sessions,
+			COUNT(*) FILTER (WHERE sa.status = ?) AS present,
+			COUNT(*) FILTER (WHERE sa.status = ?) AS late,
+			COUNT(*) FILTER (WHERE sa.status = ?) AS absent,
+			COUNT(*) FILTER (WHERE sa.status = ?) AS excused,
+			COUNT(*) AS total`,
+			models.StudentAttendanceStatusPresent, models.StudentAttendanceStatusLate,
+			models.StudentAttendanceStatusAbsent, models.StudentAttendanceStatusExcused).
+		Group("DATE_TRUNC('week', s.date)").
+		Order("week_start").
+		Scan(&points).Error
+	return points, err
+}
+
+// GetLateArrivalMinutes counts late check-ins by whole minutes after the session started, in one query
+func (r *AttendanceStatisticsRepository) GetLateArrivalMinutes(filter AttendanceStatsFilter) (map[int]int, error) {
+	var rows []struct {
+		Minutes int
+		Count   int
+	}
+	err := r.recordsQuery(filter).
+		Select("FLOOR(EXTRACT(EPOCH FROM (sa.check_in_time - s.start_time)) / 60)::int AS minutes, COUNT(*) AS count").
+		Where("sa.status = ? AND sa.check_in_time IS NOT NULL", models.StudentAttendanceStatusLate).
+		Group("minutes").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[int]int)
+	for _, row := range rows {
+		result[row.Minutes] = row.Count
+	}
+	return result, nil
+}
+
+// GetVerificationMethodCounts counts check-ins (present or late records) by verification method, in one query
+func (r *AttendanceStatisticsRepository) GetVerificationMethodCounts(filter AttendanceStatsFilter) ([]models.VerificationMethodShare, error) {
+	var rows []models.VerificationMethodShare
+	err := r.recordsQuery(filter).
+		Select("COALESCE(NULLIF(sa.verification_method, ''), 'UNKNOWN') AS method, COUNT(*) AS count").
+		Where("sa.status IN ?", []models.StudentAttendanceStatus{models.StudentAttendanceStatusPresent, models.StudentAttendanceStatusLate}).
+		Group("method").
+		Order("count DESC").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// ScheduleSessionDate is a date on which a schedule had a session
+type ScheduleSessionDate struct {
+	CourseScheduleID uint
+	Date             time.Time
+}
+
+// GetSessionDates lists the distinct dates with a non-canceled session for each schedule matching the filter
+func (r *AttendanceStatisticsRepository) GetSessionDates(filter AttendanceStatsFilter) ([]ScheduleSessionDate, error) {
+	var rows []ScheduleSessionDate
+	query := r.db.Table("attendance_sessions s").
+		Joins("JOIN course_schedules cs ON cs.id = s.course_schedule_id").
+		Where("s.deleted_at IS NULL AND s.status <> ?", models.AttendanceStatusCanceled)
+	query = applySessionFilter(query, filter)
+
+	err := query.Select("DISTINCT s.course_schedule_id, DATE(s.date) AS date").Scan(&rows).Error
+	return rows, err
+}
+
+// TeachingSession is a non-canceled session with who opened it and when
+type TeachingSession struct {
+	CourseScheduleID uint
+	LecturerID       uint
+	Date             time.Time
+	StartTime        time.Time
+	CreatorRole      string
+}
+
+// GetTeachingSessions lists the non-canceled sessions of the schedules matching the filter, in
+// order of opening
+func (r *AttendanceStatisticsRepository) GetTeachingSessions(filter AttendanceStatsFilter) ([]TeachingSession, error) {
+	var rows []TeachingSession
+	query := r.db.Table("attendance_sessions s").
+		Joins("JOIN course_schedules cs ON cs.id = s.course_schedule_id").
+		Where("s.deleted_at IS NULL AND s.status <> ?", models.AttendanceStatusCanceled)
+	query = applySessionFilter(query, filter)
+
+	err := query.Select("s.course_schedule_id, s.lecturer_id, s.date, s.start_time, s.creator_role").
+		Order("s.course_schedule_id, s.start_time, s.id").Scan(&rows).Error
+	return rows, err
+}
+
+// CourseSession is a non-canceled session of a course with who opened and closed it and the
+// scheduled hours of its schedule
+type CourseSession struct {
+	ID                uint
+	CourseID          uint
+	CreatorID         uint
+	CreatorRole       string
+	ClosedByID        *uint
+	ScheduleStartTime string
+	ScheduleEndTime   string
+}
+
+// GetCourseSessions lists the non-canceled sessions of the courses' schedules in an academic year
+func (r *AttendanceStatisticsRepository) GetCourseSessions(academicYearID uint, courseIDs []uint) ([]CourseSession, error) {
+	var rows []CourseSession
+	if len(courseIDs) == 0 {
+		return rows, nil
+	}
+	err := r.db.Table("attendance_sessions s").
+		Joins("JOIN course_schedules cs ON cs.id = s.course_schedule_id").
+		Select(`s.id, cs.course_id, s.lecturer_id AS creator_id, s.creator_role, s.closed_by_id,
+			cs.start_time AS schedule_start_time, cs.end_time AS schedule_end_time`).
+		Where("s.deleted_at IS NULL AND s.status <> ?", models.AttendanceStatusCanceled).
+		Where("cs.academic_year_id = ? AND cs.course_id IN ?", academicYearID, courseIDs).
+		Scan(&rows).Error
+	return rows, err
+}
+
+// ManualMarkCount is the number of records a user marked by hand in a course's sessions
+type ManualMarkCount struct {
+	CourseID     uint
+	VerifiedByID uint
+	Count        int
+}
+
+// GetManualMarkCounts counts, per course, the records each user marked by hand in the courses'
+// sessions of an academic year
+func (r *AttendanceStatisticsRepository) GetManualMarkCounts(academicYearID uint, courseIDs []uint, userIDs []uint) ([]ManualMarkCount, error) {
+	var rows []ManualMarkCount
+	if len(courseIDs) == 0 || len(userIDs) == 0 {
+		return rows, nil
+	}
+	err := r.db.Table("student_attendances sa").
+		Joins("JOIN attendance_sessions s ON s.id = sa.attendance_session_id AND s.deleted_at IS NULL").
+		Joins("JOIN course_schedules cs ON cs.id = s.course_schedule_id").
+		Select("cs.course_id, sa.verified_by_id, COUNT(sa.id) AS count").
+		Where("sa.deleted_at IS NULL AND sa.verified_by_id IN ?", userIDs).
+		Where("cs.academic_year_id = ? AND cs.course_id IN ?", academicYearID, courseIDs).
+		Group("cs.course_id, sa.verified_by_id").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// GetTotals counts all attendance records matching the filter by status, in one query
+func (r *AttendanceStatisticsRepository) GetTotals(filter AttendanceStatsFilter) (models.AttendanceStatusCounts, error) {
+	var counts models.AttendanceStatusCounts
+	err := r.recordsQuery(filter).
+		Select(`COUNT(*) FILTER (WHERE sa.status = ?) AS present,
+			COUNT(*) FILTER (WHERE sa.status = ?) AS late,
+			COUNT(*) FILTER (WHERE sa.status = ?) AS absent,
+			COUNT(*) FILTER (WHERE sa.status = ?) AS excused,
+			COUNT(*) AS total`,
+			models.StudentAttendanceStatusPresent, models.StudentAttendanceStatusLate,
+			models.StudentAttendanceStatusAbsent, models.StudentAttendanceStatusExcused).
+		Scan(&counts).Error
+	return counts, err
+}
+
+// GetSessionStatusCounts counts the sessions matching the filter by session status, including canceled ones
+func (r *AttendanceStatisticsRepository) GetSessionStatusCounts(filter AttendanceStatsFilter) (map[models.AttendanceStatus]int, error) {
+	var rows []struct {
+		Status models.AttendanceStatus
+		Count  int
+	}
+
+	query := r.db.Table("attendance_sessions s").
+		Joins("JOIN course_schedules cs ON cs.id = s.course_schedule_id").
+		Where("s.deleted_at IS NULL")
+	query = applySessionFilter(query, filter)
+
+	if err := query.Select("s.status, COUNT(*) AS count").Group("s.status").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	result := make(map[models.AttendanceStatus]int)
+	for _, row := range rows {
+		result[row.Status] = row.Count
+	}
+	return result, nil
+}
+
+// GetCountsBySession counts attendance records by status for each of the given sessions, in one query
+func (r *AttendanceStatisticsRepository) GetCountsBySession(sessionIDs []uint) (map[uint]models.AttendanceStatusCounts, error) {
+	result := make(map[uint]models.AttendanceStatusCounts)
+	if len(sessionIDs) == 0 {
+		return result, nil
+	}
+
+	var rows []struct {
+		SessionID uint
+		models.AttendanceStatusCounts
+	}
+	err := r.db.Table("student_attendances sa").
+		Select(`sa.attendance_session_id AS session_id,
+			COUNT(*) FILTER (WHERE sa.status = ?) AS present,
+			COUNT(*) FILTER (WHERE sa.status = ?) AS late,
+			COUNT(*) FILTER (WHERE sa.status = ?) AS absent,
+			COUNT(*) FILTER (WHERE sa.status = ?) AS excused,
+			COUNT(*) AS total`,
+			models.StudentAttendanceStatusPresent, models.StudentAttendanceStatusLate,
+			models.StudentAttendanceStatusAbsent, models.StudentAttendanceStatusExcused).
+		Where("sa.deleted_at IS NULL AND sa.attendance_session_id IN ?", sessionIDs).
+		Group("sa.attendance_session_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		result[row.SessionID] = row.AttendanceStatusCounts
+	}
+	return result, nil
+}
+
+// GetGroupSizes returns the number of students in each of the given groups, in one query
+func (r *AttendanceStatisticsRepository) GetGroupSizes(groupIDs []uint) (map[uint]int, error) {
+	result := make(map[uint]int)
+	if len(groupIDs) == 0 {
+		return result, nil
+	}
+
+	var rows []struct {
+		StudentGroupID uint
+		Count          int
+	}
+	err := r.db.Model(&models.StudentToGroup{}).
+		Select("student_group_id, COUNT(*) AS count").
+		Where("student_group_id IN ?", groupIDs).
+		Group("student_group_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		result[row.StudentGroupID] = row.Count
+	}
+	return result, nil
+}
+
+// recordsQuery builds the joined query over attendance records matching the filter
+func (r *AttendanceStatisticsRepository) recordsQuery(filter AttendanceStatsFilter) *gorm.DB {
+	query := r.db.Table("student_attendances sa").
+		Joins("JOIN attendance_sessions s ON s.id = sa.attendance_session_id AND s.deleted_at IS NULL").
+		Joins("JOIN course_schedules cs ON cs.id = s.course_schedule_id").
+		Joins("JOIN courses c ON c.id = cs.course_id").
+		Joins("JOIN students st ON st.id = sa.student_id").
+		Joins("LEFT JOIN student_groups sg ON sg.id = cs.student_group_id").
+		Where("sa.deleted_at IS NULL AND s.status <> ?", models.AttendanceStatusCanceled)
+
+	if filter.StudentUserID > 0 {
+		query = query.Where("st.user_id = ?", filter.StudentUserID)
+	}
+
+	return applySessionFilter(query, filter)
+}
+
+// applySessionFilter adds the session-level conditions of a filter, using the aliases s and cs
+func applySessionFilter(query *gorm.DB, filter AttendanceStatsFilter) *gorm.DB {
+	if len(filter.SessionIDs) > 0 {
+		query = query.Where("s.id IN ?", filter.SessionIDs)
+	}
+	if len(filter.CourseScheduleIDs) > 0 {
+		query = query.Where("cs.id IN ?", filter.CourseScheduleIDs)
+	}
+	if len(filter.CourseIDs) > 0 {
+		query = query.Where("cs.course_id IN ?", filter.CourseIDs)
+	}
+	if len(filter.StudentGroupIDs) > 0 {
+		query = query.Where("cs.student_group_id IN ?", filter.StudentGroupIDs)
+	}
+	if filter.AcademicYearID > 0 {
+		query = query.Where("cs.academic_year_id = ?", filter.AcademicYearID)
+	}
+	if filter.LecturerUserID > 0 {
+		query = query.Where("cs.lecturer_id = ? OR "+teachingTeamCondition("cs"), filter.LecturerUserID, filter.LecturerUserID)
+	}
+	if filter.StartDate != nil {
+		query = query.Where("DATE(s.date) >= ?", filter.StartDate.Format("2006-01-02"))
+	}
+	if filter.EndDate != nil {
+		query = query.Where("DATE(s.date) <= ?", filter.EndDate.Format("2006-01-02"))
+	}
+	if filter.ClosedOnly {
+		query = query.Where("s.status = ?", models.AttendanceStatusClosed)
+	}
+	return query
+}
+
+// GetStudentCourseRecap counts a student's attendance in each schedule of an academic year they
+// are enrolled in through a student group, over closed sessions, in one query. Schedules without
+// any closed session yet are included with zero counts.
+func (r *AttendanceStatisticsRepository) GetStudentCourseRecap(studentID, academicYearID uint) ([]models.CourseAttendanceRecap, error) {
+	var rows []models.CourseAttendanceRecap
+	err := r.db.Table("course_schedules cs").
+		Select(`cs.id AS course_schedule_id, c.id AS course_id, c.code AS course_code, c.name AS course_name,
+			c.credits, COALESCE(l.full_name, '') AS lecturer_name, COALESCE(sg.name, '') AS student_group_name,
+			COUNT(sa.id) AS meetings_held,
+			COUNT(sa.id) FILTER (WHERE sa.status = ?) AS present,
+			COUNT(sa.id) FILTER (WHERE sa.status = ?) AS late,
+			COUNT(sa.id) FILTER (WHERE sa.status = ?) AS absent,
+			COUNT(sa.id) FILTER (WHERE sa.status = ?) AS excused`,
+			models.StudentAttendanceStatusPresent, models.StudentAttendanceStatusLate,
+			models.StudentAttendanceStatusAbsent, models.StudentAttendanceStatusExcused).
+		Joins("JOIN student_to_groups stg ON stg.student_group_id = cs.student_group_id AND stg.student_id = ?", studentID).
+		Joins("JOIN courses c ON c.id = cs.course_id").
+		Joins("LEFT JOIN student_groups sg ON sg.id = cs.student_group_id").
+		Joins(`LEFT JOIN LATERAL (
+			SELECT full_name FROM lecturers
+			WHERE lecturers.user_id = cs.lecturer_id AND lecturers.deleted_at IS NULL
+			ORDER BY lecturers.id LIMIT 1
+		) l ON TRUE`).
+		Joins("LEFT JOIN attendance_sessions s ON s.course_schedule_id = cs.id AND s.deleted_at IS NULL AND s.status = ?",
+			models.AttendanceStatusClosed).
+		Joins("LEFT JOIN student_attendances sa ON sa.attendance_session_id = s.id AND sa.student_id = ? AND sa.deleted_at IS NULL",
+			studentID).
+		Where("cs.deleted_at IS NULL AND cs.academic_year_id = ?", academicYearID).
+		Group("cs.id, c.id, c.code, c.name, c.credits, l.full_name, sg.name").
+		Order("course_code, course_schedule_id").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// MatrixSession is a non-canceled session of a schedule, in meeting order
+type MatrixSession struct {
+	ID               uint
+	CourseScheduleID uint
+	Date             time.Time
+	StartTime        time.Time
+	Status           models.AttendanceStatus
+}
+
+// MatrixStudent is a student listed in a schedule's attendance matrix
+type MatrixStudent struct {
+	CourseScheduleID uint
+	StudentID        uint
+	NIM              string
+	FullName         string
+}
+
+// MatrixRecord is the status of one student in one session
+type MatrixRecord struct {
+	SessionID uint
+	StudentID uint
+	Status    models.StudentAttendanceStatus
+}
+
+// GetMatrixSessions lists the non-canceled sessions of the schedules ordered by schedule, date and start time
+func (r *AttendanceStatisticsRepository) GetMatrixSessions(scheduleIDs []uint) ([]MatrixSession, error) {
+	var rows []MatrixSession
+	err := r.db.Table("attendance_sessions").
+		Select("id, course_schedule_id, date, start_time, status").
+		Where("deleted_at IS NULL AND status <> ? AND course_schedule_id IN ?", models.AttendanceStatusCanceled, scheduleIDs).
+		Order("course_schedule_id, date, start_time, id").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// GetMatrixStudents lists, per schedule, the members of its student group together with any
+// other student who has a record in one of its sessions, ordered by NIM
+func (r *AttendanceStatisticsRepository) GetMatrixStudents(scheduleIDs []uint) ([]MatrixStudent, error) {
+	var rows []MatrixStudent
+	err := r.db.Raw(`
+		SELECT DISTINCT x.course_schedule_id, st.id AS student_id, st.nim, st.full_name
+		FROM (
+			SELECT cs.id AS course_schedule_id, stg.student_id
+			FROM course_schedules cs
+			JOIN student_to_groups stg ON stg.student_group_id = cs.student_group_id
+			WHERE cs.id IN ?
+			UNION
+			SELECT s.course_schedule_id, sa.student_id
+			FROM attendance_sessions s
+			JOIN student_attendances sa ON sa.attendance_session_id = s.id AND sa.deleted_at IS NULL
+			WHERE s.deleted_at IS NULL AND s.status <> ? AND s.course_schedule_id IN ?
+		) x
+		JOIN students st ON st.id = x.student_id AND st.deleted_at IS NULL
+		ORDER BY x.course_schedule_id, st.nim`,
+		scheduleIDs, models.AttendanceStatusCanceled, scheduleIDs).
+		Scan(&rows).Error
+	return rows, err
+}
+
+// GetMatrixRecords returns the status of every student record in the sessions
+func (r *AttendanceStatisticsRepository) GetMatrixRecords(sessionIDs []uint) ([]MatrixRecord, error) {
+	var rows []MatrixRecord
+	if len(sessionIDs) == 0 {
+		return rows, nil
+	}
+	err := r.db.Table("student_attendances").
+		Select("attendance_session_id AS session_id, student_id, status").
+		Where("deleted_at IS NULL AND attendance_session_id IN ?", sessionIDs).
+		Scan(&rows).Error
+	return rows, err
+}
+
+// CountSessionsBefore counts the non-canceled sessions of a schedule held before the date
+func (r *AttendanceStatisticsRepository) CountSessionsBefore(scheduleID uint, date time.Time) (int, error) {
+	var count int64
+	err := r.db.Table("attendance_sessions").
+		Where("deleted_at IS NULL AND status <> ? AND course_schedule_id = ? AND DATE(date) < ?",
+			models.AttendanceStatusCanceled, scheduleID, date.Format("2006-01-02")).
+		Count(&count).Error
+	return int(count), err
+}
+
+// FindSessionOnDate returns the latest non-canceled session of a schedule on a date, or nil when there is none
+func (r *AttendanceStatisticsRepository) FindSessionOnDate(scheduleID uint, date time.Time) (*models.AttendanceSession, error) {
+	var sessions []models.AttendanceSession
+	err := r.db.Where("status <> ? AND course_schedule_id = ? AND DATE(date) = ?",
+		models.AttendanceStatusCanceled, scheduleID, date.Format("2006-01-02")).
+		Order("start_time DESC").Limit(1).
+		Find(&sessions).Error
+	if err != nil || len(sessions) == 0 {
+		return nil, err
+	}
+	return &sessions[0], nil
+}
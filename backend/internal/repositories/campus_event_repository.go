@@ -0,0 +1,332 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/delpresence/backend/internal/database"
+	"github.com/delpresence/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CampusEventFilter narrows a campus event listing
+type CampusEventFilter struct {
+	OrganizerID uint // Zero lists the events of every organizer
+	From        *time.Time
+	To          *time.Time
+	Status      models.CampusEventStatus
+}
+
+// CampusEventRepository handles database operations for campus events and their attendance
+type CampusEventRepository struct {
+	db *gorm.DB
+}
+
+// NewCampusEventRepository creates a new campus event repository
+func NewCampusEventRepository() *CampusEventRepository {
+	return &CampusEventRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Create creates a campus event
+func (r *CampusEventRepository) Create(event *models.CampusEvent) error {
+	return r.db.Omit(clause.Associations).Create(event).Error
+}
+
+// Update saves a campus event without its associations
+func (r *CampusEventRepository) Update(event *models.CampusEvent) error {
+	return r.db.Omit(clause.Associations).Save(event).Error
+}
+
+// GetByID retrieves a campus event with its room
+func (r *CampusEventRepository) GetByID(id uint) (*models.CampusEvent, error) {
+	var event models.CampusEvent
+	err := r.eventQuery().First(&event, id).Error
+	return &event, err
+}
+
+// List lists campus events matching the filter, latest first
+func (r *CampusEventRepository) List(filter CampusEventFilter) ([]models.CampusEvent, error) {
+	var events []models.CampusEvent
+	query := r.eventQuery()
+	if filter.OrganizerID > 0 {
+		query = query.Where("organizer_id = ?", filter.OrganizerID)
+	}
+	if filter.From != nil {
+		query = query.Where("end_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("start_at <= ?", *filter.To)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	err := query.Order("start_at DESC").Find(&events).Error
+	return events, err
+}
+
+// ListForStudent lists the events whose audience includes a student, optionally only from a time on
+func (r *CampusEventRepository) ListForStudent(student *models.Student, from *time.Time) ([]models.CampusEvent, error) {
+	var events []models.CampusEvent
+	query := r.eventQuery().Where(r.audienceCondition(student)).
+		Where("status <> ?", models.CampusEventCanceled)
+	if from != nil {
+		query = query.Where("end_at >= ?", *from)
+	}
+	err := query.Order("start_at").Find(&events).Error
+	return events, err
+}
+
+// InAudience reports whether a student belongs to an event's audience
+func (r *CampusEventRepository) InAudience(eventID uint, student *models.Student) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.CampusEvent{}).
+		Where("id = ?", eventID).
+		Where(r.audienceCondition(student)).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ReplaceParticipants replaces the invited students of an event with an explicit list audience
+func (r *CampusEventRepository) ReplaceParticipants(eventID uint, studentIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("campus_event_id = ?", eventID).Delete(&models.CampusEventParticipant{}).Error; err != nil {
+			return err
+		}
+		if len(studentIDs) == 0 {
+			return nil
+		}
+		participants := make([]models.CampusEventParticipant, len(studentIDs))
+		for i, studentID := range studentIDs {
+			participants[i] = models.CampusEventParticipant{CampusEventID: eventID, StudentID: studentID}
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&participants).Error
+	})
+}
+
+// ListAudienceStudentIDs lists the IDs of the students in an event's audience
+func (r *CampusEventRepository) ListAudienceStudentIDs(event *models.CampusEvent) ([]uint, error) {
+	var ids []uint
+	query := r.db.Model(&models.Student{})
+	switch event.AudienceType {
+	case models.CampusEventAudienceAll:
+	case models.CampusEventAudienceStudyProgram:
+		query = query.Where("study_program_id = ?", event.AudienceStudyProgramID)
+	case models.CampusEventAudienceIntakeYear:
+		query = query.Where("year_enrolled = ?", event.AudienceIntakeYear)
+	case models.CampusEventAudienceGroup:
+		query = query.Where("id IN (?)", r.db.Table("student_to_groups").Select("student_id").
+			Where("student_group_id = ?", event.AudienceStudentGroupID))
+	case models.CampusEventAudienceList:
+		query = query.Where("id IN (?)", r.db.Model(&models.CampusEventParticipant{}).Select("student_id").
+			Where("campus_event_id = ?", event.ID))
+	case models.CampusEventAudienceDormitory:
+		query = query.Where("dormitory = ?", event.AudienceDormitory)
+	default:
+		return ids, nil
+	}
+	err := query.Pluck("id", &ids).Error
+	return ids, err
+}
+
+// InitializeAttendances creates ABSENT records for students that have none for an event yet
+func (r *CampusEventRepository) InitializeAttendances(eventID uint, studentIDs []uint) error {
+	if len(studentIDs) == 0 {
+		return nil
+	}
+	records := make([]models.CampusEventAttendance, len(studentIDs))
+	for i, studentID := range studentIDs {
+		records[i] = models.CampusEventAttendance{
+			CampusEventID: eventID,
+			StudentID:     studentID,
+			Status:        models.StudentAttendanceStatusAbsent,
+		}
+	}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&records, 500).Error
+}
+
+// ListAttendances lists the attendance records of an event ordered by NIM
+func (r *CampusEventRepository) ListAttendances(eventID uint) ([]models.CampusEventAttendance, error) {
+	var attendances []models.CampusEventAttendance
+	err := r.db.Preload("Student").
+		Joins("JOIN students ON students.id = campus_event_attendances.student_id").
+		Where("campus_event_attendances.campus_event_id = ?", eventID).
+		Order("students.nim").
+		Find(&attendances).Error
+	return attendances, err
+}
+
+// SaveAttendance creates or replaces a student's attendance record for an event, as organizers mark it
+func (r *CampusEventRepository) SaveAttendance(attendance *models.CampusEventAttendance) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "campus_event_id"}, {Name: "student_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"status", "check_in_time", "verification_method", "verified_by_id", "notes", "updated_at"}),
+	}).Omit(clause.Associations).Create(attendance).Error
+}
+
+// CheckInAttendance records a student's own check-in. An existing record is only updated while it
+// has no check-in and was not marked by the organizer; it reports false when nothing was recorded.
+func (r *CampusEventRepository) CheckInAttendance(attendance *models.CampusEventAttendance) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "campus_event_id"}, {Name: "student_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"status", "check_in_time", "verification_method", "updated_at"}),
+		Where: clause.Where{Exprs: []clause.Expression{clause.Expr{
+			SQL: "campus_event_attendances.check_in_time IS NULL AND campus_event_attendances.verified_by_id IS NULL",
+		}}},
+	}).Omit(clause.Associations).Create(attendance)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ListStudentAttendances lists a student's attendance records of events that were not canceled
+func (r *CampusEventRepository) ListStudentAttendances(studentID uint) ([]models.CampusEventAttendance, error) {
+	var attendances []models.CampusEventAttendance
+	err := r.db.Preload("CampusEvent").Preload("CampusEvent.Room").Preload("CampusEvent.Room.Building").
+		Joins("JOIN campus_events ON campus_events.id = campus_event_attendances.campus_event_id").
+		Where("campus_event_attendances.student_id = ? AND campus_events.status <> ? AND campus_events.deleted_at IS NULL",
+			studentID, models.CampusEventCanceled).
+		Order("campus_events.start_at DESC").
+		Find(&attendances).Error
+	return attendances, err
+}
+
+// GetCountsByEvent counts attendance records by status for each of the given events, in one query
+func (r *CampusEventRepository) GetCountsByEvent(eventIDs []uint) (map[uint]models.AttendanceStatusCounts, error) {
+	result := make(map[uint]models.AttendanceStatusCounts)
+	if len(eventIDs) == 0 {
+		return result, nil
+	}
+
+	var rows []struct {
+		EventID uint
+		models.AttendanceStatusCounts
+	}
+	err := r.db.Table("campus_event_attendances a").
+		Select(`a.campus_event_id AS event_id,
+			COUNT(*) FILTER (WHERE a.status = ?) AS present,
+			COUNT(*) FILTER (WHERE a.status = ?) AS late,
+			COUNT(*) FILTER (WHERE a.status = ?) AS absent,
+			COUNT(*) FILTER (WHERE a.status = ?) AS excused,
+			COUNT(*) AS total`,
+			models.StudentAttendanceStatusPresent, models.StudentAttendanceStatusLate,
+			models.StudentAttendanceStatusAbsent, models.StudentAttendanceStatusExcused).
+		Where("a.campus_event_id IN ?", eventIDs).
+		Group("a.campus_event_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		result[row.EventID] = row.AttendanceStatusCounts
+	}
+	return result, nil
+}
+
+// UpdateStatusIf saves the check-in state of an event only while it still has the expected status,
+// so that replicas opening or closing the same event do not both do it
+func (r *CampusEventRepository) UpdateStatusIf(event *models.CampusEvent, expected models.CampusEventStatus) (bool, error) {
+	result := r.db.Model(&models.CampusEvent{}).
+		Where("id = ? AND status = ?", event.ID, expected).
+		Updates(map[string]interface{}{
+			"status":       event.Status,
+			"qr_code_data": event.QRCodeData,
+			"opened_at":    event.OpenedAt,
+			"closed_at":    event.ClosedAt,
+			"updated_at":   time.Now(),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ListDueForOpening lists the self-opening events whose check-in window has started but not ended
+func (r *CampusEventRepository) ListDueForOpening(now time.Time) ([]models.CampusEvent, error) {
+	var events []models.CampusEvent
+	err := r.db.Where("auto_open_close = ? AND status = ? AND start_at <= ? AND end_at > ?",
+		true, models.CampusEventScheduled, now, now).
+		Find(&events).Error
+	return events, err
+}
+
+// ListDueForClosing lists the self-closing events whose check-in window has ended
+func (r *CampusEventRepository) ListDueForClosing(now time.Time) ([]models.CampusEvent, error) {
+	var events []models.CampusEvent
+	err := r.db.Where("auto_open_close = ? AND status = ? AND end_at <= ?", true, models.CampusEventActive, now).
+		Find(&events).Error
+	return events, err
+}
+
+// RollCallExists reports whether a dormitory already has a roll call starting at a time
+func (r *CampusEventRepository) RollCallExists(dormitory string, startAt time.Time) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.CampusEvent{}).
+		Where("category = ? AND audience_dormitory = ? AND start_at = ? AND status <> ?",
+			models.CampusEventRollCall, dormitory, startAt, models.CampusEventCanceled).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ListDormitories lists the dormitories of synced students with their number of students
+func (r *CampusEventRepository) ListDormitories() ([]models.DormitorySummary, error) {
+	var dormitories []models.DormitorySummary
+	err := r.db.Model(&models.Student{}).
+		Select("dormitory, COUNT(*) AS total_students").
+		Where("dormitory <> ''").
+		Group("dormitory").
+		Order("dormitory").
+		Scan(&dormitories).Error
+	return dormitories, err
+}
+
+// RollCallAttendanceRow is a student's attendance record of one roll call
+type RollCallAttendanceRow struct {
+	StudentID   uint
+	StudentName string
+	StudentNIM  string
+	Status      models.StudentAttendanceStatus
+	StartAt     time.Time
+}
+
+// ListRollCallAttendances lists the attendance records of the roll calls of a dormitory held in a
+// period, ordered by NIM and roll call
+func (r *CampusEventRepository) ListRollCallAttendances(dormitory string, from, to time.Time) ([]RollCallAttendanceRow, error) {
+	var rows []RollCallAttendanceRow
+	err := r.db.Table("campus_event_attendances a").
+		Select("a.student_id, s.full_name AS student_name, s.nim AS student_nim, a.status, e.start_at").
+		Joins("JOIN campus_events e ON e.id = a.campus_event_id").
+		Joins("JOIN students s ON s.id = a.student_id").
+		Where(r.rollCallCondition(dormitory, from, to)).
+		Order("s.nim, e.start_at").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// CountRollCalls counts the roll calls of a dormitory held in a period
+func (r *CampusEventRepository) CountRollCalls(dormitory string, from, to time.Time) (int64, error) {
+	var count int64
+	err := r.db.Table("campus_events e").Where(r.rollCallCondition(dormitory, from, to)).Count(&count).Error
+	return count, err
+}
+
+// rollCallCondition matches the roll calls of a dormitory whose check-in was opened in a period
+func (r *CampusEventRepository) rollCallCondition(dormitory string, from, to time.Time) *gorm.DB {
+	return r.db.Where("e.category = ? AND e.audience_dormitory = ? AND e.status IN ? AND e.start_at >= ? AND e.start_at < ? AND e.deleted_at IS NULL",
+		models.CampusEventRollCall, dormitory,
+		[]models.CampusEventStatus{models.CampusEventActive, models.CampusEventClosed}, from, to)
+}
+
+// audienceCondition matches the events whose audience includes a student
+func (r *CampusEventRepository) audienceCondition(student *models.Student) *gorm.DB {
+	groups := r.db.Table("student_to_groups").Select("student_group_id").Where("student_id = ?", student.ID)
+	invited := r.db.Model(&models.CampusEventParticipant{}).Select("campus_event_id").Where("student_id = ?", student.ID)
+	return r.db.Where("audience_type = ?", models.CampusEventAudienceAll).
+		Or("audience_type = ? AND audience_study_program_id = ?", models.CampusEventAudienceStudyProgram, student.StudyProgramID).
+		Or("audience_type = ? AND audience_intake_year = ?", models.CampusEventAudienceIntakeYear, student.YearEnrolled).
+		Or("audience_type = ? AND audience_student_group_id IN (?)", models.CampusEventAudienceGroup, groups).
+		Or("audience_type = ? AND id IN (?)", models.CampusEventAudienceList, invited).
+		Or("audience_type = ? AND audience_dormitory = ? AND audience_dormitory <> ''", models.CampusEventAudienceDormitory, student.Dormitory)
+}
+
+// eventQuery preloads what event responses show
+func (r *CampusEventRepository) eventQuery() *gorm.DB {
+	return r.db.Preload("Room").Preload("Room.Building")
+}
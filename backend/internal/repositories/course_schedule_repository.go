@@ -124,7 +124,8 @@ func (r *CourseScheduleRepository) GetByAcademicYear(academicYearID uint) ([]mod
 	return schedules, err
 }
 
-// GetByLecturer returns course schedules by lecturer ID
+// GetByLecturer returns course schedules by lecturer ID, including those the lecturer teaches as a
+// member of the course's teaching team
 func (r *CourseScheduleRepository) GetByLecturer(userID uint) ([]models.CourseSchedule, error) {
 	var schedules []models.CourseSchedule
 
@@ -136,7 +137,7 @@ func (r *CourseScheduleRepository) GetByLecturer(userID uint) ([]models.CourseSc
 		Preload("Lecturer").
 		Preload("StudentGroup").
 		Preload("AcademicYear").
-		Where("lecturer_id = ?", userID).
+		Where("lecturer_id = ? OR "+teachingTeamCondition("course_schedules"), userID, userID).
 		Find(&schedules).Error
 
 	if err != nil || len(schedules) == 0 {
@@ -181,7 +182,8 @@ func (r *CourseScheduleRepository) GetByLecturer(userID uint) ([]models.CourseSc
 	return schedules, err
 }
 
-// GetByLecturerAndAcademicYear returns course schedules by lecturer ID and academic year ID
+// GetByLecturerAndAcademicYear returns course schedules by lecturer ID and academic year ID, including
+// those the lecturer teaches as a member of the course's teaching team
 func (r *CourseScheduleRepository) GetByLecturerAndAcademicYear(userID uint, academicYearID uint) ([]models.CourseSchedule, error) {
 	var schedules []models.CourseSchedule
 
@@ -193,7 +195,8 @@ func (r *CourseScheduleRepository) GetByLecturerAndAcademicYear(userID uint, aca
 		Preload("Lecturer").
 		Preload("StudentGroup").
 		Preload("AcademicYear").
-		Where("lecturer_id = ? AND academic_year_id = ?", userID, academicYearID).
+		Where("lecturer_id = ? OR "+teachingTeamCondition("course_schedules"), userID, userID).
+		Where("academic_year_id = ?", academicYearID).
 		Find(&schedules).Error
 
 	if err != nil || len(schedules) == 0 {
@@ -453,6 +456,39 @@ func (r *CourseScheduleRepository) GetByStudentGroupAndAcademicYear(studentGroup
 	return schedules, err
 }
 
+// ListIDsByLecturer returns the IDs of the schedules taught by a lecturer, as their lecturer or as a
+// member of the course's teaching team
+func (r *CourseScheduleRepository) ListIDsByLecturer(userID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&models.CourseSchedule{}).
+		Where("lecturer_id = ? OR "+teachingTeamCondition("course_schedules"), userID, userID).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// IsTaughtBy reports whether a lecturer teaches a schedule, as its lecturer or as a member of the
+// course's teaching team
+func (r *CourseScheduleRepository) IsTaughtBy(schedule models.CourseSchedule, userID uint) (bool, error) {
+	if schedule.UserID == userID {
+		return true, nil
+	}
+	var count int64
+	err := r.db.Model(&models.CourseSchedule{}).
+		Where("id = ?", schedule.ID).
+		Where(teachingTeamCondition("course_schedules"), userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ListIDsByAssistant returns the IDs of the schedules of courses a teaching assistant is assigned to
+func (r *CourseScheduleRepository) ListIDsByAssistant(userID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&models.CourseSchedule{}).
+		Where("course_id IN (?)", r.db.Table("teaching_assistant_assignments").Select("course_id").Where("user_id = ?", userID)).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
 // DB returns the database instance
 func (r *CourseScheduleRepository) DB() *gorm.DB {
 	return r.db
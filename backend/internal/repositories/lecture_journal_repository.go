@@ -0,0 +1,152 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/delpresence/backend/internal/database"
+	"github.com/delpresence/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LectureJournalRepository handles database operations for lesson plans and lecture journals
+type LectureJournalRepository struct {
+	db *gorm.DB
+}
+
+// NewLectureJournalRepository creates a new lecture journal repository
+func NewLectureJournalRepository() *LectureJournalRepository {
+	return &LectureJournalRepository{
+		db: database.GetDB(),
+	}
+}
+
+// ListLessonPlan lists the planned topics of a course in meeting order
+func (r *LectureJournalRepository) ListLessonPlan(courseID uint) ([]models.LessonPlanTopic, error) {
+	var topics []models.LessonPlanTopic
+	err := r.db.Where("course_id = ?", courseID).Order("meeting_number").Find(&topics).Error
+	return topics, err
+}
+
+// GetLessonPlanTopic retrieves a planned topic by ID
+func (r *LectureJournalRepository) GetLessonPlanTopic(id uint) (*models.LessonPlanTopic, error) {
+	var topic models.LessonPlanTopic
+	err := r.db.First(&topic, id).Error
+	return &topic, err
+}
+
+// FindLessonPlanTopic finds the planned topic of a course's meeting
+func (r *LectureJournalRepository) FindLessonPlanTopic(courseID uint, meetingNumber int) (*models.LessonPlanTopic, error) {
+	var topic models.LessonPlanTopic
+	err := r.db.Where("course_id = ? AND meeting_number = ?", courseID, meetingNumber).First(&topic).Error
+	return &topic, err
+}
+
+// ReplaceLessonPlan replaces the lesson plan of a course. Topics are matched by meeting number, so
+// journals keep their link to meetings that remain; journals of removed meetings are unlinked.
+func (r *LectureJournalRepository) ReplaceLessonPlan(courseID uint, topics []models.LessonPlanTopic) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		meetings := make([]int, 0, len(topics))
+		for i := range topics {
+			topics[i].CourseID = courseID
+			meetings = append(meetings, topics[i].MeetingNumber)
+		}
+
+		removed := tx.Model(&models.LessonPlanTopic{}).Select("id").Where("course_id = ?", courseID)
+		if len(meetings) > 0 {
+			removed = removed.Where("meeting_number NOT IN ?", meetings)
+		}
+		if err := tx.Model(&models.LectureJournal{}).Where("lesson_plan_topic_id IN (?)", removed).
+			Update("lesson_plan_topic_id", nil).Error; err != nil {
+			return err
+		}
+
+		deleted := tx.Where("course_id = ?", courseID)
+		if len(meetings) > 0 {
+			deleted = deleted.Where("meeting_number NOT IN ?", meetings)
+		}
+		if err := deleted.Delete(&models.LessonPlanTopic{}).Error; err != nil {
+			return err
+		}
+
+		if len(topics) == 0 {
+			return nil
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "course_id"}, {Name: "meeting_number"}},
+			DoUpdates: clause.AssignmentColumns([]string{"topic", "sub_topics", "teaching_method", "updated_at"}),
+		}).Create(&topics).Error
+	})
+}
+
+// CreateJournal creates a lecture journal
+func (r *LectureJournalRepository) CreateJournal(journal *models.LectureJournal) error {
+	return r.db.Omit(clause.Associations).Create(journal).Error
+}
+
+// UpdateJournal saves a lecture journal without its associations
+func (r *LectureJournalRepository) UpdateJournal(journal *models.LectureJournal) error {
+	return r.db.Omit(clause.Associations).Save(journal).Error
+}
+
+// GetJournalByID retrieves a lecture journal with its schedule, planned topic and attachments
+func (r *LectureJournalRepository) GetJournalByID(id uint) (*models.LectureJournal, error) {
+	var journal models.LectureJournal
+	err := r.journalQuery().First(&journal, id).Error
+	return &journal, err
+}
+
+// FindJournalForMeeting finds the journal of a session or of a schedule's meeting date
+func (r *LectureJournalRepository) FindJournalForMeeting(sessionID *uint, courseScheduleID uint, meetingDate time.Time) (*models.LectureJournal, error) {
+	var journal models.LectureJournal
+	query := r.db.Where("course_schedule_id = ? AND meeting_date = ?", courseScheduleID, meetingDate.Format("2006-01-02"))
+	if sessionID != nil {
+		query = query.Or("attendance_session_id = ?", *sessionID)
+	}
+	err := query.First(&journal).Error
+	return &journal, err
+}
+
+// ListJournalsBySchedules lists the journals of schedules in meeting order
+func (r *LectureJournalRepository) ListJournalsBySchedules(scheduleIDs []uint) ([]models.LectureJournal, error) {
+	var journals []models.LectureJournal
+	if len(scheduleIDs) == 0 {
+		return journals, nil
+	}
+	err := r.journalQuery().Where("course_schedule_id IN ?", scheduleIDs).
+		Order("meeting_date, id").Find(&journals).Error
+	return journals, err
+}
+
+// ListPendingCosigns lists signed journals waiting for a class representative's co-signature
+func (r *LectureJournalRepository) ListPendingCosigns(studentID uint) ([]models.LectureJournal, error) {
+	var journals []models.LectureJournal
+	err := r.journalQuery().
+		Where("class_representative_id = ? AND status = ? AND cosigned_at IS NULL", studentID, models.LectureJournalSigned).
+		Order("meeting_date").Find(&journals).Error
+	return journals, err
+}
+
+// CreateAttachment creates a journal attachment
+func (r *LectureJournalRepository) CreateAttachment(attachment *models.LectureJournalAttachment) error {
+	return r.db.Create(attachment).Error
+}
+
+// GetAttachment retrieves an attachment of a journal
+func (r *LectureJournalRepository) GetAttachment(journalID, id uint) (*models.LectureJournalAttachment, error) {
+	var attachment models.LectureJournalAttachment
+	err := r.db.Where("lecture_journal_id = ?", journalID).First(&attachment, id).Error
+	return &attachment, err
+}
+
+// DeleteAttachment deletes a journal attachment
+func (r *LectureJournalRepository) DeleteAttachment(id uint) error {
+	return r.db.Delete(&models.LectureJournalAttachment{}, id).Error
+}
+
+// journalQuery preloads what journal responses show
+func (r *LectureJournalRepository) journalQuery() *gorm.DB {
+	return r.db.Preload("CourseSchedule").Preload("CourseSchedule.Course").Preload("CourseSchedule.StudentGroup").
+		Preload("LessonPlanTopic").Preload("ClassRepresentative").
+		Preload("Attachments", func(db *gorm.DB) *gorm.DB { return db.Order("id") })
+}
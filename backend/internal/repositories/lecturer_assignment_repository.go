@@ -126,7 +126,8 @@ func (r *LecturerAssignmentRepository) GetByCourseID(courseID, academicYearID ui
 	query := r.db.
 		Preload("Course").
 		Preload("AcademicYear").
-		Where("course_id = ?", courseID)
+		Where("course_id = ?", courseID).
+		Order(fmt.Sprintf("CASE WHEN role = '%s' THEN 0 ELSE 1 END, id", models.LecturerAssignmentCoordinator))
 	
 	// Only filter by academic year if it's specified
 	if academicYearID > 0 {
@@ -199,7 +200,11 @@ func (r *LecturerAssignmentRepository) Update(assignment *models.LecturerAssignm
 		Updates(map[string]interface{}{
 			"user_id":          assignment.UserID,
 			"course_id":        assignment.CourseID,
-			"academic_year_id": assignment.AcademicYearID,
+			"academic_year_id":   assignment.AcademicYearID,
+			"role":               assignment.Role,
+			"course_schedule_id": assignment.CourseScheduleID,
+			"meeting_from":       assignment.MeetingFrom,
+			"meeting_to":         assignment.MeetingTo,
 		})
 	
 	if result.Error != nil {
@@ -240,6 +245,38 @@ func (r *LecturerAssignmentRepository) AssignmentExistsForCourse(userID int, cou
 	return count > 0, err
 }
 
+// CoordinatorExists checks if a course already has a coordinator in an academic year, ignoring one
+// assignment (zero ignores none)
+func (r *LecturerAssignmentRepository) CoordinatorExists(courseID, academicYearID, exceptID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.LecturerAssignment{}).
+		Where("course_id = ? AND academic_year_id = ? AND role = ? AND id <> ?",
+			courseID, academicYearID, models.LecturerAssignmentCoordinator, exceptID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// FindForSchedule finds the assignment through which a lecturer teaches a schedule, preferring one
+// made for that class over one for the whole course
+func (r *LecturerAssignmentRepository) FindForSchedule(userID uint, schedule models.CourseSchedule) (*models.LecturerAssignment, error) {
+	var assignment models.LecturerAssignment
+	err := r.db.
+		Where("user_id = ? AND course_id = ? AND academic_year_id = ?", userID, schedule.CourseID, schedule.AcademicYearID).
+		Where("course_schedule_id IS NULL OR course_schedule_id = ?", schedule.ID).
+		Order("course_schedule_id IS NULL, id").
+		First(&assignment).Error
+	return &assignment, err
+}
+
+// teachingTeamCondition matches the schedules, under a table alias, that a lecturer (the single
+// placeholder) is assigned to teach: their course in the same academic year, for all classes or
+// for that one class
+func teachingTeamCondition(alias string) string {
+	return fmt.Sprintf(`EXISTS (SELECT 1 FROM lecturer_assignments la WHERE la.user_id = ? AND la.deleted_at IS NULL
+		AND la.course_id = %[1]s.course_id AND la.academic_year_id = %[1]s.academic_year_id
+		AND (la.course_schedule_id IS NULL OR la.course_schedule_id = %[1]s.id))`, alias)
+}
+
 // GetAvailableLecturers returns all lecturers not already assigned to the given course in the given academic year
 func (r *LecturerAssignmentRepository) GetAvailableLecturers(courseID, academicYearID uint) ([]models.Lecturer, error) {
 	var lecturers []models.Lecturer
@@ -303,6 +340,11 @@ func (r *LecturerAssignmentRepository) GetLecturerAssignmentResponses(academicYe
 			AcademicYearID: assignment.AcademicYearID,
 			CreatedAt:      assignment.CreatedAt,
 			UpdatedAt:      assignment.UpdatedAt,
+
+			Role:             assignment.Role,
+			CourseScheduleID: assignment.CourseScheduleID,
+			MeetingFrom:      assignment.MeetingFrom,
+			MeetingTo:        assignment.MeetingTo,
 		}
 		
 		// Get course information
@@ -373,6 +415,11 @@ func (r *LecturerAssignmentRepository) GetLecturerAssignmentResponseByID(id uint
 		AcademicYearID: assignment.AcademicYearID,
 		CreatedAt:      assignment.CreatedAt,
 		UpdatedAt:      assignment.UpdatedAt,
+
+		Role:             assignment.Role,
+		CourseScheduleID: assignment.CourseScheduleID,
+		MeetingFrom:      assignment.MeetingFrom,
+		MeetingTo:        assignment.MeetingTo,
 	}
 	
 	// Get course information
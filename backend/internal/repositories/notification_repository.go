@@ -0,0 +1,313 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/delpresence/backend/internal/database"
+	"github.com/delpresence/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StudentCourseAttendance holds a student's attendance counts for one course schedule
+type StudentCourseAttendance struct {
+	StudentID        uint   `json:"student_id"`
+	UserID           int    `json:"user_id"`
+	NIM              string `json:"nim"`
+	FullName         string `json:"full_name"`
+	Email            string `json:"email"`
+	CourseScheduleID uint   `json:"course_schedule_id"`
+	CourseCode       string `json:"course_code"`
+	CourseName       string `json:"course_name"`
+	Present          int    `json:"present"`
+	Late             int    `json:"late"`
+	Absent           int    `json:"absent"`
+	Excused          int    `json:"excused"`
+}
+
+// Held returns the number of closed sessions the student was expected to attend
+func (a StudentCourseAttendance) Held() int {
+	return a.Present + a.Late + a.Absent + a.Excused
+}
+
+// LecturerScheduleItem describes a schedule or session that needs a lecturer's attention
+type LecturerScheduleItem struct {
+	LecturerUserID   uint      `json:"lecturer_user_id"`
+	CourseScheduleID uint      `json:"course_schedule_id"`
+	SessionID        uint      `json:"session_id,omitempty"`
+	Date             time.Time `json:"date"`
+	StartTime        string    `json:"start_time"`
+	EndTime          string    `json:"end_time"`
+	CourseCode       string    `json:"course_code"`
+	CourseName       string    `json:"course_name"`
+	StudentGroupName string    `json:"student_group_name"`
+}
+
+// NotificationRecipient is the contact information of a campus user
+type NotificationRecipient struct {
+	UserID   int    `json:"user_id"`
+	FullName string `json:"full_name"`
+	Email    string `json:"email"`
+}
+
+// NotificationRepository handles database operations for notifications
+type NotificationRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationRepository creates a new notification repository
+func NewNotificationRepository() *NotificationRepository {
+	return &NotificationRepository{
+		db: database.GetDB(),
+	}
+}
+
+// GetPreference retrieves a user's notification preference
+func (r *NotificationRepository) GetPreference(userID int) (*models.NotificationPreference, error) {
+	var preference models.NotificationPreference
+	err := r.db.Where("user_id = ?", userID).First(&preference).Error
+	return &preference, err
+}
+
+// SavePreference creates or updates a user's notification preference
+func (r *NotificationRepository) SavePreference(preference *models.NotificationPreference) error {
+	return r.db.Save(preference).Error
+}
+
+// GetPreferencesByUserIDs retrieves preferences for several users keyed by user ID
+func (r *NotificationRepository) GetPreferencesByUserIDs(userIDs []int) (map[int]models.NotificationPreference, error) {
+	result := make(map[int]models.NotificationPreference)
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	var preferences []models.NotificationPreference
+	if err := r.db.Where("user_id IN ?", userIDs).Find(&preferences).Error; err != nil {
+		return nil, err
+	}
+
+	for _, preference := range preferences {
+		result[preference.UserID] = preference
+	}
+	return result, nil
+}
+
+// ReserveLog inserts a pending log entry. It returns false when a notification
+// with the same user, kind and dedupe key was already reserved.
+func (r *NotificationRepository) ReserveLog(entry *models.NotificationLog) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(entry)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// UpdateLog updates a notification log entry
+func (r *NotificationRepository) UpdateLog(entry *models.NotificationLog) error {
+	return r.db.Save(entry).Error
+}
+
+// ListLogs lists the most recent notification logs, optionally filtered by kind
+func (r *NotificationRepository) ListLogs(kind string, limit int) ([]models.NotificationLog, error) {
+	var logs []models.NotificationLog
+	query := r.db.Order("id DESC").Limit(limit)
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	err := query.Find(&logs).Error
+	return logs, err
+}
+
+// GetStudentCourseAttendance sums each student's attendance per course schedule over
+// closed sessions whose date falls within the range
+func (r *NotificationRepository) GetStudentCourseAttendance(from, to time.Time) ([]StudentCourseAttendance, error) {
+	var rows []StudentCourseAttendance
+	err := r.db.Table("student_attendances sa").
+		Select(`st.id AS student_id, st.user_id, st.nim, st.full_name, st.email,
+			cs.id AS course_schedule_id, c.code AS course_code, c.name AS course_name,
+			COUNT(*) FILTER (WHERE sa.status = ?) AS present,
+			COUNT(*) FILTER (WHERE sa.status = ?) AS late,
+			COUNT(*) FILTER (WHERE sa.status = ?) AS absent,
+			COUNT(*) FILTER (WHERE sa.status = ?) AS excused`,
+			models.StudentAttendanceStatusPresent, models.StudentAttendanceStatusLate,
+			models.StudentAttendanceStatusAbsent, models.StudentAttendanceStatusExcused).
+		Joins("JOIN attendance_sessions s ON s.id = sa.attendance_session_id AND s.deleted_at IS NULL").
+		Joins("JOIN course_schedules cs ON cs.id = s.course_schedule_id").
+		Joins("JOIN courses c ON c.id = cs.course_id").
+		Joins("JOIN students st ON st.id = sa.student_id").
+		Where("sa.deleted_at IS NULL AND s.status = ?", models.AttendanceStatusClosed).
+		Where("DATE(s.date) BETWEEN ? AND ?", from.Format("2006-01-02"), to.Format("2006-01-02")).
+		Group("st.id, st.user_id, st.nim, st.full_name, st.email, cs.id, c.code, c.name").
+		Order("st.id, c.code").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// GetUnopenedSchedules lists schedules that meet on the given day of an active
+// academic year but have no attendance session on that date
+func (r *NotificationRepository) GetUnopenedSchedules(date time.Time, dayName string) ([]LecturerScheduleItem, error) {
+	var rows []LecturerScheduleItem
+	dateStr := date.Format("2006-01-02")
+	err := r.db.Table("course_schedules cs").
+		Select(`cs.lecturer_id AS lecturer_user_id, cs.id AS course_schedule_id,
+			cs.start_time, cs.end_time, c.code AS course_code, c.name AS course_name,
+			COALESCE(sg.name, '') AS student_group_name`).
+		Joins("JOIN academic_years ay ON ay.id = cs.academic_year_id AND ay.deleted_at IS NULL").
+		Joins("JOIN courses c ON c.id = cs.course_id").
+		Joins("LEFT JOIN student_groups sg ON sg.id = cs.student_group_id").
+		Where("cs.deleted_at IS NULL AND LOWER(cs.day) = LOWER(?)", dayName).
+		Where("DATE(ay.start_date) <= ? AND DATE(ay.end_date) >= ?", dateStr, dateStr).
+		Where(`NOT EXISTS (SELECT 1 FROM attendance_sessions s
+			WHERE s.course_schedule_id = cs.id AND s.deleted_at IS NULL AND DATE(s.date) = ?)`, dateStr).
+		Order("cs.lecturer_id, cs.start_time").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rows {
+		rows[i].Date = date
+	}
+	return rows, nil
+}
+
+// GetUnclosedSessions lists sessions that are still active although their date is before the given day
+func (r *NotificationRepository) GetUnclosedSessions(before time.Time) ([]LecturerScheduleItem, error) {
+	var rows []LecturerScheduleItem
+	err := r.db.Table("attendance_sessions s").
+		Select(`s.lecturer_id AS lecturer_user_id, cs.id AS course_schedule_id, s.id AS session_id, s.date,
+			cs.start_time, cs.end_time, c.code AS course_code, c.name AS course_name,
+			COALESCE(sg.name, '') AS student_group_name`).
+		Joins("JOIN course_schedules cs ON cs.id = s.course_schedule_id").
+		Joins("JOIN courses c ON c.id = cs.course_id").
+		Joins("LEFT JOIN student_groups sg ON sg.id = cs.student_group_id").
+		Where("s.deleted_at IS NULL AND s.status = ?", models.AttendanceStatusActive).
+		Where("DATE(s.date) < ?", before.Format("2006-01-02")).
+		Order("s.lecturer_id, s.date").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// GetStaffRecipients returns contact information for lecturers and employees
+// (teaching assistants) keyed by their external user ID
+func (r *NotificationRepository) GetStaffRecipients(userIDs []int) (map[int]NotificationRecipient, error) {
+	result := make(map[int]NotificationRecipient)
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	var employees []NotificationRecipient
+	if err := r.db.Model(&models.Employee{}).
+		Select("user_id, full_name, email").
+		Where("user_id IN ?", userIDs).
+		Scan(&employees).Error; err != nil {
+		return nil, err
+	}
+
+	var lecturers []NotificationRecipient
+	if err := r.db.Model(&models.Lecturer{}).
+		Select("user_id, full_name, email").
+		Where("user_id IN ?", userIDs).
+		Scan(&lecturers).Error; err != nil {
+		return nil, err
+	}
+
+	// Lecturer records take precedence over employee records for the same user
+	for _, recipient := range append(employees, lecturers...) {
+		if recipient.Email != "" {
+			result[recipient.UserID] = recipient
+		}
+	}
+	return result, nil
+}
+
+// ClosingSession is an active auto-closing session with its computed close time
+type ClosingSession struct {
+	SessionID      uint      `json:"session_id"`
+	StudentGroupID uint      `json:"student_group_id"`
+	CourseCode     string    `json:"course_code"`
+	CourseName     string    `json:"course_name"`
+	ClosesAt       time.Time `json:"closes_at"`
+}
+
+// SaveDeviceToken registers a device token for a user. A token that was registered
+// by another user (e.g. after logging out and in on the same phone) is moved to this user.
+func (r *NotificationRepository) SaveDeviceToken(token *models.DeviceToken) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "token"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "role", "platform", "last_seen_at", "updated_at"}),
+	}).Create(token).Error
+}
+
+// DeleteDeviceToken removes a user's device token
+func (r *NotificationRepository) DeleteDeviceToken(userID int, token string) (bool, error) {
+	result := r.db.Where("user_id = ? AND token = ?", userID, token).Delete(&models.DeviceToken{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteDeviceTokens removes tokens the push provider reported as invalid
+func (r *NotificationRepository) DeleteDeviceTokens(tokens []string) error {
+	if len(tokens) == 0 {
+		return nil
+	}
+	return r.db.Where("token IN ?", tokens).Delete(&models.DeviceToken{}).Error
+}
+
+// ListDeviceTokens lists a user's registered devices
+func (r *NotificationRepository) ListDeviceTokens(userID int) ([]models.DeviceToken, error) {
+	var tokens []models.DeviceToken
+	err := r.db.Where("user_id = ?", userID).Order("last_seen_at DESC").Find(&tokens).Error
+	return tokens, err
+}
+
+// GetDeviceTokensByUserIDs returns the device tokens of several users keyed by user ID
+func (r *NotificationRepository) GetDeviceTokensByUserIDs(userIDs []int) (map[int][]string, error) {
+	result := make(map[int][]string)
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	var tokens []models.DeviceToken
+	if err := r.db.Where("user_id IN ?", userIDs).Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+
+	for _, token := range tokens {
+		result[token.UserID] = append(result[token.UserID], token.Token)
+	}
+	return result, nil
+}
+
+// GetStudentUserIDsInGroup returns the external user IDs of the students in a group
+func (r *NotificationRepository) GetStudentUserIDsInGroup(groupID uint) ([]int, error) {
+	var userIDs []int
+	err := r.db.Table("student_to_groups stg").
+		Joins("JOIN students st ON st.id = stg.student_id AND st.deleted_at IS NULL").
+		Where("stg.student_group_id = ?", groupID).
+		Pluck("st.user_id", &userIDs).Error
+	return userIDs, err
+}
+
+// GetAbsentStudentUserIDs returns the external user IDs of students still marked absent in a session
+func (r *NotificationRepository) GetAbsentStudentUserIDs(sessionID uint) ([]int, error) {
+	var userIDs []int
+	err := r.db.Table("student_attendances sa").
+		Joins("JOIN students st ON st.id = sa.student_id").
+		Where("sa.attendance_session_id = ? AND sa.status = ? AND sa.deleted_at IS NULL", sessionID, models.StudentAttendanceStatusAbsent).
+		Pluck("st.user_id", &userIDs).Error
+	return userIDs, err
+}
+
+// GetSessionsClosingBetween lists active auto-closing sessions whose duration ends within the range
+func (r *NotificationRepository) GetSessionsClosingBetween(from, to time.Time) ([]ClosingSession, error) {
+	var rows []ClosingSession
+	err := r.db.Table("attendance_sessions s").
+		Select(`s.id AS session_id, cs.student_group_id, c.code AS course_code, c.name AS course_name,
+			s.start_time + s.duration * INTERVAL '1 minute' AS closes_at`).
+		Joins("JOIN course_schedules cs ON cs.id = s.course_schedule_id").
+		Joins("JOIN courses c ON c.id = cs.course_id").
+		Where("s.deleted_at IS NULL AND s.status = ? AND s.auto_close = ?", models.AttendanceStatusActive, true).
+		Where("s.start_time + s.duration * INTERVAL '1 minute' BETWEEN ? AND ?", from, to).
+		Scan(&rows).Error
+	return rows, err
+}
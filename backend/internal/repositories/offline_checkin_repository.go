@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"github.com/delpresence/backend/internal/database"
+	"github.com/delpresence/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// OfflineCheckInRepository handles database operations for offline check-ins
+type OfflineCheckInRepository struct {
+	db *gorm.DB
+}
+
+// NewOfflineCheckInRepository creates a new offline check-in repository
+func NewOfflineCheckInRepository() *OfflineCheckInRepository {
+	return &OfflineCheckInRepository{
+		db: database.GetDB(),
+	}
+}
+
+// GetByClientID retrieves a user's offline check-in by the app-generated ID
+func (r *OfflineCheckInRepository) GetByClientID(userID int, clientID string) (*models.OfflineCheckIn, error) {
+	var checkIn models.OfflineCheckIn
+	err := r.db.Where("user_id = ? AND client_id = ?", userID, clientID).First(&checkIn).Error
+	return &checkIn, err
+}
+
+// GetByID retrieves an offline check-in by ID
+func (r *OfflineCheckInRepository) GetByID(id uint) (*models.OfflineCheckIn, error) {
+	var checkIn models.OfflineCheckIn
+	err := r.db.Preload("Student").First(&checkIn, id).Error
+	return &checkIn, err
+}
+
+// ListBySession lists the offline check-ins of a session, optionally filtered by outcome
+func (r *OfflineCheckInRepository) ListBySession(sessionID uint, outcome string) ([]models.OfflineCheckIn, error) {
+	var checkIns []models.OfflineCheckIn
+	query := r.db.Preload("Student").Where("attendance_session_id = ?", sessionID)
+	if outcome != "" {
+		query = query.Where("outcome = ?", outcome)
+	}
+	err := query.Order("scanned_at").Find(&checkIns).Error
+	return checkIns, err
+}
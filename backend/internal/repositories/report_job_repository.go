@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/delpresence/backend/internal/database"
+	"github.com/delpresence/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReportJobRepository handles database operations for background report jobs
+type ReportJobRepository struct {
+	db *gorm.DB
+}
+
+// NewReportJobRepository creates a new report job repository
+func NewReportJobRepository() *ReportJobRepository {
+	return &ReportJobRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Create creates a new report job
+func (r *ReportJobRepository) Create(job *models.ReportJob) error {
+	return r.db.Create(job).Error
+}
+
+// Update saves all fields of a report job
+func (r *ReportJobRepository) Update(job *models.ReportJob) error {
+	return r.db.Save(job).Error
+}
+
+// GetByID finds a report job by ID
+func (r *ReportJobRepository) GetByID(id uint) (*models.ReportJob, error) {
+	var job models.ReportJob
+	if err := r.db.First(&job, id).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ListByRequester lists the most recent jobs requested by a user in a role
+func (r *ReportJobRepository) ListByRequester(role string, userID uint, limit int) ([]models.ReportJob, error) {
+	var jobs []models.ReportJob
+	err := r.db.Where("requested_by_id = ? AND requested_by_role = ?", userID, role).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&jobs).Error
+	return jobs, err
+}
+
+// ClaimDueJobs locks pending jobs, and running jobs whose lease ran out because their worker
+// stopped, marks them running and pushes their availability forward by the lease, so other
+// replicas skip them while they are being rendered
+func (r *ReportJobRepository) ClaimDueJobs(limit int, lease time.Duration) ([]models.ReportJob, error) {
+	var jobs []models.ReportJob
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status IN ? AND available_at <= ?",
+				[]models.ReportJobStatus{models.ReportJobStatusPending, models.ReportJobStatusRunning}, now).
+			Order("available_at").
+			Limit(limit).
+			Find(&jobs).Error; err != nil {
+			return err
+		}
+
+		if len(jobs) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(jobs))
+		for i := range jobs {
+			ids[i] = jobs[i].ID
+			jobs[i].Status = models.ReportJobStatusRunning
+			jobs[i].AvailableAt = now.Add(lease)
+		}
+
+		return tx.Model(&models.ReportJob{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{
+				"status":       models.ReportJobStatusRunning,
+				"available_at": now.Add(lease),
+			}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+// ListExpiredArtifacts lists completed jobs whose artifact is past its retention period
+func (r *ReportJobRepository) ListExpiredArtifacts(now time.Time, limit int) ([]models.ReportJob, error) {
+	var jobs []models.ReportJob
+	err := r.db.Where("status = ? AND expires_at <= ?", models.ReportJobStatusCompleted, now).
+		Order("expires_at").
+		Limit(limit).
+		Find(&jobs).Error
+	return jobs, err
+}
+
+// DeleteFinishedBefore removes failed and expired jobs last updated before the given time
+func (r *ReportJobRepository) DeleteFinishedBefore(before time.Time) (int64, error) {
+	result := r.db.Where("status IN ? AND updated_at < ?",
+		[]models.ReportJobStatus{models.ReportJobStatusFailed, models.ReportJobStatusExpired}, before).
+		Delete(&models.ReportJob{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/delpresence/backend/internal/database"
+	"github.com/delpresence/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// StudentDeviceRepository handles database operations for the devices students check in with
+type StudentDeviceRepository struct {
+	db *gorm.DB
+}
+
+// NewStudentDeviceRepository creates a new student device repository
+func NewStudentDeviceRepository() *StudentDeviceRepository {
+	return &StudentDeviceRepository{
+		db: database.GetDB(),
+	}
+}
+
+// GetByID retrieves a device registration by ID
+func (r *StudentDeviceRepository) GetByID(id uint) (*models.StudentDevice, error) {
+	var device models.StudentDevice
+	err := r.db.Preload("Student").First(&device, id).Error
+	return &device, err
+}
+
+// FindByStatus returns a user's device with a status, or nil when there is none
+func (r *StudentDeviceRepository) FindByStatus(userID int, status models.StudentDeviceStatus) (*models.StudentDevice, error) {
+	var device models.StudentDevice
+	err := r.db.Where("user_id = ? AND status = ?", userID, status).Order("id DESC").First(&device).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+// FindActiveByDeviceID returns the active registration of a device, or nil when it has none
+func (r *StudentDeviceRepository) FindActiveByDeviceID(deviceID string) (*models.StudentDevice, error) {
+	var device models.StudentDevice
+	err := r.db.Where("device_id = ? AND status = ?", deviceID, models.StudentDeviceActive).First(&device).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+// CountChangesSince counts the device changes of a user that took effect since a time
+func (r *StudentDeviceRepository) CountChangesSince(userID int, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.StudentDevice{}).
+		Where("user_id = ? AND is_change = ? AND activated_at >= ?", userID, true, since).
+		Where("status IN ?", []models.StudentDeviceStatus{models.StudentDeviceActive, models.StudentDeviceRevoked}).
+		Count(&count).Error
+	return count, err
+}
+
+// List lists device registrations, optionally filtered by status and student, newest first
+func (r *StudentDeviceRepository) List(status string, studentID uint) ([]models.StudentDevice, error) {
+	var devices []models.StudentDevice
+	query := r.db.Preload("Student")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if studentID != 0 {
+		query = query.Where("student_id = ?", studentID)
+	}
+	err := query.Order("created_at DESC").Find(&devices).Error
+	return devices, err
+}
+
+// Create stores a device registration
+func (r *StudentDeviceRepository) Create(device *models.StudentDevice) error {
+	return r.db.Omit("Student").Create(device).Error
+}
+
+// Activate makes a device the active one of its student in one transaction, revoking the device
+// it replaces first so the student never has two active devices
+func (r *StudentDeviceRepository) Activate(device *models.StudentDevice, previous *models.StudentDevice, at time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if previous != nil {
+			res := tx.Model(&models.StudentDevice{}).
+				Where("id = ? AND status = ?", previous.ID, models.StudentDeviceActive).
+				Updates(map[string]interface{}{"status": models.StudentDeviceRevoked, "revoked_at": at})
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return errors.New("the current device changed meanwhile, try again")
+			}
+		}
+
+		device.Status = models.StudentDeviceActive
+		device.ActivatedAt = &at
+		return tx.Omit("Student").Save(device).Error
+	})
+}
+
+// Save updates a device registration
+func (r *StudentDeviceRepository) Save(device *models.StudentDevice) error {
+	return r.db.Omit("Student").Save(device).Error
+}
@@ -0,0 +1,139 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/delpresence/backend/internal/database"
+	"github.com/delpresence/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WebhookRepository handles database operations for webhook endpoints and deliveries
+type WebhookRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository creates a new webhook repository
+func NewWebhookRepository() *WebhookRepository {
+	return &WebhookRepository{
+		db: database.GetDB(),
+	}
+}
+
+// CreateEndpoint creates a new webhook endpoint
+func (r *WebhookRepository) CreateEndpoint(endpoint *models.WebhookEndpoint) error {
+	return r.db.Create(endpoint).Error
+}
+
+// UpdateEndpoint updates a webhook endpoint
+func (r *WebhookRepository) UpdateEndpoint(endpoint *models.WebhookEndpoint) error {
+	return r.db.Save(endpoint).Error
+}
+
+// DeleteEndpoint soft-deletes a webhook endpoint
+func (r *WebhookRepository) DeleteEndpoint(id uint) error {
+	return r.db.Delete(&models.WebhookEndpoint{}, id).Error
+}
+
+// GetEndpointByID retrieves a webhook endpoint by ID
+func (r *WebhookRepository) GetEndpointByID(id uint) (*models.WebhookEndpoint, error) {
+	var endpoint models.WebhookEndpoint
+	err := r.db.First(&endpoint, id).Error
+	return &endpoint, err
+}
+
+// ListEndpoints lists all webhook endpoints
+func (r *WebhookRepository) ListEndpoints() ([]models.WebhookEndpoint, error) {
+	var endpoints []models.WebhookEndpoint
+	err := r.db.Order("id").Find(&endpoints).Error
+	return endpoints, err
+}
+
+// ListActiveEndpoints lists all webhook endpoints that are enabled
+func (r *WebhookRepository) ListActiveEndpoints() ([]models.WebhookEndpoint, error) {
+	var endpoints []models.WebhookEndpoint
+	err := r.db.Where("active = ?", true).Find(&endpoints).Error
+	return endpoints, err
+}
+
+// CreateDeliveries queues deliveries in a single insert
+func (r *WebhookRepository) CreateDeliveries(deliveries []models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return r.db.Create(&deliveries).Error
+}
+
+// UpdateDelivery updates a webhook delivery
+func (r *WebhookRepository) UpdateDelivery(delivery *models.WebhookDelivery) error {
+	return r.db.Omit("Endpoint").Save(delivery).Error
+}
+
+// GetDeliveryByID retrieves a webhook delivery by ID
+func (r *WebhookRepository) GetDeliveryByID(id uint) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	err := r.db.First(&delivery, id).Error
+	return &delivery, err
+}
+
+// ListDeliveriesByEndpoint lists the most recent deliveries for an endpoint
+func (r *WebhookRepository) ListDeliveriesByEndpoint(endpointID uint, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := r.db.Where("endpoint_id = ?", endpointID).
+		Order("id DESC").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// ListDeliveriesByStatus lists deliveries with the given status, most recent first
+func (r *WebhookRepository) ListDeliveriesByStatus(status models.WebhookDeliveryStatus, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := r.db.Where("status = ?", status).
+		Order("id DESC").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// ClaimDueDeliveries locks pending deliveries that are due and pushes their next attempt
+// time forward by the lease, so other replicas skip them while they are being sent
+func (r *WebhookRepository) ClaimDueDeliveries(limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryStatusPending, now).
+			Order("next_attempt_at").
+			Limit(limit).
+			Find(&deliveries).Error; err != nil {
+			return err
+		}
+
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(deliveries))
+		for i, delivery := range deliveries {
+			ids[i] = delivery.ID
+		}
+
+		return tx.Model(&models.WebhookDelivery{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Load endpoints outside the locking transaction
+	for i := range deliveries {
+		r.db.Unscoped().First(&deliveries[i].Endpoint, deliveries[i].EndpointID)
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,881 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/delpresence/backend/internal/database"
+	"github.com/delpresence/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// rolloverLockClass namespaces the advisory lock that serializes rollovers into one academic year
+const rolloverLockClass = 7301
+
+var (
+	// ErrInvalidRollover is returned when a rollover request is invalid
+	ErrInvalidRollover = errors.New("invalid rollover")
+
+	// ErrRolloverConflicts is returned when committing a rollover whose preview has conflicts
+	ErrRolloverConflicts = errors.New("the rollover has conflicts, resolve them or set skip_conflicts")
+)
+
+// RolloverOptions selects what a rollover copies from one academic year into another and how
+type RolloverOptions struct {
+	SourceAcademicYearID uint `json:"source_academic_year_id"`
+	TargetAcademicYearID uint `json:"-"` // From the URL
+
+	// What to copy. CourseIDs limits the rollover to some courses of the source year; everything
+	// else follows the selected courses.
+	Courses              bool                     `json:"courses"`
+	CourseIDs            []uint                   `json:"course_ids"`
+	StudentGroups        models.RolloverGroupMode `json:"student_groups"` // REUSE (default), CLONE or CLONE_WITH_MEMBERS
+	LecturerAssignments  bool                     `json:"lecturer_assignments"`
+	AssistantAssignments bool                     `json:"assistant_assignments"`
+	Schedules            bool                     `json:"schedules"`
+
+	// Mapping rules. Course codes are unique across years, so copied courses need a suffix.
+	CourseCodeSuffix string        `json:"course_code_suffix"`
+	GroupNameSuffix  string        `json:"group_name_suffix"`
+	RoomMap          map[uint]uint `json:"room_map"` // Source room ID to the room of the copy
+	UserMap          map[uint]uint `json:"user_map"` // Lecturer or assistant user ID to their replacement
+	SkipConflicts    bool          `json:"skip_conflicts"`
+
+	seenCourseIDs map[uint]bool // Selected course IDs, filled in by validate
+}
+
+// AcademicYearRolloverService copies courses, student groups, assignments and schedules from one
+// academic year into another
+type AcademicYearRolloverService struct {
+	db *gorm.DB
+}
+
+// NewAcademicYearRolloverService creates a new academic year rollover service
+func NewAcademicYearRolloverService() *AcademicYearRolloverService {
+	return &AcademicYearRolloverService{
+		db: database.GetDB(),
+	}
+}
+
+// Preview works out what a rollover would copy without changing anything
+func (s *AcademicYearRolloverService) Preview(options RolloverOptions) (*models.RolloverPreview, error) {
+	plan, err := s.plan(s.db, &options)
+	if err != nil {
+		return nil, err
+	}
+	return plan.preview, nil
+}
+
+// Commit performs a rollover in one transaction. It is planned again under a lock on the target
+// year, so the result matches the data at commit time; a plan with conflicts is not committed.
+// Records copied by an earlier run are left alone, so committing the same rollover twice copies
+// nothing the second time.
+func (s *AcademicYearRolloverService) Commit(options RolloverOptions, userID uint) (*models.RolloverPreview, error) {
+	var preview *models.RolloverPreview
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?, ?)", rolloverLockClass, options.TargetAcademicYearID).Error; err != nil {
+			return err
+		}
+
+		plan, err := s.plan(tx, &options)
+		if err != nil {
+			return err
+		}
+		preview = plan.preview
+		if preview.Summary.Conflict > 0 {
+			return ErrRolloverConflicts
+		}
+
+		rawOptions, err := json.Marshal(options)
+		if err != nil {
+			return err
+		}
+		rollover := &models.AcademicYearRollover{
+			SourceAcademicYearID: options.SourceAcademicYearID,
+			TargetAcademicYearID: options.TargetAcademicYearID,
+			Options:              string(rawOptions),
+			Created:              preview.Summary.Create,
+			Existing:             preview.Summary.Exists,
+			Skipped:              preview.Summary.Skip,
+			CreatedByID:          userID,
+		}
+		if err := tx.Create(rollover).Error; err != nil {
+			return err
+		}
+
+		if err := plan.apply(tx, rollover.ID); err != nil {
+			return err
+		}
+		preview.Committed = true
+		preview.RolloverID = &rollover.ID
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrRolloverConflicts) {
+			return preview, err
+		}
+		return nil, err
+	}
+	return preview, nil
+}
+
+// ListRollovers lists the rollovers committed into an academic year, newest first
+func (s *AcademicYearRolloverService) ListRollovers(targetAcademicYearID uint) ([]models.AcademicYearRollover, error) {
+	var rollovers []models.AcademicYearRollover
+	err := s.db.Where("target_academic_year_id = ?", targetAcademicYearID).Order("created_at DESC").Find(&rollovers).Error
+	return rollovers, err
+}
+
+// plannedCopy is a record the plan creates in the target year, with the index of its preview item
+type plannedCopy[T any] struct {
+	sourceID uint
+	item     int
+	record   T
+}
+
+// rolloverPlan is a rollover worked out against the database: its preview, the copies that exist
+// already and the copies to create
+type rolloverPlan struct {
+	options  *RolloverOptions
+	preview  *models.RolloverPreview
+	existing map[models.RolloverEntity]map[uint]uint // Source ID to the existing copy
+	adopted  []models.AcademicYearRolloverMapping    // Existing copies matched without a mapping
+
+	courses              []plannedCopy[models.Course]
+	groups               []plannedCopy[models.StudentGroup]
+	schedules            []plannedCopy[models.CourseSchedule]
+	lecturerAssignments  []plannedCopy[models.LecturerAssignment]
+	assistantAssignments []plannedCopy[models.TeachingAssistantAssignment]
+
+	// Source IDs of the records the plan creates, so dependent copies can refer to them
+	creating map[models.RolloverEntity]map[uint]bool
+}
+
+// plan works out a rollover with a database handle, which is the transaction when committing
+func (s *AcademicYearRolloverService) plan(db *gorm.DB, options *RolloverOptions) (*rolloverPlan, error) {
+	if err := s.validate(db, options); err != nil {
+		return nil, err
+	}
+
+	plan := &rolloverPlan{
+		options: options,
+		preview: &models.RolloverPreview{
+			SourceAcademicYearID: options.SourceAcademicYearID,
+			TargetAcademicYearID: options.TargetAcademicYearID,
+			Courses:              []models.RolloverItem{},
+			StudentGroups:        []models.RolloverItem{},
+			Schedules:            []models.RolloverItem{},
+			LecturerAssignments:  []models.RolloverItem{},
+			AssistantAssignments: []models.RolloverItem{},
+		},
+		existing: make(map[models.RolloverEntity]map[uint]uint),
+		creating: make(map[models.RolloverEntity]map[uint]bool),
+	}
+	if err := plan.loadMappings(db); err != nil {
+		return nil, err
+	}
+
+	courses, err := plan.planCourses(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var schedules []models.CourseSchedule
+	if len(courses) > 0 {
+		if err := db.Preload("StudentGroup").
+			Where("academic_year_id = ? AND course_id IN ?", options.SourceAcademicYearID, courseIDs(courses)).
+			Order("course_id, day, start_time").Find(&schedules).Error; err != nil {
+			return nil, err
+		}
+	}
+	if err := plan.planGroups(db, schedules); err != nil {
+		return nil, err
+	}
+	if err := plan.planSchedules(db, courses, schedules); err != nil {
+		return nil, err
+	}
+	if err := plan.planLecturerAssignments(db, courses); err != nil {
+		return nil, err
+	}
+	if err := plan.planAssistantAssignments(db, courses); err != nil {
+		return nil, err
+	}
+
+	plan.summarize()
+	return plan, nil
+}
+
+// validate checks the academic years and fills in the defaults of a request
+func (s *AcademicYearRolloverService) validate(db *gorm.DB, options *RolloverOptions) error {
+	if options.SourceAcademicYearID == 0 {
+		return fmt.Errorf("%w: source_academic_year_id is required", ErrInvalidRollover)
+	}
+	if options.SourceAcademicYearID == options.TargetAcademicYearID {
+		return fmt.Errorf("%w: the source and target academic years must differ", ErrInvalidRollover)
+	}
+	for _, id := range []uint{options.SourceAcademicYearID, options.TargetAcademicYearID} {
+		var academicYear models.AcademicYear
+		if err := db.First(&academicYear, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: academic year %d not found", ErrInvalidRollover, id)
+			}
+			return err
+		}
+	}
+
+	options.StudentGroups = models.RolloverGroupMode(strings.ToUpper(string(options.StudentGroups)))
+	switch options.StudentGroups {
+	case "":
+		options.StudentGroups = models.RolloverGroupsReuse
+	case models.RolloverGroupsReuse, models.RolloverGroupsClone, models.RolloverGroupsCloneWithMembers:
+	default:
+		return fmt.Errorf("%w: student_groups must be REUSE, CLONE or CLONE_WITH_MEMBERS", ErrInvalidRollover)
+	}
+	if options.StudentGroups != models.RolloverGroupsReuse && strings.TrimSpace(options.GroupNameSuffix) == "" {
+		return fmt.Errorf("%w: group_name_suffix is required when copying student groups", ErrInvalidRollover)
+	}
+	if !options.Courses && !options.Schedules && !options.LecturerAssignments && !options.AssistantAssignments &&
+		options.StudentGroups == models.RolloverGroupsReuse {
+		return fmt.Errorf("%w: select at least one of courses, student_groups, lecturer_assignments, assistant_assignments and schedules", ErrInvalidRollover)
+	}
+
+	options.seenCourseIDs = make(map[uint]bool, len(options.CourseIDs))
+	for _, id := range options.CourseIDs {
+		options.seenCourseIDs[id] = true
+	}
+	return nil
+}
+
+// loadMappings loads the copies made by earlier runs of the same rollover whose target still exists
+func (p *rolloverPlan) loadMappings(db *gorm.DB) error {
+	var mappings []models.AcademicYearRolloverMapping
+	if err := db.Where("source_academic_year_id = ? AND target_academic_year_id = ?",
+		p.options.SourceAcademicYearID, p.options.TargetAcademicYearID).Find(&mappings).Error; err != nil {
+		return err
+	}
+
+	tables := map[models.RolloverEntity]interface{}{
+		models.RolloverEntityCourse:              &models.Course{},
+		models.RolloverEntityStudentGroup:        &models.StudentGroup{},
+		models.RolloverEntityCourseSchedule:      &models.CourseSchedule{},
+		models.RolloverEntityLecturerAssignment:  &models.LecturerAssignment{},
+		models.RolloverEntityAssistantAssignment: &models.TeachingAssistantAssignment{},
+	}
+	targets := make(map[models.RolloverEntity][]uint)
+	for _, mapping := range mappings {
+		targets[mapping.Entity] = append(targets[mapping.Entity], mapping.TargetID)
+	}
+	alive := make(map[models.RolloverEntity]map[uint]bool)
+	for entity, ids := range targets {
+		var found []uint
+		if err := db.Model(tables[entity]).Where("id IN ?", ids).Pluck("id", &found).Error; err != nil {
+			return err
+		}
+		alive[entity] = make(map[uint]bool, len(found))
+		for _, id := range found {
+			alive[entity][id] = true
+		}
+	}
+
+	for entity := range tables {
+		p.existing[entity] = make(map[uint]uint)
+		p.creating[entity] = make(map[uint]bool)
+	}
+	for _, mapping := range mappings {
+		// A deleted copy is made again; its mapping is updated when committing
+		if alive[mapping.Entity][mapping.TargetID] {
+			p.existing[mapping.Entity][mapping.SourceID] = mapping.TargetID
+		}
+	}
+	return nil
+}
+
+// planCourses plans the copies of the selected courses and returns the selected source courses
+func (p *rolloverPlan) planCourses(db *gorm.DB) ([]models.Course, error) {
+	var courses []models.Course
+	query := db.Where("academic_year_id = ?", p.options.SourceAcademicYearID)
+	if len(p.options.CourseIDs) > 0 {
+		query = query.Where("id IN ?", p.options.CourseIDs)
+	}
+	if err := query.Order("code").Find(&courses).Error; err != nil {
+		return nil, err
+	}
+	if len(p.options.CourseIDs) > 0 && len(courses) != len(p.options.seenCourseIDs) {
+		return nil, fmt.Errorf("%w: every course in course_ids must belong to the source academic year", ErrInvalidRollover)
+	}
+
+	for _, course := range courses {
+		item := models.RolloverItem{SourceID: course.ID, Label: fmt.Sprintf("%s %s", course.Code, course.Name)}
+
+		if targetID, ok := p.existing[models.RolloverEntityCourse][course.ID]; ok {
+			p.addItem(&p.preview.Courses, exists(item, targetID, ""))
+			continue
+		}
+		if !p.options.Courses {
+			item.Action = models.RolloverActionSkip
+			item.Reason = "courses are not copied"
+			p.addItem(&p.preview.Courses, item)
+			continue
+		}
+
+		code := course.Code + p.options.CourseCodeSuffix
+		var taken models.Course
+		err := db.Where("code = ?", code).First(&taken).Error
+		switch {
+		case err == nil && taken.AcademicYearID == p.options.TargetAcademicYearID:
+			// A course with the new code already exists in the target year, e.g. created by hand
+			p.adopt(models.RolloverEntityCourse, course.ID, taken.ID)
+			p.addItem(&p.preview.Courses, exists(item, taken.ID, "a course with code "+code+" already exists in the target year"))
+			continue
+		case err == nil:
+			conflict := fmt.Sprintf("code %s is already used in another academic year, set course_code_suffix", code)
+			if p.options.SkipConflicts {
+				// Its schedules and assignments are skipped with it
+				p.addItem(&p.preview.Courses, skip(item, conflict))
+			} else {
+				item.Action = models.RolloverActionConflict
+				item.Reason = conflict
+				p.addItem(&p.preview.Courses, item)
+			}
+			continue
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, err
+		}
+
+		item.Action = models.RolloverActionCreate
+		if code != course.Code {
+			item.Reason = "copied as " + code
+		}
+		record := models.Course{
+			Code:           code,
+			Name:           course.Name,
+			Credits:        course.Credits,
+			Semester:       course.Semester,
+			DepartmentID:   course.DepartmentID,
+			FacultyID:      course.FacultyID,
+			CourseType:     course.CourseType,
+			AcademicYearID: p.options.TargetAcademicYearID,
+		}
+		p.courses = append(p.courses, plannedCopy[models.Course]{sourceID: course.ID, item: p.addItem(&p.preview.Courses, item), record: record})
+		p.creating[models.RolloverEntityCourse][course.ID] = true
+	}
+	return courses, nil
+}
+
+// planGroups plans the copies of the student groups of the selected courses' schedules
+func (p *rolloverPlan) planGroups(db *gorm.DB, schedules []models.CourseSchedule) error {
+	if p.options.StudentGroups == models.RolloverGroupsReuse {
+		return nil
+	}
+
+	groups := make(map[uint]models.StudentGroup)
+	var groupIDs []uint
+	for _, schedule := range schedules {
+		if _, ok := groups[schedule.StudentGroupID]; !ok {
+			groups[schedule.StudentGroupID] = schedule.StudentGroup
+			groupIDs = append(groupIDs, schedule.StudentGroupID)
+		}
+	}
+	sort.Slice(groupIDs, func(i, j int) bool { return groupIDs[i] < groupIDs[j] })
+
+	memberCounts := make(map[uint]int)
+	if len(groupIDs) > 0 {
+		var counts []struct {
+			StudentGroupID uint
+			Members        int
+		}
+		if err := db.Model(&models.StudentToGroup{}).Select("student_group_id, COUNT(*) AS members").
+			Where("student_group_id IN ?", groupIDs).Group("student_group_id").Scan(&counts).Error; err != nil {
+			return err
+		}
+		for _, count := range counts {
+			memberCounts[count.StudentGroupID] = count.Members
+		}
+	}
+
+	withMembers := p.options.StudentGroups == models.RolloverGroupsCloneWithMembers
+	for _, id := range groupIDs {
+		group := groups[id]
+		item := models.RolloverItem{SourceID: id, Label: fmt.Sprintf("%s (%d members)", group.Name, memberCounts[id])}
+
+		if targetID, ok := p.existing[models.RolloverEntityStudentGroup][id]; ok {
+			p.addItem(&p.preview.StudentGroups, exists(item, targetID, ""))
+			continue
+		}
+
+		item.Action = models.RolloverActionCreate
+		item.Reason = "copied as " + group.Name + p.options.GroupNameSuffix
+		if withMembers {
+			item.Reason += fmt.Sprintf(" with %d members", memberCounts[id])
+		} else {
+			item.Reason += " without members"
+		}
+		record := models.StudentGroup{
+			Name:         group.Name + p.options.GroupNameSuffix,
+			DepartmentID: group.DepartmentID,
+		}
+		p.groups = append(p.groups, plannedCopy[models.StudentGroup]{sourceID: id, item: p.addItem(&p.preview.StudentGroups, item), record: record})
+		p.creating[models.RolloverEntityStudentGroup][id] = true
+	}
+	return nil
+}
+
+// scheduleSlot is a weekly time slot taken in the target year, for the conflict checks
+type scheduleSlot struct {
+	label     string
+	day       string
+	startTime string
+	endTime   string
+	roomID    uint
+	userID    uint
+	groupKey  string // "id:<group>" for groups that exist, "source:<group>" for groups being copied
+}
+
+// overlaps reports whether two slots are on the same day at overlapping times
+func (a scheduleSlot) overlaps(b scheduleSlot) bool {
+	return strings.EqualFold(a.day, b.day) && a.startTime < b.endTime && b.startTime < a.endTime
+}
+
+// planSchedules plans the copies of the selected courses' schedules and checks each copy against
+// the target year's schedules and the other copies for room, lecturer and group conflicts
+func (p *rolloverPlan) planSchedules(db *gorm.DB, courses []models.Course, schedules []models.CourseSchedule) error {
+	courseCodes := make(map[uint]string, len(courses))
+	for _, course := range courses {
+		courseCodes[course.ID] = course.Code
+	}
+
+	var taken []models.CourseSchedule
+	if err := db.Preload("Course").Where("academic_year_id = ?", p.options.TargetAcademicYearID).Find(&taken).Error; err != nil {
+		return err
+	}
+	slots := make([]scheduleSlot, 0, len(taken)+len(schedules))
+	for _, schedule := range taken {
+		slots = append(slots, scheduleSlot{
+			label:     fmt.Sprintf("%s %s %s-%s", schedule.Course.Code, schedule.Day, schedule.StartTime, schedule.EndTime),
+			day:       schedule.Day,
+			startTime: schedule.StartTime,
+			endTime:   schedule.EndTime,
+			roomID:    schedule.RoomID,
+			userID:    schedule.UserID,
+			groupKey:  fmt.Sprintf("id:%d", schedule.StudentGroupID),
+		})
+	}
+
+	roomExists := make(map[uint]bool)
+	for _, schedule := range schedules {
+		item := models.RolloverItem{
+			SourceID: schedule.ID,
+			Label: fmt.Sprintf("%s %s %s-%s, %s", courseCodes[schedule.CourseID], schedule.Day,
+				schedule.StartTime, schedule.EndTime, schedule.StudentGroup.Name),
+		}
+
+		if targetID, ok := p.existing[models.RolloverEntityCourseSchedule][schedule.ID]; ok {
+			p.addItem(&p.preview.Schedules, exists(item, targetID, ""))
+			continue
+		}
+		if !p.options.Schedules {
+			p.addItem(&p.preview.Schedules, skip(item, "schedules are not copied"))
+			continue
+		}
+		if !p.planned(models.RolloverEntityCourse, schedule.CourseID) {
+			p.addItem(&p.preview.Schedules, skip(item, "its course is not copied"))
+			continue
+		}
+
+		roomID := schedule.RoomID
+		if mapped, ok := p.options.RoomMap[roomID]; ok {
+			roomID = mapped
+		}
+		if _, checked := roomExists[roomID]; !checked {
+			var count int64
+			if err := db.Model(&models.Room{}).Where("id = ?", roomID).Count(&count).Error; err != nil {
+				return err
+			}
+			roomExists[roomID] = count > 0
+		}
+		if !roomExists[roomID] {
+			p.addItem(&p.preview.Schedules, skip(item, fmt.Sprintf("room %d does not exist", roomID)))
+			continue
+		}
+
+		record := models.CourseSchedule{
+			CourseID:       schedule.CourseID, // Replaced by the copy's course when committing
+			RoomID:         roomID,
+			Day:            schedule.Day,
+			StartTime:      schedule.StartTime,
+			EndTime:        schedule.EndTime,
+			UserID:         p.mapUser(schedule.UserID),
+			StudentGroupID: schedule.StudentGroupID, // Replaced by the group's copy when groups are copied
+			AcademicYearID: p.options.TargetAcademicYearID,
+			Capacity:       schedule.Capacity,
+			Enrolled:       schedule.Enrolled,
+		}
+		groupKey := fmt.Sprintf("id:%d", schedule.StudentGroupID)
+		switch {
+		case p.options.StudentGroups == models.RolloverGroupsReuse:
+		case p.creating[models.RolloverEntityStudentGroup][schedule.StudentGroupID]:
+			groupKey = fmt.Sprintf("source:%d", schedule.StudentGroupID)
+			if p.options.StudentGroups == models.RolloverGroupsClone {
+				record.Enrolled = 0
+			}
+		default:
+			groupKey = fmt.Sprintf("id:%d", p.existing[models.RolloverEntityStudentGroup][schedule.StudentGroupID])
+		}
+
+		slot := scheduleSlot{
+			label:     item.Label,
+			day:       record.Day,
+			startTime: record.StartTime,
+			endTime:   record.EndTime,
+			roomID:    record.RoomID,
+			userID:    record.UserID,
+			groupKey:  groupKey,
+		}
+		if conflict := findSlotConflict(slot, slots); conflict != "" {
+			if p.options.SkipConflicts {
+				p.addItem(&p.preview.Schedules, skip(item, conflict))
+			} else {
+				item.Action = models.RolloverActionConflict
+				item.Reason = conflict
+				p.addItem(&p.preview.Schedules, item)
+			}
+			continue
+		}
+		slots = append(slots, slot)
+
+		item.Action = models.RolloverActionCreate
+		p.schedules = append(p.schedules, plannedCopy[models.CourseSchedule]{sourceID: schedule.ID, item: p.addItem(&p.preview.Schedules, item), record: record})
+		p.creating[models.RolloverEntityCourseSchedule][schedule.ID] = true
+	}
+	return nil
+}
+
+// findSlotConflict describes the first taken slot a new slot clashes with, or returns ""
+func findSlotConflict(slot scheduleSlot, taken []scheduleSlot) string {
+	for _, other := range taken {
+		if !slot.overlaps(other) {
+			continue
+		}
+		switch {
+		case slot.roomID == other.roomID:
+			return "room is taken by " + other.label
+		case slot.userID != 0 && slot.userID == other.userID:
+			return "lecturer teaches " + other.label
+		case slot.groupKey == other.groupKey:
+			return "student group attends " + other.label
+		}
+	}
+	return ""
+}
+
+// planLecturerAssignments plans the copies of the selected courses' lecturer assignments
+func (p *rolloverPlan) planLecturerAssignments(db *gorm.DB, courses []models.Course) error {
+	if !p.options.LecturerAssignments || len(courses) == 0 {
+		return nil
+	}
+
+	var assignments []models.LecturerAssignment
+	if err := db.Preload("Course").
+		Where("academic_year_id = ? AND course_id IN ?", p.options.SourceAcademicYearID, courseIDs(courses)).
+		Order("course_id, role, id").Find(&assignments).Error; err != nil {
+		return err
+	}
+
+	for _, assignment := range assignments {
+		userID := int(p.mapUser(uint(assignment.UserID)))
+		item := models.RolloverItem{
+			SourceID: assignment.ID,
+			Label:    fmt.Sprintf("%s %s lecturer %d", assignment.Course.Code, strings.ToLower(string(assignment.Role)), assignment.UserID),
+		}
+		if userID != assignment.UserID {
+			item.Label += fmt.Sprintf(" (replaced by %d)", userID)
+		}
+
+		if targetID, ok := p.existing[models.RolloverEntityLecturerAssignment][assignment.ID]; ok {
+			p.addItem(&p.preview.LecturerAssignments, exists(item, targetID, ""))
+			continue
+		}
+		if !p.planned(models.RolloverEntityCourse, assignment.CourseID) {
+			p.addItem(&p.preview.LecturerAssignments, skip(item, "its course is not copied"))
+			continue
+		}
+		if assignment.CourseScheduleID != nil && !p.planned(models.RolloverEntityCourseSchedule, *assignment.CourseScheduleID) {
+			p.addItem(&p.preview.LecturerAssignments, skip(item, "the class it teaches is not copied"))
+			continue
+		}
+
+		// An identical assignment made by hand in the target year counts as the copy
+		if targetCourseID, ok := p.existing[models.RolloverEntityCourse][assignment.CourseID]; ok {
+			query := db.Model(&models.LecturerAssignment{}).
+				Where("academic_year_id = ? AND course_id = ? AND user_id = ? AND role = ?",
+					p.options.TargetAcademicYearID, targetCourseID, userID, assignment.Role)
+			if assignment.CourseScheduleID == nil {
+				query = query.Where("course_schedule_id IS NULL")
+			} else if targetScheduleID, ok := p.existing[models.RolloverEntityCourseSchedule][*assignment.CourseScheduleID]; ok {
+				query = query.Where("course_schedule_id = ?", targetScheduleID)
+			} else {
+				query = nil
+			}
+			if query != nil {
+				var found []uint
+				if err := query.Limit(1).Pluck("id", &found).Error; err != nil {
+					return err
+				}
+				if len(found) > 0 {
+					p.adopt(models.RolloverEntityLecturerAssignment, assignment.ID, found[0])
+					p.addItem(&p.preview.LecturerAssignments, exists(item, found[0], "the lecturer is already assigned in the target year"))
+					continue
+				}
+			}
+		}
+
+		item.Action = models.RolloverActionCreate
+		record := models.LecturerAssignment{
+			UserID:           userID,
+			CourseID:         assignment.CourseID, // Replaced by the copy's course when committing
+			Role:             assignment.Role,
+			CourseScheduleID: assignment.CourseScheduleID, // Replaced by the class's copy when committing
+			MeetingFrom:      assignment.MeetingFrom,
+			MeetingTo:        assignment.MeetingTo,
+			AcademicYearID:   p.options.TargetAcademicYearID,
+		}
+		p.lecturerAssignments = append(p.lecturerAssignments, plannedCopy[models.LecturerAssignment]{
+			sourceID: assignment.ID, item: p.addItem(&p.preview.LecturerAssignments, item), record: record,
+		})
+		p.creating[models.RolloverEntityLecturerAssignment][assignment.ID] = true
+	}
+	return nil
+}
+
+// planAssistantAssignments plans the copies of the selected courses' teaching assistant assignments
+func (p *rolloverPlan) planAssistantAssignments(db *gorm.DB, courses []models.Course) error {
+	if !p.options.AssistantAssignments || len(courses) == 0 {
+		return nil
+	}
+
+	var assignments []models.TeachingAssistantAssignment
+	if err := db.Preload("Course").
+		Where("academic_year_id = ? AND course_id IN ?", p.options.SourceAcademicYearID, courseIDs(courses)).
+		Order("course_id, id").Find(&assignments).Error; err != nil {
+		return err
+	}
+
+	for _, assignment := range assignments {
+		userID := int(p.mapUser(uint(assignment.UserID)))
+		item := models.RolloverItem{
+			SourceID: assignment.ID,
+			Label:    fmt.Sprintf("%s assistant %d", assignment.Course.Code, assignment.UserID),
+		}
+		if userID != assignment.UserID {
+			item.Label += fmt.Sprintf(" (replaced by %d)", userID)
+		}
+
+		if targetID, ok := p.existing[models.RolloverEntityAssistantAssignment][assignment.ID]; ok {
+			p.addItem(&p.preview.AssistantAssignments, exists(item, targetID, ""))
+			continue
+		}
+		if !p.planned(models.RolloverEntityCourse, assignment.CourseID) {
+			p.addItem(&p.preview.AssistantAssignments, skip(item, "its course is not copied"))
+			continue
+		}
+
+		// An assistant assigned by hand in the target year counts as the copy
+		if targetCourseID, ok := p.existing[models.RolloverEntityCourse][assignment.CourseID]; ok {
+			var found []uint
+			if err := db.Model(&models.TeachingAssistantAssignment{}).
+				Where("academic_year_id = ? AND course_id = ? AND user_id = ?", p.options.TargetAcademicYearID, targetCourseID, userID).
+				Limit(1).Pluck("id", &found).Error; err != nil {
+				return err
+			}
+			if len(found) > 0 {
+				p.adopt(models.RolloverEntityAssistantAssignment, assignment.ID, found[0])
+				p.addItem(&p.preview.AssistantAssignments, exists(item, found[0], "the assistant is already assigned in the target year"))
+				continue
+			}
+		}
+
+		item.Action = models.RolloverActionCreate
+		record := models.TeachingAssistantAssignment{
+			UserID:         userID,
+			CourseID:       assignment.CourseID, // Replaced by the copy's course when committing
+			AcademicYearID: p.options.TargetAcademicYearID,
+			AssignedByID:   assignment.AssignedByID,
+		}
+		p.assistantAssignments = append(p.assistantAssignments, plannedCopy[models.TeachingAssistantAssignment]{
+			sourceID: assignment.ID, item: p.addItem(&p.preview.AssistantAssignments, item), record: record,
+		})
+		p.creating[models.RolloverEntityAssistantAssignment][assignment.ID] = true
+	}
+	return nil
+}
+
+// apply creates the planned copies in dependency order and records a mapping for each
+func (p *rolloverPlan) apply(tx *gorm.DB, rolloverID uint) error {
+	mappings := p.adopted
+	created := func(entity models.RolloverEntity, sourceID, targetID uint, item *models.RolloverItem) {
+		p.existing[entity][sourceID] = targetID
+		item.TargetID = &targetID
+		mappings = append(mappings, models.AcademicYearRolloverMapping{
+			SourceAcademicYearID: p.options.SourceAcademicYearID,
+			TargetAcademicYearID: p.options.TargetAcademicYearID,
+			Entity:               entity,
+			SourceID:             sourceID,
+			TargetID:             targetID,
+		})
+	}
+
+	for _, copy := range p.courses {
+		record := copy.record
+		if err := tx.Omit(clause.Associations).Create(&record).Error; err != nil {
+			return fmt.Errorf("failed to copy course %s: %w", record.Code, err)
+		}
+		created(models.RolloverEntityCourse, copy.sourceID, record.ID, &p.preview.Courses[copy.item])
+	}
+
+	withMembers := p.options.StudentGroups == models.RolloverGroupsCloneWithMembers
+	for _, copy := range p.groups {
+		record := copy.record
+		if err := tx.Omit(clause.Associations).Create(&record).Error; err != nil {
+			return fmt.Errorf("failed to copy student group %s: %w", record.Name, err)
+		}
+		if withMembers {
+			if err := tx.Exec(`
+				INSERT INTO student_to_groups (student_id, user_id, student_group_id, created_at, updated_at)
+				SELECT student_id, user_id, ?, NOW(), NOW() FROM student_to_groups WHERE student_group_id = ?`,
+				record.ID, copy.sourceID).Error; err != nil {
+				return fmt.Errorf("failed to copy the members of student group %s: %w", record.Name, err)
+			}
+		}
+		created(models.RolloverEntityStudentGroup, copy.sourceID, record.ID, &p.preview.StudentGroups[copy.item])
+	}
+
+	for _, copy := range p.schedules {
+		record := copy.record
+		record.CourseID = p.existing[models.RolloverEntityCourse][record.CourseID]
+		if p.options.StudentGroups != models.RolloverGroupsReuse {
+			record.StudentGroupID = p.existing[models.RolloverEntityStudentGroup][record.StudentGroupID]
+		}
+		if err := tx.Omit(clause.Associations).Create(&record).Error; err != nil {
+			return fmt.Errorf("failed to copy schedule %s: %w", p.preview.Schedules[copy.item].Label, err)
+		}
+		created(models.RolloverEntityCourseSchedule, copy.sourceID, record.ID, &p.preview.Schedules[copy.item])
+	}
+
+	for _, copy := range p.lecturerAssignments {
+		record := copy.record
+		record.CourseID = p.existing[models.RolloverEntityCourse][record.CourseID]
+		if record.CourseScheduleID != nil {
+			scheduleID := p.existing[models.RolloverEntityCourseSchedule][*record.CourseScheduleID]
+			record.CourseScheduleID = &scheduleID
+		}
+		if err := tx.Omit(clause.Associations).Create(&record).Error; err != nil {
+			return fmt.Errorf("failed to copy lecturer assignment %s: %w", p.preview.LecturerAssignments[copy.item].Label, err)
+		}
+		created(models.RolloverEntityLecturerAssignment, copy.sourceID, record.ID, &p.preview.LecturerAssignments[copy.item])
+	}
+
+	for _, copy := range p.assistantAssignments {
+		record := copy.record
+		record.CourseID = p.existing[models.RolloverEntityCourse][record.CourseID]
+		if err := tx.Omit(clause.Associations).Create(&record).Error; err != nil {
+			return fmt.Errorf("failed to copy assistant assignment %s: %w", p.preview.AssistantAssignments[copy.item].Label, err)
+		}
+		created(models.RolloverEntityAssistantAssignment, copy.sourceID, record.ID, &p.preview.AssistantAssignments[copy.item])
+	}
+
+	if len(mappings) == 0 {
+		return nil
+	}
+	for i := range mappings {
+		mappings[i].RolloverID = rolloverID
+	}
+	// A mapping whose copy was deleted points to the new copy
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{
+			{Name: "source_academic_year_id"}, {Name: "target_academic_year_id"}, {Name: "entity"}, {Name: "source_id"},
+		},
+		DoUpdates: clause.AssignmentColumns([]string{"target_id", "rollover_id", "updated_at"}),
+	}).Create(&mappings).Error
+}
+
+// planned reports whether a source record has a copy in the target year, existing or planned
+func (p *rolloverPlan) planned(entity models.RolloverEntity, sourceID uint) bool {
+	_, ok := p.existing[entity][sourceID]
+	return ok || p.creating[entity][sourceID]
+}
+
+// adopt records an existing target-year record as the copy of a source record
+func (p *rolloverPlan) adopt(entity models.RolloverEntity, sourceID, targetID uint) {
+	p.existing[entity][sourceID] = targetID
+	p.adopted = append(p.adopted, models.AcademicYearRolloverMapping{
+		SourceAcademicYearID: p.options.SourceAcademicYearID,
+		TargetAcademicYearID: p.options.TargetAcademicYearID,
+		Entity:               entity,
+		SourceID:             sourceID,
+		TargetID:             targetID,
+	})
+}
+
+// mapUser applies the user mapping rule to a lecturer or assistant user ID
+func (p *rolloverPlan) mapUser(userID uint) uint {
+	if mapped, ok := p.options.UserMap[userID]; ok {
+		return mapped
+	}
+	return userID
+}
+
+// addItem appends an item to a preview list and returns its index
+func (p *rolloverPlan) addItem(items *[]models.RolloverItem, item models.RolloverItem) int {
+	*items = append(*items, item)
+	return len(*items) - 1
+}
+
+// summarize counts the preview's items by action
+func (p *rolloverPlan) summarize() {
+	summary := &p.preview.Summary
+	for _, items := range [][]models.RolloverItem{
+		p.preview.Courses, p.preview.StudentGroups, p.preview.Schedules,
+		p.preview.LecturerAssignments, p.preview.AssistantAssignments,
+	} {
+		for _, item := range items {
+			switch item.Action {
+			case models.RolloverActionCreate:
+				summary.Create++
+			case models.RolloverActionExists:
+				summary.Exists++
+			case models.RolloverActionSkip:
+				summary.Skip++
+			case models.RolloverActionConflict:
+				summary.Conflict++
+			}
+		}
+	}
+}
+
+// exists marks an item as already copied
+func exists(item models.RolloverItem, targetID uint, reason string) models.RolloverItem {
+	item.Action = models.RolloverActionExists
+	item.TargetID = &targetID
+	item.Reason = reason
+	return item
+}
+
+// skip marks an item as not copied for a reason
+func skip(item models.RolloverItem, reason string) models.RolloverItem {
+	item.Action = models.RolloverActionSkip
+	item.Reason = reason
+	return item
+}
+
+// courseIDs returns the IDs of courses
+func courseIDs(courses []models.Course) []uint {
+	ids := make([]uint, len(courses))
+	for i, course := range courses {
+		ids[i] = course.ID
+	}
+	return ids
+}
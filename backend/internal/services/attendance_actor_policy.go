@@ -0,0 +1,141 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/delpresence/backend/internal/utils"
+)
+
+// AttendanceActor identifies a kind of user operating attendance sessions through the API
+type AttendanceActor string
+
+const (
+	AttendanceActorLecturer  AttendanceActor = "lecturer"
+	AttendanceActorAssistant AttendanceActor = "assistant"
+	AttendanceActorAdmin     AttendanceActor = "admin"
+)
+
+// AttendanceAction is an operation on attendance sessions that policy grants per actor
+type AttendanceAction string
+
+const (
+	AttendanceActionOpen       AttendanceAction = "open"        // Open a session for a schedule
+	AttendanceActionView       AttendanceAction = "view"        // Session details, student list, QR code and report
+	AttendanceActionClose      AttendanceAction = "close"       // Close an active session
+	AttendanceActionCancel     AttendanceAction = "cancel"      // Cancel an active session
+	AttendanceActionMark       AttendanceAction = "mark"        // Set a student's status manually in an active session
+	AttendanceActionCorrect    AttendanceAction = "correct"     // Correct a student's status in a closed session within the grace window
+	AttendanceActionReopen     AttendanceAction = "reopen"      // Reopen a closed session for a few minutes within the grace window
+	AttendanceActionStatistics AttendanceAction = "statistics"  // Statistics of a schedule
+	AttendanceActionJournal    AttendanceAction = "journal"     // Fill in and sign the lecture journals of a schedule
+	AttendanceActionLessonPlan AttendanceAction = "lesson_plan" // Edit a course's lesson plan and view its coverage report
+)
+
+// AttendanceAccess is a relation between a user and a session that lets an actor act on it
+type AttendanceAccess string
+
+const (
+	AttendanceAccessCreator          AttendanceAccess = "creator"           // The user opened the session
+	AttendanceAccessScheduleLecturer AttendanceAccess = "schedule_lecturer" // The user is the lecturer of the schedule
+	AttendanceAccessCourseAssistant  AttendanceAccess = "course_assistant"  // The user assists the course
+	AttendanceAccessAll              AttendanceAccess = "all"               // Every session
+)
+
+// defaultAttendanceActorPolicies are used for actors without environment overrides
+var defaultAttendanceActorPolicies = map[AttendanceActor]AttendanceActorPolicy{
+	AttendanceActorLecturer: {
+		Actor: AttendanceActorLecturer,
+		Actions: []AttendanceAction{
+			AttendanceActionOpen, AttendanceActionView, AttendanceActionClose, AttendanceActionCancel,
+			AttendanceActionMark, AttendanceActionCorrect, AttendanceActionReopen, AttendanceActionStatistics,
+			AttendanceActionJournal, AttendanceActionLessonPlan,
+		},
+		Access: []AttendanceAccess{
+			AttendanceAccessCreator, AttendanceAccessScheduleLecturer, AttendanceAccessCourseAssistant,
+		},
+	},
+	AttendanceActorAssistant: {
+		Actor: AttendanceActorAssistant,
+		Actions: []AttendanceAction{
+			AttendanceActionOpen, AttendanceActionView, AttendanceActionClose, AttendanceActionMark,
+			AttendanceActionJournal,
+		},
+		Access: []AttendanceAccess{
+			AttendanceAccessCreator, AttendanceAccessCourseAssistant,
+		},
+	},
+	// Admins change sessions through AttendanceOverrideService, which requires a reason and records it
+	AttendanceActorAdmin: {
+		Actor:   AttendanceActorAdmin,
+		Actions: []AttendanceAction{AttendanceActionView, AttendanceActionStatistics, AttendanceActionLessonPlan},
+		Access:  []AttendanceAccess{AttendanceAccessAll},
+	},
+}
+
+// AttendanceActorPolicy lists what an actor may do with attendance sessions and which
+// sessions it may do it on
+type AttendanceActorPolicy struct {
+	Actor   AttendanceActor    `json:"actor"`
+	Actions []AttendanceAction `json:"actions"`
+	Access  []AttendanceAccess `json:"access"`
+}
+
+// LoadAttendanceActorPolicy returns the policy of an actor. ATTENDANCE_POLICY_<ACTOR>_ACTIONS and
+// ATTENDANCE_POLICY_<ACTOR>_ACCESS (comma-separated) override the defaults, and define the policy of
+// actors without defaults, such as a lab coordinator.
+func LoadAttendanceActorPolicy(actor AttendanceActor) AttendanceActorPolicy {
+	policy := defaultAttendanceActorPolicies[actor]
+	policy.Actor = actor
+
+	prefix := "ATTENDANCE_POLICY_" + strings.ToUpper(strings.NewReplacer("-", "_", " ", "_").Replace(string(actor)))
+	if value := utils.GetEnvWithDefault(prefix+"_ACTIONS", ""); value != "" {
+		policy.Actions = nil
+		for _, action := range splitPolicyList(value) {
+			policy.Actions = append(policy.Actions, AttendanceAction(action))
+		}
+	}
+	if value := utils.GetEnvWithDefault(prefix+"_ACCESS", ""); value != "" {
+		policy.Access = nil
+		for _, access := range splitPolicyList(value) {
+			policy.Access = append(policy.Access, AttendanceAccess(access))
+		}
+	}
+
+	return policy
+}
+
+// AttendanceActorForRole maps an authenticated role to the attendance actor it acts as
+func AttendanceActorForRole(role string) (AttendanceActor, error) {
+	switch strings.ToLower(role) {
+	case "dosen":
+		return AttendanceActorLecturer, nil
+	case "asisten dosen":
+		return AttendanceActorAssistant, nil
+	case "admin":
+		return AttendanceActorAdmin, nil
+	}
+	return "", fmt.Errorf("role %q cannot manage attendance sessions", role)
+}
+
+// Allows reports whether the policy grants an action
+func (p AttendanceActorPolicy) Allows(action AttendanceAction) bool {
+	return slices.Contains(p.Actions, action)
+}
+
+// Grants reports whether the policy gives access through a relation
+func (p AttendanceActorPolicy) Grants(access AttendanceAccess) bool {
+	return slices.Contains(p.Access, access)
+}
+
+// splitPolicyList splits a comma-separated policy value into lowercase items
+func splitPolicyList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,282 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/delpresence/backend/internal/models"
+	"github.com/delpresence/backend/internal/repositories"
+)
+
+// lateArrivalBuckets are the ranges of minutes after session start used for the late-arrival distribution
+var lateArrivalBuckets = []models.LateArrivalBucket{
+	{Label: "0-10", MinMinutes: 0, MaxMinutes: 10},
+	{Label: "10-15", MinMinutes: 10, MaxMinutes: 15},
+	{Label: "15-20", MinMinutes: 15, MaxMinutes: 20},
+	{Label: "20-30", MinMinutes: 20, MaxMinutes: 30},
+	{Label: "30-45", MinMinutes: 30, MaxMinutes: 45},
+	{Label: "45-60", MinMinutes: 45, MaxMinutes: 60},
+	{Label: "60+", MinMinutes: 60},
+}
+
+// AttendanceAnalyticsOverview summarizes attendance across an academic year
+type AttendanceAnalyticsOverview struct {
+	AcademicYear     *models.AcademicYear             `json:"academic_year"`
+	Totals           models.AttendanceStatusCounts    `json:"totals"`
+	AttendanceRate   float64                          `json:"attendance_rate"`
+	ActiveSessions   int                              `json:"active_sessions"`
+	ClosedSessions   int                              `json:"closed_sessions"`
+	CanceledSessions int                              `json:"canceled_sessions"`
+	PlannedMeetings  int                              `json:"planned_meetings"`
+	NeverOpened      int                              `json:"never_opened"`
+	NeverOpenedShare float64                          `json:"never_opened_share"`
+	Verification     []models.VerificationMethodShare `json:"verification_methods"`
+}
+
+// PlannedCoverageReport compares planned meetings with opened sessions for every schedule of an academic year
+type PlannedCoverageReport struct {
+	Planned          int                             `json:"planned"`
+	Opened           int                             `json:"opened"`
+	NeverOpened      int                             `json:"never_opened"`
+	Unplanned        int                             `json:"unplanned"`
+	NeverOpenedShare float64                         `json:"never_opened_share"`
+	Schedules        []models.PlannedSessionCoverage `json:"schedules"`
+}
+
+// AttendanceAnalyticsService computes institution-wide attendance analytics for admins
+type AttendanceAnalyticsService struct {
+	statsRepo        *repositories.AttendanceStatisticsRepository
+	scheduleRepo     *repositories.CourseScheduleRepository
+	academicYearRepo *repositories.AcademicYearRepository
+}
+
+// NewAttendanceAnalyticsService creates a new attendance analytics service
+func NewAttendanceAnalyticsService() *AttendanceAnalyticsService {
+	return &AttendanceAnalyticsService{
+		statsRepo:        repositories.NewAttendanceStatisticsRepository(),
+		scheduleRepo:     repositories.NewCourseScheduleRepository(),
+		academicYearRepo: repositories.NewAcademicYearRepository(),
+	}
+}
+
+// ResolveAcademicYear returns the given academic year, or the active one when the ID is zero
+func (s *AttendanceAnalyticsService) ResolveAcademicYear(academicYearID uint) (*models.AcademicYear, error) {
+	return resolveAcademicYear(s.academicYearRepo, academicYearID)
+}
+
+// GetOverview returns the attendance totals, session counts, never-opened share and
+// verification-method mix of an academic year
+func (s *AttendanceAnalyticsService) GetOverview(academicYear *models.AcademicYear) (*AttendanceAnalyticsOverview, error) {
+	filter := repositories.AttendanceStatsFilter{AcademicYearID: academicYear.ID}
+
+	totals, err := s.statsRepo.GetTotals(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionCounts, err := s.statsRepo.GetSessionStatusCounts(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	verification, err := s.GetVerificationMix(academicYear)
+	if err != nil {
+		return nil, err
+	}
+
+	coverage, err := s.GetPlannedCoverage(academicYear)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AttendanceAnalyticsOverview{
+		AcademicYear:     academicYear,
+		Totals:           totals,
+		AttendanceRate:   totals.AttendedRate(),
+		ActiveSessions:   sessionCounts[models.AttendanceStatusActive],
+		ClosedSessions:   sessionCounts[models.AttendanceStatusClosed],
+		CanceledSessions: sessionCounts[models.AttendanceStatusCanceled],
+		PlannedMeetings:  coverage.Planned,
+		NeverOpened:      coverage.NeverOpened,
+		NeverOpenedShare: coverage.NeverOpenedShare,
+		Verification:     verification,
+	}, nil
+}
+
+// GetBreakdown returns the attendance rate of an academic year grouped by the given dimension
+func (s *AttendanceAnalyticsService) GetBreakdown(academicYear *models.AcademicYear, breakdown models.AttendanceBreakdown) ([]models.AttendanceBreakdownItem, error) {
+	return s.statsRepo.GetBreakdown(breakdown, repositories.AttendanceStatsFilter{AcademicYearID: academicYear.ID})
+}
+
+// GetWeeklyTrend returns attendance per week, numbered from the first week of the academic year
+func (s *AttendanceAnalyticsService) GetWeeklyTrend(academicYear *models.AcademicYear) ([]models.AttendanceTrendPoint, error) {
+	points, err := s.statsRepo.GetWeeklyTrend(repositories.AttendanceStatsFilter{AcademicYearID: academicYear.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	firstWeek := startOfISOWeek(academicYear.StartDate)
+	for i := range points {
+		weekStart, err := time.Parse("2006-01-02", points[i].WeekStart)
+		if err == nil {
+			points[i].Week = int(weekStart.Sub(firstWeek).Hours()/(24*7)) + 1
+		}
+		points[i].AttendanceRate = models.AttendanceStatusCounts{
+			Present: points[i].Present,
+			Late:    points[i].Late,
+			Total:   points[i].Total,
+		}.AttendedRate()
+	}
+
+	return points, nil
+}
+
+// GetLateArrivalDistribution buckets late check-ins by how many minutes after the session start they arrived
+func (s *AttendanceAnalyticsService) GetLateArrivalDistribution(academicYear *models.AcademicYear) ([]models.LateArrivalBucket, error) {
+	minutes, err := s.statsRepo.GetLateArrivalMinutes(repositories.AttendanceStatsFilter{AcademicYearID: academicYear.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := make([]models.LateArrivalBucket, len(lateArrivalBuckets))
+	copy(buckets, lateArrivalBuckets)
+
+	total := 0
+	for minute, count := range minutes {
+		total += count
+		for i := range buckets {
+			if minute >= buckets[i].MinMinutes && (buckets[i].MaxMinutes == 0 || minute < buckets[i].MaxMinutes) {
+				buckets[i].Count += count
+				break
+			}
+		}
+	}
+
+	if total > 0 {
+		for i := range buckets {
+			buckets[i].Share = float64(buckets[i].Count) * 100 / float64(total)
+		}
+	}
+
+	return buckets, nil
+}
+
+// GetVerificationMix returns the share of check-ins recorded with each verification method
+func (s *AttendanceAnalyticsService) GetVerificationMix(academicYear *models.AcademicYear) ([]models.VerificationMethodShare, error) {
+	methods, err := s.statsRepo.GetVerificationMethodCounts(repositories.AttendanceStatsFilter{AcademicYearID: academicYear.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	total := 0
+	for _, method := range methods {
+		total += method.Count
+	}
+	if total > 0 {
+		for i := range methods {
+			methods[i].Share = float64(methods[i].Count) * 100 / float64(total)
+		}
+	}
+
+	return methods, nil
+}
+
+// GetPlannedCoverage compares the meetings each schedule should have had so far in the
+// academic year with the dates a session was actually opened
+func (s *AttendanceAnalyticsService) GetPlannedCoverage(academicYear *models.AcademicYear) (*PlannedCoverageReport, error) {
+	schedules, err := s.scheduleRepo.GetByAcademicYear(academicYear.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionDates, err := s.statsRepo.GetSessionDates(repositories.AttendanceStatsFilter{AcademicYearID: academicYear.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	opened := make(map[uint]map[string]bool)
+	for _, row := range sessionDates {
+		if opened[row.CourseScheduleID] == nil {
+			opened[row.CourseScheduleID] = make(map[string]bool)
+		}
+		opened[row.CourseScheduleID][row.Date.Format("2006-01-02")] = true
+	}
+
+	// Only count meetings up to today
+	until := academicYear.EndDate
+	if today := GetIndonesiaTime(); today.Before(until) {
+		until = today
+	}
+
+	report := &PlannedCoverageReport{}
+	for _, schedule := range schedules {
+		coverage := models.PlannedSessionCoverage{
+			CourseScheduleID: schedule.ID,
+			CourseCode:       schedule.Course.Code,
+			CourseName:       schedule.Course.Name,
+			LecturerUserID:   schedule.UserID,
+			Day:              schedule.Day,
+			StartTime:        schedule.StartTime,
+		}
+
+		planned := make(map[string]bool)
+		for _, date := range plannedMeetingDates(schedule, academicYear.StartDate, until) {
+			key := date.Format("2006-01-02")
+			planned[key] = true
+			coverage.Planned++
+			if opened[schedule.ID][key] {
+				coverage.Opened++
+			}
+		}
+		for date := range opened[schedule.ID] {
+			if !planned[date] {
+				coverage.Unplanned++
+			}
+		}
+
+		coverage.NeverOpened = coverage.Planned - coverage.Opened
+		if coverage.Planned > 0 {
+			coverage.NeverOpenedShare = float64(coverage.NeverOpened) * 100 / float64(coverage.Planned)
+		}
+
+		report.Planned += coverage.Planned
+		report.Opened += coverage.Opened
+		report.NeverOpened += coverage.NeverOpened
+		report.Unplanned += coverage.Unplanned
+		report.Schedules = append(report.Schedules, coverage)
+	}
+
+	if report.Planned > 0 {
+		report.NeverOpenedShare = float64(report.NeverOpened) * 100 / float64(report.Planned)
+	}
+
+	return report, nil
+}
+
+// startOfISOWeek returns the Monday of the week containing t
+func startOfISOWeek(t time.Time) time.Time {
+	t = truncateToDate(t)
+	offset := (int(t.Weekday()) + 6) % 7
+	return t.AddDate(0, 0, -offset)
+}
+
+// resolveAcademicYear returns the given academic year, or the active one when the ID is zero
+func resolveAcademicYear(repo *repositories.AcademicYearRepository, academicYearID uint) (*models.AcademicYear, error) {
+	var academicYear *models.AcademicYear
+	var err error
+	if academicYearID > 0 {
+		academicYear, err = repo.FindByID(academicYearID)
+	} else {
+		academicYear, err = repo.GetActiveAcademicYear()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if academicYear == nil {
+		if academicYearID > 0 {
+			return nil, errors.New("academic year not found")
+		}
+		return nil, errors.New("no active academic year, specify academic_year_id")
+	}
+	return academicYear, nil
+}
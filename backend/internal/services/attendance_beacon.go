@@ -0,0 +1,103 @@
+package services
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/delpresence/backend/internal/models"
+	"github.com/delpresence/backend/internal/utils"
+)
+
+// beaconRotation is how long a BEACON session broadcasts an identifier before the next one
+const beaconRotation = 30 * time.Second
+
+// defaultBeaconServiceUUID is the Bluetooth service the app scans for unless BEACON_SERVICE_UUID is set
+const defaultBeaconServiceUUID = "6e9f5b1e-7d3a-4c2b-9a61-64656c707265"
+
+var (
+	// ErrInvalidBeacon is returned when a student submits an identifier the session did not broadcast
+	// recently
+	ErrInvalidBeacon = errors.New("invalid or expired beacon identifier")
+
+	// ErrBeaconTooFar is returned when the signal a student observed is weaker than BEACON_MIN_RSSI
+	ErrBeaconTooFar = errors.New("beacon signal too weak, move closer to the lecturer")
+)
+
+// SubmitBeaconAttendance records a student's check-in to a BEACON session with the identifier the
+// phone observed and its signal strength (RSSI, in dBm). The identifier of the previous rotation is
+// still accepted; signals weaker than BEACON_MIN_RSSI (default -90) are rejected.
+func (s *AttendanceService) SubmitBeaconAttendance(sessionID, externalUserID uint, identifier string, rssi int, meta CheckInMetadata) error {
+	session, err := s.attendanceRepo.GetAttendanceSessionByID(sessionID)
+	if err != nil {
+		return errors.New("attendance session not found")
+	}
+	if session.Status != models.AttendanceStatusActive {
+		return errors.New("attendance session is not active")
+	}
+	if session.Type != models.AttendanceTypeBeacon {
+		return errors.New("this attendance session does not support beacon verification")
+	}
+
+	student, err := s.findEnrolledStudent(session, externalUserID)
+	if err != nil {
+		return err
+	}
+
+	if rssi >= 0 || rssi < -127 {
+		return fmt.Errorf("invalid rssi %d, expected dBm between -127 and -1", rssi)
+	}
+	if minRSSI := utils.GetEnvAsInt("BEACON_MIN_RSSI", -90); rssi < minRSSI {
+		return fmt.Errorf("%w (%d dBm, at least %d dBm required)", ErrBeaconTooFar, rssi, minRSSI)
+	}
+	valid, codeExpiredAt := verifySessionBeacon(session.BeaconSecret, identifier, GetIndonesiaTime())
+	if !valid {
+		return ErrInvalidBeacon
+	}
+
+	meta.CodeExpiredAt = codeExpiredAt
+	return s.recordCheckIn(session, student, models.StudentAttendanceStatusPresent, string(models.AttendanceTypeBeacon), meta)
+}
+
+// GetCurrentBeacon returns the identifier the lecturer's device should broadcast for a BEACON session
+func (s *AttendanceSessionService) GetCurrentBeacon(sessionID, userID uint) (*models.AttendanceBeaconResponse, error) {
+	session, err := s.authorize(AttendanceActionView, sessionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Type != models.AttendanceTypeBeacon {
+		return nil, errors.New("this session does not use beacon")
+	}
+	if session.Status != models.AttendanceStatusActive {
+		return nil, errors.New("attendance session is not active")
+	}
+
+	now := GetIndonesiaTime()
+	return &models.AttendanceBeaconResponse{
+		SessionID:       session.ID,
+		ServiceUUID:     utils.GetEnvWithDefault("BEACON_SERVICE_UUID", defaultBeaconServiceUUID),
+		Identifier:      sessionBeaconIdentifier(session.BeaconSecret, now),
+		ExpiresAt:       now.Truncate(beaconRotation).Add(beaconRotation),
+		RotationSeconds: int(beaconRotation.Seconds()),
+	}, nil
+}
+
+// sessionBeaconIdentifier derives the 8-byte identifier a BEACON session broadcasts in the rotation
+// period containing a time, hex encoded
+func sessionBeaconIdentifier(secret string, at time.Time) string {
+	return hex.EncodeToString(rotatingHMAC(secret, at, beaconRotation)[:8])
+}
+
+// verifySessionBeacon reports whether an identifier is the current or the previous identifier of a
+// BEACON session. For the previous identifier it also returns when it was replaced.
+func verifySessionBeacon(secret, identifier string, now time.Time) (bool, *time.Time) {
+	identifier = strings.ToLower(strings.TrimSpace(identifier))
+	if secret == "" || identifier == "" {
+		return false, nil
+	}
+	return matchRotatingCode(identifier, now, beaconRotation, func(at time.Time) string {
+		return sessionBeaconIdentifier(secret, at)
+	})
+}
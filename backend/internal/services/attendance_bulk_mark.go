@@ -0,0 +1,281 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/delpresence/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// maxBulkMarkEntries limits the number of entries of one bulk marking request
+const maxBulkMarkEntries = 500
+
+// ErrBulkMarkRejected is returned when a bulk marking has invalid rows; nothing is applied
+var ErrBulkMarkRejected = errors.New("some rows are invalid; no attendance was changed")
+
+// Bulk marking row results
+const (
+	BulkMarkUpdated   = "UPDATED"
+	BulkMarkUnchanged = "UNCHANGED"
+	BulkMarkRejected  = "REJECTED"
+)
+
+// BulkMarkEntry sets the status of one student. StudentID is the external user ID, as in the
+// session's student list.
+type BulkMarkEntry struct {
+	StudentID uint                           `json:"student_id"`
+	Status    models.StudentAttendanceStatus `json:"status"`
+	Notes     string                         `json:"notes"`
+}
+
+// BulkMarkInput marks many students of a session at once. RemainingStatus, when set, applies to
+// every student who is not listed in Entries and has not checked in or been marked yet.
+type BulkMarkInput struct {
+	Entries            []BulkMarkEntry                `json:"entries"`
+	RemainingStatus    models.StudentAttendanceStatus `json:"remaining_status"`
+	VerificationMethod string                         `json:"verification_method"`
+	Reason             string                         `json:"reason"` // Required for closed sessions
+}
+
+// BulkMarkRowResult is the outcome for one student of a bulk marking
+type BulkMarkRowResult struct {
+	StudentID      uint   `json:"student_id"`
+	StudentNIM     string `json:"student_nim,omitempty"`
+	StudentName    string `json:"student_name,omitempty"`
+	PreviousStatus string `json:"previous_status,omitempty"`
+	Status         string `json:"status,omitempty"`
+	Result         string `json:"result"` // UPDATED, UNCHANGED or REJECTED
+	Error          string `json:"error,omitempty"`
+}
+
+// BulkMarkResult summarizes a bulk marking. Applied is false when any row was rejected; the other
+// rows then show what would have changed.
+type BulkMarkResult struct {
+	SessionID uint                `json:"session_id"`
+	Applied   bool                `json:"applied"`
+	Updated   int                 `json:"updated"`
+	Unchanged int                 `json:"unchanged"`
+	Rejected  int                 `json:"rejected"`
+	Rows      []BulkMarkRowResult `json:"rows"`
+}
+
+// bulkMarkChange is a validated change to one attendance record
+type bulkMarkChange struct {
+	attendance *models.StudentAttendance
+	previous   models.StudentAttendanceStatus
+	status     models.StudentAttendanceStatus
+	notes      string
+	row        int
+}
+
+// BulkMarkStudentAttendance sets the status of many students of a session in one transaction.
+// All rows are validated first; if any is rejected nothing is applied. In a closed session the
+// marking is a correction: it needs a reason, the grace window applies and every change is recorded
+// in the session's history.
+func (s *AttendanceSessionService) BulkMarkStudentAttendance(sessionID, userID uint, input BulkMarkInput) (*BulkMarkResult, error) {
+	session, err := s.loadSession(sessionID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	correcting := session.Status != models.AttendanceStatusActive
+	reason := strings.TrimSpace(input.Reason)
+	if correcting {
+		if !s.policy.Allows(AttendanceActionCorrect) {
+			return nil, ErrAttendanceActionNotAllowed
+		}
+		if err := checkEditWindow(session); err != nil {
+			return nil, err
+		}
+		if reason == "" {
+			return nil, ErrOverrideReasonRequired
+		}
+	} else if !s.policy.Allows(AttendanceActionMark) {
+		return nil, ErrAttendanceActionNotAllowed
+	}
+
+	if len(input.Entries) == 0 && input.RemainingStatus == "" {
+		return nil, fmt.Errorf("%w: entries or remaining_status is required", ErrInvalidOverride)
+	}
+	if len(input.Entries) > maxBulkMarkEntries {
+		return nil, fmt.Errorf("%w: at most %d entries per request", ErrInvalidOverride, maxBulkMarkEntries)
+	}
+	if input.RemainingStatus != "" && !validStudentAttendanceStatus(input.RemainingStatus) {
+		return nil, fmt.Errorf("%w: invalid remaining_status %q", ErrInvalidOverride, input.RemainingStatus)
+	}
+
+	attendances, err := s.attendanceRepo.ListStudentAttendances(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	byStudent := make(map[uint]*models.StudentAttendance, len(attendances))
+	for i := range attendances {
+		byStudent[uint(attendances[i].Student.UserID)] = &attendances[i]
+	}
+
+	result := &BulkMarkResult{SessionID: sessionID}
+	var changes []bulkMarkChange
+	listed := make(map[uint]bool, len(input.Entries))
+
+	for _, entry := range input.Entries {
+		row := BulkMarkRowResult{StudentID: entry.StudentID, Status: string(entry.Status)}
+
+		attendance, found := byStudent[entry.StudentID]
+		switch {
+		case listed[entry.StudentID]:
+			row.Error = "student is listed more than once"
+		case !validStudentAttendanceStatus(entry.Status):
+			row.Error = "invalid attendance status"
+		case !found:
+			student, err := findSessionStudent(s.db, s.studentRepo, session, entry.StudentID)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				row.Error = "student not found"
+				break
+			}
+			if errors.Is(err, ErrInvalidOverride) {
+				row.Error = "student is not in the session's student group"
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			// Students who joined the group after the session opened have no record yet
+			attendance = &models.StudentAttendance{AttendanceSessionID: sessionID, StudentID: student.ID, Student: *student}
+		}
+		listed[entry.StudentID] = true
+
+		if row.Error != "" {
+			row.Result = BulkMarkRejected
+			result.Rows = append(result.Rows, row)
+			continue
+		}
+
+		notes := entry.Notes
+		if correcting {
+			notes = reason
+		}
+		changes = append(changes, bulkMarkChange{attendance: attendance, status: entry.Status, notes: notes, row: len(result.Rows)})
+		result.Rows = append(result.Rows, row)
+	}
+
+	if input.RemainingStatus != "" {
+		for i := range attendances {
+			attendance := &attendances[i]
+			studentID := uint(attendance.Student.UserID)
+			if listed[studentID] || attendance.CheckInTime != nil || attendance.VerifiedByID != nil {
+				continue
+			}
+			notes := ""
+			if correcting {
+				notes = reason
+			}
+			changes = append(changes, bulkMarkChange{attendance: attendance, status: input.RemainingStatus, notes: notes, row: len(result.Rows)})
+			result.Rows = append(result.Rows, BulkMarkRowResult{StudentID: studentID, Status: string(input.RemainingStatus)})
+		}
+	}
+
+	for i := range changes {
+		change := &changes[i]
+		requested := change.status
+		if !correcting {
+			change.status = manualCheckInStatus(session, change.status)
+		}
+		change.previous = change.attendance.Status
+
+		row := &result.Rows[change.row]
+		row.StudentNIM = change.attendance.Student.NIM
+		row.StudentName = change.attendance.Student.FullName
+		row.PreviousStatus = string(change.previous)
+		row.Status = string(change.status)
+		row.Result = BulkMarkUpdated
+		// A student who already has the status keeps the record, including an earlier check-in
+		if change.attendance.ID != 0 && (change.previous == requested || change.previous == change.status) {
+			row.Status = string(change.previous)
+			row.Result = BulkMarkUnchanged
+		}
+	}
+
+	for _, row := range result.Rows {
+		switch row.Result {
+		case BulkMarkUpdated:
+			result.Updated++
+		case BulkMarkUnchanged:
+			result.Unchanged++
+		case BulkMarkRejected:
+			result.Rejected++
+		}
+	}
+	if result.Rejected > 0 {
+		return result, ErrBulkMarkRejected
+	}
+
+	verificationMethod := input.VerificationMethod
+	if verificationMethod == "" {
+		verificationMethod = "MANUAL"
+	}
+	now := GetIndonesiaTime()
+
+	var applied []*models.StudentAttendance
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		for _, change := range changes {
+			if result.Rows[change.row].Result != BulkMarkUpdated {
+				continue
+			}
+
+			attendance := change.attendance
+			attendance.Status = change.status
+			attendance.Notes = change.notes
+			attendance.VerifiedByID = &userID
+			if correcting {
+				if (change.status == models.StudentAttendanceStatusPresent || change.status == models.StudentAttendanceStatusLate) && attendance.VerificationMethod == "" {
+					attendance.VerificationMethod = "MANUAL"
+				}
+			} else {
+				attendance.CheckInTime = &now
+				attendance.VerificationMethod = verificationMethod
+			}
+
+			if err := tx.Omit("AttendanceSession", "Student").Save(attendance).Error; err != nil {
+				return err
+			}
+			if correcting {
+				if err := tx.Create(&models.AttendanceOverride{
+					Action:              models.AttendanceOverrideSetStatus,
+					AttendanceSessionID: sessionID,
+					StudentID:           &attendance.StudentID,
+					PreviousValue:       string(change.previous),
+					NewValue:            string(change.status),
+					Reason:              reason,
+					PerformedByID:       userID,
+					PerformedByRole:     string(s.policy.Actor),
+				}).Error; err != nil {
+					return err
+				}
+			}
+			applied = append(applied, attendance)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, attendance := range applied {
+		publishStudentAttendanceEvent(models.AttendanceEventStudentStatusChanged, session, &attendance.Student, attendance)
+	}
+
+	result.Applied = true
+	return result, nil
+}
+
+// validStudentAttendanceStatus reports whether a status can be set by hand
+func validStudentAttendanceStatus(status models.StudentAttendanceStatus) bool {
+	switch status {
+	case models.StudentAttendanceStatusPresent, models.StudentAttendanceStatusLate,
+		models.StudentAttendanceStatusAbsent, models.StudentAttendanceStatusExcused:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"log"
+
+	"github.com/delpresence/backend/internal/models"
+	"github.com/delpresence/backend/internal/realtime"
+)
+
+// publishSessionEvent notifies subscribers of the session and of its student group
+// that the session was opened, closed or canceled, and queues the matching webhook event.
+// Students of the group also get a push notification when the session opens.
+func publishSessionEvent(eventType models.AttendanceEventType, session *models.AttendanceSession, schedule *models.CourseSchedule) {
+	event := models.AttendanceEvent{
+		Type:             eventType,
+		SessionID:        session.ID,
+		CourseScheduleID: session.CourseScheduleID,
+		SessionStatus:    string(session.Status),
+		OccurredAt:       GetIndonesiaTime(),
+	}
+
+	if schedule != nil {
+		event.StudentGroupID = schedule.StudentGroupID
+		event.CourseCode = schedule.Course.Code
+		event.CourseName = schedule.Course.Name
+	}
+
+	publishAttendanceEvent(realtime.SessionTopic(session.ID), event)
+
+	if event.StudentGroupID > 0 {
+		publishAttendanceEvent(realtime.StudentGroupTopic(event.StudentGroupID), event)
+	}
+
+	dispatchWebhookEvent(string(event.Type), event)
+
+	if eventType == models.AttendanceEventSessionOpened {
+		dispatchSessionOpenedPush(session, schedule)
+	}
+}
+
+// publishStudentAttendanceEvent notifies subscribers of a session that a student's attendance changed
+// and queues the matching webhook event
+func publishStudentAttendanceEvent(eventType models.AttendanceEventType, session *models.AttendanceSession, student *models.Student, attendance *models.StudentAttendance) {
+	event := models.AttendanceEvent{
+		Type:               eventType,
+		SessionID:          session.ID,
+		CourseScheduleID:   session.CourseScheduleID,
+		StudentGroupID:     session.CourseSchedule.StudentGroupID,
+		SessionStatus:      string(session.Status),
+		Status:             string(attendance.Status),
+		VerificationMethod: attendance.VerificationMethod,
+		OccurredAt:         GetIndonesiaTime(),
+	}
+
+	if attendance.CheckInTime != nil {
+		event.CheckInTime = attendance.CheckInTime.In(getIndonesiaLocation()).Format("15:04:05")
+	}
+
+	if student != nil {
+		event.StudentID = uint(student.UserID)
+		event.StudentNIM = student.NIM
+		event.StudentName = student.FullName
+	}
+
+	publishAttendanceEvent(realtime.SessionTopic(session.ID), event)
+	dispatchWebhookEvent(string(event.Type), event)
+}
+
+// publishAttendanceEvent publishes an event without failing the calling operation
+func publishAttendanceEvent(topic string, event models.AttendanceEvent) {
+	if err := realtime.Publish(topic, string(event.Type), event); err != nil {
+		log.Printf("Failed to publish %s event for session %d: %v", event.Type, event.SessionID, err)
+	}
+}
@@ -0,0 +1,271 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/delpresence/backend/internal/models"
+	"github.com/delpresence/backend/internal/repositories"
+	"github.com/delpresence/backend/internal/utils"
+)
+
+// fraudVerificationMethod is recorded when a reviewer confirms a check-in was proxy attendance
+const fraudVerificationMethod = "MANUAL"
+
+// AttendanceFraudService looks for proxy attendance among students' own check-ins
+type AttendanceFraudService struct {
+	repo *repositories.AttendanceFraudRepository
+}
+
+// NewAttendanceFraudService creates a new attendance fraud service
+func NewAttendanceFraudService() *AttendanceFraudService {
+	return &AttendanceFraudService{
+		repo: repositories.NewAttendanceFraudRepository(),
+	}
+}
+
+// fraudThresholds are the limits of the fraud rules, read from the environment
+type fraudThresholds struct {
+	maxSpeedKMH     float64       // IMPOSSIBLE_TRAVEL: fastest plausible travel between check-ins
+	minTravelMeters float64       // IMPOSSIBLE_TRAVEL: distances below this are GPS noise
+	staleCodeGrace  time.Duration // STALE_CODE: how long after a rotation the old code is still fine
+}
+
+// loadFraudThresholds reads FRAUD_MAX_SPEED_KMH (default 80), FRAUD_MIN_TRAVEL_METERS (default
+// 1000) and FRAUD_STALE_CODE_GRACE_SECONDS (default 5)
+func loadFraudThresholds() fraudThresholds {
+	return fraudThresholds{
+		maxSpeedKMH:     float64(utils.GetEnvAsInt("FRAUD_MAX_SPEED_KMH", 80)),
+		minTravelMeters: float64(utils.GetEnvAsInt("FRAUD_MIN_TRAVEL_METERS", 1000)),
+		staleCodeGrace:  time.Duration(utils.GetEnvAsInt("FRAUD_STALE_CODE_GRACE_SECONDS", 5)) * time.Second,
+	}
+}
+
+// StartAnalysisWorker analyzes recent check-ins every FRAUD_ANALYSIS_INTERVAL_MINUTES (default 15),
+// looking back FRAUD_ANALYSIS_LOOKBACK_HOURS (default 24). Flags are deduplicated in the database,
+// so it is safe to run on every replica.
+func (s *AttendanceFraudService) StartAnalysisWorker() {
+	interval := time.Duration(utils.GetEnvAsInt("FRAUD_ANALYSIS_INTERVAL_MINUTES", 15)) * time.Minute
+	lookback := time.Duration(utils.GetEnvAsInt("FRAUD_ANALYSIS_LOOKBACK_HOURS", 24)) * time.Hour
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if _, err := s.AnalyzeSince(GetIndonesiaTime().Add(-lookback)); err != nil {
+				log.Printf("Attendance fraud analysis failed: %v", err)
+			}
+		}
+	}()
+
+	log.Printf("Attendance fraud analysis started (interval %s, lookback %s)", interval, lookback)
+}
+
+// AnalyzeSince flags suspicious check-ins made since a time and returns how many new flags it stored
+func (s *AttendanceFraudService) AnalyzeSince(since time.Time) (int, error) {
+	checkIns, err := s.repo.ListSelfCheckInsSince(since)
+	if err != nil {
+		return 0, err
+	}
+
+	created, err := s.repo.CreateFlags(detectFraud(checkIns, loadFraudThresholds()))
+	if err != nil {
+		return 0, err
+	}
+	if created > 0 {
+		log.Printf("Attendance fraud analysis flagged %d check-ins", created)
+	}
+	return int(created), nil
+}
+
+// detectFraud applies the fraud rules to check-ins ordered by check-in time
+func detectFraud(checkIns []models.StudentAttendance, thresholds fraudThresholds) []models.AttendanceFraudFlag {
+	var flags []models.AttendanceFraudFlag
+	flag := func(attendance models.StudentAttendance, rule models.AttendanceFraudRule, details string) {
+		flags = append(flags, models.AttendanceFraudFlag{
+			StudentAttendanceID: attendance.ID,
+			Rule:                rule,
+			AttendanceSessionID: attendance.AttendanceSessionID,
+			StudentID:           attendance.StudentID,
+			Details:             details,
+			Status:              models.FraudFlagOpen,
+		})
+	}
+
+	// Check-ins of different students in one session that share a device, instant or location
+	sameSession := func(rule models.AttendanceFraudRule, key func(models.StudentAttendance) string, describe func(key string, students int) string) {
+		groups := make(map[string][]models.StudentAttendance)
+		for _, checkIn := range checkIns {
+			if k := key(checkIn); k != "" {
+				groupKey := fmt.Sprintf("%d|%s", checkIn.AttendanceSessionID, k)
+				groups[groupKey] = append(groups[groupKey], checkIn)
+			}
+		}
+		for groupKey, group := range groups {
+			students := make(map[uint]bool)
+			for _, checkIn := range group {
+				students[checkIn.StudentID] = true
+			}
+			if len(students) < 2 {
+				continue
+			}
+			details := describe(groupKey[strings.Index(groupKey, "|")+1:], len(students))
+			for _, checkIn := range group {
+				flag(checkIn, rule, details)
+			}
+		}
+	}
+
+	sameSession(models.FraudRuleSharedDevice, func(a models.StudentAttendance) string {
+		return a.DeviceID
+	}, func(device string, students int) string {
+		return fmt.Sprintf("Device %s checked in %d students in this session", device, students)
+	})
+	sameSession(models.FraudRuleIdenticalTimestamp, func(a models.StudentAttendance) string {
+		return a.CheckInTime.UTC().Truncate(time.Millisecond).Format(time.RFC3339Nano)
+	}, func(at string, students int) string {
+		return fmt.Sprintf("%d students checked in at the same instant (%s)", students, at)
+	})
+	sameSession(models.FraudRuleIdenticalLocation, func(a models.StudentAttendance) string {
+		if a.Latitude == nil || a.Longitude == nil {
+			return ""
+		}
+		return fmt.Sprintf("%.6f,%.6f", *a.Latitude, *a.Longitude)
+	}, func(location string, students int) string {
+		return fmt.Sprintf("%d students reported the exact same location (%s)", students, location)
+	})
+
+	// Consecutive located check-ins of a student too far apart for the time between them
+	byStudent := make(map[uint][]models.StudentAttendance)
+	for _, checkIn := range checkIns {
+		if checkIn.Latitude != nil && checkIn.Longitude != nil {
+			byStudent[checkIn.StudentID] = append(byStudent[checkIn.StudentID], checkIn)
+		}
+	}
+	for _, located := range byStudent {
+		sort.SliceStable(located, func(i, j int) bool { return located[i].CheckInTime.Before(*located[j].CheckInTime) })
+		for i := 1; i < len(located); i++ {
+			previous, current := located[i-1], located[i]
+			if previous.AttendanceSessionID == current.AttendanceSessionID {
+				continue
+			}
+			distance := distanceMeters(*previous.Latitude, *previous.Longitude, *current.Latitude, *current.Longitude)
+			if distance < thresholds.minTravelMeters {
+				continue
+			}
+			elapsed := current.CheckInTime.Sub(*previous.CheckInTime)
+			if elapsed <= 0 || distance/1000/elapsed.Hours() > thresholds.maxSpeedKMH {
+				flag(current, models.FraudRuleImpossibleTravel, fmt.Sprintf(
+					"%.1f km from the check-in to session %d only %s earlier",
+					distance/1000, previous.AttendanceSessionID, elapsed.Round(time.Second)))
+			}
+		}
+	}
+
+	// Check-ins with a code that had already rotated, e.g. a photo of the QR code sent by a friend
+	for _, checkIn := range checkIns {
+		if checkIn.CodeExpiredAt == nil {
+			continue
+		}
+		if late := checkIn.CheckInTime.Sub(*checkIn.CodeExpiredAt); late > thresholds.staleCodeGrace {
+			flag(checkIn, models.FraudRuleStaleCode, fmt.Sprintf(
+				"Checked in %s after the %s code rotated", late.Round(time.Second), checkIn.VerificationMethod))
+		}
+	}
+
+	return flags
+}
+
+// ListFraudFlags lists the fraud flags of a session the user has access to, optionally by status
+func (s *AttendanceSessionService) ListFraudFlags(sessionID, userID uint, status string) ([]models.AttendanceFraudFlag, error) {
+	if _, err := s.authorize(AttendanceActionView, sessionID, userID); err != nil {
+		return nil, err
+	}
+	return repositories.NewAttendanceFraudRepository().ListBySession(sessionID, strings.ToUpper(status))
+}
+
+// ListFraudQueue lists the fraud flags of every session the user has access to, open flags when
+// no status is given
+func (s *AttendanceSessionService) ListFraudQueue(userID uint, status string) ([]models.AttendanceFraudFlag, error) {
+	if !s.policy.Allows(AttendanceActionView) {
+		return nil, ErrAttendanceActionNotAllowed
+	}
+	if status == "" {
+		status = string(models.FraudFlagOpen)
+	}
+
+	flags, err := repositories.NewAttendanceFraudRepository().ListByStatus(strings.ToUpper(status))
+	if err != nil {
+		return nil, err
+	}
+
+	access := make(map[uint]bool)
+	queue := make([]models.AttendanceFraudFlag, 0, len(flags))
+	for _, flag := range flags {
+		allowed, checked := access[flag.AttendanceSessionID]
+		if !checked {
+			_, err := s.loadSession(flag.AttendanceSessionID, userID)
+			allowed = err == nil
+			access[flag.AttendanceSessionID] = allowed
+		}
+		if allowed {
+			queue = append(queue, flag)
+		}
+	}
+	return queue, nil
+}
+
+// ReviewFraudFlag confirms or dismisses an open fraud flag. Confirming marks the student absent the
+// way a manual mark or correction would; either way every open flag of the check-in is resolved.
+func (s *AttendanceSessionService) ReviewFraudFlag(flagID, userID uint, confirm bool, notes string) (*models.AttendanceFraudFlag, error) {
+	if !s.policy.Allows(AttendanceActionMark) && !s.policy.Allows(AttendanceActionCorrect) {
+		return nil, ErrAttendanceActionNotAllowed
+	}
+
+	repo := repositories.NewAttendanceFraudRepository()
+	flag, err := repo.GetByID(flagID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.loadSession(flag.AttendanceSessionID, userID); err != nil {
+		return nil, err
+	}
+	if flag.Status != models.FraudFlagOpen {
+		return nil, fmt.Errorf("%w: only open flags can be reviewed", ErrInvalidOverride)
+	}
+
+	notes = strings.TrimSpace(notes)
+	status := models.FraudFlagDismissed
+	if confirm {
+		status = models.FraudFlagConfirmed
+		reason := fmt.Sprintf("Proxy attendance confirmed (%s)", flag.Rule)
+		if notes != "" {
+			reason += ": " + notes
+		}
+		err := s.MarkStudentAttendance(flag.AttendanceSessionID, flag.StudentID, models.StudentAttendanceStatusAbsent,
+			fraudVerificationMethod, reason, reason, userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	now := GetIndonesiaTime()
+	resolved, err := repo.ResolveOpenFlags(flag.StudentAttendanceID, status, userID, notes, now)
+	if err != nil {
+		return nil, err
+	}
+	if resolved == 0 {
+		return nil, errors.New("the flag was reviewed meanwhile")
+	}
+
+	flag.Status = status
+	flag.ReviewedByID = &userID
+	flag.ReviewedAt = &now
+	flag.ReviewNotes = notes
+	return flag, nil
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/delpresence/backend/internal/models"
+	"github.com/delpresence/backend/internal/utils"
+	"github.com/tealeg/xlsx/v3"
+)
+
+// matrixCodeStatuses maps matrix codes back to the statuses whose cell colors they share
+var matrixCodeStatuses = map[string]string{
+	"H": "PRESENT",
+	"T": "LATE",
+	"A": "ABSENT",
+	"I": "EXCUSED",
+}
+
+// MatrixFilename returns the download file name of a matrix export
+func MatrixFilename(matrices []models.AttendanceMatrix, extension string) string {
+	first := matrices[0]
+	parts := []string{"Matriks_Presensi", first.CourseCode}
+	if len(matrices) == 1 {
+		parts = append(parts, first.StudentGroupName)
+	}
+	parts = append(parts, first.AcademicYearName, first.Semester)
+	return reportFilename(extension, parts...)
+}
+
+// RenderMatrixXLSX renders attendance matrices as an Excel workbook with one sheet per schedule
+func RenderMatrixXLSX(matrices []models.AttendanceMatrix) ([]byte, error) {
+	file := xlsx.NewFile()
+	usedNames := make(map[string]bool)
+
+	for _, matrix := range matrices {
+		sheet, err := file.AddSheet(uniqueSheetName(fmt.Sprintf("%s %s", matrix.CourseCode, matrix.StudentGroupName), usedNames))
+		if err != nil {
+			return nil, err
+		}
+
+		titleCell := sheet.AddRow().AddCell()
+		titleCell.Value = "REKAP PRESENSI PERKULIAHAN"
+		titleCell.SetStyle(utils.NewXLSXTitleStyle())
+		sheet.AddRow()
+
+		info := [][2]string{
+			{"Mata Kuliah", fmt.Sprintf("%s - %s", matrix.CourseCode, matrix.CourseName)},
+			{"Kelas", matrix.StudentGroupName},
+			{"Dosen", matrix.LecturerName},
+			{"Jadwal", fmt.Sprintf("%s, %s - %s", matrix.Day, matrix.StartTime, matrix.EndTime)},
+			{"Tahun Akademik", fmt.Sprintf("%s %s", matrix.AcademicYearName, matrix.Semester)},
+		}
+		for _, item := range info {
+			row := sheet.AddRow()
+			row.AddCell().Value = item[0]
+			row.AddCell().Value = item[1]
+		}
+		sheet.AddRow()
+
+		// Meeting numbers with their dates on a second header row
+		headerStyle := utils.NewXLSXHeaderStyle()
+		numberRow := sheet.AddRow()
+		dateRow := sheet.AddRow()
+		for _, header := range []string{"No", "NIM", "Nama Mahasiswa"} {
+			addStyledString(numberRow, header, headerStyle)
+			addStyledString(dateRow, "", headerStyle)
+		}
+		for _, meeting := range matrix.Meetings {
+			addStyledInt(numberRow, meeting.Number, headerStyle)
+			addStyledString(dateRow, shortDate(meeting.Date), headerStyle)
+		}
+		for _, header := range []string{"H", "T", "A", "I", "Persentase"} {
+			addStyledString(numberRow, header, headerStyle)
+			addStyledString(dateRow, "", headerStyle)
+		}
+
+		dataStyle := utils.NewXLSXCellStyle()
+		codeStyles := make(map[string]*xlsx.Style)
+		for code, status := range matrixCodeStatuses {
+			codeStyles[code] = utils.NewXLSXStatusStyle(status)
+		}
+		emptyStyle := utils.NewXLSXCellStyle()
+		emptyStyle.Alignment.Horizontal = "center"
+
+		for i, student := range matrix.Rows {
+			row := sheet.AddRow()
+			addStyledInt(row, i+1, dataStyle)
+			addStyledString(row, student.NIM, dataStyle)
+			addStyledString(row, student.FullName, dataStyle)
+			for _, code := range student.Codes {
+				style, ok := codeStyles[code]
+				if !ok {
+					style = emptyStyle
+				}
+				addStyledString(row, code, style)
+			}
+			addStyledInt(row, student.Present, dataStyle)
+			addStyledInt(row, student.Late, dataStyle)
+			addStyledInt(row, student.Absent, dataStyle)
+			addStyledInt(row, student.Excused, dataStyle)
+			addStyledString(row, formatPercent(student.AttendanceRate), dataStyle)
+		}
+
+		sheet.AddRow()
+		sheet.AddRow().AddCell().Value = "Keterangan: H = Hadir, T = Terlambat, A = Alpa, I = Izin, - = Tidak ada catatan"
+
+		sheet.SetColWidth(1, 1, 5)
+		sheet.SetColWidth(2, 2, 15)
+		sheet.SetColWidth(3, 3, 30)
+		if len(matrix.Meetings) > 0 {
+			sheet.SetColWidth(4, 3+len(matrix.Meetings), 6)
+		}
+		sheet.SetColWidth(4+len(matrix.Meetings), 7+len(matrix.Meetings), 5)
+		sheet.SetColWidth(8+len(matrix.Meetings), 8+len(matrix.Meetings), 12)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := file.Write(buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// RenderMatrixCSV renders attendance matrices as one CSV table with a row per student and
+// schedule, padding the meeting columns to the schedule with the most meetings
+func RenderMatrixCSV(matrices []models.AttendanceMatrix) ([]byte, error) {
+	meetings := 0
+	for _, matrix := range matrices {
+		if len(matrix.Meetings) > meetings {
+			meetings = len(matrix.Meetings)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	writer := csv.NewWriter(buf)
+
+	header := []string{"kode_mk", "nama_mk", "kelas", "nim", "nama"}
+	for i := 1; i <= meetings; i++ {
+		header = append(header, fmt.Sprintf("p%d", i))
+	}
+	header = append(header, "hadir", "terlambat", "alpa", "izin", "persentase")
+	if err := writer.Write(header); err != nil {
+		return nil, err
+	}
+
+	for _, matrix := range matrices {
+		for _, student := range matrix.Rows {
+			record := []string{matrix.CourseCode, matrix.CourseName, matrix.StudentGroupName, student.NIM, student.FullName}
+			for i := 0; i < meetings; i++ {
+				if i < len(student.Codes) {
+					record = append(record, student.Codes[i])
+				} else {
+					record = append(record, "")
+				}
+			}
+			record = append(record,
+				fmt.Sprint(student.Present), fmt.Sprint(student.Late), fmt.Sprint(student.Absent),
+				fmt.Sprint(student.Excused), fmt.Sprintf("%.2f", student.AttendanceRate))
+			if err := writer.Write(record); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// uniqueSheetName makes a valid worksheet name (at most 31 characters, no []:*?/\) that is not used yet
+func uniqueSheetName(name string, used map[string]bool) string {
+	name = strings.NewReplacer("[", "", "]", "", ":", "", "*", "", "?", "", "/", "-", "\\", "-").Replace(name)
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = "Sheet"
+	}
+	if len([]rune(name)) > 31 {
+		name = string([]rune(name)[:31])
+	}
+
+	candidate := name
+	for i := 2; used[candidate]; i++ {
+		suffix := fmt.Sprintf(" (%d)", i)
+		runes := []rune(name)
+		if len(runes)+len(suffix) > 31 {
+			runes = runes[:31-len(suffix)]
+		}
+		candidate = string(runes) + suffix
+	}
+	used[candidate] = true
+	return candidate
+}
+
+// shortDate formats a YYYY-MM-DD date as DD/MM for narrow columns
+func shortDate(date string) string {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return date
+	}
+	return t.Format("02/01")
+}
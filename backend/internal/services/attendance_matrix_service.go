@@ -0,0 +1,221 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/delpresence/backend/internal/models"
+	"github.com/delpresence/backend/internal/repositories"
+)
+
+// ErrScheduleForbidden is returned when a user may not see a schedule's attendance documents
+var ErrScheduleForbidden = errors.New("you do not have access to this course schedule")
+
+// AttendanceMatrixService builds semester attendance matrices (students by meetings)
+type AttendanceMatrixService struct {
+	statsRepo        *repositories.AttendanceStatisticsRepository
+	scheduleRepo     *repositories.CourseScheduleRepository
+	lecturerRepo     *repositories.LecturerRepository
+	assistantRepo    *repositories.TeachingAssistantAssignmentRepository
+	academicYearRepo *repositories.AcademicYearRepository
+	policy           EligibilityPolicy
+}
+
+// NewAttendanceMatrixService creates a new attendance matrix service
+func NewAttendanceMatrixService() *AttendanceMatrixService {
+	return &AttendanceMatrixService{
+		statsRepo:        repositories.NewAttendanceStatisticsRepository(),
+		scheduleRepo:     repositories.NewCourseScheduleRepository(),
+		lecturerRepo:     repositories.NewLecturerRepository(),
+		assistantRepo:    repositories.NewTeachingAssistantAssignmentRepository(),
+		academicYearRepo: repositories.NewAcademicYearRepository(),
+		policy:           LoadEligibilityPolicy(),
+	}
+}
+
+// GetScheduleMatrix returns the matrix of one course schedule
+func (s *AttendanceMatrixService) GetScheduleMatrix(scheduleID uint, role string, userID uint) (*models.AttendanceMatrix, error) {
+	schedule, err := s.scheduleRepo.GetByID(scheduleID)
+	if err != nil {
+		return nil, errors.New("course schedule not found")
+	}
+
+	allowed, err := s.canAccess(schedule, role, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrScheduleForbidden
+	}
+
+	matrices, err := s.buildMatrices([]models.CourseSchedule{schedule})
+	if err != nil {
+		return nil, err
+	}
+	return &matrices[0], nil
+}
+
+// GetCourseMatrices returns one matrix per schedule of a course in an academic year that the user
+// can access. The academic year defaults to the active one.
+func (s *AttendanceMatrixService) GetCourseMatrices(courseID, academicYearID uint, role string, userID uint) ([]models.AttendanceMatrix, error) {
+	academicYear, err := resolveAcademicYear(s.academicYearRepo, academicYearID)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules, err := s.scheduleRepo.GetByCourseAndAcademicYear(courseID, academicYear.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(schedules) == 0 {
+		return nil, errors.New("course has no schedules in this academic year")
+	}
+
+	var accessible []models.CourseSchedule
+	for _, schedule := range schedules {
+		allowed, err := s.canAccess(schedule, role, userID)
+		if err != nil {
+			return nil, err
+		}
+		if allowed {
+			accessible = append(accessible, schedule)
+		}
+	}
+	if len(accessible) == 0 {
+		return nil, ErrScheduleForbidden
+	}
+
+	return s.buildMatrices(accessible)
+}
+
+// canAccess reports whether a user may export a schedule
+func (s *AttendanceMatrixService) canAccess(schedule models.CourseSchedule, role string, userID uint) (bool, error) {
+	return canAccessSchedule(s.scheduleRepo, s.assistantRepo, schedule, role, userID)
+}
+
+// canAccessSchedule reports whether a user may see a schedule's attendance documents: admins always,
+// lecturers for schedules they teach (alone or in a teaching team) and teaching assistants for
+// courses they assist
+func canAccessSchedule(scheduleRepo *repositories.CourseScheduleRepository, assistantRepo *repositories.TeachingAssistantAssignmentRepository, schedule models.CourseSchedule, role string, userID uint) (bool, error) {
+	switch strings.ToLower(role) {
+	case "admin":
+		return true, nil
+	case "dosen":
+		return scheduleRepo.IsTaughtBy(schedule, userID)
+	case "asisten dosen":
+		return assistantRepo.AssignmentExistsForCourse(int(userID), schedule.CourseID)
+	default:
+		return false, nil
+	}
+}
+
+// buildMatrices builds the matrices of the schedules with a constant number of queries
+func (s *AttendanceMatrixService) buildMatrices(schedules []models.CourseSchedule) ([]models.AttendanceMatrix, error) {
+	scheduleIDs := make([]uint, len(schedules))
+	for i, schedule := range schedules {
+		scheduleIDs[i] = schedule.ID
+	}
+
+	sessions, err := s.statsRepo.GetMatrixSessions(scheduleIDs)
+	if err != nil {
+		return nil, err
+	}
+	students, err := s.statsRepo.GetMatrixStudents(scheduleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionIDs := make([]uint, len(sessions))
+	for i, session := range sessions {
+		sessionIDs[i] = session.ID
+	}
+	records, err := s.statsRepo.GetMatrixRecords(sessionIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	type recordKey struct{ sessionID, studentID uint }
+	statuses := make(map[recordKey]models.StudentAttendanceStatus, len(records))
+	for _, record := range records {
+		statuses[recordKey{record.SessionID, record.StudentID}] = record.Status
+	}
+
+	sessionsBySchedule := make(map[uint][]repositories.MatrixSession)
+	for _, session := range sessions {
+		sessionsBySchedule[session.CourseScheduleID] = append(sessionsBySchedule[session.CourseScheduleID], session)
+	}
+	studentsBySchedule := make(map[uint][]repositories.MatrixStudent)
+	for _, student := range students {
+		studentsBySchedule[student.CourseScheduleID] = append(studentsBySchedule[student.CourseScheduleID], student)
+	}
+
+	lecturerNames := make(map[uint]string)
+	matrices := make([]models.AttendanceMatrix, 0, len(schedules))
+	for _, schedule := range schedules {
+		if _, ok := lecturerNames[schedule.UserID]; !ok {
+			lecturer, err := s.lecturerRepo.GetByUserID(int(schedule.UserID))
+			if err != nil {
+				return nil, err
+			}
+			lecturerNames[schedule.UserID] = lecturer.FullName
+		}
+
+		matrix := models.AttendanceMatrix{
+			CourseScheduleID: schedule.ID,
+			CourseID:         schedule.CourseID,
+			CourseCode:       schedule.Course.Code,
+			CourseName:       schedule.Course.Name,
+			StudentGroupName: schedule.StudentGroup.Name,
+			LecturerName:     lecturerNames[schedule.UserID],
+			Day:              schedule.Day,
+			StartTime:        schedule.StartTime,
+			EndTime:          schedule.EndTime,
+			AcademicYearName: schedule.AcademicYear.Name,
+			Semester:         schedule.AcademicYear.Semester,
+		}
+
+		scheduleSessions := sessionsBySchedule[schedule.ID]
+		for i, session := range scheduleSessions {
+			matrix.Meetings = append(matrix.Meetings, models.AttendanceMatrixMeeting{
+				Number:    i + 1,
+				SessionID: session.ID,
+				Date:      session.Date.Format("2006-01-02"),
+				StartTime: session.StartTime.In(getIndonesiaLocation()).Format("15:04"),
+				Status:    string(session.Status),
+			})
+		}
+
+		for _, student := range studentsBySchedule[schedule.ID] {
+			row := models.AttendanceMatrixRow{
+				StudentID: student.StudentID,
+				NIM:       student.NIM,
+				FullName:  student.FullName,
+				Codes:     make([]string, len(scheduleSessions)),
+			}
+			for i, session := range scheduleSessions {
+				status, ok := statuses[recordKey{session.ID, student.StudentID}]
+				row.Codes[i] = models.AttendanceStatusCode(status)
+				if !ok {
+					continue
+				}
+				switch status {
+				case models.StudentAttendanceStatusPresent:
+					row.Present++
+				case models.StudentAttendanceStatusLate:
+					row.Late++
+				case models.StudentAttendanceStatusAbsent:
+					row.Absent++
+				case models.StudentAttendanceStatusExcused:
+					row.Excused++
+				}
+			}
+			row.AttendanceRate = s.policy.Rate(row.Present, row.Late, row.Excused,
+				row.Present+row.Late+row.Absent+row.Excused)
+			matrix.Rows = append(matrix.Rows, row)
+		}
+
+		matrices = append(matrices, matrix)
+	}
+
+	return matrices, nil
+}
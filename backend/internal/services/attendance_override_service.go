@@ -0,0 +1,494 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/delpresence/backend/internal/database"
+	"github.com/delpresence/backend/internal/models"
+	"github.com/delpresence/backend/internal/repositories"
+	"gorm.io/gorm"
+)
+
+// maxExcuseRangeDays limits how many days one group excuse may cover
+const maxExcuseRangeDays = 31
+
+var (
+	// ErrOverrideReasonRequired is returned when an admin override has no reason
+	ErrOverrideReasonRequired = errors.New("a reason is required for attendance overrides")
+
+	// ErrInvalidOverride is returned when an override request is malformed or does not apply
+	ErrInvalidOverride = errors.New("invalid attendance override")
+)
+
+// RetroactiveSessionInput describes a session that took place without being opened in the app
+type RetroactiveSessionInput struct {
+	CourseScheduleID uint                  `json:"course_schedule_id" binding:"required"`
+	LecturerID       uint                  `json:"lecturer_id"` // External user ID; defaults to the schedule's lecturer
+	Date             string                `json:"date" binding:"required"`
+	StartTime        string                `json:"start_time"` // HH:MM; defaults to the schedule's start time
+	EndTime          string                `json:"end_time"`   // HH:MM; defaults to the schedule's end time
+	Type             models.AttendanceType `json:"type"`       // Defaults to MANUAL
+	Notes            string                `json:"notes"`
+	Reason           string                `json:"reason"`
+}
+
+// GroupExcuseInput excuses the absences of a student group, e.g. for a campus event
+type GroupExcuseInput struct {
+	StudentGroupID   uint   `json:"student_group_id" binding:"required"`
+	StartDate        string `json:"start_date" binding:"required"`
+	EndDate          string `json:"end_date"`           // Defaults to StartDate
+	CourseScheduleID uint   `json:"course_schedule_id"` // Limits the excuse to one schedule
+	Reason           string `json:"reason"`
+}
+
+// GroupExcuseResult summarizes a group excuse
+type GroupExcuseResult struct {
+	StudentGroupID uint `json:"student_group_id"`
+	Students       int  `json:"students"`
+	Sessions       int  `json:"sessions"`
+	Excused        int  `json:"excused"` // Records changed from ABSENT to EXCUSED
+}
+
+// AttendanceOverrideService lets admins change attendance outside the normal session flow.
+// Every change needs a reason and is recorded as an AttendanceOverride in the same transaction.
+type AttendanceOverrideService struct {
+	attendanceService *AttendanceService
+	attendanceRepo    *repositories.AttendanceRepository
+	scheduleRepo      *repositories.CourseScheduleRepository
+	studentRepo       *repositories.StudentRepository
+	groupRepo         *repositories.StudentGroupRepository
+	overrideRepo      *repositories.AttendanceOverrideRepository
+	db                *gorm.DB
+}
+
+// NewAttendanceOverrideService creates a new attendance override service
+func NewAttendanceOverrideService() *AttendanceOverrideService {
+	return &AttendanceOverrideService{
+		attendanceService: NewAttendanceService(),
+		attendanceRepo:    repositories.NewAttendanceRepository(),
+		scheduleRepo:      repositories.NewCourseScheduleRepository(),
+		studentRepo:       repositories.NewStudentRepository(),
+		groupRepo:         repositories.NewStudentGroupRepository(),
+		overrideRepo:      repositories.NewAttendanceOverrideRepository(),
+		db:                database.GetDB(),
+	}
+}
+
+// ListOverrides lists the audit trail of admin overrides
+func (s *AttendanceOverrideService) ListOverrides(filter repositories.AttendanceOverrideFilter) ([]models.AttendanceOverride, error) {
+	return s.overrideRepo.List(filter)
+}
+
+// CreateRetroactiveSession records a closed session for a meeting the lecturer held but did not
+// open in the app. All students of the group start as ABSENT and can then be corrected.
+func (s *AttendanceOverrideService) CreateRetroactiveSession(input RetroactiveSessionInput, adminID uint) (*models.AttendanceSessionResponse, error) {
+	reason, err := overrideReason(input.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule, err := s.scheduleRepo.GetByID(input.CourseScheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	date, err := time.Parse("2006-01-02", input.Date)
+	if err != nil {
+		return nil, fmt.Errorf("%w: date must be YYYY-MM-DD", ErrInvalidOverride)
+	}
+	if date.Format("2006-01-02") > GetIndonesiaTime().Format("2006-01-02") {
+		return nil, fmt.Errorf("%w: retroactive sessions cannot be dated in the future", ErrInvalidOverride)
+	}
+
+	startTime, err := sessionClockTime(date, input.StartTime, schedule.StartTime)
+	if err != nil {
+		return nil, err
+	}
+	endTime, err := sessionClockTime(date, input.EndTime, schedule.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	if !endTime.After(startTime) {
+		return nil, fmt.Errorf("%w: end_time must be after start_time", ErrInvalidOverride)
+	}
+
+	attendanceType := input.Type
+	if attendanceType == "" {
+		attendanceType = models.AttendanceTypeManual
+	}
+	switch attendanceType {
+	case models.AttendanceTypeManual, models.AttendanceTypeQRCode, models.AttendanceTypeFaceRecognition, models.AttendanceTypeBoth:
+	default:
+		return nil, fmt.Errorf("%w: unknown attendance type %q", ErrInvalidOverride, attendanceType)
+	}
+
+	exists, err := s.attendanceRepo.SessionExistsForScheduleOnDate(schedule.ID, date)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, fmt.Errorf("%w: the schedule already has a session on %s", ErrInvalidOverride, input.Date)
+	}
+
+	lecturerID := input.LecturerID
+	if lecturerID == 0 {
+		lecturerID = schedule.UserID
+	}
+
+	session := &models.AttendanceSession{
+		CourseScheduleID: schedule.ID,
+		LecturerID:       lecturerID,
+		CreatorRole:      "ADMIN",
+		Date:             date,
+		StartTime:        startTime,
+		EndTime:          &endTime,
+		FirstClosedAt:    &endTime,
+		Type:             attendanceType,
+		Status:           models.AttendanceStatusClosed,
+		AutoClose:        false,
+		Duration:         int(endTime.Sub(startTime).Minutes()),
+		AllowLate:        false,
+		Notes:            input.Notes,
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("CourseSchedule", "Lecturer").Create(session).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.AttendanceOverride{
+			Action:              models.AttendanceOverrideCreateSession,
+			AttendanceSessionID: session.ID,
+			NewValue:            string(session.Status),
+			Reason:              reason,
+			PerformedByID:       adminID,
+			PerformedByRole:     string(AttendanceActorAdmin),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.attendanceService.initializeStudentAttendances(session.ID, schedule.ID); err != nil {
+		fmt.Printf("Error initializing student attendances: %v\n", err)
+	}
+
+	created, err := s.attendanceRepo.GetAttendanceSessionByID(session.ID)
+	if err != nil {
+		return nil, err
+	}
+	return s.attendanceService.mapSessionToResponse(created)
+}
+
+// ReopenSession makes a closed session active again so students can still check in
+func (s *AttendanceOverrideService) ReopenSession(sessionID uint, reason string, adminID uint) (*models.AttendanceSessionResponse, error) {
+	session, err := s.attendanceRepo.GetAttendanceSessionByID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != models.AttendanceStatusClosed {
+		return nil, fmt.Errorf("%w: only closed sessions can be reopened", ErrInvalidOverride)
+	}
+
+	active, err := s.attendanceRepo.GetActiveSessionsForSchedule(session.CourseScheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if len(active) > 0 {
+		return nil, fmt.Errorf("%w: the schedule already has an active session", ErrInvalidOverride)
+	}
+
+	recordFirstClose(session)
+	session.EndTime = nil
+	if err := saveSessionStatusOverride(s.db, session, models.AttendanceStatusActive, models.AttendanceOverrideReopenSession, reason, adminID, AttendanceActorAdmin); err != nil {
+		return nil, err
+	}
+
+	publishSessionEvent(models.AttendanceEventSessionOpened, session, &session.CourseSchedule)
+	return s.attendanceService.mapSessionToResponse(session)
+}
+
+// CloseSession closes an active session
+func (s *AttendanceOverrideService) CloseSession(sessionID uint, reason string, adminID uint) (*models.AttendanceSessionResponse, error) {
+	session, err := s.attendanceRepo.GetAttendanceSessionByID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != models.AttendanceStatusActive {
+		return nil, fmt.Errorf("%w: attendance session is not active", ErrInvalidOverride)
+	}
+
+	now := GetIndonesiaTime()
+	session.EndTime = &now
+	session.ReopenedUntil = nil
+	recordFirstClose(session)
+	session.ClosedByID = &adminID
+	session.ClosedByRole = "ADMIN"
+	if err := saveSessionStatusOverride(s.db, session, models.AttendanceStatusClosed, models.AttendanceOverrideCloseSession, reason, adminID, AttendanceActorAdmin); err != nil {
+		return nil, err
+	}
+
+	publishSessionEvent(models.AttendanceEventSessionClosed, session, &session.CourseSchedule)
+	return s.attendanceService.mapSessionToResponse(session)
+}
+
+// CancelSession cancels an active or closed session, removing it from statistics and recaps
+func (s *AttendanceOverrideService) CancelSession(sessionID uint, reason string, adminID uint) (*models.AttendanceSessionResponse, error) {
+	session, err := s.attendanceRepo.GetAttendanceSessionByID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status == models.AttendanceStatusCanceled {
+		return nil, fmt.Errorf("%w: attendance session is already canceled", ErrInvalidOverride)
+	}
+
+	session.ReopenedUntil = nil
+
+	if err := saveSessionStatusOverride(s.db, session, models.AttendanceStatusCanceled, models.AttendanceOverrideCancelSession, reason, adminID, AttendanceActorAdmin); err != nil {
+		return nil, err
+	}
+
+	publishSessionEvent(models.AttendanceEventSessionCanceled, session, &session.CourseSchedule)
+	return s.attendanceService.mapSessionToResponse(session)
+}
+
+// SetStudentStatus corrects a student's status in an active or closed session. The student is
+// identified by the external user ID, as in the session's student list.
+func (s *AttendanceOverrideService) SetStudentStatus(sessionID, studentUserID uint, status models.StudentAttendanceStatus, reason string, adminID uint) (*models.StudentAttendance, error) {
+	session, err := s.attendanceRepo.GetAttendanceSessionByID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status == models.AttendanceStatusCanceled {
+		return nil, fmt.Errorf("%w: attendance session is canceled", ErrInvalidOverride)
+	}
+
+	student, err := findSessionStudent(s.db, s.studentRepo, session, studentUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	attendance, err := saveStudentStatusOverride(s.db, s.attendanceRepo, session, student, status, reason, adminID, AttendanceActorAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	publishStudentAttendanceEvent(models.AttendanceEventStudentStatusChanged, session, student, attendance)
+	return attendance, nil
+}
+
+// ExcuseGroup marks every absence of a student group between two dates as EXCUSED. Students who
+// attended keep their status.
+func (s *AttendanceOverrideService) ExcuseGroup(input GroupExcuseInput, adminID uint) (*GroupExcuseResult, error) {
+	reason, err := overrideReason(input.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	startDate, err := time.Parse("2006-01-02", input.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: start_date must be YYYY-MM-DD", ErrInvalidOverride)
+	}
+	endDate := startDate
+	if input.EndDate != "" {
+		if endDate, err = time.Parse("2006-01-02", input.EndDate); err != nil {
+			return nil, fmt.Errorf("%w: end_date must be YYYY-MM-DD", ErrInvalidOverride)
+		}
+	}
+	if endDate.Before(startDate) {
+		return nil, fmt.Errorf("%w: end_date must not be before start_date", ErrInvalidOverride)
+	}
+	if endDate.Sub(startDate) >= maxExcuseRangeDays*24*time.Hour {
+		return nil, fmt.Errorf("%w: a group excuse can cover at most %d days", ErrInvalidOverride, maxExcuseRangeDays)
+	}
+
+	if _, err := s.groupRepo.GetByID(input.StudentGroupID); err != nil {
+		return nil, err
+	}
+	members, err := s.groupRepo.GetGroupMembers(input.StudentGroupID)
+	if err != nil {
+		return nil, err
+	}
+	studentIDs := make([]uint, 0, len(members))
+	for _, member := range members {
+		studentIDs = append(studentIDs, member.ID)
+	}
+
+	absences, err := s.attendanceRepo.ListAbsencesInDateRange(studentIDs, startDate, endDate, input.CourseScheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	groupID := input.StudentGroupID
+	sessions := make(map[uint]bool)
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		for i := range absences {
+			attendance := &absences[i]
+			attendance.Status = models.StudentAttendanceStatusExcused
+			attendance.Notes = reason
+			attendance.VerifiedByID = &adminID
+			if err := tx.Omit("AttendanceSession", "Student").Save(attendance).Error; err != nil {
+				return err
+			}
+			if err := tx.Create(&models.AttendanceOverride{
+				Action:              models.AttendanceOverrideExcuseGroup,
+				AttendanceSessionID: attendance.AttendanceSessionID,
+				StudentID:           &attendance.StudentID,
+				PreviousValue:       string(models.StudentAttendanceStatusAbsent),
+				NewValue:            string(models.StudentAttendanceStatusExcused),
+				Reason:              reason,
+				StudentGroupID:      &groupID,
+				PerformedByID:       adminID,
+				PerformedByRole:     string(AttendanceActorAdmin),
+			}).Error; err != nil {
+				return err
+			}
+			sessions[attendance.AttendanceSessionID] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range absences {
+		attendance := &absences[i]
+		publishStudentAttendanceEvent(models.AttendanceEventStudentStatusChanged, &attendance.AttendanceSession, &attendance.Student, attendance)
+	}
+
+	return &GroupExcuseResult{
+		StudentGroupID: groupID,
+		Students:       len(members),
+		Sessions:       len(sessions),
+		Excused:        len(absences),
+	}, nil
+}
+
+// saveSessionStatusOverride saves a session with a new status and records the change
+func saveSessionStatusOverride(db *gorm.DB, session *models.AttendanceSession, status models.AttendanceStatus, action models.AttendanceOverrideAction, reason string, userID uint, actor AttendanceActor) error {
+	reason, err := overrideReason(reason)
+	if err != nil {
+		return err
+	}
+
+	previous := session.Status
+	session.Status = status
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("CourseSchedule", "Lecturer").Save(session).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.AttendanceOverride{
+			Action:              action,
+			AttendanceSessionID: session.ID,
+			PreviousValue:       string(previous),
+			NewValue:            string(status),
+			Reason:              reason,
+			PerformedByID:       userID,
+			PerformedByRole:     string(actor),
+		}).Error
+	})
+}
+
+// findSessionStudent finds a student of a session by external user ID: a member of the session's
+// student group, or a student who already has a record in the session
+func findSessionStudent(db *gorm.DB, studentRepo *repositories.StudentRepository, session *models.AttendanceSession, externalUserID uint) (*models.Student, error) {
+	student, err := studentRepo.FindByUserID(int(externalUserID))
+	if err != nil {
+		return nil, err
+	}
+	if student == nil {
+		return nil, fmt.Errorf("student not found: %w", gorm.ErrRecordNotFound)
+	}
+	if err := checkSessionStudent(db, session, student); err != nil {
+		return nil, err
+	}
+	return student, nil
+}
+
+// checkSessionStudent checks that a student belongs to a session: a member of the session's
+// student group, or a student who already has a record in the session
+func checkSessionStudent(db *gorm.DB, session *models.AttendanceSession, student *models.Student) error {
+	var inSession bool
+	if err := db.Raw(`
+		SELECT EXISTS (
+			SELECT 1 FROM student_to_groups WHERE student_group_id = ? AND student_id = ?
+		) OR EXISTS (
+			SELECT 1 FROM student_attendances WHERE attendance_session_id = ? AND student_id = ?
+		)`,
+		session.CourseSchedule.StudentGroupID, student.ID, session.ID, student.ID).Scan(&inSession).Error; err != nil {
+		return err
+	}
+	if !inSession {
+		return fmt.Errorf("%w: the student is not in the session's student group", ErrInvalidOverride)
+	}
+	return nil
+}
+
+// saveStudentStatusOverride sets a student's status in a session outside the check-in flow and
+// records the change
+func saveStudentStatusOverride(db *gorm.DB, attendanceRepo *repositories.AttendanceRepository, session *models.AttendanceSession, student *models.Student, status models.StudentAttendanceStatus, reason string, userID uint, actor AttendanceActor) (*models.StudentAttendance, error) {
+	reason, err := overrideReason(reason)
+	if err != nil {
+		return nil, err
+	}
+
+	attendance, err := attendanceRepo.GetStudentAttendance(session.ID, student.ID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		attendance = &models.StudentAttendance{
+			AttendanceSessionID: session.ID,
+			StudentID:           student.ID,
+		}
+	}
+
+	previous := string(attendance.Status)
+	attendance.Status = status
+	attendance.Notes = reason
+	attendance.VerifiedByID = &userID
+	if (status == models.StudentAttendanceStatusPresent || status == models.StudentAttendanceStatusLate) && attendance.VerificationMethod == "" {
+		attendance.VerificationMethod = "MANUAL"
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("AttendanceSession", "Student").Save(attendance).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.AttendanceOverride{
+			Action:              models.AttendanceOverrideSetStatus,
+			AttendanceSessionID: session.ID,
+			StudentID:           &student.ID,
+			PreviousValue:       previous,
+			NewValue:            string(status),
+			Reason:              reason,
+			PerformedByID:       userID,
+			PerformedByRole:     string(actor),
+		}).Error
+	})
+	return attendance, err
+}
+
+// overrideReason trims the reason of an override and checks that it is present
+func overrideReason(reason string) (string, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return "", ErrOverrideReasonRequired
+	}
+	return reason, nil
+}
+
+// sessionClockTime combines a session date with a HH:MM time, falling back to the schedule's time
+func sessionClockTime(date time.Time, value, fallback string) (time.Time, error) {
+	if value == "" {
+		value = fallback
+	}
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if clock, err := time.Parse(layout, value); err == nil {
+			return time.Date(date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), clock.Second(), 0, getIndonesiaLocation()), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: invalid time %q, expected HH:MM", ErrInvalidOverride, value)
+}
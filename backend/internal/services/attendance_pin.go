@@ -0,0 +1,234 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/delpresence/backend/internal/models"
+	"github.com/delpresence/backend/internal/utils"
+)
+
+const (
+	// pinDigits is the length of the code of PIN sessions
+	pinDigits = 6
+
+	// pinModulo keeps pinDigits digits of a derived code
+	pinModulo = 1000000
+
+	// pinRotation is how long a code of a PIN session is shown before the next one
+	pinRotation = time.Minute
+
+	// defaultGeofenceRadius is the geofence radius in meters when a session sets none
+	defaultGeofenceRadius = 100
+)
+
+var (
+	// ErrInvalidPIN is returned when a student submits a wrong or expired code
+	ErrInvalidPIN = errors.New("invalid attendance code")
+
+	// ErrPINAttemptsExceeded is returned when a student submitted too many wrong codes recently
+	ErrPINAttemptsExceeded = errors.New("too many wrong attendance codes, try again later")
+
+	// ErrInvalidGeofence is returned when the geofence settings of a new session are invalid
+	ErrInvalidGeofence = errors.New("invalid geofence")
+
+	// ErrLocationRequired is returned when a geofenced check-in carries no location
+	ErrLocationRequired = errors.New("your location is required to check in to this session")
+
+	// ErrOutsideGeofence is returned when a geofenced check-in is too far from the classroom
+	ErrOutsideGeofence = errors.New("you are too far from the classroom to check in")
+)
+
+// SubmitPINAttendance records a student's check-in to a PIN session with the code the lecturer
+// read aloud. The code of the previous minute is still accepted. Wrong codes are counted, and a
+// student who enters PIN_MAX_ATTEMPTS (default 5) wrong codes within PIN_LOCKOUT_MINUTES (default 5)
+// must wait. Geofenced sessions also require the student's location.
+func (s *AttendanceService) SubmitPINAttendance(sessionID, externalUserID uint, pin string, meta CheckInMetadata) error {
+	session, err := s.attendanceRepo.GetAttendanceSessionByID(sessionID)
+	if err != nil {
+		return errors.New("attendance session not found")
+	}
+	if session.Status != models.AttendanceStatusActive {
+		return errors.New("attendance session is not active")
+	}
+	if session.Type != models.AttendanceTypePIN {
+		return errors.New("this attendance session does not support PIN verification")
+	}
+
+	student, err := s.findEnrolledStudent(session, externalUserID)
+	if err != nil {
+		return err
+	}
+
+	if session.GeofenceRadius > 0 {
+		if meta.Latitude == nil || meta.Longitude == nil {
+			return ErrLocationRequired
+		}
+		distance := distanceMeters(*session.GeofenceLatitude, *session.GeofenceLongitude, *meta.Latitude, *meta.Longitude)
+		if distance > float64(session.GeofenceRadius) {
+			return fmt.Errorf("%w (%.0f m away, at most %d m allowed)", ErrOutsideGeofence, distance, session.GeofenceRadius)
+		}
+	}
+
+	valid, codeExpiredAt := verifySessionPIN(session.PINSecret, pin, GetIndonesiaTime())
+
+	// A locked-out student is refused even with the right code
+	maxAttempts := utils.GetEnvAsInt("PIN_MAX_ATTEMPTS", 5)
+	lockout := time.Duration(utils.GetEnvAsInt("PIN_LOCKOUT_MINUTES", 5)) * time.Minute
+	attempts, err := s.attendanceRepo.CheckPINAttempt(session.ID, student.ID, time.Now().Add(-lockout), maxAttempts, !valid)
+	if err != nil {
+		return err
+	}
+	if attempts >= int64(maxAttempts) {
+		return ErrPINAttemptsExceeded
+	}
+
+	if !valid {
+		remaining := maxAttempts - int(attempts) - 1
+		if remaining < 0 {
+			remaining = 0
+		}
+		return fmt.Errorf("%w (%d attempts left)", ErrInvalidPIN, remaining)
+	}
+
+	meta.CodeExpiredAt = codeExpiredAt
+	return s.recordCheckIn(session, student, models.StudentAttendanceStatusPresent, string(models.AttendanceTypePIN), meta)
+}
+
+// GetCurrentPIN returns the code a PIN session currently shows to students
+func (s *AttendanceSessionService) GetCurrentPIN(sessionID, userID uint) (*models.AttendancePINResponse, error) {
+	session, err := s.authorize(AttendanceActionView, sessionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Type != models.AttendanceTypePIN {
+		return nil, errors.New("this session does not use PIN")
+	}
+	if session.Status != models.AttendanceStatusActive {
+		return nil, errors.New("attendance session is not active")
+	}
+
+	now := GetIndonesiaTime()
+	return &models.AttendancePINResponse{
+		SessionID:       session.ID,
+		PIN:             sessionPIN(session.PINSecret, now),
+		ExpiresAt:       now.Truncate(pinRotation).Add(pinRotation),
+		RotationSeconds: int(pinRotation.Seconds()),
+	}, nil
+}
+
+// applyGeofenceSettings reads the optional "geofence" setting of a new session, an object with
+// latitude, longitude and radius in meters. Only PIN sessions can be geofenced.
+func applyGeofenceSettings(session *models.AttendanceSession, settings map[string]interface{}) error {
+	value, ok := settings["geofence"]
+	if !ok || value == nil {
+		return nil
+	}
+	geofence, ok := value.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%w: geofence must be an object", ErrInvalidGeofence)
+	}
+	if session.Type != models.AttendanceTypePIN {
+		return fmt.Errorf("%w: only PIN sessions can be geofenced", ErrInvalidGeofence)
+	}
+
+	latitude, ok := geofence["latitude"].(float64)
+	if !ok || latitude < -90 || latitude > 90 {
+		return fmt.Errorf("%w: latitude must be between -90 and 90", ErrInvalidGeofence)
+	}
+	longitude, ok := geofence["longitude"].(float64)
+	if !ok || longitude < -180 || longitude > 180 {
+		return fmt.Errorf("%w: longitude must be between -180 and 180", ErrInvalidGeofence)
+	}
+	radius := defaultGeofenceRadius
+	if value, ok := geofence["radius"].(float64); ok {
+		if value <= 0 {
+			return fmt.Errorf("%w: radius must be positive", ErrInvalidGeofence)
+		}
+		radius = int(math.Ceil(value))
+	}
+
+	session.GeofenceLatitude = &latitude
+	session.GeofenceLongitude = &longitude
+	session.GeofenceRadius = radius
+	return nil
+}
+
+// generateRotatingSecret generates the seed of the rotating code of a PIN or BEACON session
+func generateRotatingSecret() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// sessionPIN derives the code of a PIN session for the rotation period containing a time, the
+// way TOTP derives one-time passwords
+func sessionPIN(secret string, at time.Time) string {
+	sum := rotatingHMAC(secret, at, pinRotation)
+	offset := sum[len(sum)-1] & 0x0f
+	code := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", pinDigits, code%pinModulo)
+}
+
+// rotatingHMAC returns the HMAC of the rotation period containing a time under a session's secret
+func rotatingHMAC(secret string, at time.Time, period time.Duration) []byte {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(at.Unix()/int64(period.Seconds())))
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(counter[:])
+	return mac.Sum(nil)
+}
+
+// verifySessionPIN reports whether a code is the current or the previous code of a PIN session.
+// For the previous code it also returns when that code was replaced.
+func verifySessionPIN(secret, pin string, now time.Time) (bool, *time.Time) {
+	if secret == "" || len(pin) != pinDigits {
+		return false, nil
+	}
+	return matchRotatingCode(pin, now, pinRotation, func(at time.Time) string {
+		return sessionPIN(secret, at)
+	})
+}
+
+// matchRotatingCode reports whether a code is the current or the previous code of a rotating
+// sequence. The previous code is accepted so a check-in sent just before the code changed still
+// counts; for it the time the code was replaced is returned too.
+func matchRotatingCode(code string, now time.Time, period time.Duration, derive func(time.Time) string) (bool, *time.Time) {
+	if hmac.Equal([]byte(derive(now)), []byte(code)) {
+		return true, nil
+	}
+	if hmac.Equal([]byte(derive(now.Add(-period))), []byte(code)) {
+		replacedAt := rotationStart(now, period)
+		return true, &replacedAt
+	}
+	return false, nil
+}
+
+// rotationStart returns when the rotation period containing a time began, on the same boundaries
+// as rotatingHMAC
+func rotationStart(at time.Time, period time.Duration) time.Time {
+	seconds := int64(period.Seconds())
+	return time.Unix(at.Unix()/seconds*seconds, 0).In(at.Location())
+}
+
+// distanceMeters returns the great-circle distance between two coordinates in meters
+func distanceMeters(lat1, lng1, lat2, lng2 float64) float64 {
+	const earthRadius = 6371000
+	toRadians := func(degrees float64) float64 { return degrees * math.Pi / 180 }
+
+	dLat := toRadians(lat2 - lat1)
+	dLng := toRadians(lng2 - lng1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return earthRadius * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
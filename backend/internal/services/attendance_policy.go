@@ -0,0 +1,56 @@
+package services
+
+import (
+	"strconv"
+
+	"github.com/delpresence/backend/internal/utils"
+)
+
+// EligibilityPolicy decides whether a student attended enough meetings to sit the final exam
+type EligibilityPolicy struct {
+	ThresholdPercent       float64 `json:"threshold_percent"`         // Minimum attendance rate to be eligible
+	WarningMarginPercent   float64 `json:"warning_margin_percent"`    // Students below threshold + margin get warned
+	ExcusedCountsAsPresent bool    `json:"excused_counts_as_present"` // Whether EXCUSED meetings count as attended
+}
+
+// LoadEligibilityPolicy reads the eligibility policy from environment variables
+func LoadEligibilityPolicy() EligibilityPolicy {
+	return EligibilityPolicy{
+		ThresholdPercent:       getEnvAsFloat("ATTENDANCE_ELIGIBILITY_THRESHOLD", 75),
+		WarningMarginPercent:   getEnvAsFloat("ATTENDANCE_WARNING_MARGIN", 10),
+		ExcusedCountsAsPresent: utils.GetEnvAsBool("ATTENDANCE_EXCUSED_COUNTS_AS_PRESENT", true),
+	}
+}
+
+// Rate returns the attendance percentage for the given counts over the meetings held
+func (p EligibilityPolicy) Rate(present, late, excused, held int) float64 {
+	if held <= 0 {
+		return 100
+	}
+
+	attended := present + late
+	if p.ExcusedCountsAsPresent {
+		attended += excused
+	}
+
+	return float64(attended) * 100 / float64(held)
+}
+
+// IsEligible reports whether an attendance rate meets the threshold
+func (p EligibilityPolicy) IsEligible(rate float64) bool {
+	return rate >= p.ThresholdPercent
+}
+
+// IsNearThreshold reports whether an attendance rate is below the threshold or within the warning margin
+func (p EligibilityPolicy) IsNearThreshold(rate float64) bool {
+	return rate < p.ThresholdPercent+p.WarningMarginPercent
+}
+
+// getEnvAsFloat gets an environment variable as a float or returns a default value
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	value, err := strconv.ParseFloat(utils.GetEnvWithDefault(key, ""), 64)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/delpresence/backend/internal/models"
+)
+
+// minQRRotationSeconds is the shortest rotation of a QR code phones can still scan reliably
+const minQRRotationSeconds = 10
+
+// ErrInvalidQRRotation is returned when the QR rotation setting of a new session is invalid
+var ErrInvalidQRRotation = errors.New("invalid QR code rotation")
+
+// applyQRRotationSettings reads the optional "qrRotationSeconds" setting of a new session. A QR
+// session with a rotation shows a new code every so many seconds, so a photo of the code stops
+// working shortly after it was taken.
+func applyQRRotationSettings(session *models.AttendanceSession, settings map[string]interface{}) error {
+	value, ok := settings["qrRotationSeconds"]
+	if !ok || value == nil {
+		return nil
+	}
+	seconds, ok := value.(float64)
+	if !ok || seconds != math.Trunc(seconds) || seconds < 0 {
+		return fmt.Errorf("%w: qrRotationSeconds must be a whole number of seconds", ErrInvalidQRRotation)
+	}
+	if seconds == 0 {
+		return nil
+	}
+	if session.Type != models.AttendanceTypeQRCode && session.Type != models.AttendanceTypeBoth {
+		return fmt.Errorf("%w: only QR code sessions can rotate their code", ErrInvalidQRRotation)
+	}
+	if seconds < minQRRotationSeconds {
+		return fmt.Errorf("%w: the code must rotate every %d seconds or more", ErrInvalidQRRotation, minQRRotationSeconds)
+	}
+
+	session.QRRotationSeconds = int(seconds)
+	return nil
+}
+
+// sessionQRPayload returns the text a QR session shows at a time. A rotating session appends a
+// code derived from its QR data and the rotation period containing the time.
+func sessionQRPayload(session *models.AttendanceSession, at time.Time) string {
+	if session.QRCodeData == "" {
+		return fmt.Sprintf("delpresence:attendance:%d", session.ID)
+	}
+	if session.QRRotationSeconds <= 0 {
+		return session.QRCodeData
+	}
+	period := time.Duration(session.QRRotationSeconds) * time.Second
+	return fmt.Sprintf("%s:%s", session.QRCodeData, hex.EncodeToString(rotatingHMAC(session.QRCodeData, at, period)[:6]))
+}
+
+// verifySessionQRCode reports whether scanned QR data belongs to a session at a time. A rotating
+// session accepts the current and the previous code; for the previous code it also returns when
+// that code was replaced. Sessions created without QR data accept the payload they show instead.
+func verifySessionQRCode(session *models.AttendanceSession, qrData string, at time.Time) (bool, *time.Time) {
+	if session.QRCodeData == "" {
+		return qrData == sessionQRPayload(session, at), nil
+	}
+	return verifyIssuedQRCode(session, qrData, at)
+}
+
+// verifyIssuedQRCode is verifySessionQRCode without the ID payload of sessions created without QR
+// data, which anyone can derive from the session ID
+func verifyIssuedQRCode(session *models.AttendanceSession, qrData string, at time.Time) (bool, *time.Time) {
+	if session.QRCodeData == "" {
+		return false, nil
+	}
+	if session.QRRotationSeconds <= 0 {
+		return qrData == session.QRCodeData, nil
+	}
+	period := time.Duration(session.QRRotationSeconds) * time.Second
+	return matchRotatingCode(qrData, at, period, func(t time.Time) string {
+		return sessionQRPayload(session, t)
+	})
+}
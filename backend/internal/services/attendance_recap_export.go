@@ -0,0 +1,245 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/delpresence/backend/internal/models"
+	"github.com/delpresence/backend/internal/pdf"
+	"github.com/delpresence/backend/internal/utils"
+	"github.com/tealeg/xlsx/v3"
+)
+
+// RecapFilename returns the download file name of a recap export
+func RecapFilename(recap *models.StudentAttendanceRecap, extension string) string {
+	return reportFilename(extension, "Rekap_Presensi", recap.NIM, recap.AcademicYear.Name, recap.AcademicYear.Semester)
+}
+
+// eligibilityLabel describes an eligibility verdict in Indonesian
+func eligibilityLabel(eligible bool) string {
+	if eligible {
+		return "Memenuhi"
+	}
+	return "Tidak Memenuhi"
+}
+
+// RenderRecapXLSX renders a student's attendance recap as an Excel workbook
+func RenderRecapXLSX(recap *models.StudentAttendanceRecap) ([]byte, error) {
+	file := xlsx.NewFile()
+	sheet, err := file.AddSheet("Rekap Presensi")
+	if err != nil {
+		return nil, err
+	}
+
+	titleCell := sheet.AddRow().AddCell()
+	titleCell.Value = "REKAP PRESENSI MAHASISWA"
+	titleCell.SetStyle(utils.NewXLSXTitleStyle())
+	sheet.AddRow()
+
+	info := [][2]string{
+		{"NIM", recap.NIM},
+		{"Nama", recap.FullName},
+		{"Program Studi", recap.StudyProgram},
+		{"Tahun Akademik", fmt.Sprintf("%s %s", recap.AcademicYear.Name, recap.AcademicYear.Semester)},
+		{"Dosen Wali", recap.AdvisorName},
+		{"Batas Kehadiran", formatPercent(recap.ThresholdPercent)},
+	}
+	for _, item := range info {
+		row := sheet.AddRow()
+		row.AddCell().Value = item[0]
+		row.AddCell().Value = item[1]
+	}
+	sheet.AddRow()
+
+	headerStyle := utils.NewXLSXHeaderStyle()
+	headerRow := sheet.AddRow()
+	headers := []string{"No", "Kode MK", "Mata Kuliah", "SKS", "Dosen", "Kelas", "Pertemuan",
+		"Hadir", "Terlambat", "Tidak Hadir", "Izin", "Persentase", "Status"}
+	for _, header := range headers {
+		cell := headerRow.AddCell()
+		cell.Value = header
+		cell.SetStyle(headerStyle)
+	}
+
+	dataStyle := utils.NewXLSXCellStyle()
+	for i, course := range recap.Courses {
+		row := sheet.AddRow()
+		addStyledInt(row, i+1, dataStyle)
+		addStyledString(row, course.CourseCode, dataStyle)
+		addStyledString(row, course.CourseName, dataStyle)
+		addStyledInt(row, course.Credits, dataStyle)
+		addStyledString(row, course.LecturerName, dataStyle)
+		addStyledString(row, course.StudentGroupName, dataStyle)
+		addStyledInt(row, course.MeetingsHeld, dataStyle)
+		addStyledInt(row, course.Present, dataStyle)
+		addStyledInt(row, course.Late, dataStyle)
+		addStyledInt(row, course.Absent, dataStyle)
+		addStyledInt(row, course.Excused, dataStyle)
+		addStyledString(row, formatPercent(course.AttendanceRate), dataStyle)
+		addStyledString(row, eligibilityLabel(course.Eligible), eligibilityStyle(course.Eligible))
+	}
+
+	totalStyle := utils.NewXLSXCellStyle()
+	totalStyle.Font.Bold = true
+	totalRow := sheet.AddRow()
+	addStyledString(totalRow, "", totalStyle)
+	addStyledString(totalRow, "", totalStyle)
+	addStyledString(totalRow, "Total", totalStyle)
+	addStyledString(totalRow, "", totalStyle)
+	addStyledString(totalRow, "", totalStyle)
+	addStyledString(totalRow, "", totalStyle)
+	addStyledInt(totalRow, recap.MeetingsHeld, totalStyle)
+	addStyledInt(totalRow, recap.Present, totalStyle)
+	addStyledInt(totalRow, recap.Late, totalStyle)
+	addStyledInt(totalRow, recap.Absent, totalStyle)
+	addStyledInt(totalRow, recap.Excused, totalStyle)
+	addStyledString(totalRow, formatPercent(recap.AttendanceRate), totalStyle)
+	addStyledString(totalRow, fmt.Sprintf("%d tidak memenuhi", recap.IneligibleCount), totalStyle)
+
+	widths := []float64{5, 12, 35, 6, 30, 15, 11, 8, 10, 11, 8, 12, 16}
+	for i, width := range widths {
+		sheet.SetColWidth(i+1, i+1, width)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := file.Write(buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// RenderRecapPDF renders a student's attendance recap as a printable PDF with signature lines
+func RenderRecapPDF(recap *models.StudentAttendanceRecap) ([]byte, error) {
+	doc := pdf.New(fmt.Sprintf("Rekap Presensi %s", recap.NIM))
+	doc.Footer = pageNumberFooter
+
+	doc.Heading("REKAP PRESENSI MAHASISWA", 14, pdf.AlignCenter)
+	doc.Heading(fmt.Sprintf("Tahun Akademik %s %s", recap.AcademicYear.Name, recap.AcademicYear.Semester), 11, pdf.AlignCenter)
+	doc.Space(10)
+
+	doc.KeyValue("NIM", recap.NIM, 95, 10)
+	doc.KeyValue("Nama", recap.FullName, 95, 10)
+	doc.KeyValue("Program Studi", recap.StudyProgram, 95, 10)
+	doc.KeyValue("Dosen Wali", recap.AdvisorName, 95, 10)
+	doc.KeyValue("Batas Kehadiran", formatPercent(recap.ThresholdPercent), 95, 10)
+	doc.Space(8)
+
+	columns := []pdf.Column{
+		{Header: "No", Width: 22, Align: pdf.AlignCenter},
+		{Header: "Kode", Width: 52},
+		{Header: "Mata Kuliah", Width: 150},
+		{Header: "SKS", Width: 26, Align: pdf.AlignCenter},
+		{Header: "Pert.", Width: 32, Align: pdf.AlignCenter},
+		{Header: "H", Width: 28, Align: pdf.AlignCenter},
+		{Header: "T", Width: 28, Align: pdf.AlignCenter},
+		{Header: "A", Width: 28, Align: pdf.AlignCenter},
+		{Header: "I", Width: 28, Align: pdf.AlignCenter},
+		{Header: "%", Width: 45, Align: pdf.AlignRight},
+		{Header: "Status", Width: 76, Align: pdf.AlignCenter},
+	}
+
+	rows := make([][]string, 0, len(recap.Courses)+1)
+	for i, course := range recap.Courses {
+		rows = append(rows, []string{
+			fmt.Sprint(i + 1), course.CourseCode, course.CourseName, fmt.Sprint(course.Credits),
+			fmt.Sprint(course.MeetingsHeld), fmt.Sprint(course.Present), fmt.Sprint(course.Late),
+			fmt.Sprint(course.Absent), fmt.Sprint(course.Excused),
+			formatPercent(course.AttendanceRate), eligibilityLabel(course.Eligible),
+		})
+	}
+	rows = append(rows, []string{
+		"", "", "Total", "", fmt.Sprint(recap.MeetingsHeld), fmt.Sprint(recap.Present),
+		fmt.Sprint(recap.Late), fmt.Sprint(recap.Absent), fmt.Sprint(recap.Excused),
+		formatPercent(recap.AttendanceRate), "",
+	})
+
+	ineligibleFill := pdf.Hex("FFC7CE")
+	doc.Table(columns, rows, pdf.TableStyle{
+		FontSize:  9,
+		RowHeight: 16,
+		RowFill: func(row int) *pdf.Color {
+			if row < len(recap.Courses) && !recap.Courses[row].Eligible {
+				return &ineligibleFill
+			}
+			return nil
+		},
+	})
+	doc.Space(6)
+	doc.Paragraph("H = Hadir, T = Terlambat, A = Tidak Hadir, I = Izin. Pertemuan dihitung dari sesi presensi yang telah ditutup.", pdf.Regular, 8)
+
+	drawSignatures(doc, []signatureBlock{
+		{Role: "Mahasiswa", Name: recap.FullName, ID: "NIM. " + recap.NIM},
+		{Role: "Dosen Wali", Name: recap.AdvisorName, ID: nipLabel(recap.AdvisorNIP)},
+	})
+
+	return doc.Bytes()
+}
+
+// signatureBlock is one signature column at the end of a printed report
+type signatureBlock struct {
+	Role string // May span two lines separated by "\n"
+	Name string
+	ID   string
+}
+
+// drawSignatures draws signature columns side by side, dated today, with space to sign
+func drawSignatures(doc *pdf.Document, blocks []signatureBlock) {
+	height := 110.0
+	doc.Space(20)
+	doc.EnsureSpace(height)
+
+	width := doc.ContentWidth() / float64(len(blocks))
+	city := utils.GetEnvWithDefault("REPORT_SIGNATURE_CITY", "Sitoluama")
+	doc.TextInBox(doc.Margin+width*float64(len(blocks)-1), doc.Y+10, width, pdf.AlignCenter, pdf.Regular, 10,
+		fmt.Sprintf("%s, %s", city, formatIndonesianDate(GetIndonesiaTime())))
+
+	for i, block := range blocks {
+		x := doc.Margin + width*float64(i)
+		for j, line := range strings.Split(block.Role, "\n") {
+			doc.TextInBox(x, doc.Y+26+float64(j)*12, width, pdf.AlignCenter, pdf.Regular, 10, line)
+		}
+		doc.Line(x+20, doc.Y+88, x+width-20, doc.Y+88, 0.5)
+		doc.TextInBox(x, doc.Y+86, width, pdf.AlignCenter, pdf.Bold, 10, block.Name)
+		doc.TextInBox(x, doc.Y+100, width, pdf.AlignCenter, pdf.Regular, 9, block.ID)
+	}
+	doc.Space(height)
+}
+
+// pageNumberFooter prints the generation time and page number at the bottom of each page
+func pageNumberFooter(doc *pdf.Document, page, pages int) {
+	y := doc.Height - doc.Margin/2
+	doc.Text(doc.Margin, y, pdf.Regular, 8, "Dicetak "+GetIndonesiaTime().Format("02-01-2006 15:04")+" WIB")
+	doc.TextInBox(doc.Margin, y, doc.ContentWidth(), pdf.AlignRight, pdf.Regular, 8,
+		fmt.Sprintf("Halaman %d dari %d", page, pages))
+}
+
+// nipLabel formats a lecturer NIP for a signature line
+func nipLabel(nip string) string {
+	if nip == "" {
+		return ""
+	}
+	return "NIP. " + nip
+}
+
+// eligibilityStyle colors an eligibility cell like a present or absent status
+func eligibilityStyle(eligible bool) *xlsx.Style {
+	if eligible {
+		return utils.NewXLSXStatusStyle("PRESENT")
+	}
+	return utils.NewXLSXStatusStyle("ABSENT")
+}
+
+// addStyledString appends a styled text cell to a row
+func addStyledString(row *xlsx.Row, value string, style *xlsx.Style) {
+	cell := row.AddCell()
+	cell.Value = value
+	cell.SetStyle(style)
+}
+
+// addStyledInt appends a styled number cell to a row
+func addStyledInt(row *xlsx.Row, value int, style *xlsx.Style) {
+	cell := row.AddCell()
+	cell.SetInt(value)
+	cell.SetStyle(style)
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/delpresence/backend/internal/models"
+	"github.com/delpresence/backend/internal/repositories"
+)
+
+// ErrRecapForbidden is returned when a user may not see a student's attendance recap
+var ErrRecapForbidden = errors.New("you do not have access to this student's attendance recap")
+
+// AttendanceRecapService builds per-student attendance recaps and manages academic advisors
+type AttendanceRecapService struct {
+	statsRepo        *repositories.AttendanceStatisticsRepository
+	studentRepo      *repositories.StudentRepository
+	lecturerRepo     *repositories.LecturerRepository
+	advisorRepo      *repositories.AcademicAdvisorRepository
+	academicYearRepo *repositories.AcademicYearRepository
+	policy           EligibilityPolicy
+}
+
+// NewAttendanceRecapService creates a new attendance recap service
+func NewAttendanceRecapService() *AttendanceRecapService {
+	return &AttendanceRecapService{
+		statsRepo:        repositories.NewAttendanceStatisticsRepository(),
+		studentRepo:      repositories.NewStudentRepository(),
+		lecturerRepo:     repositories.NewLecturerRepository(),
+		advisorRepo:      repositories.NewAcademicAdvisorRepository(),
+		academicYearRepo: repositories.NewAcademicYearRepository(),
+		policy:           LoadEligibilityPolicy(),
+	}
+}
+
+// GetRecapForUser returns the recap of a student on behalf of a user, checking that admins,
+// the student themselves or their academic advisor are the only ones who can see it
+func (s *AttendanceRecapService) GetRecapForUser(studentID uint, role string, userID uint, academicYearID uint) (*models.StudentAttendanceRecap, error) {
+	student, err := s.studentRepo.FindByID(studentID)
+	if err != nil {
+		return nil, errors.New("student not found")
+	}
+
+	switch strings.ToLower(role) {
+	case "admin":
+	case "mahasiswa":
+		if student.UserID != int(userID) {
+			return nil, ErrRecapForbidden
+		}
+	case "dosen":
+		isAdvisor, err := s.advisorRepo.IsAdvisor(int(userID), student.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !isAdvisor {
+			return nil, ErrRecapForbidden
+		}
+	default:
+		return nil, ErrRecapForbidden
+	}
+
+	return s.buildRecap(student, academicYearID)
+}
+
+// GetRecapByStudentUserID returns the recap of the student with the given external user ID
+func (s *AttendanceRecapService) GetRecapByStudentUserID(userID uint, academicYearID uint) (*models.StudentAttendanceRecap, error) {
+	student, err := s.studentRepo.FindByUserID(int(userID))
+	if err != nil {
+		return nil, err
+	}
+	if student == nil {
+		return nil, errors.New("student not found")
+	}
+	return s.buildRecap(student, academicYearID)
+}
+
+// buildRecap computes a student's attendance per course of an academic year with eligibility verdicts
+func (s *AttendanceRecapService) buildRecap(student *models.Student, academicYearID uint) (*models.StudentAttendanceRecap, error) {
+	academicYear, err := resolveAcademicYear(s.academicYearRepo, academicYearID)
+	if err != nil {
+		return nil, err
+	}
+
+	courses, err := s.statsRepo.GetStudentCourseRecap(student.ID, academicYear.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	recap := &models.StudentAttendanceRecap{
+		StudentID:        student.ID,
+		StudentUserID:    student.UserID,
+		NIM:              student.NIM,
+		FullName:         student.FullName,
+		StudyProgram:     student.StudyProgram,
+		YearEnrolled:     student.YearEnrolled,
+		AcademicYear:     academicYear,
+		ThresholdPercent: s.policy.ThresholdPercent,
+		Courses:          courses,
+	}
+
+	advisor, err := s.advisorRepo.FindByStudentID(student.ID)
+	if err != nil {
+		return nil, err
+	}
+	if advisor != nil {
+		lecturer, err := s.lecturerRepo.GetByUserID(advisor.LecturerUserID)
+		if err != nil {
+			return nil, err
+		}
+		recap.AdvisorName = lecturer.FullName
+		recap.AdvisorNIP = lecturer.NIP
+	}
+
+	for i := range recap.Courses {
+		course := &recap.Courses[i]
+		course.AttendanceRate = s.policy.Rate(course.Present, course.Late, course.Excused, course.MeetingsHeld)
+		course.Eligible = s.policy.IsEligible(course.AttendanceRate)
+		if !course.Eligible {
+			recap.IneligibleCount++
+		}
+
+		recap.MeetingsHeld += course.MeetingsHeld
+		recap.Present += course.Present
+		recap.Late += course.Late
+		recap.Absent += course.Absent
+		recap.Excused += course.Excused
+	}
+	recap.AttendanceRate = s.policy.Rate(recap.Present, recap.Late, recap.Excused, recap.MeetingsHeld)
+
+	return recap, nil
+}
+
+// AssignAdvisor makes a lecturer the academic advisor of a student
+func (s *AttendanceRecapService) AssignAdvisor(studentID uint, lecturerUserID int) (*models.AcademicAdvisor, error) {
+	if _, err := s.studentRepo.FindByID(studentID); err != nil {
+		return nil, errors.New("student not found")
+	}
+
+	lecturer, err := s.lecturerRepo.GetByUserID(lecturerUserID)
+	if err != nil {
+		return nil, err
+	}
+	if lecturer.ID == 0 {
+		return nil, errors.New("lecturer not found")
+	}
+
+	advisor := &models.AcademicAdvisor{StudentID: studentID, LecturerUserID: lecturerUserID}
+	if err := s.advisorRepo.Assign(advisor); err != nil {
+		return nil, err
+	}
+
+	advisor.Lecturer = &lecturer
+	return advisor, nil
+}
+
+// RemoveAdvisor removes the academic advisor of a student
+func (s *AttendanceRecapService) RemoveAdvisor(studentID uint) error {
+	return s.advisorRepo.DeleteByStudentID(studentID)
+}
+
+// ListAdvisors returns advisor assignments, optionally only those of one lecturer
+func (s *AttendanceRecapService) ListAdvisors(lecturerUserID int) ([]models.AcademicAdvisor, error) {
+	advisors, err := s.advisorRepo.ListAll(lecturerUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	lecturers := make(map[int]*models.Lecturer)
+	for i := range advisors {
+		userID := advisors[i].LecturerUserID
+		if _, ok := lecturers[userID]; !ok {
+			lecturer, err := s.lecturerRepo.GetByUserID(userID)
+			if err != nil {
+				return nil, err
+			}
+			lecturers[userID] = &lecturer
+		}
+		advisors[i].Lecturer = lecturers[userID]
+	}
+
+	return advisors, nil
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"log"
 	"strconv"
 	"strings"
 	"time"
@@ -15,11 +16,16 @@ import (
 	"gorm.io/gorm"
 )
 
+// reopenCloserInterval is how often reopened sessions are checked for the end of their window
+const reopenCloserInterval = 30 * time.Second
+
 // AttendanceService handles attendance-related business logic
 type AttendanceService struct {
 	attendanceRepo *repositories.AttendanceRepository
 	scheduleRepo   *repositories.CourseScheduleRepository
 	studentRepo    *repositories.StudentRepository
+	statsRepo      *repositories.AttendanceStatisticsRepository
+	eventRepo      *repositories.CampusEventRepository
 	db             *gorm.DB
 }
 
@@ -29,12 +35,23 @@ func NewAttendanceService() *AttendanceService {
 		attendanceRepo: repositories.NewAttendanceRepository(),
 		scheduleRepo:   repositories.NewCourseScheduleRepository(),
 		studentRepo:    repositories.NewStudentRepository(),
+		statsRepo:      repositories.NewAttendanceStatisticsRepository(),
+		eventRepo:      repositories.NewCampusEventRepository(),
 		db:             database.GetDB(),
 	}
 }
 
-// CreateAttendanceSession creates a new attendance session for a course schedule
-func (s *AttendanceService) CreateAttendanceSession(userID uint, courseScheduleID uint, date time.Time, attendanceType models.AttendanceType, settings map[string]interface{}) (*models.AttendanceSession, error) {
+// openSession opens a new attendance session for a course schedule. Callers check
+// that the user may open sessions for the schedule.
+func (s *AttendanceService) openSession(actor AttendanceActor, userID uint, schedule models.CourseSchedule, date time.Time, attendanceType models.AttendanceType, settings map[string]interface{}) (*models.AttendanceSession, error) {
+	courseScheduleID := schedule.ID
+
+	// Members of the course's teaching team act as lecturers of the schedule
+	isCurrentUserLecturer, err := s.scheduleRepo.IsTaughtBy(schedule, userID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check if there's already an active session for this schedule and date
 	existingSession, err := s.attendanceRepo.GetActiveSessionForSchedule(courseScheduleID, date)
 	if err == nil && existingSession.ID != 0 {
@@ -43,21 +60,13 @@ func (s *AttendanceService) CreateAttendanceSession(userID uint, courseScheduleI
 			return nil, errors.New("you already have an active attendance session for this schedule")
 		}
 
-		// Check if current user is a lecturer and existing session is by a teaching assistant or vice versa
-		var isCurrentUserLecturer, isExistingSessionLecturer bool
-
-		// Get the course schedule to determine lecturer ownership
-		schedule, err := s.scheduleRepo.GetByID(courseScheduleID)
+		// Check if current user is a lecturer and existing session is by a teaching assistant or vice versa.
+		// Check if the creator of the existing session is one of the schedule's lecturers
+		isExistingSessionLecturer, err := s.scheduleRepo.IsTaughtBy(schedule, existingSession.LecturerID)
 		if err != nil {
 			return nil, err
 		}
 
-		// Current user is the assigned lecturer for this schedule
-		isCurrentUserLecturer = (schedule.UserID == userID)
-
-		// Check if the creator of the existing session is the assigned lecturer
-		isExistingSessionLecturer = (schedule.UserID == existingSession.LecturerID)
-
 		// Allow only if current user is lecturer and existing session is by TA
 		if isCurrentUserLecturer && !isExistingSessionLecturer {
 			// Lecturer can override TA's session - proceed with creation
@@ -70,28 +79,6 @@ func (s *AttendanceService) CreateAttendanceSession(userID uint, courseScheduleI
 		}
 	}
 
-	// Get the course schedule to validate
-	schedule, err := s.scheduleRepo.GetByID(courseScheduleID)
-	if err != nil {
-		return nil, err
-	}
-
-	// Verify that the user is either the assigned lecturer or a teaching assistant
-	if schedule.UserID != uint(userID) {
-		// Check if user is a teaching assistant for this course
-		var isAssistant bool
-		err = s.db.Raw(`
-			SELECT EXISTS (
-				SELECT 1 FROM teaching_assistant_assignments 
-				WHERE user_id = ? AND course_id = ?
-			) as is_assistant`,
-			userID, schedule.CourseID).Scan(&isAssistant).Error
-
-		if err != nil || !isAssistant {
-			return nil, errors.New("user is neither the assigned lecturer nor a teaching assistant for this course")
-		}
-	}
-
 	// Create a new attendance session
 	session := &models.AttendanceSession{
 		CourseScheduleID: courseScheduleID,
@@ -106,12 +93,7 @@ func (s *AttendanceService) CreateAttendanceSession(userID uint, courseScheduleI
 		LateThreshold:    10, // Default 10 minutes
 	}
 
-	// Set creator role based on whether user is lecturer or teaching assistant
-	if schedule.UserID == userID {
-		session.CreatorRole = "LECTURER"
-	} else {
-		session.CreatorRole = "ASSISTANT"
-	}
+	session.CreatorRole = sessionActorRole(actor, isCurrentUserLecturer)
 
 	// Apply custom settings if provided
 	if settings != nil {
@@ -171,6 +153,25 @@ func (s *AttendanceService) CreateAttendanceSession(userID uint, courseScheduleI
 		session.QRCodeData = qrData
 	}
 
+	// PIN and BEACON sessions derive a code that rotates from a secret seed
+	if attendanceType == models.AttendanceTypePIN || attendanceType == models.AttendanceTypeBeacon {
+		secret, err := generateRotatingSecret()
+		if err != nil {
+			return nil, err
+		}
+		if attendanceType == models.AttendanceTypePIN {
+			session.PINSecret = secret
+		} else {
+			session.BeaconSecret = secret
+		}
+	}
+	if err := applyGeofenceSettings(session, settings); err != nil {
+		return nil, err
+	}
+	if err := applyQRRotationSettings(session, settings); err != nil {
+		return nil, err
+	}
+
 	// Save the session
 	if err := s.attendanceRepo.CreateAttendanceSession(session); err != nil {
 		return nil, err
@@ -182,43 +183,14 @@ func (s *AttendanceService) CreateAttendanceSession(userID uint, courseScheduleI
 		fmt.Printf("Error initializing student attendances: %v\n", err)
 	}
 
+	// Let the lecturer's screen and the students of this group know the session is open
+	publishSessionEvent(models.AttendanceEventSessionOpened, session, &schedule)
+
 	return session, nil
 }
 
-// CloseAttendanceSession closes an active attendance session
-func (s *AttendanceService) CloseAttendanceSession(sessionID uint, userID uint) error {
-	session, err := s.attendanceRepo.GetAttendanceSessionByID(sessionID)
-	if err != nil {
-		return err
-	}
-
-	// First check if the user is the creator of this session
-	if session.LecturerID != userID {
-		// If not, check if they are a teaching assistant for this course
-		var isAssistant bool
-
-		// Get the course ID from the session's schedule
-		var courseID uint
-		if err := s.db.Model(&models.CourseSchedule{}).
-			Where("id = ?", session.CourseScheduleID).
-			Select("course_id").
-			First(&courseID).Error; err != nil {
-			return errors.New("failed to verify course assignment")
-		}
-
-		// Check if the user is a teaching assistant for this course
-		err = s.db.Raw(`
-			SELECT EXISTS (
-				SELECT 1 FROM teaching_assistant_assignments 
-				WHERE user_id = ? AND course_id = ?
-			) as is_assistant`,
-			userID, courseID).Scan(&isAssistant).Error
-
-		if err != nil || !isAssistant {
-			return errors.New("user does not have permission to close this attendance session")
-		}
-	}
-
+// closeSession closes an active attendance session
+func (s *AttendanceService) closeSession(session *models.AttendanceSession, closedByID uint, closedByRole string) error {
 	// Verify that the session is active
 	if session.Status != models.AttendanceStatusActive {
 		return errors.New("attendance session is not active")
@@ -228,22 +200,36 @@ func (s *AttendanceService) CloseAttendanceSession(sessionID uint, userID uint)
 	now := GetIndonesiaTime()
 	session.Status = models.AttendanceStatusClosed
 	session.EndTime = &now
+	session.ReopenedUntil = nil
+	recordFirstClose(session)
+	session.ClosedByID = &closedByID
+	session.ClosedByRole = closedByRole
 
-	return s.attendanceRepo.UpdateAttendanceSession(session)
-}
-
-// CancelAttendanceSession cancels an active attendance session
-func (s *AttendanceService) CancelAttendanceSession(sessionID uint, lecturerID uint) error {
-	session, err := s.attendanceRepo.GetAttendanceSessionByID(sessionID)
-	if err != nil {
+	if err := s.attendanceRepo.UpdateAttendanceSession(session); err != nil {
 		return err
 	}
 
-	// Verify that the lecturer owns this session
-	if session.LecturerID != lecturerID {
-		return errors.New("lecturer does not own this attendance session")
+	publishSessionEvent(models.AttendanceEventSessionClosed, session, &session.CourseSchedule)
+
+	return nil
+}
+
+// sessionActorRole returns the role a user acts in on a schedule's session: one of the schedule's
+// lecturers, an assistant (including lecturers acting on a schedule they do not teach), or any other
+// actor under its own name
+func sessionActorRole(actor AttendanceActor, teachesSchedule bool) string {
+	switch {
+	case teachesSchedule:
+		return "LECTURER"
+	case actor == AttendanceActorLecturer || actor == AttendanceActorAssistant:
+		return "ASSISTANT"
+	default:
+		return strings.ToUpper(string(actor))
 	}
+}
 
+// cancelSession cancels an active attendance session
+func (s *AttendanceService) cancelSession(session *models.AttendanceSession) error {
 	// Verify that the session is active
 	if session.Status != models.AttendanceStatusActive {
 		return errors.New("attendance session is not active")
@@ -251,18 +237,21 @@ func (s *AttendanceService) CancelAttendanceSession(sessionID uint, lecturerID u
 
 	// Update session status
 	session.Status = models.AttendanceStatusCanceled
+	session.ReopenedUntil = nil
 
-	return s.attendanceRepo.UpdateAttendanceSession(session)
-}
-
-// MarkStudentAttendance marks a student's attendance for a session
-func (s *AttendanceService) MarkStudentAttendance(sessionID uint, studentID uint, status models.StudentAttendanceStatus, verificationMethod string, notes string, verifiedByID *uint) error {
-	// Check if the session exists and is active
-	session, err := s.attendanceRepo.GetAttendanceSessionByID(sessionID)
-	if err != nil {
+	if err := s.attendanceRepo.UpdateAttendanceSession(session); err != nil {
 		return err
 	}
 
+	publishSessionEvent(models.AttendanceEventSessionCanceled, session, &session.CourseSchedule)
+
+	return nil
+}
+
+// markStudentAttendance sets a student's attendance for an active session by hand
+func (s *AttendanceService) markStudentAttendance(session *models.AttendanceSession, studentID uint, status models.StudentAttendanceStatus, verificationMethod string, notes string, verifiedByID *uint) error {
+	sessionID := session.ID
+
 	if session.Status != models.AttendanceStatusActive {
 		return errors.New("attendance session is not active")
 	}
@@ -272,13 +261,7 @@ func (s *AttendanceService) MarkStudentAttendance(sessionID uint, studentID uint
 	isNewRecord := err != nil
 
 	// Determine if the student is late based on session settings
-	if status == models.StudentAttendanceStatusPresent && session.AllowLate {
-		elapsed := time.Since(session.StartTime)
-		if int(elapsed.Minutes()) > session.LateThreshold {
-			// Student is late, change status to late
-			status = models.StudentAttendanceStatusLate
-		}
-	}
+	status = manualCheckInStatus(session, status)
 
 	now := GetIndonesiaTime()
 
@@ -293,7 +276,7 @@ func (s *AttendanceService) MarkStudentAttendance(sessionID uint, studentID uint
 			VerificationMethod:  verificationMethod,
 			VerifiedByID:        verifiedByID,
 		}
-		return s.attendanceRepo.CreateStudentAttendance(attendance)
+		err = s.attendanceRepo.CreateStudentAttendance(attendance)
 	} else {
 		// Update existing record
 		attendance.Status = status
@@ -301,234 +284,99 @@ func (s *AttendanceService) MarkStudentAttendance(sessionID uint, studentID uint
 		attendance.Notes = notes
 		attendance.VerificationMethod = verificationMethod
 		attendance.VerifiedByID = verifiedByID
-		return s.attendanceRepo.UpdateStudentAttendance(attendance)
+		err = s.attendanceRepo.UpdateStudentAttendance(attendance)
 	}
-}
 
-// GetActiveSessionsForUser gets all active attendance sessions for a user (lecturer or teaching assistant)
-func (s *AttendanceService) GetActiveSessionsForUser(userID uint) ([]models.AttendanceSessionResponse, error) {
-	// First, get sessions where the user is directly the lecturer
-	sessions, err := s.attendanceRepo.ListActiveSessions(userID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	// For teaching assistants, also get sessions from courses where they are assigned as TAs
-	// First, get all course IDs where the user is a teaching assistant
-	var courseIDs []uint
-	if err := s.db.Raw(`
-		SELECT course_id FROM teaching_assistant_assignments 
-		WHERE user_id = ?`,
-		userID).Scan(&courseIDs).Error; err != nil {
-		// Just log the error but continue with direct sessions
-		fmt.Printf("Error fetching TA assignments for user %d: %v\n", userID, err)
-	} else if len(courseIDs) > 0 {
-		// Get schedules for these courses
-		var scheduleIDs []uint
-		if err := s.db.Model(&models.CourseSchedule{}).
-			Where("course_id IN (?)", courseIDs).
-			Pluck("id", &scheduleIDs).Error; err != nil {
-			fmt.Printf("Error fetching course schedules for TA %d: %v\n", userID, err)
-		} else if len(scheduleIDs) > 0 {
-			// Get active sessions for these schedules
-			taSessions, err := s.attendanceRepo.ListActiveSessionsBySchedules(scheduleIDs)
-			if err == nil {
-				// Add these sessions to the list, avoiding duplicates
-				sessionMap := make(map[uint]models.AttendanceSession)
-				for _, s := range sessions {
-					sessionMap[s.ID] = s
-				}
+	// Student details are only needed for the event payload, so a failed lookup is not fatal
+	student, _ := s.studentRepo.FindByID(studentID)
+	publishStudentAttendanceEvent(models.AttendanceEventStudentStatusChanged, session, student, attendance)
 
-				for _, s := range taSessions {
-					if _, exists := sessionMap[s.ID]; !exists {
-						sessionMap[s.ID] = s
-						sessions = append(sessions, s)
-					}
-				}
-			}
-		}
-	}
+	return nil
+}
 
-	// Transform to response objects
-	var responses []models.AttendanceSessionResponse
-	for _, session := range sessions {
-		response, err := s.mapSessionToResponse(&session)
-		if err != nil {
-			continue
+// StartReopenCloser closes reopened sessions when their reopen window ends (safe to run on every replica)
+func (s *AttendanceService) StartReopenCloser() {
+	go func() {
+		ticker := time.NewTicker(reopenCloserInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			s.closeExpiredReopenedSessions()
 		}
-		responses = append(responses, *response)
-	}
+	}()
 
-	return responses, nil
+	log.Printf("Reopened session closer started (interval %s)", reopenCloserInterval)
 }
 
-// GetActiveSessionsByCourse gets all active attendance sessions for a specific course
-func (s *AttendanceService) GetActiveSessionsByCourse(courseID uint) ([]models.AttendanceSessionResponse, error) {
-	// Get all course schedules for this course
-	schedules, err := s.scheduleRepo.GetByCourse(courseID)
+// closeExpiredReopenedSessions closes the reopened sessions whose window has ended
+func (s *AttendanceService) closeExpiredReopenedSessions() {
+	sessions, err := s.attendanceRepo.ListExpiredReopenedSessions(GetIndonesiaTime())
 	if err != nil {
-		return nil, err
+		log.Printf("Failed to list expired reopened sessions: %v", err)
+		return
 	}
 
-	var allResponses []models.AttendanceSessionResponse
-
-	// For each schedule, get active attendance sessions
-	for _, schedule := range schedules {
-		sessions, err := s.attendanceRepo.GetActiveSessionsForSchedule(schedule.ID)
+	for i := range sessions {
+		session := &sessions[i]
+		endTime := *session.ReopenedUntil
+		closed, err := s.attendanceRepo.CloseReopenedSession(session.ID, endTime)
 		if err != nil {
-			fmt.Printf("Error getting active sessions for schedule %d: %v\n", schedule.ID, err)
+			log.Printf("Failed to close reopened session %d: %v", session.ID, err)
 			continue
 		}
-
-		// Transform to response objects
-		for _, session := range sessions {
-			response, err := s.mapSessionToResponse(&session)
-			if err != nil {
-				continue
-			}
-			allResponses = append(allResponses, *response)
+		if !closed {
+			continue
 		}
-	}
 
-	return allResponses, nil
-}
-
-// GetSessionsByDateRange gets attendance sessions for a user within a date range
-func (s *AttendanceService) GetSessionsByDateRange(userID uint, startDate, endDate time.Time) ([]models.AttendanceSessionResponse, error) {
-	// First, get sessions where the user is directly the lecturer
-	sessions, err := s.attendanceRepo.ListSessionsByDateRange(userID, startDate, endDate)
-	if err != nil {
-		return nil, err
-	}
-
-	// For teaching assistants, also get sessions from courses where they are assigned as TAs
-	// First, get all course IDs where the user is a teaching assistant
-	var courseIDs []uint
-	if err := s.db.Raw(`
-		SELECT course_id FROM teaching_assistant_assignments 
-		WHERE user_id = ?`,
-		userID).Scan(&courseIDs).Error; err != nil {
-		// Just log the error but continue with direct sessions
-		fmt.Printf("Error fetching TA assignments for user %d: %v\n", userID, err)
-	} else if len(courseIDs) > 0 {
-		// Get schedules for these courses
-		var scheduleIDs []uint
-		if err := s.db.Model(&models.CourseSchedule{}).
-			Where("course_id IN (?)", courseIDs).
-			Pluck("id", &scheduleIDs).Error; err != nil {
-			fmt.Printf("Error fetching course schedules for TA %d: %v\n", userID, err)
-		} else if len(scheduleIDs) > 0 {
-			// Get sessions for these schedules in the given date range
-			var taSessions []models.AttendanceSession
-			err := s.db.Preload("CourseSchedule").
-				Preload("CourseSchedule.Course").
-				Preload("CourseSchedule.Room").
-				Where("course_schedule_id IN (?) AND date BETWEEN ? AND ?",
-					scheduleIDs, startDate.Format("2006-01-02"), endDate.Format("2006-01-02")).
-				Find(&taSessions).Error
-
-			if err == nil {
-				// Add these sessions to the list, avoiding duplicates
-				sessionMap := make(map[uint]models.AttendanceSession)
-				for _, s := range sessions {
-					sessionMap[s.ID] = s
-				}
-
-				for _, s := range taSessions {
-					if _, exists := sessionMap[s.ID]; !exists {
-						sessionMap[s.ID] = s
-						sessions = append(sessions, s)
-					}
-				}
-			}
-		}
+		session.Status = models.AttendanceStatusClosed
+		session.EndTime = &endTime
+		session.ReopenedUntil = nil
+		recordFirstClose(session)
+		publishSessionEvent(models.AttendanceEventSessionClosed, session, &session.CourseSchedule)
 	}
+}
 
-	// Transform to response objects
-	var responses []models.AttendanceSessionResponse
-	for _, session := range sessions {
-		response, err := s.mapSessionToResponse(&session)
-		if err != nil {
-			continue
+// manualCheckInStatus turns PRESENT into LATE when a student is marked by hand after the
+// session's late threshold
+func manualCheckInStatus(session *models.AttendanceSession, status models.StudentAttendanceStatus) models.StudentAttendanceStatus {
+	if status == models.StudentAttendanceStatusPresent && session.AllowLate {
+		elapsed := time.Since(session.StartTime)
+		if int(elapsed.Minutes()) > session.LateThreshold {
+			return models.StudentAttendanceStatusLate
 		}
-		responses = append(responses, *response)
 	}
-
-	return responses, nil
+	return status
 }
 
-// GetSessionDetails gets detailed information for an attendance session
-func (s *AttendanceService) GetSessionDetails(sessionID uint, userID uint) (*models.AttendanceSessionResponse, error) {
-	session, err := s.attendanceRepo.GetAttendanceSessionByID(sessionID)
+// GetActiveSessionsByCourse gets all active attendance sessions for a specific course
+func (s *AttendanceService) GetActiveSessionsByCourse(courseID uint) ([]models.AttendanceSessionResponse, error) {
+	// Get all course schedules for this course
+	schedules, err := s.scheduleRepo.GetByCourse(courseID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Check if user is the assigned lecturer for this session
-	if session.LecturerID != userID {
-		// If not, check if they are a teaching assistant for this course
-		var isAssistant bool
-
-		// Get the course ID from the session's schedule
-		var courseID uint
-		if err := s.db.Model(&models.CourseSchedule{}).
-			Where("id = ?", session.CourseScheduleID).
-			Select("course_id").
-			First(&courseID).Error; err != nil {
-			return nil, errors.New("failed to verify course assignment")
-		}
-
-		// Check if the user is a teaching assistant for this course
-		err = s.db.Raw(`
-			SELECT EXISTS (
-				SELECT 1 FROM teaching_assistant_assignments 
-				WHERE user_id = ? AND course_id = ?
-			) as is_assistant`,
-			userID, courseID).Scan(&isAssistant).Error
-
-		if err != nil || !isAssistant {
-			return nil, errors.New("user does not have access to this session")
-		}
+	scheduleIDs := make([]uint, len(schedules))
+	for i, schedule := range schedules {
+		scheduleIDs[i] = schedule.ID
 	}
 
-	return s.mapSessionToResponse(session)
-}
-
-// GetStudentAttendances gets student attendance records for a session
-func (s *AttendanceService) GetStudentAttendances(sessionID uint, userID uint) ([]models.StudentAttendanceResponse, error) {
-	// Verify the session exists
-	session, err := s.attendanceRepo.GetAttendanceSessionByID(sessionID)
+	// Get active attendance sessions for all schedules at once
+	sessions, err := s.attendanceRepo.ListActiveSessionsBySchedules(scheduleIDs)
 	if err != nil {
 		return nil, err
 	}
 
-	// Check if user is the assigned lecturer for this session
-	if session.LecturerID != userID {
-		// If not, check if they are a teaching assistant for this course
-		var isAssistant bool
-
-		// Get the course ID from the session's schedule
-		var courseID uint
-		if err := s.db.Model(&models.CourseSchedule{}).
-			Where("id = ?", session.CourseScheduleID).
-			Select("course_id").
-			First(&courseID).Error; err != nil {
-			return nil, errors.New("failed to verify course assignment")
-		}
-
-		// Check if the user is a teaching assistant for this course
-		err = s.db.Raw(`
-			SELECT EXISTS (
-				SELECT 1 FROM teaching_assistant_assignments 
-				WHERE user_id = ? AND course_id = ?
-			) as is_assistant`,
-			userID, courseID).Scan(&isAssistant).Error
-
-		if err != nil || !isAssistant {
-			return nil, errors.New("user does not have access to this session")
-		}
-	}
+	// Transform to response objects
+	return s.mapSessionsToResponses(sessions)
+}
 
+// listStudentAttendanceResponses lists the attendance records of a session
+func (s *AttendanceService) listStudentAttendanceResponses(sessionID uint) ([]models.StudentAttendanceResponse, error) {
 	// Get all student attendances for this session
 	attendances, err := s.attendanceRepo.ListStudentAttendances(sessionID)
 	if err != nil {
@@ -572,21 +420,6 @@ func (s *AttendanceService) GetStudentAttendances(sessionID uint, userID uint) (
 	return responses, nil
 }
 
-// GetAttendanceStatistics gets attendance statistics for a course
-func (s *AttendanceService) GetAttendanceStatistics(courseScheduleID uint, lecturerID uint) (*models.AttendanceStatistics, error) {
-	// Verify the course schedule exists and lecturer has access
-	schedule, err := s.scheduleRepo.GetByID(courseScheduleID)
-	if err != nil {
-		return nil, err
-	}
-
-	if schedule.UserID != uint(lecturerID) {
-		return nil, errors.New("lecturer does not have access to this course schedule")
-	}
-
-	return s.attendanceRepo.GetAttendanceStats(courseScheduleID)
-}
-
 // GetActiveSessionsBySchedules gets all active attendance sessions for specific schedules
 func (s *AttendanceService) GetActiveSessionsBySchedules(scheduleIDs []uint) ([]models.AttendanceSession, error) {
 	if len(scheduleIDs) == 0 {
@@ -650,11 +483,12 @@ func (s *AttendanceService) MarkStudentAttendanceViaQR(sessionID uint, userID ui
 		return errors.New("student is not enrolled in this course")
 	}
 
-	// If QR data was provided, verify it
-	if qrData != "" && qrData != fmt.Sprintf("delpresence:attendance:%d", sessionID) && qrData != session.QRCodeData {
-		fmt.Printf("QR Code verification failed. Provided: %s, Expected: %s or %s\n",
-			qrData, fmt.Sprintf("delpresence:attendance:%d", sessionID), session.QRCodeData)
-		return errors.New("invalid QR code data")
+	// If QR data was provided, verify it; rotating sessions always require it
+	if qrData != "" || session.QRRotationSeconds > 0 {
+		if valid, _ := verifySessionQRCode(session, qrData, GetIndonesiaTime()); !valid {
+			fmt.Printf("QR Code verification failed for session %d. Provided: %s\n", sessionID, qrData)
+			return errors.New("invalid QR code data")
+		}
 	}
 
 	// Calculate if the student is late based on session settings
@@ -709,6 +543,12 @@ func (s *AttendanceService) MarkStudentAttendanceViaQR(sessionID uint, userID ui
 		}
 	}
 
+	publishStudentAttendanceEvent(models.AttendanceEventStudentCheckedIn, session, student, &models.StudentAttendance{
+		Status:             status,
+		CheckInTime:        &checkInTime,
+		VerificationMethod: "QR_CODE",
+	})
+
 	return nil
 }
 
@@ -718,7 +558,7 @@ func GetIndonesiaTime() time.Time {
 }
 
 // MarkStudentAttendanceByExternalID marks a student's attendance using their external user ID
-func (s *AttendanceService) MarkStudentAttendanceByExternalID(sessionID uint, externalUserID uint, status models.StudentAttendanceStatus, qrData string) error {
+func (s *AttendanceService) MarkStudentAttendanceByExternalID(sessionID uint, externalUserID uint, status models.StudentAttendanceStatus, qrData string, meta CheckInMetadata) error {
 	// Get the session by ID
 	session, err := s.attendanceRepo.GetAttendanceSessionByID(sessionID)
 	if err != nil {
@@ -735,22 +575,43 @@ func (s *AttendanceService) MarkStudentAttendanceByExternalID(sessionID uint, ex
 		return errors.New("this attendance session does not support QR code verification")
 	}
 
+	student, err := s.findEnrolledStudent(session, externalUserID)
+	if err != nil {
+		return err
+	}
+
+	// If QR data was provided, verify it; rotating sessions always require it
+	if qrData != "" || session.QRRotationSeconds > 0 {
+		valid, codeExpiredAt := verifySessionQRCode(session, qrData, GetIndonesiaTime())
+		if !valid {
+			fmt.Printf("QR Code verification failed for session %d. Provided: %s\n", sessionID, qrData)
+			return errors.New("invalid QR code data")
+		}
+		meta.CodeExpiredAt = codeExpiredAt
+	}
+
+	return s.recordCheckIn(session, student, status, string(models.AttendanceTypeQRCode), meta)
+}
+
+// findEnrolledStudent finds the student of an external user ID checking in to a session, failing
+// when the student is not in the session's student group
+func (s *AttendanceService) findEnrolledStudent(session *models.AttendanceSession, externalUserID uint) (*models.Student, error) {
 	// Check if the student exists with this external user ID
 	var student *models.Student
 	result := s.db.Where("user_id = ?", externalUserID).First(&student)
 	if result.Error != nil {
-		return errors.New("student record not found")
+		return nil, errors.New("student record not found")
 	}
 
 	// Get the course schedule to find the student group
 	var schedule models.CourseSchedule
 	if err := s.db.First(&schedule, session.CourseScheduleID).Error; err != nil {
-		return errors.New("course schedule not found")
+		return nil, errors.New("course schedule not found")
 	}
 
 	// Check if the student is in the course's student group
 	var isEnrolled bool
-	err = s.db.Raw(`
+	err := s.db.Raw(`
 		SELECT EXISTS (
 			SELECT 1 FROM student_to_groups
 			WHERE student_group_id = ? AND student_id = ?
@@ -758,19 +619,29 @@ func (s *AttendanceService) MarkStudentAttendanceByExternalID(sessionID uint, ex
 		schedule.StudentGroupID, student.ID).Scan(&isEnrolled).Error
 
 	if err != nil {
-		return errors.New("error checking enrollment: " + err.Error())
+		return nil, errors.New("error checking enrollment: " + err.Error())
 	}
 
 	if !isEnrolled {
-		return errors.New("student is not enrolled in this course")
+		return nil, errors.New("student is not enrolled in this course")
 	}
 
-	// If QR data was provided, verify it
-	if qrData != "" && qrData != fmt.Sprintf("delpresence:attendance:%d", sessionID) && qrData != session.QRCodeData {
-		fmt.Printf("QR Code verification failed. Provided: %s, Expected: %s or %s\n",
-			qrData, fmt.Sprintf("delpresence:attendance:%d", sessionID), session.QRCodeData)
-		return errors.New("invalid QR code data")
-	}
+	return student, nil
+}
+
+// CheckInMetadata is what a student's own check-in records besides its status, for the fraud
+// analysis to compare check-ins
+type CheckInMetadata struct {
+	DeviceID      string
+	Latitude      *float64
+	Longitude     *float64
+	CodeExpiredAt *time.Time // When the rotating code used was replaced, if it already was
+}
+
+// recordCheckIn records a student's own check-in to a session with a verification method, turning
+// PRESENT into LATE past the session's late threshold
+func (s *AttendanceService) recordCheckIn(session *models.AttendanceSession, student *models.Student, status models.StudentAttendanceStatus, method string, meta CheckInMetadata) error {
+	sessionID := session.ID
 
 	// Calculate if the student is late based on session settings
 	now := GetIndonesiaTime()
@@ -786,13 +657,13 @@ func (s *AttendanceService) MarkStudentAttendanceByExternalID(sessionID uint, ex
 
 	// Find existing attendance record by external user ID
 	var attendanceExists bool
-	err = s.db.Raw(`
+	err := s.db.Raw(`
 		SELECT EXISTS (
 			SELECT 1 FROM student_attendances sa
 			JOIN students s ON sa.student_id = s.id
 			WHERE sa.attendance_session_id = ? AND s.user_id = ?
 		) as attendance_exists
-	`, sessionID, externalUserID).Scan(&attendanceExists).Error
+	`, sessionID, student.UserID).Scan(&attendanceExists).Error
 
 	if err != nil {
 		return errors.New("error checking existing attendance: " + err.Error())
@@ -800,14 +671,16 @@ func (s *AttendanceService) MarkStudentAttendanceByExternalID(sessionID uint, ex
 
 	if attendanceExists {
 		// Update existing record using SQL that links by external user ID
-		result = s.db.Exec(`
+		result := s.db.Exec(`
 			UPDATE student_attendances 
-			SET status = ?, verification_method = ?, check_in_time = ?, notes = ?
+			SET status = ?, verification_method = ?, check_in_time = ?, notes = ?,
+				device_id = ?, latitude = ?, longitude = ?, code_expired_at = ?
 			WHERE attendance_session_id = ? 
 			AND student_id IN (
 				SELECT id FROM students WHERE user_id = ?
 			)`,
-			status, "QR_CODE", checkInTime, notes, sessionID, externalUserID)
+			status, method, checkInTime, notes, meta.DeviceID, meta.Latitude, meta.Longitude, meta.CodeExpiredAt,
+			sessionID, student.UserID)
 
 		if result.Error != nil {
 			return errors.New("failed to update attendance: " + result.Error.Error())
@@ -822,8 +695,12 @@ func (s *AttendanceService) MarkStudentAttendanceByExternalID(sessionID uint, ex
 			StudentID:           student.ID,
 			Status:              status,
 			CheckInTime:         &checkInTime,
-			VerificationMethod:  "QR_CODE",
+			VerificationMethod:  method,
 			Notes:               notes,
+			DeviceID:            meta.DeviceID,
+			Latitude:            meta.Latitude,
+			Longitude:           meta.Longitude,
+			CodeExpiredAt:       meta.CodeExpiredAt,
 		}
 
 		if err := s.db.Create(&attendance).Error; err != nil {
@@ -831,6 +708,12 @@ func (s *AttendanceService) MarkStudentAttendanceByExternalID(sessionID uint, ex
 		}
 	}
 
+	publishStudentAttendanceEvent(models.AttendanceEventStudentCheckedIn, session, student, &models.StudentAttendance{
+		Status:             status,
+		CheckInTime:        &checkInTime,
+		VerificationMethod: method,
+	})
+
 	return nil
 }
 
@@ -950,6 +833,7 @@ func (s *AttendanceService) GetStudentAttendanceHistory(externalUserID uint) ([]
 
 		responses = append(responses, models.StudentAttendanceHistoryResponse{
 			ID:                 attendance.ID,
+			Source:             models.AttendanceHistorySourceCourse,
 			Date:               attendance.AttendanceSession.Date.Format("2006-01-02"),
 			CourseCode:         attendance.AttendanceSession.CourseSchedule.Course.Code,
 			CourseName:         attendance.AttendanceSession.CourseSchedule.Course.Name,
@@ -960,7 +844,33 @@ func (s *AttendanceService) GetStudentAttendanceHistory(externalUserID uint) ([]
 		})
 	}
 
-	return responses, nil
+	// Campus events count towards the same history
+	eventAttendances, err := s.eventRepo.ListStudentAttendances(studentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch event attendance history: %v", err)
+	}
+
+	return mergeEventHistory(responses, eventAttendances), nil
+}
+
+// GetStudentGroupIDsByExternalID gets the IDs of the student groups a student belongs to
+func (s *AttendanceService) GetStudentGroupIDsByExternalID(externalUserID uint) ([]uint, error) {
+	student, err := s.studentRepo.FindByUserID(int(externalUserID))
+	if err != nil {
+		return nil, err
+	}
+	if student == nil {
+		return nil, fmt.Errorf("no student found with user ID %d", externalUserID)
+	}
+
+	var groupIDs []uint
+	if err := s.db.Model(&models.StudentToGroup{}).
+		Where("student_id = ?", student.ID).
+		Pluck("student_group_id", &groupIDs).Error; err != nil {
+		return nil, err
+	}
+
+	return groupIDs, nil
 }
 
 // Helper functions
@@ -1013,74 +923,98 @@ func (s *AttendanceService) mapSessionToResponse(session *models.AttendanceSessi
 		return nil, errors.New("invalid session data")
 	}
 
-	// Get attendance counts
-	attendances, err := s.attendanceRepo.ListStudentAttendances(session.ID)
+	responses, err := s.mapSessionsToResponses([]models.AttendanceSession{*session})
 	if err != nil {
 		return nil, err
 	}
+	return &responses[0], nil
+}
 
-	var attendedCount, lateCount, absentCount, excusedCount int
-	for _, a := range attendances {
-		switch a.Status {
-		case models.StudentAttendanceStatusPresent:
-			attendedCount++
-		case models.StudentAttendanceStatusLate:
-			lateCount++
-		case models.StudentAttendanceStatusAbsent:
-			absentCount++
-		case models.StudentAttendanceStatusExcused:
-			excusedCount++
+// mapSessionsToResponses maps sessions to their response format. Attendance counts and
+// group sizes are loaded with one aggregate query each, however many sessions there are.
+// Sessions with incomplete related data are skipped.
+func (s *AttendanceService) mapSessionsToResponses(sessions []models.AttendanceSession) ([]models.AttendanceSessionResponse, error) {
+	var sessionIDs, groupIDs []uint
+	for _, session := range sessions {
+		sessionIDs = append(sessionIDs, session.ID)
+		if session.CourseSchedule.Enrolled == 0 && session.CourseSchedule.StudentGroupID > 0 {
+			groupIDs = append(groupIDs, session.CourseSchedule.StudentGroupID)
 		}
 	}
 
-	endTime := ""
-	if session.EndTime != nil {
-		endTime = session.EndTime.Format("15:04")
-	}
-
-	// Create QR code URL if applicable
-	qrCodeURL := ""
-	if (session.Type == models.AttendanceTypeQRCode || session.Type == models.AttendanceTypeBoth) && session.QRCodeData != "" {
-		qrCodeURL = fmt.Sprintf("/api/attendance/qrcode/%d", session.ID)
-	}
-
-	// Calculate total students directly from database if CourseSchedule.Enrolled is 0
-	totalStudents := session.CourseSchedule.Enrolled
-	if totalStudents == 0 && session.CourseSchedule.StudentGroupID > 0 {
-		var count int64
-		s.db.Model(&models.StudentToGroup{}).
-			Where("student_group_id = ?", session.CourseSchedule.StudentGroupID).
-			Count(&count)
-		totalStudents = int(count)
-	}
-
-	return &models.AttendanceSessionResponse{
-		ID:                session.ID,
-		CourseScheduleID:  session.CourseScheduleID,
-		CourseCode:        session.CourseSchedule.Course.Code,
-		CourseName:        session.CourseSchedule.Course.Name,
-		Room:              session.CourseSchedule.Room.Name,
-		Date:              session.Date.Format("2006-01-02"),
-		StartTime:         session.StartTime.Format("15:04"),
-		EndTime:           endTime,
-		ScheduleStartTime: session.CourseSchedule.StartTime,
-		ScheduleEndTime:   session.CourseSchedule.EndTime,
-		Type:              string(session.Type),
-		Status:            string(session.Status),
-		CreatorRole:       session.CreatorRole,
-		AutoClose:         session.AutoClose,
-		Duration:          session.Duration,
-		AllowLate:         session.AllowLate,
-		LateThreshold:     session.LateThreshold,
-		Notes:             session.Notes,
-		QRCodeURL:         qrCodeURL,
-		TotalStudents:     totalStudents,
-		AttendedCount:     attendedCount,
-		LateCount:         lateCount,
-		AbsentCount:       absentCount,
-		ExcusedCount:      excusedCount,
-		CreatedAt:         session.CreatedAt,
-	}, nil
+	counts, err := s.statsRepo.GetCountsBySession(sessionIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count attendances: %w", err)
+	}
+
+	groupSizes, err := s.statsRepo.GetGroupSizes(groupIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count students: %w", err)
+	}
+
+	var responses []models.AttendanceSessionResponse
+	for _, session := range sessions {
+		if session.CourseSchedule.Course.ID == 0 || session.CourseSchedule.Room.ID == 0 {
+			continue
+		}
+
+		endTime := ""
+		if session.EndTime != nil {
+			endTime = session.EndTime.Format("15:04")
+		}
+
+		// Create QR code URL if applicable
+		qrCodeURL := ""
+		if (session.Type == models.AttendanceTypeQRCode || session.Type == models.AttendanceTypeBoth) && session.QRCodeData != "" {
+			qrCodeURL = fmt.Sprintf("/api/attendance/qrcode/%d", session.ID)
+		}
+
+		// Use the group size if CourseSchedule.Enrolled is 0
+		totalStudents := session.CourseSchedule.Enrolled
+		if totalStudents == 0 {
+			totalStudents = groupSizes[session.CourseSchedule.StudentGroupID]
+		}
+
+		sessionCounts := counts[session.ID]
+
+		var editableUntil *time.Time
+		if session.Status == models.AttendanceStatusClosed {
+			deadline := sessionEditDeadline(&session)
+			editableUntil = &deadline
+		}
+
+		responses = append(responses, models.AttendanceSessionResponse{
+			ID:                session.ID,
+			CourseScheduleID:  session.CourseScheduleID,
+			CourseCode:        session.CourseSchedule.Course.Code,
+			CourseName:        session.CourseSchedule.Course.Name,
+			Room:              session.CourseSchedule.Room.Name,
+			Date:              session.Date.Format("2006-01-02"),
+			StartTime:         session.StartTime.Format("15:04"),
+			EndTime:           endTime,
+			ScheduleStartTime: session.CourseSchedule.StartTime,
+			ScheduleEndTime:   session.CourseSchedule.EndTime,
+			Type:              string(session.Type),
+			Status:            string(session.Status),
+			CreatorRole:       session.CreatorRole,
+			AutoClose:         session.AutoClose,
+			Duration:          session.Duration,
+			AllowLate:         session.AllowLate,
+			LateThreshold:     session.LateThreshold,
+			Notes:             session.Notes,
+			QRCodeURL:         qrCodeURL,
+			TotalStudents:     totalStudents,
+			AttendedCount:     sessionCounts.Present,
+			LateCount:         sessionCounts.Late,
+			AbsentCount:       sessionCounts.Absent,
+			ExcusedCount:      sessionCounts.Excused,
+			ReopenedUntil:     session.ReopenedUntil,
+			EditableUntil:     editableUntil,
+			CreatedAt:         session.CreatedAt,
+		})
+	}
+
+	return responses, nil
 }
 
 // generateQRCodeData generates a random string for QR code data
@@ -0,0 +1,479 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/delpresence/backend/internal/database"
+	"github.com/delpresence/backend/internal/models"
+	"github.com/delpresence/backend/internal/repositories"
+	"github.com/delpresence/backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrAttendanceActionNotAllowed is returned when the actor's policy does not grant an action
+	ErrAttendanceActionNotAllowed = errors.New("this action is not allowed for your role")
+
+	// ErrSessionAccessDenied is returned when the user has no relation to the session or schedule
+	// that the actor's policy accepts
+	ErrSessionAccessDenied = errors.New("you do not have access to this attendance session")
+
+	// ErrCourseAccessDenied is returned when the user teaches or assists no schedule of a course
+	ErrCourseAccessDenied = errors.New("you do not have access to this course")
+
+	// ErrOutsideMeetingRange is returned when a teaching team member opens a meeting outside the
+	// range of meetings they are assigned to
+	ErrOutsideMeetingRange = errors.New("this meeting is outside the meetings you are assigned to teach")
+
+	// ErrEditWindowClosed is returned when a closed session is changed after its grace window
+	ErrEditWindowClosed = errors.New("the correction window of this session has closed; ask an admin to override it")
+)
+
+// AttendanceSessionService runs attendance sessions on behalf of one actor. Every operation is
+// checked against the actor's AttendanceActorPolicy, so lecturers, teaching assistants, admins and
+// any other actor share the same code and differ only in policy.
+type AttendanceSessionService struct {
+	policy            AttendanceActorPolicy
+	attendanceService *AttendanceService
+	attendanceRepo    *repositories.AttendanceRepository
+	scheduleRepo      *repositories.CourseScheduleRepository
+	assistantRepo     *repositories.TeachingAssistantAssignmentRepository
+	lecturerRepo      *repositories.LecturerAssignmentRepository
+	studentRepo       *repositories.StudentRepository
+	overrideRepo      *repositories.AttendanceOverrideRepository
+	db                *gorm.DB
+}
+
+// NewAttendanceSessionService creates an attendance session service for an actor
+func NewAttendanceSessionService(actor AttendanceActor) *AttendanceSessionService {
+	return &AttendanceSessionService{
+		policy:            LoadAttendanceActorPolicy(actor),
+		attendanceService: NewAttendanceService(),
+		attendanceRepo:    repositories.NewAttendanceRepository(),
+		scheduleRepo:      repositories.NewCourseScheduleRepository(),
+		assistantRepo:     repositories.NewTeachingAssistantAssignmentRepository(),
+		lecturerRepo:      repositories.NewLecturerAssignmentRepository(),
+		studentRepo:       repositories.NewStudentRepository(),
+		overrideRepo:      repositories.NewAttendanceOverrideRepository(),
+		db:                database.GetDB(),
+	}
+}
+
+// NewAttendanceSessionServiceForRole creates an attendance session service for the actor of a role
+func NewAttendanceSessionServiceForRole(role string) (*AttendanceSessionService, error) {
+	actor, err := AttendanceActorForRole(role)
+	if err != nil {
+		return nil, err
+	}
+	return NewAttendanceSessionService(actor), nil
+}
+
+// Policy returns the policy the service enforces
+func (s *AttendanceSessionService) Policy() AttendanceActorPolicy {
+	return s.policy
+}
+
+// OpenSession opens an attendance session for a schedule
+func (s *AttendanceSessionService) OpenSession(userID, scheduleID uint, date time.Time, attendanceType models.AttendanceType, settings map[string]interface{}) (*models.AttendanceSessionResponse, error) {
+	if !s.policy.Allows(AttendanceActionOpen) {
+		return nil, ErrAttendanceActionNotAllowed
+	}
+
+	schedule, err := s.scheduleRepo.GetByID(scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkScheduleAccess(schedule, userID); err != nil {
+		return nil, err
+	}
+	if err := s.checkMeetingRange(schedule, userID, date); err != nil {
+		return nil, err
+	}
+
+	session, err := s.attendanceService.openSession(s.policy.Actor, userID, schedule, date, attendanceType, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reload with the schedule, course and room for the response
+	created, err := s.attendanceRepo.GetAttendanceSessionByID(session.ID)
+	if err != nil {
+		return nil, err
+	}
+	return s.attendanceService.mapSessionToResponse(created)
+}
+
+// ListActiveSessions lists the active sessions the user can see
+func (s *AttendanceSessionService) ListActiveSessions(userID uint) ([]models.AttendanceSessionResponse, error) {
+	return s.listSessions(userID, repositories.SessionListFilter{ActiveOnly: true})
+}
+
+// ListSessions lists the sessions the user can see within a date range
+func (s *AttendanceSessionService) ListSessions(userID uint, startDate, endDate time.Time) ([]models.AttendanceSessionResponse, error) {
+	return s.listSessions(userID, repositories.SessionListFilter{StartDate: &startDate, EndDate: &endDate})
+}
+
+// GetSession returns the details of a session
+func (s *AttendanceSessionService) GetSession(sessionID, userID uint) (*models.AttendanceSessionResponse, error) {
+	session, err := s.authorize(AttendanceActionView, sessionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.attendanceService.mapSessionToResponse(session)
+}
+
+// GetStudentAttendances returns the attendance records of a session
+func (s *AttendanceSessionService) GetStudentAttendances(sessionID, userID uint) ([]models.StudentAttendanceResponse, error) {
+	if _, err := s.authorize(AttendanceActionView, sessionID, userID); err != nil {
+		return nil, err
+	}
+	return s.attendanceService.listStudentAttendanceResponses(sessionID)
+}
+
+// CloseSession closes an active session
+func (s *AttendanceSessionService) CloseSession(sessionID, userID uint) error {
+	session, err := s.authorize(AttendanceActionClose, sessionID, userID)
+	if err != nil {
+		return err
+	}
+	teaches, err := s.scheduleRepo.IsTaughtBy(session.CourseSchedule, userID)
+	if err != nil {
+		return err
+	}
+	return s.attendanceService.closeSession(session, userID, sessionActorRole(s.policy.Actor, teaches))
+}
+
+// CancelSession cancels an active session
+func (s *AttendanceSessionService) CancelSession(sessionID, userID uint) error {
+	session, err := s.authorize(AttendanceActionCancel, sessionID, userID)
+	if err != nil {
+		return err
+	}
+	return s.attendanceService.cancelSession(session)
+}
+
+// MarkStudentAttendance sets a student's status by hand. In an active session this is a manual
+// check-in; in a closed session it is a correction that needs a reason, is allowed only within the
+// grace window and is recorded in the session's history.
+func (s *AttendanceSessionService) MarkStudentAttendance(sessionID, studentID uint, status models.StudentAttendanceStatus, verificationMethod, notes, reason string, userID uint) error {
+	session, err := s.loadSession(sessionID, userID)
+	if err != nil {
+		return err
+	}
+
+	student, err := s.studentRepo.FindByID(studentID)
+	if err != nil {
+		return err
+	}
+	if err := checkSessionStudent(s.db, session, student); err != nil {
+		return err
+	}
+
+	if session.Status == models.AttendanceStatusActive {
+		if !s.policy.Allows(AttendanceActionMark) {
+			return ErrAttendanceActionNotAllowed
+		}
+		return s.attendanceService.markStudentAttendance(session, studentID, status, verificationMethod, notes, &userID)
+	}
+
+	if !s.policy.Allows(AttendanceActionCorrect) {
+		return ErrAttendanceActionNotAllowed
+	}
+	if err := checkEditWindow(session); err != nil {
+		return err
+	}
+	if strings.TrimSpace(reason) == "" {
+		return ErrOverrideReasonRequired
+	}
+
+	attendance, err := saveStudentStatusOverride(s.db, s.attendanceRepo, session, student, status, reason, userID, s.policy.Actor)
+	if err != nil {
+		return err
+	}
+
+	publishStudentAttendanceEvent(models.AttendanceEventStudentStatusChanged, session, student, attendance)
+	return nil
+}
+
+// ReopenSession makes a closed session active again for a few minutes, so students who missed
+// it can still check in. It closes itself when the minutes are up.
+func (s *AttendanceSessionService) ReopenSession(sessionID, userID uint, minutes int, reason string) (*models.AttendanceSessionResponse, error) {
+	session, err := s.authorize(AttendanceActionReopen, sessionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkEditWindow(session); err != nil {
+		return nil, err
+	}
+
+	maxMinutes := utils.GetEnvAsInt("ATTENDANCE_REOPEN_MAX_MINUTES", 15)
+	if minutes == 0 {
+		minutes = maxMinutes
+	}
+	if minutes < 0 || minutes > maxMinutes {
+		return nil, fmt.Errorf("%w: a session can be reopened for 1 to %d minutes", ErrInvalidOverride, maxMinutes)
+	}
+
+	active, err := s.attendanceRepo.GetActiveSessionsForSchedule(session.CourseScheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if len(active) > 0 {
+		return nil, fmt.Errorf("%w: the schedule already has an active session", ErrInvalidOverride)
+	}
+
+	reopenedUntil := GetIndonesiaTime().Add(time.Duration(minutes) * time.Minute)
+	recordFirstClose(session)
+	session.EndTime = nil
+	session.ReopenedUntil = &reopenedUntil
+	if err := saveSessionStatusOverride(s.db, session, models.AttendanceStatusActive, models.AttendanceOverrideReopenSession, reason, userID, s.policy.Actor); err != nil {
+		return nil, err
+	}
+
+	publishSessionEvent(models.AttendanceEventSessionOpened, session, &session.CourseSchedule)
+	return s.attendanceService.mapSessionToResponse(session)
+}
+
+// GetSessionHistory lists the corrections, reopenings and admin overrides of a session
+func (s *AttendanceSessionService) GetSessionHistory(sessionID, userID uint) ([]models.AttendanceOverride, error) {
+	if _, err := s.authorize(AttendanceActionView, sessionID, userID); err != nil {
+		return nil, err
+	}
+	return s.overrideRepo.List(repositories.AttendanceOverrideFilter{SessionID: sessionID})
+}
+
+// GetScheduleStatistics returns the attendance statistics of a schedule
+func (s *AttendanceSessionService) GetScheduleStatistics(scheduleID, userID uint, filter repositories.AttendanceStatsFilter) (*models.AttendanceStatistics, error) {
+	if !s.policy.Allows(AttendanceActionStatistics) {
+		return nil, ErrAttendanceActionNotAllowed
+	}
+
+	schedule, err := s.scheduleRepo.GetByID(scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkScheduleAccess(schedule, userID); err != nil {
+		return nil, err
+	}
+
+	return NewAttendanceStatisticsService().GetScheduleStatistics(scheduleID, filter)
+}
+
+// GetQRCodePayload returns the text students scan to check in to a QR code session
+func (s *AttendanceSessionService) GetQRCodePayload(sessionID, userID uint) (string, error) {
+	session, err := s.authorize(AttendanceActionView, sessionID, userID)
+	if err != nil {
+		return "", err
+	}
+
+	if session.Type != models.AttendanceTypeQRCode && session.Type != models.AttendanceTypeBoth {
+		return "", errors.New("this session does not use QR code")
+	}
+
+	return sessionQRPayload(session, GetIndonesiaTime()), nil
+}
+
+// RenderSessionReport renders the xlsx attendance report of a session
+func (s *AttendanceSessionService) RenderSessionReport(sessionID, userID uint) ([]byte, string, error) {
+	session, err := s.authorize(AttendanceActionView, sessionID, userID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	response, err := s.attendanceService.mapSessionToResponse(session)
+	if err != nil {
+		return nil, "", err
+	}
+	attendances, err := s.attendanceService.listStudentAttendanceResponses(sessionID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	content, err := RenderSessionReportXLSX(response, attendances)
+	return content, SessionReportFilename(response), err
+}
+
+// authorize loads a session and checks that the policy grants the action on it to the user
+func (s *AttendanceSessionService) authorize(action AttendanceAction, sessionID, userID uint) (*models.AttendanceSession, error) {
+	if !s.policy.Allows(action) {
+		return nil, ErrAttendanceActionNotAllowed
+	}
+	return s.loadSession(sessionID, userID)
+}
+
+// loadSession loads a session the policy gives the user access to
+func (s *AttendanceSessionService) loadSession(sessionID, userID uint) (*models.AttendanceSession, error) {
+	session, err := s.attendanceRepo.GetAttendanceSessionByID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.policy.Grants(AttendanceAccessCreator) && session.LecturerID == userID {
+		return session, nil
+	}
+	if err := s.checkScheduleAccess(session.CourseSchedule, userID); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// checkScheduleAccess checks that the user relates to the schedule in a way the policy accepts
+func (s *AttendanceSessionService) checkScheduleAccess(schedule models.CourseSchedule, userID uint) error {
+	if s.policy.Grants(AttendanceAccessAll) {
+		return nil
+	}
+	if s.policy.Grants(AttendanceAccessScheduleLecturer) {
+		teaches, err := s.scheduleRepo.IsTaughtBy(schedule, userID)
+		if err != nil {
+			return err
+		}
+		if teaches {
+			return nil
+		}
+	}
+	if s.policy.Grants(AttendanceAccessCourseAssistant) {
+		isAssistant, err := s.assistantRepo.AssignmentExistsForCourse(int(userID), schedule.CourseID)
+		if err != nil {
+			return err
+		}
+		if isAssistant {
+			return nil
+		}
+	}
+	return ErrSessionAccessDenied
+}
+
+// checkCourseAccess checks that the user relates to a course in a way the policy accepts: as a
+// lecturer of one of its schedules or as one of its assistants
+func (s *AttendanceSessionService) checkCourseAccess(courseID, userID uint) error {
+	if s.policy.Grants(AttendanceAccessAll) {
+		return nil
+	}
+	if s.policy.Grants(AttendanceAccessScheduleLecturer) {
+		schedules, err := s.scheduleRepo.GetByCourse(courseID)
+		if err != nil {
+			return err
+		}
+		for _, schedule := range schedules {
+			teaches, err := s.scheduleRepo.IsTaughtBy(schedule, userID)
+			if err != nil {
+				return err
+			}
+			if teaches {
+				return nil
+			}
+		}
+	}
+	if s.policy.Grants(AttendanceAccessCourseAssistant) {
+		isAssistant, err := s.assistantRepo.AssignmentExistsForCourse(int(userID), courseID)
+		if err != nil {
+			return err
+		}
+		if isAssistant {
+			return nil
+		}
+	}
+	return ErrCourseAccessDenied
+}
+
+// checkMeetingRange checks that a teaching team member only opens the meetings they are assigned
+// to. The schedule's own lecturer and users outside the team are not limited. A date that already
+// has a session keeps its meeting number; otherwise it is the next meeting.
+func (s *AttendanceSessionService) checkMeetingRange(schedule models.CourseSchedule, userID uint, date time.Time) error {
+	if schedule.UserID == userID {
+		return nil
+	}
+	assignment, err := s.lecturerRepo.FindForSchedule(userID, schedule)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if assignment.MeetingFrom == nil && assignment.MeetingTo == nil {
+		return nil
+	}
+
+	held, err := s.attendanceRepo.CountMeetingsBefore(schedule.ID, date)
+	if err != nil {
+		return err
+	}
+	if !assignment.CoversMeeting(int(held) + 1) {
+		return ErrOutsideMeetingRange
+	}
+	return nil
+}
+
+// listSessions lists sessions matching the filter, narrowed to those the policy gives the user access to
+func (s *AttendanceSessionService) listSessions(userID uint, filter repositories.SessionListFilter) ([]models.AttendanceSessionResponse, error) {
+	if !s.policy.Allows(AttendanceActionView) {
+		return nil, ErrAttendanceActionNotAllowed
+	}
+
+	if s.policy.Grants(AttendanceAccessAll) {
+		filter.All = true
+	} else {
+		if s.policy.Grants(AttendanceAccessCreator) {
+			filter.CreatorID = userID
+		}
+		if s.policy.Grants(AttendanceAccessScheduleLecturer) {
+			ids, err := s.scheduleRepo.ListIDsByLecturer(userID)
+			if err != nil {
+				return nil, err
+			}
+			filter.ScheduleIDs = append(filter.ScheduleIDs, ids...)
+		}
+		if s.policy.Grants(AttendanceAccessCourseAssistant) {
+			ids, err := s.scheduleRepo.ListIDsByAssistant(userID)
+			if err != nil {
+				return nil, err
+			}
+			filter.ScheduleIDs = append(filter.ScheduleIDs, ids...)
+		}
+	}
+
+	sessions, err := s.attendanceRepo.ListSessions(filter)
+	if err != nil {
+		return nil, err
+	}
+	return s.attendanceService.mapSessionsToResponses(sessions)
+}
+
+// sessionEditDeadline returns when a closed session stops accepting corrections and reopening by
+// lecturers; after it only admin overrides can change it. ATTENDANCE_EDIT_GRACE_DAYS sets the
+// number of days after the session first closed (default 3), however often it was reopened.
+func sessionEditDeadline(session *models.AttendanceSession) time.Time {
+	closedAt := session.Date
+	if session.FirstClosedAt != nil {
+		closedAt = *session.FirstClosedAt
+	} else if session.EndTime != nil {
+		closedAt = *session.EndTime
+	}
+	return closedAt.AddDate(0, 0, utils.GetEnvAsInt("ATTENDANCE_EDIT_GRACE_DAYS", 3))
+}
+
+// recordFirstClose keeps the time a session first closed, so reopening it cannot extend its
+// correction window. Sessions closed before the time was recorded use their end time.
+func recordFirstClose(session *models.AttendanceSession) {
+	if session.FirstClosedAt == nil && session.EndTime != nil {
+		closedAt := *session.EndTime
+		session.FirstClosedAt = &closedAt
+	}
+}
+
+// checkEditWindow checks that a session is closed and still within its grace window
+func checkEditWindow(session *models.AttendanceSession) error {
+	switch session.Status {
+	case models.AttendanceStatusCanceled:
+		return fmt.Errorf("%w: attendance session is canceled", ErrInvalidOverride)
+	case models.AttendanceStatusActive:
+		return fmt.Errorf("%w: attendance session is still active", ErrInvalidOverride)
+	}
+	if GetIndonesiaTime().After(sessionEditDeadline(session)) {
+		return ErrEditWindowClosed
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/delpresence/backend/internal/models"
+	"github.com/delpresence/backend/internal/pdf"
+	"github.com/delpresence/backend/internal/qrcode"
+)
+
+// attendanceSheet is the content of one printed attendance document: a header with a
+// verification QR code, a student table, notes and signature columns
+type attendanceSheet struct {
+	Title      string
+	Subtitle   string
+	Header     [][2]string
+	Columns    []pdf.Column
+	Rows       [][]string
+	RowHeight  float64
+	CellFill   func(row, column int) *pdf.Color // Background of a table cell, nil for none
+	Notes      []string
+	Signatures []signatureBlock
+	VerifyRef  string // Reference encoded in the verification QR code, empty for none
+}
+
+// statusFills are the cell backgrounds of attendance statuses, matching the xlsx report colors
+var statusFills = map[string]pdf.Color{
+	"H": pdf.Hex("C6EFCE"),
+	"T": pdf.Hex("FFEB9C"),
+	"A": pdf.Hex("FFC7CE"),
+	"I": pdf.Hex("DDEBF7"),
+}
+
+// renderAttendanceSheets renders sheets into one PDF, each starting on a new page
+func renderAttendanceSheets(title string, landscape bool, sheets []attendanceSheet) ([]byte, error) {
+	doc := pdf.New(title)
+	if landscape {
+		doc = pdf.NewLandscape(title)
+	}
+	doc.Footer = pageNumberFooter
+
+	for _, sheet := range sheets {
+		doc.AddPage()
+		if err := drawSheetHeader(doc, sheet); err != nil {
+			return nil, err
+		}
+
+		rowHeight := sheet.RowHeight
+		if rowHeight == 0 {
+			rowHeight = 16
+		}
+		doc.Table(sheet.Columns, sheet.Rows, pdf.TableStyle{FontSize: 8.5, RowHeight: rowHeight, CellFill: sheet.CellFill})
+
+		if len(sheet.Notes) > 0 {
+			doc.Space(8)
+			for _, note := range sheet.Notes {
+				doc.Paragraph(note, pdf.Regular, 9)
+			}
+		}
+		if len(sheet.Signatures) > 0 {
+			drawSignatures(doc, sheet.Signatures)
+		}
+	}
+
+	return doc.Bytes()
+}
+
+// drawSheetHeader draws the title, the header fields and the verification QR code at the top right
+func drawSheetHeader(doc *pdf.Document, sheet attendanceSheet) error {
+	qrSize := 0.0
+	if sheet.VerifyRef != "" {
+		code, err := qrcode.Encode(sheetVerificationURL(sheet.VerifyRef))
+		if err != nil {
+			return err
+		}
+		qrSize = 78
+		moduleSize := qrSize / float64(code.Size)
+		x := doc.Width - doc.Margin - qrSize
+		doc.Modules(x, doc.Margin, moduleSize, code.Size, code.Dark)
+		doc.TextInBox(x-10, doc.Margin+qrSize+9, qrSize+20, pdf.AlignCenter, pdf.Regular, 6.5, "Pindai untuk verifikasi")
+		doc.TextInBox(x-10, doc.Margin+qrSize+17, qrSize+20, pdf.AlignCenter, pdf.Regular, 6.5, "Ref. "+sheet.VerifyRef)
+	}
+
+	textWidth := doc.ContentWidth() - qrSize - 12
+	doc.TextInBox(doc.Margin, doc.Y+14, textWidth, pdf.AlignLeft, pdf.Bold, 14, sheet.Title)
+	doc.Y += 20
+	if sheet.Subtitle != "" {
+		doc.TextInBox(doc.Margin, doc.Y+10, textWidth, pdf.AlignLeft, pdf.Regular, 10, sheet.Subtitle)
+		doc.Y += 16
+	}
+	doc.Y += 4
+
+	labelWidth := 85.0
+	for _, field := range sheet.Header {
+		doc.Text(doc.Margin, doc.Y+9, pdf.Regular, 9, field[0])
+		doc.Text(doc.Margin+labelWidth, doc.Y+9, pdf.Regular, 9, ":")
+		doc.TextInBox(doc.Margin+labelWidth+6, doc.Y+9, textWidth-labelWidth-6, pdf.AlignLeft, pdf.Bold, 9, field[1])
+		doc.Y += 13
+	}
+
+	if bottom := doc.Margin + qrSize + 22; doc.Y < bottom {
+		doc.Y = bottom
+	}
+	doc.Y += 8
+	return nil
+}
+
+// sheetVerificationURL returns the public URL that verifies a printed sheet reference
+func sheetVerificationURL(ref string) string {
+	return publicURL(fmt.Sprintf("/api/attendance-sheets/verify?ref=%s&sig=%s",
+		url.QueryEscape(ref), signSheetReference(ref)))
+}
+
+// signSheetReference signs a sheet reference so that printed QR codes cannot be forged
+func signSheetReference(ref string) string {
+	return signReportValue("ATTENDANCE_SHEET_SECRET", "attendance-sheet|"+ref)
+}
+
+// RenderMatrixPDF renders attendance matrices as a printable semester recap, one schedule per page
+func RenderMatrixPDF(matrices []models.AttendanceMatrix) ([]byte, error) {
+	sheets := make([]attendanceSheet, 0, len(matrices))
+	for _, matrix := range matrices {
+		sheets = append(sheets, matrixSheet(matrix))
+	}
+	return renderAttendanceSheets(fmt.Sprintf("Rekap Presensi %s", matrices[0].CourseCode), true, sheets)
+}
+
+// matrixSheet lays out one attendance matrix as a landscape recap sheet
+func matrixSheet(matrix models.AttendanceMatrix) attendanceSheet {
+	contentWidth := pdf.NewLandscape("").ContentWidth()
+	fixed := []pdf.Column{
+		{Header: "No", Width: 22, Align: pdf.AlignCenter},
+		{Header: "NIM", Width: 62},
+		{Header: "Nama Mahasiswa", Width: 140},
+	}
+	totals := []pdf.Column{
+		{Header: "H", Width: 22, Align: pdf.AlignCenter},
+		{Header: "T", Width: 22, Align: pdf.AlignCenter},
+		{Header: "A", Width: 22, Align: pdf.AlignCenter},
+		{Header: "I", Width: 22, Align: pdf.AlignCenter},
+		{Header: "%", Width: 44, Align: pdf.AlignRight},
+	}
+
+	used := 0.0
+	for _, column := range append(fixed, totals...) {
+		used += column.Width
+	}
+	meetingWidth := 24.0
+	if n := len(matrix.Meetings); n > 0 && (contentWidth-used)/float64(n) < meetingWidth {
+		meetingWidth = (contentWidth - used) / float64(n)
+	}
+
+	columns := append([]pdf.Column{}, fixed...)
+	for _, meeting := range matrix.Meetings {
+		columns = append(columns, pdf.Column{Header: fmt.Sprint(meeting.Number), Width: meetingWidth, Align: pdf.AlignCenter})
+	}
+	columns = append(columns, totals...)
+
+	rows := make([][]string, 0, len(matrix.Rows))
+	for i, student := range matrix.Rows {
+		row := []string{fmt.Sprint(i + 1), student.NIM, student.FullName}
+		row = append(row, student.Codes...)
+		row = append(row, fmt.Sprint(student.Present), fmt.Sprint(student.Late), fmt.Sprint(student.Absent),
+			fmt.Sprint(student.Excused), fmt.Sprintf("%.1f", student.AttendanceRate))
+		rows = append(rows, row)
+	}
+
+	dates := make([]string, 0, len(matrix.Meetings))
+	for _, meeting := range matrix.Meetings {
+		dates = append(dates, fmt.Sprintf("%d: %s", meeting.Number, shortDate(meeting.Date)))
+	}
+
+	firstMeeting := len(fixed)
+	return attendanceSheet{
+		Title:    "REKAP PRESENSI PERKULIAHAN",
+		Subtitle: fmt.Sprintf("Tahun Akademik %s %s", matrix.AcademicYearName, matrix.Semester),
+		Header: [][2]string{
+			{"Mata Kuliah", fmt.Sprintf("%s - %s", matrix.CourseCode, matrix.CourseName)},
+			{"Kelas", matrix.StudentGroupName},
+			{"Dosen", matrix.LecturerName},
+			{"Jadwal", fmt.Sprintf("%s, %s - %s", matrix.Day, matrix.StartTime, matrix.EndTime)},
+		},
+		Columns: columns,
+		Rows:    rows,
+		CellFill: func(row, column int) *pdf.Color {
+			if column < firstMeeting || column >= firstMeeting+len(matrix.Meetings) {
+				return nil
+			}
+			if fill, ok := statusFills[matrix.Rows[row].Codes[column-firstMeeting]]; ok {
+				return &fill
+			}
+			return nil
+		},
+		Notes: []string{
+			"Keterangan: H = Hadir, T = Terlambat, A = Alpa, I = Izin, - = Tidak ada catatan.",
+			"Tanggal pertemuan: " + strings.Join(dates, ", "),
+		},
+		Signatures: []signatureBlock{
+			{Role: "Mengetahui,\nKetua Program Studi", ID: "NIP."},
+			{Role: "Dosen Pengampu", Name: matrix.LecturerName},
+		},
+		VerifyRef: fmt.Sprintf("r%d", matrix.CourseScheduleID),
+	}
+}
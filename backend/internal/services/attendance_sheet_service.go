@@ -0,0 +1,382 @@
+package services
+
+import (
+	"crypto/hmac"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/delpresence/backend/internal/models"
+	"github.com/delpresence/backend/internal/pdf"
+	"github.com/delpresence/backend/internal/repositories"
+	"github.com/delpresence/backend/internal/utils"
+)
+
+// SheetVerification describes the record behind a printed attendance sheet's QR code
+type SheetVerification struct {
+	Reference        string                         `json:"reference"`
+	Kind             string                         `json:"kind"` // session, planned or recap
+	CourseScheduleID uint                           `json:"course_schedule_id"`
+	CourseCode       string                         `json:"course_code"`
+	CourseName       string                         `json:"course_name"`
+	StudentGroupName string                         `json:"student_group_name"`
+	LecturerName     string                         `json:"lecturer_name"`
+	Date             string                         `json:"date,omitempty"`
+	SessionID        uint                           `json:"session_id,omitempty"`
+	SessionStatus    string                         `json:"session_status,omitempty"`
+	Counts           *models.AttendanceStatusCounts `json:"counts,omitempty"`
+	Sessions         int                            `json:"sessions,omitempty"`
+}
+
+// AttendanceSheetService generates printable attendance sheets (daftar hadir) and verifies their QR codes
+type AttendanceSheetService struct {
+	scheduleRepo   *repositories.CourseScheduleRepository
+	attendanceRepo *repositories.AttendanceRepository
+	groupRepo      *repositories.StudentGroupRepository
+	statsRepo      *repositories.AttendanceStatisticsRepository
+	lecturerRepo   *repositories.LecturerRepository
+	assistantRepo  *repositories.TeachingAssistantAssignmentRepository
+}
+
+// NewAttendanceSheetService creates a new attendance sheet service
+func NewAttendanceSheetService() *AttendanceSheetService {
+	return &AttendanceSheetService{
+		scheduleRepo:   repositories.NewCourseScheduleRepository(),
+		attendanceRepo: repositories.NewAttendanceRepository(),
+		groupRepo:      repositories.NewStudentGroupRepository(),
+		statsRepo:      repositories.NewAttendanceStatisticsRepository(),
+		lecturerRepo:   repositories.NewLecturerRepository(),
+		assistantRepo:  repositories.NewTeachingAssistantAssignmentRepository(),
+	}
+}
+
+// PlannedSheetPDF renders a blank attendance sheet for a schedule's meeting on the given date,
+// listing the members of its student group with space to sign
+func (s *AttendanceSheetService) PlannedSheetPDF(scheduleID uint, date time.Time, role string, userID uint) ([]byte, string, error) {
+	schedule, err := s.accessibleSchedule(scheduleID, role, userID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if weekday, ok := scheduleWeekday(schedule.Day); ok && weekday != date.Weekday() {
+		return nil, "", fmt.Errorf("schedule meets on %s, not on %s", schedule.Day, indonesianDayName(date.Weekday()))
+	}
+
+	students, err := s.groupRepo.GetGroupMembers(schedule.StudentGroupID)
+	if err != nil {
+		return nil, "", err
+	}
+	sort.Slice(students, func(i, j int) bool { return students[i].NIM < students[j].NIM })
+
+	previous, err := s.statsRepo.CountSessionsBefore(schedule.ID, date)
+	if err != nil {
+		return nil, "", err
+	}
+
+	lecturer, err := s.lecturerRepo.GetByUserID(int(schedule.UserID))
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Signatures alternate between the left and right half of the column, as on paper sheets
+	rows := make([][]string, 0, len(students))
+	for i, student := range students {
+		signature := fmt.Sprintf("%d.", i+1)
+		if i%2 == 1 {
+			signature = "                              " + signature
+		}
+		rows = append(rows, []string{fmt.Sprint(i + 1), student.NIM, student.FullName, signature, ""})
+	}
+
+	sheet := attendanceSheet{
+		Title:    "DAFTAR HADIR PERKULIAHAN",
+		Subtitle: fmt.Sprintf("Tahun Akademik %s %s", schedule.AcademicYear.Name, schedule.AcademicYear.Semester),
+		Header: append(scheduleSheetHeader(schedule, lecturer.FullName),
+			[2]string{"Tanggal", fmt.Sprintf("%s, %s", indonesianDayName(date.Weekday()), formatIndonesianDate(date))},
+			[2]string{"Pertemuan ke", fmt.Sprint(previous + 1)},
+		),
+		Columns: []pdf.Column{
+			{Header: "No", Width: 25, Align: pdf.AlignCenter},
+			{Header: "NIM", Width: 75},
+			{Header: "Nama Mahasiswa", Width: 185},
+			{Header: "Tanda Tangan", Width: 150},
+			{Header: "Keterangan", Width: 80},
+		},
+		Rows:      rows,
+		RowHeight: 22,
+		Notes: []string{
+			"Materi perkuliahan: ................................................................................................................",
+			"Jumlah mahasiswa hadir: ........ dari " + fmt.Sprint(len(students)) + " mahasiswa",
+		},
+		Signatures: []signatureBlock{{Role: "Dosen Pengampu", Name: lecturer.FullName, ID: nipLabel(lecturer.NIP)}},
+		VerifyRef:  fmt.Sprintf("p%d-%s", schedule.ID, date.Format("20060102")),
+	}
+
+	content, err := renderAttendanceSheets("Daftar Hadir "+schedule.Course.Code, false, []attendanceSheet{sheet})
+	if err != nil {
+		return nil, "", err
+	}
+	return content, reportFilename("pdf", "Daftar_Hadir", schedule.Course.Code, schedule.StudentGroup.Name, date.Format("2006-01-02")), nil
+}
+
+// SessionSheetPDF renders the filled attendance sheet of a closed session
+func (s *AttendanceSheetService) SessionSheetPDF(sessionID uint, role string, userID uint) ([]byte, string, error) {
+	session, err := s.attendanceRepo.GetAttendanceSessionByID(sessionID)
+	if err != nil {
+		return nil, "", errors.New("attendance session not found")
+	}
+	if session.Status != models.AttendanceStatusClosed {
+		return nil, "", errors.New("attendance sheets can only be printed for closed sessions")
+	}
+
+	schedule, err := s.scheduleRepo.GetByID(session.CourseScheduleID)
+	if err != nil {
+		return nil, "", errors.New("course schedule not found")
+	}
+	if session.LecturerID != userID {
+		allowed, err := canAccessSchedule(s.scheduleRepo, s.assistantRepo, schedule, role, userID)
+		if err != nil {
+			return nil, "", err
+		}
+		if !allowed {
+			return nil, "", ErrScheduleForbidden
+		}
+	}
+
+	records, err := s.attendanceRepo.ListStudentAttendances(session.ID)
+	if err != nil {
+		return nil, "", err
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Student.NIM < records[j].Student.NIM })
+
+	lecturer, err := s.lecturerRepo.GetByUserID(int(schedule.UserID))
+	if err != nil {
+		return nil, "", err
+	}
+
+	location := getIndonesiaLocation()
+	counts := models.AttendanceStatusCounts{Total: len(records)}
+	rows := make([][]string, 0, len(records))
+	for i, record := range records {
+		checkIn := ""
+		if record.CheckInTime != nil {
+			checkIn = record.CheckInTime.In(location).Format("15:04")
+		}
+		switch record.Status {
+		case models.StudentAttendanceStatusPresent:
+			counts.Present++
+		case models.StudentAttendanceStatusLate:
+			counts.Late++
+		case models.StudentAttendanceStatusAbsent:
+			counts.Absent++
+		case models.StudentAttendanceStatusExcused:
+			counts.Excused++
+		}
+		rows = append(rows, []string{
+			fmt.Sprint(i + 1), record.Student.NIM, record.Student.FullName,
+			utils.AttendanceStatusLabel(string(record.Status)), checkIn, verificationMethodText(record.VerificationMethod), "",
+		})
+	}
+
+	closedAt := ""
+	if session.EndTime != nil {
+		closedAt = session.EndTime.In(location).Format("15:04")
+	}
+
+	notes := []string{fmt.Sprintf("Hadir: %d, Terlambat: %d, Tidak Hadir: %d, Izin: %d dari %d mahasiswa (%s).",
+		counts.Present, counts.Late, counts.Absent, counts.Excused, counts.Total, formatPercent(counts.AttendedRate()))}
+	if session.Notes != "" {
+		notes = append(notes, "Catatan: "+session.Notes)
+	}
+
+	sheet := attendanceSheet{
+		Title:    "DAFTAR HADIR PERKULIAHAN",
+		Subtitle: fmt.Sprintf("Tahun Akademik %s %s", schedule.AcademicYear.Name, schedule.AcademicYear.Semester),
+		Header: append(scheduleSheetHeader(schedule, lecturer.FullName),
+			[2]string{"Tanggal", fmt.Sprintf("%s, %s", indonesianDayName(session.Date.Weekday()), formatIndonesianDate(session.Date))},
+			[2]string{"Sesi", fmt.Sprintf("%s - %s WIB", session.StartTime.In(location).Format("15:04"), closedAt)},
+		),
+		Columns: []pdf.Column{
+			{Header: "No", Width: 25, Align: pdf.AlignCenter},
+			{Header: "NIM", Width: 70},
+			{Header: "Nama Mahasiswa", Width: 160},
+			{Header: "Status", Width: 70, Align: pdf.AlignCenter},
+			{Header: "Jam", Width: 45, Align: pdf.AlignCenter},
+			{Header: "Metode", Width: 65},
+			{Header: "Paraf", Width: 80},
+		},
+		Rows:      rows,
+		RowHeight: 18,
+		CellFill: func(row, column int) *pdf.Color {
+			if column != 3 {
+				return nil
+			}
+			if fill, ok := statusFills[models.AttendanceStatusCode(records[row].Status)]; ok {
+				return &fill
+			}
+			return nil
+		},
+		Notes:      notes,
+		Signatures: []signatureBlock{{Role: "Dosen Pengampu", Name: lecturer.FullName, ID: nipLabel(lecturer.NIP)}},
+		VerifyRef:  fmt.Sprintf("s%d", session.ID),
+	}
+
+	content, err := renderAttendanceSheets("Daftar Hadir "+schedule.Course.Code, false, []attendanceSheet{sheet})
+	if err != nil {
+		return nil, "", err
+	}
+	return content, reportFilename("pdf", "Daftar_Hadir", schedule.Course.Code, schedule.StudentGroup.Name, session.Date.Format("2006-01-02")), nil
+}
+
+// VerifySheet checks the signature of a printed sheet reference and describes the record behind it
+func (s *AttendanceSheetService) VerifySheet(ref, signature string) (*SheetVerification, error) {
+	if ref == "" || !hmac.Equal([]byte(signSheetReference(ref)), []byte(strings.ToLower(signature))) {
+		return nil, errors.New("invalid or forged attendance sheet reference")
+	}
+
+	verification := &SheetVerification{Reference: ref}
+	var scheduleID uint
+	var date time.Time
+
+	switch ref[0] {
+	case 's':
+		sessionID, err := strconv.ParseUint(ref[1:], 10, 32)
+		if err != nil {
+			return nil, errors.New("invalid attendance sheet reference")
+		}
+		session, err := s.attendanceRepo.GetAttendanceSessionByID(uint(sessionID))
+		if err != nil {
+			return nil, errors.New("attendance session no longer exists")
+		}
+		verification.Kind = "session"
+		scheduleID = session.CourseScheduleID
+		date = session.Date
+		if err := s.describeSession(verification, session); err != nil {
+			return nil, err
+		}
+	case 'p':
+		parts := strings.SplitN(ref[1:], "-", 2)
+		if len(parts) != 2 {
+			return nil, errors.New("invalid attendance sheet reference")
+		}
+		id, err := strconv.ParseUint(parts[0], 10, 32)
+		if err != nil {
+			return nil, errors.New("invalid attendance sheet reference")
+		}
+		date, err = time.Parse("20060102", parts[1])
+		if err != nil {
+			return nil, errors.New("invalid attendance sheet reference")
+		}
+		verification.Kind = "planned"
+		scheduleID = uint(id)
+
+		session, err := s.statsRepo.FindSessionOnDate(scheduleID, date)
+		if err != nil {
+			return nil, err
+		}
+		if session != nil {
+			if err := s.describeSession(verification, session); err != nil {
+				return nil, err
+			}
+		}
+	case 'r':
+		id, err := strconv.ParseUint(ref[1:], 10, 32)
+		if err != nil {
+			return nil, errors.New("invalid attendance sheet reference")
+		}
+		verification.Kind = "recap"
+		scheduleID = uint(id)
+
+		sessions, err := s.statsRepo.GetMatrixSessions([]uint{scheduleID})
+		if err != nil {
+			return nil, err
+		}
+		verification.Sessions = len(sessions)
+	default:
+		return nil, errors.New("invalid attendance sheet reference")
+	}
+
+	schedule, err := s.scheduleRepo.GetByID(scheduleID)
+	if err != nil {
+		return nil, errors.New("course schedule no longer exists")
+	}
+	lecturer, err := s.lecturerRepo.GetByUserID(int(schedule.UserID))
+	if err != nil {
+		return nil, err
+	}
+
+	verification.CourseScheduleID = schedule.ID
+	verification.CourseCode = schedule.Course.Code
+	verification.CourseName = schedule.Course.Name
+	verification.StudentGroupName = schedule.StudentGroup.Name
+	verification.LecturerName = lecturer.FullName
+	if !date.IsZero() {
+		verification.Date = date.Format("2006-01-02")
+	}
+
+	return verification, nil
+}
+
+// describeSession adds the status and attendance counts of a session to a verification
+func (s *AttendanceSheetService) describeSession(verification *SheetVerification, session *models.AttendanceSession) error {
+	counts, err := s.statsRepo.GetCountsBySession([]uint{session.ID})
+	if err != nil {
+		return err
+	}
+	sessionCounts := counts[session.ID]
+	verification.SessionID = session.ID
+	verification.SessionStatus = string(session.Status)
+	verification.Counts = &sessionCounts
+	return nil
+}
+
+// accessibleSchedule loads a schedule and checks that the user may print its sheets
+func (s *AttendanceSheetService) accessibleSchedule(scheduleID uint, role string, userID uint) (models.CourseSchedule, error) {
+	schedule, err := s.scheduleRepo.GetByID(scheduleID)
+	if err != nil {
+		return schedule, errors.New("course schedule not found")
+	}
+
+	allowed, err := canAccessSchedule(s.scheduleRepo, s.assistantRepo, schedule, role, userID)
+	if err != nil {
+		return schedule, err
+	}
+	if !allowed {
+		return schedule, ErrScheduleForbidden
+	}
+	return schedule, nil
+}
+
+// scheduleSheetHeader returns the course and room header fields of a schedule's sheets
+func scheduleSheetHeader(schedule models.CourseSchedule, lecturerName string) [][2]string {
+	room := schedule.Room.Name
+	if schedule.Room.Building.Name != "" {
+		room = fmt.Sprintf("%s (%s)", schedule.Room.Name, schedule.Room.Building.Name)
+	}
+	return [][2]string{
+		{"Mata Kuliah", fmt.Sprintf("%s - %s (%d SKS)", schedule.Course.Code, schedule.Course.Name, schedule.Course.Credits)},
+		{"Kelas", schedule.StudentGroup.Name},
+		{"Dosen", lecturerName},
+		{"Ruangan", room},
+		{"Jadwal", fmt.Sprintf("%s, %s - %s", schedule.Day, schedule.StartTime, schedule.EndTime)},
+	}
+}
+
+// verificationMethodText translates a verification method to Indonesian
+func verificationMethodText(method string) string {
+	switch method {
+	case "QR_CODE":
+		return "Kode QR"
+	case "QR_CODE_OFFLINE":
+		return "Kode QR (offline)"
+	case "FACE_RECOGNITION":
+		return "Wajah"
+	case "MANUAL":
+		return "Manual"
+	default:
+		return method
+	}
+}
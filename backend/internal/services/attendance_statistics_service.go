@@ -0,0 +1,116 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/delpresence/backend/internal/models"
+	"github.com/delpresence/backend/internal/repositories"
+)
+
+// AttendanceStatisticsService computes attendance statistics with a fixed number of aggregate queries,
+// regardless of how many sessions or students are involved
+type AttendanceStatisticsService struct {
+	statsRepo    *repositories.AttendanceStatisticsRepository
+	scheduleRepo *repositories.CourseScheduleRepository
+}
+
+// NewAttendanceStatisticsService creates a new attendance statistics service
+func NewAttendanceStatisticsService() *AttendanceStatisticsService {
+	return &AttendanceStatisticsService{
+		statsRepo:    repositories.NewAttendanceStatisticsRepository(),
+		scheduleRepo: repositories.NewCourseScheduleRepository(),
+	}
+}
+
+// ParseAttendanceBreakdown validates a breakdown name from a request
+func ParseAttendanceBreakdown(value string) (models.AttendanceBreakdown, error) {
+	breakdown := models.AttendanceBreakdown(strings.ToLower(strings.TrimSpace(value)))
+	switch breakdown {
+	case models.AttendanceBreakdownSession, models.AttendanceBreakdownSchedule, models.AttendanceBreakdownCourse,
+		models.AttendanceBreakdownStudent, models.AttendanceBreakdownGroup, models.AttendanceBreakdownFaculty,
+		models.AttendanceBreakdownStudyProgram, models.AttendanceBreakdownLecturer,
+		models.AttendanceBreakdownDayOfWeek, models.AttendanceBreakdownTimeSlot:
+		return breakdown, nil
+	default:
+		return "", errors.New("group_by must be one of session, schedule, course, student, group, faculty, study_program, lecturer, day_of_week or time_slot")
+	}
+}
+
+// GetScheduleStatistics returns the statistics of a course schedule, with per-session and
+// per-student breakdowns. The filter can narrow it down further, e.g. to a date range.
+func (s *AttendanceStatisticsService) GetScheduleStatistics(courseScheduleID uint, filter repositories.AttendanceStatsFilter) (*models.AttendanceStatistics, error) {
+	schedule, err := s.scheduleRepo.GetByID(courseScheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter.CourseScheduleIDs = []uint{courseScheduleID}
+
+	stats := &models.AttendanceStatistics{
+		TotalStudents: schedule.Enrolled,
+	}
+
+	if stats.TotalStudents == 0 && schedule.StudentGroupID > 0 {
+		sizes, err := s.statsRepo.GetGroupSizes([]uint{schedule.StudentGroupID})
+		if err != nil {
+			return nil, err
+		}
+		stats.TotalStudents = sizes[schedule.StudentGroupID]
+	}
+
+	sessionCounts, err := s.statsRepo.GetSessionStatusCounts(filter)
+	if err != nil {
+		return nil, err
+	}
+	stats.ActiveSessions = sessionCounts[models.AttendanceStatusActive]
+	stats.ClosedSessions = sessionCounts[models.AttendanceStatusClosed]
+	stats.CanceledSessions = sessionCounts[models.AttendanceStatusCanceled]
+	stats.TotalSessions = stats.ActiveSessions + stats.ClosedSessions + stats.CanceledSessions
+
+	totals, err := s.statsRepo.GetTotals(filter)
+	if err != nil {
+		return nil, err
+	}
+	stats.TotalAttendance = totals.Present
+	stats.TotalLate = totals.Late
+	stats.TotalAbsent = totals.Absent
+	stats.TotalExcused = totals.Excused
+	stats.TotalRecords = totals.Total
+	stats.AttendanceRate = totals.AttendedRate()
+
+	// Calculate average attendance percentage
+	if stats.TotalSessions > 0 && stats.TotalStudents > 0 {
+		totalPossibleAttendances := stats.TotalSessions * stats.TotalStudents
+		totalPresent := stats.TotalAttendance + stats.TotalLate
+		stats.AverageAttendance = (totalPresent * 100) / totalPossibleAttendances
+	}
+
+	if stats.BySession, err = s.statsRepo.GetBreakdown(models.AttendanceBreakdownSession, filter); err != nil {
+		return nil, err
+	}
+	if stats.ByStudent, err = s.statsRepo.GetBreakdown(models.AttendanceBreakdownStudent, filter); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// GetBreakdown returns attendance counts grouped by the given dimension along with the overall totals
+func (s *AttendanceStatisticsService) GetBreakdown(breakdown models.AttendanceBreakdown, filter repositories.AttendanceStatsFilter) ([]models.AttendanceBreakdownItem, models.AttendanceStatusCounts, error) {
+	items, err := s.statsRepo.GetBreakdown(breakdown, filter)
+	if err != nil {
+		return nil, models.AttendanceStatusCounts{}, err
+	}
+
+	var totals models.AttendanceStatusCounts
+	for _, item := range items {
+		totals.Present += item.Present
+		totals.Late += item.Late
+		totals.Absent += item.Absent
+		totals.Excused += item.Excused
+		totals.Total += item.Total
+	}
+
+	return items, totals, nil
+}
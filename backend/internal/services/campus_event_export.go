@@ -0,0 +1,101 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/delpresence/backend/internal/models"
+	"github.com/delpresence/backend/internal/utils"
+	"github.com/tealeg/xlsx/v3"
+)
+
+// CampusEventReportFilename returns the download file name of a campus event attendance report
+func CampusEventReportFilename(event *models.CampusEventResponse) string {
+	return reportFilename("xlsx", "Presensi_Kegiatan", event.Title,
+		event.StartAt.In(getIndonesiaLocation()).Format("2006-01-02"))
+}
+
+// RenderCampusEventReportXLSX renders the attendance report of a campus event in the layout of
+// session reports
+func RenderCampusEventReportXLSX(event *models.CampusEventResponse, attendances []models.StudentAttendanceResponse) ([]byte, error) {
+	location := getIndonesiaLocation()
+	return renderAttendanceReportXLSX(attendanceReportHeader{
+		SubjectLabel:  "Kegiatan",
+		Subject:       event.Title,
+		Date:          event.StartAt.In(location).Format("2006-01-02"),
+		StartTime:     event.StartAt.In(location).Format("15:04"),
+		EndTime:       event.EndAt.In(location).Format("15:04"),
+		Room:          event.RoomName,
+		TotalStudents: event.TotalStudents,
+		AttendedCount: event.AttendedCount,
+		LateCount:     event.LateCount,
+		AbsentCount:   event.AbsentCount,
+		ExcusedCount:  event.ExcusedCount,
+	}, attendances)
+}
+
+// RollCallAbsenceFilename returns the download file name of a roll call absence report
+func RollCallAbsenceFilename(report *models.RollCallAbsenceReport) string {
+	return reportFilename("xlsx", "Absensi_Apel", report.Dormitory, report.From, report.To)
+}
+
+// RenderRollCallAbsenceXLSX renders a roll call absence report with one row per student
+func RenderRollCallAbsenceXLSX(report *models.RollCallAbsenceReport) ([]byte, error) {
+	file := xlsx.NewFile()
+	sheet, err := file.AddSheet("Absensi Apel")
+	if err != nil {
+		return nil, err
+	}
+
+	titleCell := sheet.AddRow().AddCell()
+	titleCell.Value = "REKAP KETIDAKHADIRAN APEL ASRAMA"
+	titleCell.SetStyle(utils.NewXLSXTitleStyle())
+	sheet.AddRow()
+
+	info := [][2]string{
+		{"Asrama", report.Dormitory},
+		{"Periode", fmt.Sprintf("%s s.d. %s", report.From, report.To)},
+		{"Jumlah Apel", fmt.Sprintf("%d", report.RollCalls)},
+		{"Minimal Tidak Hadir", fmt.Sprintf("%d", report.MinAbsences)},
+	}
+	for _, item := range info {
+		row := sheet.AddRow()
+		row.AddCell().Value = item[0]
+		row.AddCell().Value = item[1]
+	}
+	sheet.AddRow()
+
+	headerStyle := utils.NewXLSXHeaderStyle()
+	header := sheet.AddRow()
+	for _, title := range []string{"No", "NIM", "Nama Mahasiswa", "Apel", "Hadir", "Terlambat", "Izin", "Tidak Hadir", "Persentase", "Tanggal Tidak Hadir"} {
+		addStyledString(header, title, headerStyle)
+	}
+
+	dataStyle := utils.NewXLSXCellStyle()
+	for i, student := range report.Students {
+		row := sheet.AddRow()
+		addStyledInt(row, i+1, dataStyle)
+		addStyledString(row, student.StudentNIM, dataStyle)
+		addStyledString(row, student.StudentName, dataStyle)
+		addStyledInt(row, student.RollCalls, dataStyle)
+		addStyledInt(row, student.PresentCount, dataStyle)
+		addStyledInt(row, student.LateCount, dataStyle)
+		addStyledInt(row, student.ExcusedCount, dataStyle)
+		addStyledInt(row, student.AbsentCount, dataStyle)
+		addStyledString(row, formatPercent(student.AttendanceRate), dataStyle)
+		addStyledString(row, strings.Join(student.AbsenceDates, ", "), dataStyle)
+	}
+
+	sheet.SetColWidth(1, 1, 5)
+	sheet.SetColWidth(2, 2, 14)
+	sheet.SetColWidth(3, 3, 30)
+	sheet.SetColWidth(4, 9, 11)
+	sheet.SetColWidth(10, 10, 50)
+
+	buf := &bytes.Buffer{}
+	if err := file.Write(buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
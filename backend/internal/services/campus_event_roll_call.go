@@ -0,0 +1,207 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/delpresence/backend/internal/models"
+)
+
+// maxRollCallNights is the longest period roll calls can be scheduled for at once
+const maxRollCallNights = 62
+
+// RollCallScheduleInput is the body of a request scheduling nightly roll calls of a dormitory
+type RollCallScheduleInput struct {
+	Dormitory      string `json:"dormitory"`
+	StartDate      string `json:"start_date"`     // YYYY-MM-DD
+	EndDate        string `json:"end_date"`       // YYYY-MM-DD, inclusive
+	StartTime      string `json:"start_time"`     // HH:MM WIB, when check-in opens
+	WindowMinutes  int    `json:"window_minutes"` // How long check-in stays open, default 30
+	Weekdays       []int  `json:"weekdays"`       // 0 (Sunday) to 6; empty schedules every night
+	AttendanceType string `json:"attendance_type"`
+	AllowLate      *bool  `json:"allow_late"`
+	LateThreshold  *int   `json:"late_threshold"`
+	RoomID         *uint  `json:"room_id"`
+	Location       string `json:"location"`
+	Organizer      string `json:"organizer"`
+}
+
+// ScheduleRollCalls creates a self-opening roll call of a dormitory for every selected night of a
+// period. Nights that already have a roll call at that time are skipped, so scheduling the same
+// period again only fills the gaps.
+func (s *CampusEventService) ScheduleRollCalls(input RollCallScheduleInput, userID uint, role string) ([]models.CampusEventResponse, error) {
+	dormitory := strings.TrimSpace(input.Dormitory)
+	if dormitory == "" {
+		return nil, fmt.Errorf("%w: dormitory is required", ErrInvalidCampusEvent)
+	}
+
+	location := getIndonesiaLocation()
+	startDate, err := time.ParseInLocation("2006-01-02", input.StartDate, location)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid start_date", ErrInvalidCampusEvent)
+	}
+	endDate, err := time.ParseInLocation("2006-01-02", input.EndDate, location)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid end_date", ErrInvalidCampusEvent)
+	}
+	if endDate.Before(startDate) {
+		return nil, fmt.Errorf("%w: end_date must not be before start_date", ErrInvalidCampusEvent)
+	}
+	if endDate.Sub(startDate) >= maxRollCallNights*24*time.Hour {
+		return nil, fmt.Errorf("%w: roll calls can be scheduled for at most %d nights at once", ErrInvalidCampusEvent, maxRollCallNights)
+	}
+
+	startTime, err := time.Parse("15:04", input.StartTime)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid start_time, use HH:MM", ErrInvalidCampusEvent)
+	}
+	window := input.WindowMinutes
+	if window == 0 {
+		window = 30
+	}
+	if window < 0 {
+		return nil, fmt.Errorf("%w: window_minutes cannot be negative", ErrInvalidCampusEvent)
+	}
+
+	weekdays := make(map[time.Weekday]bool, len(input.Weekdays))
+	for _, day := range input.Weekdays {
+		if day < 0 || day > 6 {
+			return nil, fmt.Errorf("%w: weekdays must be between 0 (Sunday) and 6", ErrInvalidCampusEvent)
+		}
+		weekdays[time.Weekday(day)] = true
+	}
+
+	autoOpenClose := true
+	var created []models.CampusEvent
+	for date := startDate; !date.After(endDate); date = date.AddDate(0, 0, 1) {
+		if len(weekdays) > 0 && !weekdays[date.Weekday()] {
+			continue
+		}
+
+		startAt := time.Date(date.Year(), date.Month(), date.Day(), startTime.Hour(), startTime.Minute(), 0, 0, location)
+		exists, err := s.repo.RollCallExists(dormitory, startAt)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			continue
+		}
+
+		event := &models.CampusEvent{
+			OrganizerID:   userID,
+			OrganizerRole: strings.ToUpper(role),
+			Status:        models.CampusEventScheduled,
+			AllowLate:     true,
+			LateThreshold: 10,
+		}
+		err = s.applyInput(event, CampusEventInput{
+			Title:             fmt.Sprintf("Apel Malam %s %s", dormitory, date.Format("2006-01-02")),
+			Category:          string(models.CampusEventRollCall),
+			StartAt:           startAt.Format(time.RFC3339),
+			EndAt:             startAt.Add(time.Duration(window) * time.Minute).Format(time.RFC3339),
+			RoomID:            input.RoomID,
+			Location:          input.Location,
+			Organizer:         input.Organizer,
+			AudienceType:      string(models.CampusEventAudienceDormitory),
+			AudienceDormitory: dormitory,
+			AttendanceType:    input.AttendanceType,
+			AllowLate:         input.AllowLate,
+			LateThreshold:     input.LateThreshold,
+			AutoOpenClose:     &autoOpenClose,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := s.repo.Create(event); err != nil {
+			return nil, err
+		}
+		created = append(created, *event)
+	}
+
+	return s.mapEventsToResponses(created)
+}
+
+// ListDormitories lists the dormitories of synced students
+func (s *CampusEventService) ListDormitories() ([]models.DormitorySummary, error) {
+	return s.repo.ListDormitories()
+}
+
+// GetRollCallAbsenceReport lists the students of a dormitory who missed at least minAbsences of the
+// roll calls held between two dates (inclusive), most absences first
+func (s *CampusEventService) GetRollCallAbsenceReport(dormitory string, from, to time.Time, minAbsences int) (*models.RollCallAbsenceReport, error) {
+	dormitory = strings.TrimSpace(dormitory)
+	if dormitory == "" {
+		return nil, fmt.Errorf("%w: dormitory is required", ErrInvalidCampusEvent)
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: to must not be before from", ErrInvalidCampusEvent)
+	}
+	if minAbsences < 1 {
+		minAbsences = 1
+	}
+
+	location := getIndonesiaLocation()
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, location)
+	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, location).AddDate(0, 0, 1)
+
+	rollCalls, err := s.repo.CountRollCalls(dormitory, start, end)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.repo.ListRollCallAttendances(dormitory, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.RollCallAbsenceReport{
+		Dormitory:   dormitory,
+		From:        start.Format("2006-01-02"),
+		To:          to.Format("2006-01-02"),
+		RollCalls:   int(rollCalls),
+		MinAbsences: minAbsences,
+		Students:    []models.RollCallAbsence{},
+	}
+
+	// Rows come ordered by NIM, so each student's records are contiguous
+	var current *models.RollCallAbsence
+	flush := func() {
+		if current == nil || current.AbsentCount < minAbsences {
+			return
+		}
+		current.AttendanceRate = float64(current.PresentCount+current.LateCount) / float64(current.RollCalls) * 100
+		report.Students = append(report.Students, *current)
+	}
+	for _, row := range rows {
+		if current == nil || current.StudentID != row.StudentID {
+			flush()
+			current = &models.RollCallAbsence{
+				StudentID:    row.StudentID,
+				StudentName:  row.StudentName,
+				StudentNIM:   row.StudentNIM,
+				Dormitory:    dormitory,
+				AbsenceDates: []string{},
+			}
+		}
+
+		current.RollCalls++
+		switch row.Status {
+		case models.StudentAttendanceStatusPresent:
+			current.PresentCount++
+		case models.StudentAttendanceStatusLate:
+			current.LateCount++
+		case models.StudentAttendanceStatusExcused:
+			current.ExcusedCount++
+		case models.StudentAttendanceStatusAbsent:
+			current.AbsentCount++
+			current.AbsenceDates = append(current.AbsenceDates, row.StartAt.In(location).Format("2006-01-02"))
+		}
+	}
+	flush()
+
+	sort.SliceStable(report.Students, func(i, j int) bool {
+		return report.Students[i].AbsentCount > report.Students[j].AbsentCount
+	})
+	return report, nil
+}
@@ -0,0 +1,747 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/delpresence/backend/internal/models"
+	"github.com/delpresence/backend/internal/repositories"
+)
+
+// campusEventWindowInterval is how often self-opening events are checked for the start and end of
+// their check-in window
+const campusEventWindowInterval = 30 * time.Second
+
+var (
+	// ErrInvalidCampusEvent is returned when a campus event input is invalid
+	ErrInvalidCampusEvent = errors.New("invalid campus event")
+
+	// ErrCampusEventAccessDenied is returned when a user other than the organizer or an admin
+	// manages an event
+	ErrCampusEventAccessDenied = errors.New("you do not manage this campus event")
+
+	// ErrCampusEventNotOpen is returned when a student checks in while the event's check-in is not open
+	ErrCampusEventNotOpen = errors.New("check-in for this event is not open")
+
+	// ErrAlreadyCheckedIn is returned when a student checks in to an event again, or after the
+	// organizer marked them
+	ErrAlreadyCheckedIn = errors.New("your attendance for this event is already recorded")
+
+	// ErrNotInEventAudience is returned when a student outside an event's audience checks in or is marked
+	ErrNotInEventAudience = errors.New("student is not in the audience of this event")
+)
+
+// CampusEventInput is the body of a campus event create or update request. Times are RFC 3339 or
+// "YYYY-MM-DD HH:MM" in Indonesia time.
+type CampusEventInput struct {
+	Title                  string `json:"title"`
+	Description            string `json:"description"`
+	Category               string `json:"category"`
+	StartAt                string `json:"start_at"`
+	EndAt                  string `json:"end_at"`
+	RoomID                 *uint  `json:"room_id"`
+	Location               string `json:"location"`
+	Organizer              string `json:"organizer"`
+	AudienceType           string `json:"audience_type"`
+	AudienceStudyProgramID *int   `json:"audience_study_program_id"`
+	AudienceIntakeYear     *int   `json:"audience_intake_year"`
+	AudienceStudentGroupID *uint  `json:"audience_student_group_id"`
+	AudienceDormitory      string `json:"audience_dormitory"`
+	StudentIDs             []uint `json:"student_ids"` // Invited students of a LIST audience
+	AttendanceType         string `json:"attendance_type"`
+	AllowLate              *bool  `json:"allow_late"`
+	LateThreshold          *int   `json:"late_threshold"`
+	AutoOpenClose          *bool  `json:"auto_open_close"` // Defaults to true for roll calls
+}
+
+// StudentCampusEvent is an event in a student's event list with the student's own attendance
+type StudentCampusEvent struct {
+	models.CampusEventResponse
+	MyStatus string `json:"my_status,omitempty"`
+}
+
+// CampusEventService manages campus events outside courses and their attendance. Admins manage
+// every event; other organizers manage the events they created.
+type CampusEventService struct {
+	repo        *repositories.CampusEventRepository
+	studentRepo *repositories.StudentRepository
+	roomRepo    *repositories.RoomRepository
+}
+
+// NewCampusEventService creates a new campus event service
+func NewCampusEventService() *CampusEventService {
+	return &CampusEventService{
+		repo:        repositories.NewCampusEventRepository(),
+		studentRepo: repositories.NewStudentRepository(),
+		roomRepo:    repositories.NewRoomRepository(),
+	}
+}
+
+// CreateEvent creates a scheduled campus event organized by the user
+func (s *CampusEventService) CreateEvent(input CampusEventInput, userID uint, role string) (*models.CampusEventResponse, error) {
+	event := &models.CampusEvent{
+		OrganizerID:   userID,
+		OrganizerRole: strings.ToUpper(role),
+		Status:        models.CampusEventScheduled,
+		AllowLate:     true,
+		LateThreshold: 10,
+	}
+	if err := s.applyInput(event, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(event); err != nil {
+		return nil, err
+	}
+	if event.AudienceType == models.CampusEventAudienceList {
+		if err := s.repo.ReplaceParticipants(event.ID, input.StudentIDs); err != nil {
+			return nil, err
+		}
+	}
+	return s.GetEvent(event.ID, userID, role)
+}
+
+// UpdateEvent replaces the details of an event that is not closed or canceled. When check-in is
+// already open, students added to the audience get an ABSENT record.
+func (s *CampusEventService) UpdateEvent(eventID uint, input CampusEventInput, userID uint, role string) (*models.CampusEventResponse, error) {
+	event, err := s.loadManaged(eventID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status == models.CampusEventClosed || event.Status == models.CampusEventCanceled {
+		return nil, fmt.Errorf("%w: a %s event can no longer be changed", ErrInvalidCampusEvent, strings.ToLower(string(event.Status)))
+	}
+
+	if err := s.applyInput(event, input); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(event); err != nil {
+		return nil, err
+	}
+	if event.AudienceType == models.CampusEventAudienceList {
+		if err := s.repo.ReplaceParticipants(event.ID, input.StudentIDs); err != nil {
+			return nil, err
+		}
+	}
+	if event.Status == models.CampusEventActive {
+		if err := s.initializeAttendances(event); err != nil {
+			return nil, err
+		}
+	}
+	return s.GetEvent(event.ID, userID, role)
+}
+
+// ListEvents lists the events the user manages within an optional time range
+func (s *CampusEventService) ListEvents(userID uint, role string, filter repositories.CampusEventFilter) ([]models.CampusEventResponse, error) {
+	if !isAdminRole(role) {
+		filter.OrganizerID = userID
+	}
+	events, err := s.repo.List(filter)
+	if err != nil {
+		return nil, err
+	}
+	return s.mapEventsToResponses(events)
+}
+
+// GetEvent returns an event the user manages
+func (s *CampusEventService) GetEvent(eventID, userID uint, role string) (*models.CampusEventResponse, error) {
+	event, err := s.loadManaged(eventID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	responses, err := s.mapEventsToResponses([]models.CampusEvent{*event})
+	if err != nil {
+		return nil, err
+	}
+	return &responses[0], nil
+}
+
+// OpenCheckIn opens check-in for an event: it generates the QR code of QR events and records
+// every student of the audience as ABSENT until they check in
+func (s *CampusEventService) OpenCheckIn(eventID, userID uint, role string) (*models.CampusEventResponse, error) {
+	event, err := s.loadManaged(eventID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status != models.CampusEventScheduled && event.Status != models.CampusEventClosed {
+		return nil, fmt.Errorf("%w: check-in of a %s event cannot be opened", ErrInvalidCampusEvent, strings.ToLower(string(event.Status)))
+	}
+
+	if _, err := s.openCheckIn(event); err != nil {
+		return nil, err
+	}
+	return s.GetEvent(event.ID, userID, role)
+}
+
+// CloseCheckIn closes check-in for an event; students who did not check in stay ABSENT
+func (s *CampusEventService) CloseCheckIn(eventID, userID uint, role string) (*models.CampusEventResponse, error) {
+	event, err := s.loadManaged(eventID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status != models.CampusEventActive {
+		return nil, fmt.Errorf("%w: check-in of this event is not open", ErrInvalidCampusEvent)
+	}
+
+	if _, err := s.closeCheckIn(event); err != nil {
+		return nil, err
+	}
+	return s.GetEvent(event.ID, userID, role)
+}
+
+// StartWindowWorker opens and closes the check-in of self-opening events, such as roll calls, at
+// the start and end of their window (safe to run on every replica)
+func (s *CampusEventService) StartWindowWorker() {
+	go func() {
+		ticker := time.NewTicker(campusEventWindowInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			s.applyCheckInWindows()
+		}
+	}()
+
+	log.Printf("Campus event check-in window worker started (interval %s)", campusEventWindowInterval)
+}
+
+// applyCheckInWindows opens the self-opening events whose window started and closes those whose
+// window ended
+func (s *CampusEventService) applyCheckInWindows() {
+	now := GetIndonesiaTime()
+
+	due, err := s.repo.ListDueForOpening(now)
+	if err != nil {
+		log.Printf("Failed to list campus events due for opening: %v", err)
+	}
+	for i := range due {
+		if _, err := s.openCheckIn(&due[i]); err != nil {
+			log.Printf("Failed to open check-in of campus event %d: %v", due[i].ID, err)
+		}
+	}
+
+	ended, err := s.repo.ListDueForClosing(now)
+	if err != nil {
+		log.Printf("Failed to list campus events due for closing: %v", err)
+	}
+	for i := range ended {
+		if _, err := s.closeCheckIn(&ended[i]); err != nil {
+			log.Printf("Failed to close check-in of campus event %d: %v", ended[i].ID, err)
+		}
+	}
+}
+
+// openCheckIn opens check-in for an event unless another request or replica changed its status
+// first, then records the audience as ABSENT
+func (s *CampusEventService) openCheckIn(event *models.CampusEvent) (bool, error) {
+	previous := event.Status
+	if usesQRCode(event.AttendanceType) && event.QRCodeData == "" {
+		qrData, err := generateQRCodeData()
+		if err != nil {
+			return false, err
+		}
+		event.QRCodeData = qrData
+	}
+	now := GetIndonesiaTime()
+	if event.OpenedAt == nil {
+		event.OpenedAt = &now
+	}
+	event.ClosedAt = nil
+	event.Status = models.CampusEventActive
+
+	opened, err := s.repo.UpdateStatusIf(event, previous)
+	if err != nil || !opened {
+		return false, err
+	}
+	return true, s.initializeAttendances(event)
+}
+
+// closeCheckIn closes check-in for an event unless another request or replica changed its status first
+func (s *CampusEventService) closeCheckIn(event *models.CampusEvent) (bool, error) {
+	now := GetIndonesiaTime()
+	event.Status = models.CampusEventClosed
+	event.ClosedAt = &now
+	return s.repo.UpdateStatusIf(event, models.CampusEventActive)
+}
+
+// CancelEvent cancels an event that has not been closed; it disappears from student histories
+func (s *CampusEventService) CancelEvent(eventID, userID uint, role string) error {
+	event, err := s.loadManaged(eventID, userID, role)
+	if err != nil {
+		return err
+	}
+	if event.Status == models.CampusEventClosed || event.Status == models.CampusEventCanceled {
+		return fmt.Errorf("%w: a %s event cannot be canceled", ErrInvalidCampusEvent, strings.ToLower(string(event.Status)))
+	}
+
+	event.Status = models.CampusEventCanceled
+	return s.repo.Update(event)
+}
+
+// GetQRCodePayload returns the check-in QR payload of an event
+func (s *CampusEventService) GetQRCodePayload(eventID, userID uint, role string) (string, error) {
+	event, err := s.loadManaged(eventID, userID, role)
+	if err != nil {
+		return "", err
+	}
+	if !usesQRCode(event.AttendanceType) {
+		return "", fmt.Errorf("%w: this event does not use QR code", ErrInvalidCampusEvent)
+	}
+
+	// The code is generated when check-in opens
+	if event.QRCodeData == "" {
+		return "", ErrCampusEventNotOpen
+	}
+	return event.QRCodeData, nil
+}
+
+// ListAttendances lists the attendance records of an event, optionally only those with a status
+// such as the absentees of a roll call
+func (s *CampusEventService) ListAttendances(eventID uint, status models.StudentAttendanceStatus, userID uint, role string) ([]models.StudentAttendanceResponse, error) {
+	if _, err := s.loadManaged(eventID, userID, role); err != nil {
+		return nil, err
+	}
+	attendances, err := s.listAttendanceResponses(eventID)
+	if err != nil || status == "" {
+		return attendances, err
+	}
+
+	filtered := make([]models.StudentAttendanceResponse, 0, len(attendances))
+	for _, attendance := range attendances {
+		if attendance.Status == string(status) {
+			filtered = append(filtered, attendance)
+		}
+	}
+	return filtered, nil
+}
+
+// MarkAttendance records a student's attendance by hand while check-in is open or after it closed.
+// PRESENT becomes LATE past the late threshold while check-in is open.
+func (s *CampusEventService) MarkAttendance(eventID, studentID uint, status models.StudentAttendanceStatus, notes string, userID uint, role string) error {
+	event, err := s.loadManaged(eventID, userID, role)
+	if err != nil {
+		return err
+	}
+	if event.Status != models.CampusEventActive && event.Status != models.CampusEventClosed {
+		return fmt.Errorf("%w: check-in of this event has not been opened", ErrInvalidCampusEvent)
+	}
+	if !validStudentAttendanceStatus(status) {
+		return fmt.Errorf("%w: invalid attendance status", ErrInvalidCampusEvent)
+	}
+
+	student, err := s.studentRepo.FindByID(studentID)
+	if err != nil {
+		return err
+	}
+	inAudience, err := s.repo.InAudience(event.ID, student)
+	if err != nil {
+		return err
+	}
+	if !inAudience {
+		return ErrNotInEventAudience
+	}
+
+	now := GetIndonesiaTime()
+	if event.Status == models.CampusEventActive && status == models.StudentAttendanceStatusPresent && isLateForEvent(event, now) {
+		status = models.StudentAttendanceStatusLate
+	}
+	attendance := &models.CampusEventAttendance{
+		CampusEventID:      event.ID,
+		StudentID:          student.ID,
+		Status:             status,
+		VerificationMethod: string(models.AttendanceTypeManual),
+		VerifiedByID:       &userID,
+		Notes:              notes,
+	}
+	if status == models.StudentAttendanceStatusPresent || status == models.StudentAttendanceStatusLate {
+		attendance.CheckInTime = &now
+	}
+	return s.repo.SaveAttendance(attendance)
+}
+
+// RenderReport renders the xlsx attendance report of an event
+func (s *CampusEventService) RenderReport(eventID, userID uint, role string) ([]byte, string, error) {
+	event, err := s.GetEvent(eventID, userID, role)
+	if err != nil {
+		return nil, "", err
+	}
+	attendances, err := s.listAttendanceResponses(eventID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	content, err := RenderCampusEventReportXLSX(event, attendances)
+	if err != nil {
+		return nil, "", err
+	}
+	return content, CampusEventReportFilename(event), nil
+}
+
+// ListStudentEvents lists the events of a student's audience that have not ended, with the
+// student's attendance status
+func (s *CampusEventService) ListStudentEvents(externalUserID uint) ([]StudentCampusEvent, error) {
+	student, err := s.findStudent(externalUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := GetIndonesiaTime()
+	events, err := s.repo.ListForStudent(student, &now)
+	if err != nil {
+		return nil, err
+	}
+	responses, err := s.mapEventsToResponses(events)
+	if err != nil {
+		return nil, err
+	}
+
+	attendances, err := s.repo.ListStudentAttendances(student.ID)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make(map[uint]string, len(attendances))
+	for _, attendance := range attendances {
+		statuses[attendance.CampusEventID] = string(attendance.Status)
+	}
+
+	result := make([]StudentCampusEvent, len(responses))
+	for i, response := range responses {
+		// Students scan the code; they never see its payload
+		response.QRCodeURL = ""
+		result[i] = StudentCampusEvent{CampusEventResponse: response, MyStatus: statuses[response.ID]}
+	}
+	return result, nil
+}
+
+// CheckIn records a student's QR check-in to an event whose check-in is open
+func (s *CampusEventService) CheckIn(externalUserID, eventID uint, qrData string) (*models.CampusEventAttendance, error) {
+	student, err := s.findStudent(externalUserID)
+	if err != nil {
+		return nil, err
+	}
+	event, err := s.repo.GetByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status != models.CampusEventActive {
+		return nil, ErrCampusEventNotOpen
+	}
+	if !usesQRCode(event.AttendanceType) {
+		return nil, fmt.Errorf("%w: this event does not support QR code verification", ErrInvalidCampusEvent)
+	}
+	if event.QRCodeData == "" || qrData != event.QRCodeData {
+		return nil, errors.New("invalid QR code data")
+	}
+
+	inAudience, err := s.repo.InAudience(event.ID, student)
+	if err != nil {
+		return nil, err
+	}
+	if !inAudience {
+		return nil, ErrNotInEventAudience
+	}
+
+	now := GetIndonesiaTime()
+	status := models.StudentAttendanceStatusPresent
+	if isLateForEvent(event, now) {
+		status = models.StudentAttendanceStatusLate
+	}
+	attendance := &models.CampusEventAttendance{
+		CampusEventID:      event.ID,
+		StudentID:          student.ID,
+		Status:             status,
+		CheckInTime:        &now,
+		VerificationMethod: string(models.AttendanceTypeQRCode),
+	}
+	recorded, err := s.repo.CheckInAttendance(attendance)
+	if err != nil {
+		return nil, err
+	}
+	if !recorded {
+		return nil, ErrAlreadyCheckedIn
+	}
+	return attendance, nil
+}
+
+// applyInput validates an input and copies it onto an event
+func (s *CampusEventService) applyInput(event *models.CampusEvent, input CampusEventInput) error {
+	title := strings.TrimSpace(input.Title)
+	if title == "" {
+		return fmt.Errorf("%w: title is required", ErrInvalidCampusEvent)
+	}
+
+	startAt, err := parseCampusEventTime(input.StartAt)
+	if err != nil {
+		return fmt.Errorf("%w: invalid start_at", ErrInvalidCampusEvent)
+	}
+	endAt, err := parseCampusEventTime(input.EndAt)
+	if err != nil {
+		return fmt.Errorf("%w: invalid end_at", ErrInvalidCampusEvent)
+	}
+	if !endAt.After(startAt) {
+		return fmt.Errorf("%w: end_at must be after start_at", ErrInvalidCampusEvent)
+	}
+
+	category := models.CampusEventCategory(strings.ToUpper(input.Category))
+	switch category {
+	case models.CampusEventGeneralLecture, models.CampusEventSeminar, models.CampusEventAssembly,
+		models.CampusEventCeremony, models.CampusEventRollCall, models.CampusEventOther:
+	case "":
+		category = models.CampusEventOther
+	default:
+		return fmt.Errorf("%w: category must be one of GENERAL_LECTURE, SEMINAR, ASSEMBLY, CEREMONY, ROLL_CALL or OTHER", ErrInvalidCampusEvent)
+	}
+
+	dormitory := strings.TrimSpace(input.AudienceDormitory)
+	location := strings.TrimSpace(input.Location)
+	if category == models.CampusEventRollCall && location == "" {
+		// Roll calls are held in the dormitory itself
+		location = dormitory
+	}
+	if input.RoomID != nil {
+		if _, err := s.roomRepo.FindByID(*input.RoomID); err != nil {
+			return fmt.Errorf("%w: room not found", ErrInvalidCampusEvent)
+		}
+	} else if location == "" {
+		return fmt.Errorf("%w: room_id or location is required", ErrInvalidCampusEvent)
+	}
+
+	event.AudienceStudyProgramID, event.AudienceIntakeYear, event.AudienceStudentGroupID = nil, nil, nil
+	event.AudienceDormitory = nil
+	audience := models.CampusEventAudience(strings.ToUpper(input.AudienceType))
+	switch audience {
+	case models.CampusEventAudienceAll:
+	case models.CampusEventAudienceStudyProgram:
+		if input.AudienceStudyProgramID == nil {
+			return fmt.Errorf("%w: audience_study_program_id is required", ErrInvalidCampusEvent)
+		}
+		event.AudienceStudyProgramID = input.AudienceStudyProgramID
+	case models.CampusEventAudienceIntakeYear:
+		if input.AudienceIntakeYear == nil {
+			return fmt.Errorf("%w: audience_intake_year is required", ErrInvalidCampusEvent)
+		}
+		event.AudienceIntakeYear = input.AudienceIntakeYear
+	case models.CampusEventAudienceGroup:
+		if input.AudienceStudentGroupID == nil {
+			return fmt.Errorf("%w: audience_student_group_id is required", ErrInvalidCampusEvent)
+		}
+		event.AudienceStudentGroupID = input.AudienceStudentGroupID
+	case models.CampusEventAudienceList:
+		if len(input.StudentIDs) == 0 {
+			return fmt.Errorf("%w: student_ids is required for a LIST audience", ErrInvalidCampusEvent)
+		}
+	case models.CampusEventAudienceDormitory:
+		if dormitory == "" {
+			return fmt.Errorf("%w: audience_dormitory is required", ErrInvalidCampusEvent)
+		}
+		event.AudienceDormitory = &dormitory
+	default:
+		return fmt.Errorf("%w: audience_type must be one of ALL_STUDENTS, STUDY_PROGRAM, INTAKE_YEAR, STUDENT_GROUP, LIST or DORMITORY", ErrInvalidCampusEvent)
+	}
+	if category == models.CampusEventRollCall && audience != models.CampusEventAudienceDormitory {
+		return fmt.Errorf("%w: roll calls must have a DORMITORY audience", ErrInvalidCampusEvent)
+	}
+
+	attendanceType := models.AttendanceType(strings.ToUpper(input.AttendanceType))
+	switch attendanceType {
+	case models.AttendanceTypeQRCode, models.AttendanceTypeFaceRecognition, models.AttendanceTypeManual, models.AttendanceTypeBoth:
+	case "":
+		attendanceType = models.AttendanceTypeQRCode
+	default:
+		return fmt.Errorf("%w: attendance_type must be one of QR_CODE, FACE_RECOGNITION, MANUAL or BOTH", ErrInvalidCampusEvent)
+	}
+	if event.Status == models.CampusEventActive && attendanceType != event.AttendanceType {
+		return fmt.Errorf("%w: attendance_type cannot change while check-in is open", ErrInvalidCampusEvent)
+	}
+
+	if input.LateThreshold != nil {
+		if *input.LateThreshold < 0 {
+			return fmt.Errorf("%w: late_threshold cannot be negative", ErrInvalidCampusEvent)
+		}
+		event.LateThreshold = *input.LateThreshold
+	}
+	if input.AllowLate != nil {
+		event.AllowLate = *input.AllowLate
+	}
+	if input.AutoOpenClose != nil {
+		event.AutoOpenClose = *input.AutoOpenClose
+	} else if event.ID == 0 {
+		event.AutoOpenClose = category == models.CampusEventRollCall
+	}
+
+	event.Title = title
+	event.Description = strings.TrimSpace(input.Description)
+	event.Category = category
+	event.StartAt = startAt
+	event.EndAt = endAt
+	event.RoomID = input.RoomID
+	event.Room = nil
+	event.Location = location
+	event.Organizer = strings.TrimSpace(input.Organizer)
+	event.AudienceType = audience
+	event.AttendanceType = attendanceType
+	return nil
+}
+
+// loadManaged loads an event the user manages
+func (s *CampusEventService) loadManaged(eventID, userID uint, role string) (*models.CampusEvent, error) {
+	event, err := s.repo.GetByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdminRole(role) && event.OrganizerID != userID {
+		return nil, ErrCampusEventAccessDenied
+	}
+	return event, nil
+}
+
+// initializeAttendances records the students of an event's audience who have no record yet as ABSENT
+func (s *CampusEventService) initializeAttendances(event *models.CampusEvent) error {
+	studentIDs, err := s.repo.ListAudienceStudentIDs(event)
+	if err != nil {
+		return err
+	}
+	return s.repo.InitializeAttendances(event.ID, studentIDs)
+}
+
+// findStudent finds the student record of an external user ID
+func (s *CampusEventService) findStudent(externalUserID uint) (*models.Student, error) {
+	student, err := s.studentRepo.FindByUserID(int(externalUserID))
+	if err != nil {
+		return nil, err
+	}
+	if student == nil {
+		return nil, errors.New("student record not found")
+	}
+	return student, nil
+}
+
+// listAttendanceResponses lists the attendance records of an event in the shape of course session records
+func (s *CampusEventService) listAttendanceResponses(eventID uint) ([]models.StudentAttendanceResponse, error) {
+	attendances, err := s.repo.ListAttendances(eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	location := getIndonesiaLocation()
+	responses := make([]models.StudentAttendanceResponse, len(attendances))
+	for i, attendance := range attendances {
+		checkInTime := ""
+		if attendance.CheckInTime != nil {
+			checkInTime = attendance.CheckInTime.In(location).Format("15:04")
+		}
+		responses[i] = models.StudentAttendanceResponse{
+			ID:                 attendance.ID,
+			StudentID:          attendance.StudentID,
+			StudentName:        attendance.Student.FullName,
+			StudentNIM:         attendance.Student.NIM,
+			Status:             string(attendance.Status),
+			CheckInTime:        checkInTime,
+			Notes:              attendance.Notes,
+			VerificationMethod: attendance.VerificationMethod,
+		}
+	}
+	return responses, nil
+}
+
+// mapEventsToResponses adds attendance counts to events with one aggregate query
+func (s *CampusEventService) mapEventsToResponses(events []models.CampusEvent) ([]models.CampusEventResponse, error) {
+	eventIDs := make([]uint, len(events))
+	for i, event := range events {
+		eventIDs[i] = event.ID
+	}
+	counts, err := s.repo.GetCountsByEvent(eventIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.CampusEventResponse, len(events))
+	for i, event := range events {
+		eventCounts := counts[event.ID]
+		response := models.CampusEventResponse{
+			CampusEvent:   event,
+			RoomName:      event.Location,
+			TotalStudents: eventCounts.Total,
+			AttendedCount: eventCounts.Present,
+			LateCount:     eventCounts.Late,
+			AbsentCount:   eventCounts.Absent,
+			ExcusedCount:  eventCounts.Excused,
+		}
+		if event.Room != nil && event.Room.ID != 0 {
+			response.RoomName = event.Room.Name
+			if event.Room.Building.ID != 0 {
+				response.RoomName = fmt.Sprintf("%s - %s", event.Room.Building.Name, event.Room.Name)
+			}
+		}
+		if usesQRCode(event.AttendanceType) && event.Status == models.CampusEventActive {
+			response.QRCodeURL = fmt.Sprintf("/api/events/%d/qrcode", event.ID)
+		}
+		responses[i] = response
+	}
+	return responses, nil
+}
+
+// mergeEventHistory adds a student's campus event attendance to their course attendance history,
+// latest first
+func mergeEventHistory(history []models.StudentAttendanceHistoryResponse, attendances []models.CampusEventAttendance) []models.StudentAttendanceHistoryResponse {
+	location := getIndonesiaLocation()
+	for _, attendance := range attendances {
+		event := attendance.CampusEvent
+		checkInTime := ""
+		if attendance.CheckInTime != nil {
+			checkInTime = attendance.CheckInTime.In(location).Format("15:04")
+		}
+		roomName := event.Location
+		if event.Room != nil && event.Room.ID != 0 {
+			roomName = event.Room.Name
+			if event.Room.Building.ID != 0 {
+				roomName = fmt.Sprintf("%s - %s", event.Room.Building.Name, event.Room.Name)
+			}
+		}
+
+		eventID := event.ID
+		history = append(history, models.StudentAttendanceHistoryResponse{
+			ID:                 attendance.ID,
+			Source:             models.AttendanceHistorySourceEvent,
+			CampusEventID:      &eventID,
+			Date:               event.StartAt.In(location).Format("2006-01-02"),
+			CourseName:         event.Title,
+			RoomName:           roomName,
+			CheckInTime:        checkInTime,
+			Status:             string(attendance.Status),
+			VerificationMethod: attendance.VerificationMethod,
+		})
+	}
+
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].Date > history[j].Date
+	})
+	return history
+}
+
+// usesQRCode reports whether an attendance type checks students in by QR code
+func usesQRCode(attendanceType models.AttendanceType) bool {
+	return attendanceType == models.AttendanceTypeQRCode || attendanceType == models.AttendanceTypeBoth
+}
+
+// isLateForEvent reports whether a check-in at a time is past an event's late threshold
+func isLateForEvent(event *models.CampusEvent, at time.Time) bool {
+	return event.AllowLate && at.Sub(event.StartAt).Minutes() > float64(event.LateThreshold)
+}
+
+// parseCampusEventTime parses an RFC 3339 time or "YYYY-MM-DD HH:MM" in Indonesia time
+func parseCampusEventTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02 15:04", value, getIndonesiaLocation())
+}
+
+// isAdminRole reports whether a role is the admin role
+func isAdminRole(role string) bool {
+	return strings.ToLower(role) == "admin"
+}
@@ -247,6 +247,8 @@ func (s *CourseScheduleService) CreateSchedule(schedule models.CourseSchedule) (
 	fmt.Printf("Created new schedule with ID=%d, lecturer_id=%d, student_group_id=%d\n",
 		createdSchedule.ID, createdSchedule.UserID, createdSchedule.StudentGroupID)
 
+	dispatchWebhookEvent(models.WebhookEventScheduleCreated, s.FormatScheduleForResponse(createdSchedule))
+
 	return createdSchedule, nil
 }
 
@@ -290,18 +292,31 @@ func (s *CourseScheduleService) UpdateSchedule(schedule models.CourseSchedule) (
 	// Keep some fields from existing
 	schedule.CreatedAt = existingSchedule.CreatedAt
 
-	return s.repo.Update(schedule)
+	updatedSchedule, err := s.repo.Update(schedule)
+	if err != nil {
+		return updatedSchedule, err
+	}
+
+	dispatchWebhookEvent(models.WebhookEventScheduleUpdated, s.FormatScheduleForResponse(updatedSchedule))
+
+	return updatedSchedule, nil
 }
 
 // DeleteSchedule deletes a course schedule
 func (s *CourseScheduleService) DeleteSchedule(id uint) error {
 	// Check if schedule exists
-	_, err := s.repo.GetByID(id)
+	existingSchedule, err := s.repo.GetByID(id)
 	if err != nil {
 		return errors.New("schedule not found")
 	}
 
-	return s.repo.Delete(id)
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+
+	dispatchWebhookEvent(models.WebhookEventScheduleDeleted, s.FormatScheduleForResponse(existingSchedule))
+
+	return nil
 }
 
 // GetSchedulesByAcademicYear retrieves schedules by academic year
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/delpresence/backend/internal/models"
+	"github.com/delpresence/backend/internal/utils"
+	"github.com/tealeg/xlsx/v3"
+)
+
+// LessonPlanCoverageFilename returns the download file name of a lesson plan coverage report
+func LessonPlanCoverageFilename(coverage *models.LessonPlanCoverage) string {
+	return reportFilename("xlsx", "Realisasi_RPS", coverage.CourseCode)
+}
+
+// RenderLessonPlanCoverageXLSX renders a lesson plan coverage report with one sheet per schedule
+// listing the planned topic of every meeting next to what was delivered
+func RenderLessonPlanCoverageXLSX(coverage *models.LessonPlanCoverage) ([]byte, error) {
+	file := xlsx.NewFile()
+	usedNames := make(map[string]bool)
+
+	if len(coverage.Schedules) == 0 {
+		sheet, err := file.AddSheet(uniqueSheetName(coverage.CourseCode, usedNames))
+		if err != nil {
+			return nil, err
+		}
+		sheet.AddRow().AddCell().Value = "Belum ada kelas untuk mata kuliah ini"
+	}
+
+	for _, schedule := range coverage.Schedules {
+		sheet, err := file.AddSheet(uniqueSheetName(fmt.Sprintf("%s %s", coverage.CourseCode, schedule.StudentGroup), usedNames))
+		if err != nil {
+			return nil, err
+		}
+
+		titleCell := sheet.AddRow().AddCell()
+		titleCell.Value = "REALISASI RENCANA PEMBELAJARAN SEMESTER"
+		titleCell.SetStyle(utils.NewXLSXTitleStyle())
+		sheet.AddRow()
+
+		info := [][2]string{
+			{"Mata Kuliah", fmt.Sprintf("%s - %s", coverage.CourseCode, coverage.CourseName)},
+			{"Kelas", schedule.StudentGroup},
+			{"Dosen", schedule.LecturerName},
+			{"Pertemuan Direncanakan", fmt.Sprintf("%d", coverage.PlannedMeetings)},
+			{"Topik Terlaksana", fmt.Sprintf("%d (%s)", schedule.TopicsDelivered, formatPercent(schedule.CoverageRate))},
+			{"Berita Acara", fmt.Sprintf("%d diisi, %d ditandatangani", schedule.JournalsFilled, schedule.JournalsSigned)},
+		}
+		for _, item := range info {
+			row := sheet.AddRow()
+			row.AddCell().Value = item[0]
+			row.AddCell().Value = item[1]
+		}
+		sheet.AddRow()
+
+		headerStyle := utils.NewXLSXHeaderStyle()
+		header := sheet.AddRow()
+		for _, title := range []string{"Pertemuan", "Topik Rencana", "Tanggal", "Topik Realisasi", "Status"} {
+			addStyledString(header, title, headerStyle)
+		}
+
+		dataStyle := utils.NewXLSXCellStyle()
+		addDelivery := func(delivery models.LessonPlanTopicDelivery, planned string) {
+			status := "Belum terlaksana"
+			switch {
+			case delivery.Delivered && delivery.Signed:
+				status = "Ditandatangani"
+			case delivery.Delivered:
+				status = "Draf"
+			}
+
+			row := sheet.AddRow()
+			if delivery.MeetingNumber > 0 {
+				addStyledInt(row, delivery.MeetingNumber, dataStyle)
+			} else {
+				addStyledString(row, "-", dataStyle)
+			}
+			addStyledString(row, planned, dataStyle)
+			addStyledString(row, strings.Join(delivery.DeliveredDates, ", "), dataStyle)
+			addStyledString(row, strings.Join(delivery.ActualTopics, "; "), dataStyle)
+			addStyledString(row, status, dataStyle)
+		}
+
+		for _, delivery := range schedule.Topics {
+			addDelivery(delivery, delivery.PlannedTopic)
+		}
+		for _, delivery := range schedule.Unplanned {
+			addDelivery(delivery, "(di luar RPS)")
+		}
+
+		sheet.SetColWidth(1, 1, 11)
+		sheet.SetColWidth(2, 2, 40)
+		sheet.SetColWidth(3, 3, 14)
+		sheet.SetColWidth(4, 4, 40)
+		sheet.SetColWidth(5, 5, 16)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := file.Write(buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
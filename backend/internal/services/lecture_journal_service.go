@@ -0,0 +1,704 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/delpresence/backend/internal/models"
+	"github.com/delpresence/backend/internal/repositories"
+	"github.com/delpresence/backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidLectureJournal is returned when a lecture journal or lesson plan input is invalid
+	ErrInvalidLectureJournal = errors.New("invalid lecture journal")
+
+	// ErrLectureJournalExists is returned when a meeting already has a lecture journal
+	ErrLectureJournalExists = errors.New("a lecture journal already exists for this meeting")
+
+	// ErrLectureJournalLocked is returned when a signed lecture journal is changed
+	ErrLectureJournalLocked = errors.New("lecture journal is signed and can no longer be changed")
+
+	// ErrAttachmentTooLarge is returned when an uploaded attachment exceeds the size limit
+	ErrAttachmentTooLarge = errors.New("attachment exceeds the upload size limit")
+)
+
+// LectureJournalInput fills in a lecture journal. A journal belongs to an attendance session, or to
+// a meeting date of a schedule when no session was opened. Topic, sub-topics and teaching method
+// default to those of the planned topic.
+type LectureJournalInput struct {
+	CourseScheduleID      uint     `json:"course_schedule_id"`
+	AttendanceSessionID   *uint    `json:"attendance_session_id"`
+	MeetingDate           string   `json:"meeting_date"` // YYYY-MM-DD, defaults to the session date
+	MeetingNumber         int      `json:"meeting_number"`
+	LessonPlanTopicID     *uint    `json:"lesson_plan_topic_id"`
+	Topic                 string   `json:"topic"`
+	SubTopics             []string `json:"sub_topics"`
+	TeachingMethod        string   `json:"teaching_method"`
+	Notes                 string   `json:"notes"`
+	ClassRepresentativeID *uint    `json:"class_representative_id"` // External user ID of a student of the schedule's group
+}
+
+// LessonPlanTopicInput is one planned meeting of a lesson plan
+type LessonPlanTopicInput struct {
+	MeetingNumber  int      `json:"meeting_number"`
+	Topic          string   `json:"topic"`
+	SubTopics      []string `json:"sub_topics"`
+	TeachingMethod string   `json:"teaching_method"`
+}
+
+// LectureJournalService manages lecture journals and lesson plans on behalf of an attendance
+// actor; the actor's policy decides who may fill in journals and edit lesson plans
+type LectureJournalService struct {
+	sessions      *AttendanceSessionService
+	journalRepo   *repositories.LectureJournalRepository
+	scheduleRepo  *repositories.CourseScheduleRepository
+	courseRepo    *repositories.CourseRepository
+	groupRepo     *repositories.StudentGroupRepository
+	studentRepo   *repositories.StudentRepository
+	lecturerRepo  *repositories.LecturerRepository
+	storageDir    string
+	maxUploadSize int64
+}
+
+// NewLectureJournalService creates a lecture journal service for an actor. Uploaded attachments are
+// stored under JOURNAL_STORAGE_DIR and limited to LECTURE_JOURNAL_MAX_UPLOAD_MB (default 10).
+func NewLectureJournalService(actor AttendanceActor) *LectureJournalService {
+	return &LectureJournalService{
+		sessions:      NewAttendanceSessionService(actor),
+		journalRepo:   repositories.NewLectureJournalRepository(),
+		scheduleRepo:  repositories.NewCourseScheduleRepository(),
+		courseRepo:    repositories.NewCourseRepository(),
+		groupRepo:     repositories.NewStudentGroupRepository(),
+		studentRepo:   repositories.NewStudentRepository(),
+		lecturerRepo:  repositories.NewLecturerRepository(),
+		storageDir:    utils.GetEnvWithDefault("JOURNAL_STORAGE_DIR", "./storage/journals"),
+		maxUploadSize: int64(utils.GetEnvAsInt("LECTURE_JOURNAL_MAX_UPLOAD_MB", 10)) << 20,
+	}
+}
+
+// NewStudentLectureJournalService creates a lecture journal service for class representatives'
+// co-signatures; staff operations are not available through it
+func NewStudentLectureJournalService() *LectureJournalService {
+	return &LectureJournalService{
+		journalRepo: repositories.NewLectureJournalRepository(),
+		studentRepo: repositories.NewStudentRepository(),
+	}
+}
+
+// MaxUploadSize returns the size limit of uploaded attachments in bytes
+func (s *LectureJournalService) MaxUploadSize() int64 {
+	return s.maxUploadSize
+}
+
+// CreateJournal fills in the lecture journal of a meeting as a draft
+func (s *LectureJournalService) CreateJournal(input LectureJournalInput, userID uint) (*models.LectureJournal, error) {
+	if !s.sessions.policy.Allows(AttendanceActionJournal) {
+		return nil, ErrAttendanceActionNotAllowed
+	}
+
+	journal := &models.LectureJournal{
+		AttendanceSessionID: input.AttendanceSessionID,
+		Status:              models.LectureJournalDraft,
+		CreatedByID:         userID,
+	}
+
+	var schedule models.CourseSchedule
+	if input.AttendanceSessionID != nil {
+		session, err := s.sessions.loadSession(*input.AttendanceSessionID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if input.CourseScheduleID != 0 && input.CourseScheduleID != session.CourseScheduleID {
+			return nil, fmt.Errorf("%w: attendance session does not belong to the course schedule", ErrInvalidLectureJournal)
+		}
+		if session.Status == models.AttendanceStatusCanceled {
+			return nil, fmt.Errorf("%w: attendance session is canceled", ErrInvalidLectureJournal)
+		}
+		schedule = session.CourseSchedule
+		journal.MeetingDate = truncateToDate(session.Date)
+	} else {
+		if input.CourseScheduleID == 0 {
+			return nil, fmt.Errorf("%w: course_schedule_id or attendance_session_id is required", ErrInvalidLectureJournal)
+		}
+		var err error
+		schedule, err = s.scheduleRepo.GetByID(input.CourseScheduleID)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.sessions.checkScheduleAccess(schedule, userID); err != nil {
+			return nil, err
+		}
+	}
+	journal.CourseScheduleID = schedule.ID
+
+	if input.MeetingDate != "" {
+		date, err := time.ParseInLocation("2006-01-02", input.MeetingDate, getIndonesiaLocation())
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid meeting_date format, use YYYY-MM-DD", ErrInvalidLectureJournal)
+		}
+		if input.AttendanceSessionID != nil && date.Format("2006-01-02") != journal.MeetingDate.Format("2006-01-02") {
+			return nil, fmt.Errorf("%w: meeting_date differs from the attendance session date", ErrInvalidLectureJournal)
+		}
+		journal.MeetingDate = date
+	}
+	if journal.MeetingDate.IsZero() {
+		return nil, fmt.Errorf("%w: meeting_date is required without an attendance session", ErrInvalidLectureJournal)
+	}
+	if journal.MeetingDate.Format("2006-01-02") > GetIndonesiaTime().Format("2006-01-02") {
+		return nil, fmt.Errorf("%w: meeting_date cannot be in the future", ErrInvalidLectureJournal)
+	}
+
+	if _, err := s.journalRepo.FindJournalForMeeting(input.AttendanceSessionID, schedule.ID, journal.MeetingDate); err == nil {
+		return nil, ErrLectureJournalExists
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if err := s.applyInput(journal, schedule, input); err != nil {
+		return nil, err
+	}
+	if err := s.journalRepo.CreateJournal(journal); err != nil {
+		return nil, err
+	}
+	return s.journalRepo.GetJournalByID(journal.ID)
+}
+
+// UpdateJournal replaces the contents of a draft journal; its meeting cannot change
+func (s *LectureJournalService) UpdateJournal(journalID uint, input LectureJournalInput, userID uint) (*models.LectureJournal, error) {
+	journal, err := s.loadDraft(journalID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.applyInput(journal, journal.CourseSchedule, input); err != nil {
+		return nil, err
+	}
+	if err := s.journalRepo.UpdateJournal(journal); err != nil {
+		return nil, err
+	}
+	return s.journalRepo.GetJournalByID(journal.ID)
+}
+
+// SignJournal signs a draft journal, after which it can no longer be changed. The class
+// representative, if any, can then co-sign it.
+func (s *LectureJournalService) SignJournal(journalID, userID uint) (*models.LectureJournal, error) {
+	journal, err := s.loadDraft(journalID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := GetIndonesiaTime()
+	journal.Status = models.LectureJournalSigned
+	journal.SignedByID = &userID
+	journal.SignedByRole = string(s.sessions.policy.Actor)
+	journal.SignedAt = &now
+	if err := s.journalRepo.UpdateJournal(journal); err != nil {
+		return nil, err
+	}
+	return journal, nil
+}
+
+// GetJournal returns a journal the user has access to
+func (s *LectureJournalService) GetJournal(journalID, userID uint) (*models.LectureJournal, error) {
+	return s.loadJournal(journalID, userID)
+}
+
+// ListJournals lists the journals of a schedule in meeting order
+func (s *LectureJournalService) ListJournals(scheduleID, userID uint) ([]models.LectureJournal, error) {
+	if !s.sessions.policy.Allows(AttendanceActionJournal) && !s.sessions.policy.Allows(AttendanceActionView) {
+		return nil, ErrAttendanceActionNotAllowed
+	}
+
+	schedule, err := s.scheduleRepo.GetByID(scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sessions.checkScheduleAccess(schedule, userID); err != nil {
+		return nil, err
+	}
+	return s.journalRepo.ListJournalsBySchedules([]uint{scheduleID})
+}
+
+// AddLinkAttachment attaches a material link to a draft journal
+func (s *LectureJournalService) AddLinkAttachment(journalID uint, title, link string, userID uint) (*models.LectureJournalAttachment, error) {
+	if _, err := s.loadDraft(journalID, userID); err != nil {
+		return nil, err
+	}
+
+	parsed, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("%w: attachment url must be an http or https link", ErrInvalidLectureJournal)
+	}
+
+	attachment := &models.LectureJournalAttachment{
+		LectureJournalID: journalID,
+		Title:            strings.TrimSpace(title),
+		URL:              parsed.String(),
+		UploadedByID:     userID,
+	}
+	if attachment.Title == "" {
+		attachment.Title = parsed.Host
+	}
+	if err := s.journalRepo.CreateAttachment(attachment); err != nil {
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// AddFileAttachment stores an uploaded file and attaches it to a draft journal
+func (s *LectureJournalService) AddFileAttachment(journalID uint, title, fileName, contentType string, size int64, content io.Reader, userID uint) (*models.LectureJournalAttachment, error) {
+	if _, err := s.loadDraft(journalID, userID); err != nil {
+		return nil, err
+	}
+	if size > s.maxUploadSize {
+		return nil, ErrAttachmentTooLarge
+	}
+
+	fileName = filepath.Base(strings.ReplaceAll(fileName, "\\", "/"))
+	if fileName == "." || fileName == "/" || fileName == "" {
+		return nil, fmt.Errorf("%w: attachment file name is required", ErrInvalidLectureJournal)
+	}
+
+	dir := filepath.Join(s.storageDir, fmt.Sprintf("journal-%d", journalID))
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create journal storage: %w", err)
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%d-%s", time.Now().UnixNano(), fileName))
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write attachment: %w", err)
+	}
+	written, err := io.Copy(file, io.LimitReader(content, s.maxUploadSize+1))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && written > s.maxUploadSize {
+		err = ErrAttachmentTooLarge
+	}
+	if err != nil {
+		os.Remove(path)
+		if errors.Is(err, ErrAttachmentTooLarge) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to write attachment: %w", err)
+	}
+
+	attachment := &models.LectureJournalAttachment{
+		LectureJournalID: journalID,
+		Title:            strings.TrimSpace(title),
+		FileName:         fileName,
+		ContentType:      contentType,
+		StoragePath:      path,
+		FileSize:         written,
+		UploadedByID:     userID,
+	}
+	if attachment.Title == "" {
+		attachment.Title = fileName
+	}
+	if attachment.ContentType == "" {
+		attachment.ContentType = "application/octet-stream"
+	}
+	if err := s.journalRepo.CreateAttachment(attachment); err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// DeleteAttachment removes an attachment from a draft journal
+func (s *LectureJournalService) DeleteAttachment(journalID, attachmentID, userID uint) error {
+	if _, err := s.loadDraft(journalID, userID); err != nil {
+		return err
+	}
+
+	attachment, err := s.journalRepo.GetAttachment(journalID, attachmentID)
+	if err != nil {
+		return err
+	}
+	if err := s.journalRepo.DeleteAttachment(attachment.ID); err != nil {
+		return err
+	}
+	if attachment.StoragePath != "" {
+		if err := os.Remove(attachment.StoragePath); err != nil && !os.IsNotExist(err) {
+			log.Printf("Failed to remove attachment file %s: %v", attachment.StoragePath, err)
+		}
+	}
+	return nil
+}
+
+// OpenAttachment opens an uploaded attachment of a journal the user has access to
+func (s *LectureJournalService) OpenAttachment(journalID, attachmentID, userID uint) (*models.LectureJournalAttachment, *os.File, error) {
+	if _, err := s.loadJournal(journalID, userID); err != nil {
+		return nil, nil, err
+	}
+
+	attachment, err := s.journalRepo.GetAttachment(journalID, attachmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if attachment.StoragePath == "" {
+		return nil, nil, fmt.Errorf("%w: attachment is a link", ErrInvalidLectureJournal)
+	}
+
+	file, err := os.Open(attachment.StoragePath)
+	if err != nil {
+		log.Printf("Failed to open attachment %d of lecture journal %d: %v", attachment.ID, journalID, err)
+		return nil, nil, gorm.ErrRecordNotFound
+	}
+	return attachment, file, nil
+}
+
+// GetLessonPlan returns the planned topics of a course
+func (s *LectureJournalService) GetLessonPlan(courseID, userID uint) ([]models.LessonPlanTopic, error) {
+	if !s.sessions.policy.Allows(AttendanceActionJournal) && !s.sessions.policy.Allows(AttendanceActionLessonPlan) {
+		return nil, ErrAttendanceActionNotAllowed
+	}
+	if _, err := s.courseRepo.GetByID(courseID); err != nil {
+		return nil, err
+	}
+	if err := s.sessions.checkCourseAccess(courseID, userID); err != nil {
+		return nil, err
+	}
+	return s.journalRepo.ListLessonPlan(courseID)
+}
+
+// ReplaceLessonPlan replaces the planned topics of a course
+func (s *LectureJournalService) ReplaceLessonPlan(courseID uint, input []LessonPlanTopicInput, userID uint) ([]models.LessonPlanTopic, error) {
+	if !s.sessions.policy.Allows(AttendanceActionLessonPlan) {
+		return nil, ErrAttendanceActionNotAllowed
+	}
+	if _, err := s.courseRepo.GetByID(courseID); err != nil {
+		return nil, err
+	}
+	if err := s.sessions.checkCourseAccess(courseID, userID); err != nil {
+		return nil, err
+	}
+
+	topics := make([]models.LessonPlanTopic, 0, len(input))
+	seen := make(map[int]bool, len(input))
+	for _, item := range input {
+		topic := strings.TrimSpace(item.Topic)
+		switch {
+		case item.MeetingNumber <= 0:
+			return nil, fmt.Errorf("%w: meeting_number must be positive", ErrInvalidLectureJournal)
+		case seen[item.MeetingNumber]:
+			return nil, fmt.Errorf("%w: meeting %d is listed more than once", ErrInvalidLectureJournal, item.MeetingNumber)
+		case topic == "":
+			return nil, fmt.Errorf("%w: meeting %d has no topic", ErrInvalidLectureJournal, item.MeetingNumber)
+		}
+		seen[item.MeetingNumber] = true
+
+		topics = append(topics, models.LessonPlanTopic{
+			MeetingNumber:  item.MeetingNumber,
+			Topic:          topic,
+			SubTopics:      cleanSubTopics(item.SubTopics),
+			TeachingMethod: strings.TrimSpace(item.TeachingMethod),
+		})
+	}
+
+	if err := s.journalRepo.ReplaceLessonPlan(courseID, topics); err != nil {
+		return nil, err
+	}
+	return s.journalRepo.ListLessonPlan(courseID)
+}
+
+// GetLessonPlanCoverage compares the lesson plan of a course with the journals of each of its
+// schedules the user has access to
+func (s *LectureJournalService) GetLessonPlanCoverage(courseID, userID uint) (*models.LessonPlanCoverage, error) {
+	if !s.sessions.policy.Allows(AttendanceActionLessonPlan) {
+		return nil, ErrAttendanceActionNotAllowed
+	}
+
+	course, err := s.courseRepo.GetByID(courseID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sessions.checkCourseAccess(courseID, userID); err != nil {
+		return nil, err
+	}
+
+	plan, err := s.journalRepo.ListLessonPlan(courseID)
+	if err != nil {
+		return nil, err
+	}
+	schedules, err := s.scheduleRepo.GetByCourse(courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	coverage := &models.LessonPlanCoverage{
+		CourseID:        course.ID,
+		CourseCode:      course.Code,
+		CourseName:      course.Name,
+		PlannedMeetings: len(plan),
+		Schedules:       []models.LessonPlanScheduleItem{},
+	}
+
+	scheduleIDs := make([]uint, 0, len(schedules))
+	visible := make([]models.CourseSchedule, 0, len(schedules))
+	for _, schedule := range schedules {
+		// Lecturers see only their own classes of a team-taught course
+		if err := s.sessions.checkScheduleAccess(schedule, userID); err != nil {
+			if errors.Is(err, ErrSessionAccessDenied) {
+				continue
+			}
+			return nil, err
+		}
+		visible = append(visible, schedule)
+		scheduleIDs = append(scheduleIDs, schedule.ID)
+	}
+
+	journals, err := s.journalRepo.ListJournalsBySchedules(scheduleIDs)
+	if err != nil {
+		return nil, err
+	}
+	bySchedule := make(map[uint][]models.LectureJournal)
+	for _, journal := range journals {
+		bySchedule[journal.CourseScheduleID] = append(bySchedule[journal.CourseScheduleID], journal)
+	}
+
+	lecturerNames := make(map[uint]string)
+	for _, schedule := range visible {
+		if _, ok := lecturerNames[schedule.UserID]; !ok {
+			lecturer, err := s.lecturerRepo.GetByUserID(int(schedule.UserID))
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, err
+			}
+			lecturerNames[schedule.UserID] = lecturer.FullName
+		}
+
+		item := models.LessonPlanScheduleItem{
+			CourseScheduleID: schedule.ID,
+			StudentGroup:     schedule.StudentGroup.Name,
+			LecturerName:     lecturerNames[schedule.UserID],
+			Topics:           make([]models.LessonPlanTopicDelivery, 0, len(plan)),
+			Unplanned:        []models.LessonPlanTopicDelivery{},
+		}
+
+		byTopic := make(map[uint][]models.LectureJournal)
+		for _, journal := range bySchedule[schedule.ID] {
+			item.JournalsFilled++
+			if journal.Status == models.LectureJournalSigned {
+				item.JournalsSigned++
+			}
+			if journal.LessonPlanTopicID != nil {
+				byTopic[*journal.LessonPlanTopicID] = append(byTopic[*journal.LessonPlanTopicID], journal)
+			} else {
+				item.Unplanned = append(item.Unplanned, journalDelivery(journal.MeetingNumber, "", []models.LectureJournal{journal}))
+			}
+		}
+
+		for _, topic := range plan {
+			delivery := journalDelivery(topic.MeetingNumber, topic.Topic, byTopic[topic.ID])
+			if delivery.Delivered {
+				item.TopicsDelivered++
+			}
+			item.Topics = append(item.Topics, delivery)
+		}
+		if len(plan) > 0 {
+			item.CoverageRate = float64(item.TopicsDelivered) / float64(len(plan)) * 100
+		}
+
+		coverage.Schedules = append(coverage.Schedules, item)
+	}
+
+	return coverage, nil
+}
+
+// ListPendingCosigns lists the signed journals a class representative has yet to co-sign
+func (s *LectureJournalService) ListPendingCosigns(studentUserID uint) ([]models.LectureJournal, error) {
+	student, err := s.findStudent(studentUserID)
+	if err != nil {
+		return nil, err
+	}
+	return s.journalRepo.ListPendingCosigns(student.ID)
+}
+
+// CosignJournal records the class representative's co-signature of a signed journal
+func (s *LectureJournalService) CosignJournal(journalID, studentUserID uint) (*models.LectureJournal, error) {
+	student, err := s.findStudent(studentUserID)
+	if err != nil {
+		return nil, err
+	}
+	journal, err := s.journalRepo.GetJournalByID(journalID)
+	if err != nil {
+		return nil, err
+	}
+
+	if journal.ClassRepresentativeID == nil || *journal.ClassRepresentativeID != student.ID {
+		return nil, ErrSessionAccessDenied
+	}
+	if journal.Status != models.LectureJournalSigned {
+		return nil, fmt.Errorf("%w: lecture journal has not been signed by the lecturer yet", ErrInvalidLectureJournal)
+	}
+	if journal.CosignedAt != nil {
+		return journal, nil
+	}
+
+	now := GetIndonesiaTime()
+	journal.CosignedAt = &now
+	if err := s.journalRepo.UpdateJournal(journal); err != nil {
+		return nil, err
+	}
+	return journal, nil
+}
+
+// applyInput fills in the contents of a journal, linking it to the planned topic of its meeting
+func (s *LectureJournalService) applyInput(journal *models.LectureJournal, schedule models.CourseSchedule, input LectureJournalInput) error {
+	journal.MeetingNumber = input.MeetingNumber
+	journal.LessonPlanTopicID = nil
+	journal.LessonPlanTopic = nil
+	journal.Topic = strings.TrimSpace(input.Topic)
+	journal.SubTopics = cleanSubTopics(input.SubTopics)
+	journal.TeachingMethod = strings.TrimSpace(input.TeachingMethod)
+	journal.Notes = input.Notes
+
+	var planned *models.LessonPlanTopic
+	var err error
+	switch {
+	case input.LessonPlanTopicID != nil:
+		planned, err = s.journalRepo.GetLessonPlanTopic(*input.LessonPlanTopicID)
+		if err != nil || planned.CourseID != schedule.CourseID {
+			return fmt.Errorf("%w: lesson plan topic does not belong to the course", ErrInvalidLectureJournal)
+		}
+	case input.MeetingNumber > 0:
+		planned, err = s.journalRepo.FindLessonPlanTopic(schedule.CourseID, input.MeetingNumber)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			planned = nil
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if planned != nil {
+		journal.LessonPlanTopicID = &planned.ID
+		if journal.MeetingNumber == 0 {
+			journal.MeetingNumber = planned.MeetingNumber
+		}
+		if journal.Topic == "" {
+			journal.Topic = planned.Topic
+		}
+		if len(journal.SubTopics) == 0 {
+			journal.SubTopics = planned.SubTopics
+		}
+		if journal.TeachingMethod == "" {
+			journal.TeachingMethod = planned.TeachingMethod
+		}
+	}
+	if journal.MeetingNumber < 0 {
+		return fmt.Errorf("%w: meeting_number cannot be negative", ErrInvalidLectureJournal)
+	}
+	if journal.Topic == "" {
+		return fmt.Errorf("%w: topic is required", ErrInvalidLectureJournal)
+	}
+
+	journal.ClassRepresentativeID = nil
+	journal.ClassRepresentative = nil
+	if input.ClassRepresentativeID != nil {
+		student, err := s.studentRepo.FindByUserID(int(*input.ClassRepresentativeID))
+		if err != nil {
+			return err
+		}
+		if student == nil {
+			return fmt.Errorf("%w: class representative not found", ErrInvalidLectureJournal)
+		}
+		inGroup, err := s.groupRepo.IsStudentInGroup(schedule.StudentGroupID, student.ID)
+		if err != nil {
+			return err
+		}
+		if !inGroup {
+			return fmt.Errorf("%w: class representative is not a student of this class", ErrInvalidLectureJournal)
+		}
+		journal.ClassRepresentativeID = &student.ID
+	}
+
+	return nil
+}
+
+// findStudent finds the student record of an external user ID
+func (s *LectureJournalService) findStudent(externalUserID uint) (*models.Student, error) {
+	student, err := s.studentRepo.FindByUserID(int(externalUserID))
+	if err != nil {
+		return nil, err
+	}
+	if student == nil {
+		return nil, fmt.Errorf("student record not found: %w", gorm.ErrRecordNotFound)
+	}
+	return student, nil
+}
+
+// loadJournal loads a journal of a schedule the user has access to
+func (s *LectureJournalService) loadJournal(journalID, userID uint) (*models.LectureJournal, error) {
+	if !s.sessions.policy.Allows(AttendanceActionJournal) && !s.sessions.policy.Allows(AttendanceActionView) {
+		return nil, ErrAttendanceActionNotAllowed
+	}
+
+	journal, err := s.journalRepo.GetJournalByID(journalID)
+	if err != nil {
+		return nil, err
+	}
+	if s.sessions.policy.Grants(AttendanceAccessCreator) && journal.CreatedByID == userID {
+		return journal, nil
+	}
+	if err := s.sessions.checkScheduleAccess(journal.CourseSchedule, userID); err != nil {
+		return nil, err
+	}
+	return journal, nil
+}
+
+// loadDraft loads a journal the user may still change
+func (s *LectureJournalService) loadDraft(journalID, userID uint) (*models.LectureJournal, error) {
+	if !s.sessions.policy.Allows(AttendanceActionJournal) {
+		return nil, ErrAttendanceActionNotAllowed
+	}
+
+	journal, err := s.loadJournal(journalID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if journal.Status != models.LectureJournalDraft {
+		return nil, ErrLectureJournalLocked
+	}
+	return journal, nil
+}
+
+// journalDelivery summarizes the journals that delivered a meeting
+func journalDelivery(meetingNumber int, plannedTopic string, journals []models.LectureJournal) models.LessonPlanTopicDelivery {
+	delivery := models.LessonPlanTopicDelivery{
+		MeetingNumber:  meetingNumber,
+		PlannedTopic:   plannedTopic,
+		Delivered:      len(journals) > 0,
+		DeliveredDates: []string{},
+		ActualTopics:   []string{},
+		Signed:         len(journals) > 0,
+	}
+	for _, journal := range journals {
+		delivery.DeliveredDates = append(delivery.DeliveredDates, journal.MeetingDate.Format("2006-01-02"))
+		delivery.ActualTopics = append(delivery.ActualTopics, journal.Topic)
+		if journal.Status != models.LectureJournalSigned {
+			delivery.Signed = false
+		}
+	}
+	return delivery
+}
+
+// cleanSubTopics trims sub-topics and drops empty ones
+func cleanSubTopics(subTopics []string) []string {
+	cleaned := make([]string, 0, len(subTopics))
+	for _, subTopic := range subTopics {
+		if subTopic = strings.TrimSpace(subTopic); subTopic != "" {
+			cleaned = append(cleaned, subTopic)
+		}
+	}
+	return cleaned
+}
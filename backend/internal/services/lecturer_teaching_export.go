@@ -0,0 +1,127 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/delpresence/backend/internal/models"
+	"github.com/delpresence/backend/internal/utils"
+	"github.com/tealeg/xlsx/v3"
+)
+
+// LecturerTeachingFilename returns the download file name of a lecturer teaching report
+func LecturerTeachingFilename(report *models.LecturerTeachingReport) string {
+	return reportFilename("xlsx", "Rekap_Mengajar_Dosen", report.AcademicYearName, report.Semester)
+}
+
+// RenderLecturerTeachingXLSX renders a lecturer teaching report as an Excel workbook with a summary
+// sheet of one row per lecturer and a detail sheet of one row per schedule
+func RenderLecturerTeachingXLSX(report *models.LecturerTeachingReport) ([]byte, error) {
+	file := xlsx.NewFile()
+	headerStyle := utils.NewXLSXHeaderStyle()
+	dataStyle := utils.NewXLSXCellStyle()
+
+	summary, err := file.AddSheet("Rekap Dosen")
+	if err != nil {
+		return nil, err
+	}
+	addTeachingTitle(summary, report)
+
+	header := summary.AddRow()
+	for _, title := range []string{
+		"No", "NIP", "Nama Dosen", "Rencana", "Terlaksana", "Oleh Dosen", "Oleh Asisten", "Tidak Terlaksana",
+		"Terlambat Dibuka", "Rata-rata Keterlambatan (menit)", "Persentase", "SKS Rencana", "SKS Dosen", "SKS Asisten",
+	} {
+		addStyledString(header, title, headerStyle)
+	}
+	for i, lecturer := range report.Lecturers {
+		row := summary.AddRow()
+		addStyledInt(row, i+1, dataStyle)
+		addStyledString(row, lecturer.NIP, dataStyle)
+		addStyledString(row, lecturer.LecturerName, dataStyle)
+		addStyledInt(row, lecturer.Planned, dataStyle)
+		addStyledInt(row, lecturer.Taught, dataStyle)
+		addStyledInt(row, lecturer.TaughtByLecturer, dataStyle)
+		addStyledInt(row, lecturer.TaughtByAssistant, dataStyle)
+		addStyledInt(row, lecturer.Missed, dataStyle)
+		addStyledInt(row, lecturer.LateOpened, dataStyle)
+		addStyledString(row, fmt.Sprintf("%.1f", lecturer.AverageDelayMinutes), dataStyle)
+		addStyledString(row, formatPercent(lecturer.TeachingRate), dataStyle)
+		addStyledInt(row, lecturer.CreditHoursPlanned, dataStyle)
+		addStyledInt(row, lecturer.CreditHoursTaught, dataStyle)
+		addStyledInt(row, lecturer.CreditHoursByAssistant, dataStyle)
+	}
+	summary.SetColWidth(1, 1, 5)
+	summary.SetColWidth(2, 2, 20)
+	summary.SetColWidth(3, 3, 30)
+	summary.SetColWidth(4, 14, 12)
+
+	details, err := file.AddSheet("Rincian Kelas")
+	if err != nil {
+		return nil, err
+	}
+	addTeachingTitle(details, report)
+
+	header = details.AddRow()
+	for _, title := range []string{
+		"Nama Dosen", "Kode MK", "Mata Kuliah", "SKS", "Kelas", "Jadwal", "Rencana", "Terlaksana", "Oleh Dosen",
+		"Oleh Asisten", "Di Luar Jadwal", "Tidak Terlaksana", "Terlambat Dibuka", "Rata-rata Keterlambatan (menit)",
+		"Keterlambatan Maks. (menit)", "SKS Dosen", "SKS Asisten",
+	} {
+		addStyledString(header, title, headerStyle)
+	}
+	for _, lecturer := range report.Lecturers {
+		for _, schedule := range lecturer.Schedules {
+			row := details.AddRow()
+			addStyledString(row, lecturer.LecturerName, dataStyle)
+			addStyledString(row, schedule.CourseCode, dataStyle)
+			addStyledString(row, schedule.CourseName, dataStyle)
+			addStyledInt(row, schedule.Credits, dataStyle)
+			addStyledString(row, schedule.StudentGroup, dataStyle)
+			addStyledString(row, fmt.Sprintf("%s, %s", schedule.Day, schedule.StartTime), dataStyle)
+			addStyledInt(row, schedule.Planned, dataStyle)
+			addStyledInt(row, schedule.Taught, dataStyle)
+			addStyledInt(row, schedule.TaughtByLecturer, dataStyle)
+			addStyledInt(row, schedule.TaughtByAssistant, dataStyle)
+			addStyledInt(row, schedule.Unplanned, dataStyle)
+			addStyledInt(row, schedule.Missed, dataStyle)
+			addStyledInt(row, schedule.LateOpened, dataStyle)
+			addStyledString(row, fmt.Sprintf("%.1f", schedule.AverageDelayMinutes), dataStyle)
+			addStyledInt(row, schedule.MaxDelayMinutes, dataStyle)
+			addStyledInt(row, schedule.CreditHoursTaught, dataStyle)
+			addStyledInt(row, schedule.CreditHoursByAssistant, dataStyle)
+		}
+	}
+	details.SetColWidth(1, 1, 30)
+	details.SetColWidth(2, 2, 12)
+	details.SetColWidth(3, 3, 30)
+	details.SetColWidth(4, 5, 8)
+	details.SetColWidth(6, 6, 16)
+	details.SetColWidth(7, 17, 12)
+
+	buf := &bytes.Buffer{}
+	if err := file.Write(buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// addTeachingTitle adds the title and period rows of a lecturer teaching sheet
+func addTeachingTitle(sheet *xlsx.Sheet, report *models.LecturerTeachingReport) {
+	titleCell := sheet.AddRow().AddCell()
+	titleCell.Value = "REKAPITULASI KEHADIRAN MENGAJAR DOSEN"
+	titleCell.SetStyle(utils.NewXLSXTitleStyle())
+	sheet.AddRow()
+
+	info := [][2]string{
+		{"Tahun Akademik", fmt.Sprintf("%s %s", report.AcademicYearName, report.Semester)},
+		{"Dihitung Sampai", report.Until},
+		{"Batas Terlambat", fmt.Sprintf("%d menit setelah jadwal mulai", report.LateThreshold)},
+	}
+	for _, item := range info {
+		row := sheet.AddRow()
+		row.AddCell().Value = item[0]
+		row.AddCell().Value = item[1]
+	}
+	sheet.AddRow()
+}
@@ -0,0 +1,268 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/delpresence/backend/internal/models"
+	"github.com/delpresence/backend/internal/repositories"
+	"github.com/delpresence/backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+// LecturerTeachingService reports how many meetings lecturers taught against their schedules
+type LecturerTeachingService struct {
+	statsRepo        *repositories.AttendanceStatisticsRepository
+	scheduleRepo     *repositories.CourseScheduleRepository
+	academicYearRepo *repositories.AcademicYearRepository
+	lecturerRepo     *repositories.LecturerRepository
+	assignmentRepo   *repositories.LecturerAssignmentRepository
+}
+
+// NewLecturerTeachingService creates a new lecturer teaching service
+func NewLecturerTeachingService() *LecturerTeachingService {
+	return &LecturerTeachingService{
+		statsRepo:        repositories.NewAttendanceStatisticsRepository(),
+		scheduleRepo:     repositories.NewCourseScheduleRepository(),
+		academicYearRepo: repositories.NewAcademicYearRepository(),
+		lecturerRepo:     repositories.NewLecturerRepository(),
+		assignmentRepo:   repositories.NewLecturerAssignmentRepository(),
+	}
+}
+
+// teachingMeeting is one date on which a schedule held sessions
+type teachingMeeting struct {
+	lecturerUserID uint // Who opened the first lecturer session of the date, zero when only assistants did
+	delay          *int // Minutes between the scheduled start and the first opening, nil when unknown
+}
+
+// ResolveAcademicYear returns the given academic year, or the active one when the ID is zero
+func (s *LecturerTeachingService) ResolveAcademicYear(academicYearID uint) (*models.AcademicYear, error) {
+	return resolveAcademicYear(s.academicYearRepo, academicYearID)
+}
+
+// GetReport builds the teaching record of every lecturer with schedules in an academic year, or of
+// one lecturer when lecturerUserID is set. A meeting is a date with at least one non-canceled
+// session. It is credited to the lecturer who opened its first lecturer session, so team members
+// are credited with the meetings they taught; meetings whose sessions were all opened by an
+// assistant, and planned meetings nobody held, stay with the schedule's coordinator. Planned
+// meetings are counted up to today, and a meeting opened more than LECTURER_LATE_THRESHOLD_MINUTES
+// (default 15) after the scheduled start counts as opened late.
+func (s *LecturerTeachingService) GetReport(academicYear *models.AcademicYear, lecturerUserID uint) (*models.LecturerTeachingReport, error) {
+	schedules, err := s.scheduleRepo.GetByAcademicYear(academicYear.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.statsRepo.GetTeachingSessions(repositories.AttendanceStatsFilter{
+		AcademicYearID: academicYear.ID,
+		LecturerUserID: lecturerUserID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var assignments []models.LecturerAssignment
+	if err := s.assignmentRepo.DB().Model(&models.LecturerAssignment{}).
+		Select("user_id, course_id, course_schedule_id").
+		Where("academic_year_id = ?", academicYear.ID).Find(&assignments).Error; err != nil {
+		return nil, err
+	}
+
+	until := academicYear.EndDate
+	if today := GetIndonesiaTime(); today.Before(until) {
+		until = today
+	}
+	lateThreshold := utils.GetEnvAsInt("LECTURER_LATE_THRESHOLD_MINUTES", 15)
+
+	scheduleByID := make(map[uint]models.CourseSchedule, len(schedules))
+	for _, schedule := range schedules {
+		scheduleByID[schedule.ID] = schedule
+	}
+
+	// Sessions are ordered by opening time, so the first session of a date sets its delay
+	location := getIndonesiaLocation()
+	meetings := make(map[uint]map[string]*teachingMeeting)
+	for _, session := range sessions {
+		schedule, ok := scheduleByID[session.CourseScheduleID]
+		if !ok {
+			continue
+		}
+		date := session.Date.In(location)
+		key := date.Format("2006-01-02")
+		if meetings[schedule.ID] == nil {
+			meetings[schedule.ID] = make(map[string]*teachingMeeting)
+		}
+
+		var teacher uint
+		if session.CreatorRole != "ASSISTANT" {
+			teacher = session.LecturerID
+		}
+		meeting, ok := meetings[schedule.ID][key]
+		if !ok {
+			meeting = &teachingMeeting{lecturerUserID: teacher}
+			// Sessions recorded retroactively by an admin have no real opening time
+			if session.CreatorRole != "ADMIN" {
+				if scheduled, err := sessionClockTime(date, schedule.StartTime, ""); err == nil {
+					delay := int(math.Max(0, session.StartTime.Sub(scheduled).Minutes()))
+					meeting.delay = &delay
+				}
+			}
+			meetings[schedule.ID][key] = meeting
+		} else if meeting.lecturerUserID == 0 {
+			meeting.lecturerUserID = teacher
+		}
+	}
+
+	records := make(map[uint]*models.LecturerTeachingRecord)
+	delays := make(map[uint][2]int) // Total delay and opened meetings per lecturer
+	for _, schedule := range schedules {
+		planned := make(map[string]bool)
+		for _, date := range plannedMeetingDates(schedule, academicYear.StartDate, until) {
+			planned[date.Format("2006-01-02")] = true
+		}
+
+		for _, teacher := range scheduleTeachers(schedule, assignments, meetings[schedule.ID]) {
+			if lecturerUserID > 0 && teacher != lecturerUserID {
+				continue
+			}
+			record, ok := records[teacher]
+			if !ok {
+				lecturer, err := s.lecturerRepo.GetByUserID(int(teacher))
+				if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil, err
+				}
+				record = &models.LecturerTeachingRecord{
+					LecturerUserID: teacher,
+					LecturerName:   lecturer.FullName,
+					NIP:            lecturer.NIP,
+				}
+				records[teacher] = record
+			}
+
+			item := models.LecturerScheduleTeaching{
+				CourseScheduleID: schedule.ID,
+				CourseCode:       schedule.Course.Code,
+				CourseName:       schedule.Course.Name,
+				Credits:          schedule.Course.Credits,
+				StudentGroup:     schedule.StudentGroup.Name,
+				Day:              schedule.Day,
+				StartTime:        schedule.StartTime,
+			}
+
+			// The coordinator answers for every planned meeting no team member taught
+			coordinator := teacher == schedule.UserID
+			credited := func(meeting *teachingMeeting) bool {
+				return meeting.lecturerUserID == teacher || (coordinator && meeting.lecturerUserID == 0)
+			}
+			for date := range planned {
+				meeting, held := meetings[schedule.ID][date]
+				if (held && credited(meeting)) || (!held && coordinator) {
+					item.Planned++
+				}
+			}
+
+			totalDelay, opened := 0, 0
+			for date, meeting := range meetings[schedule.ID] {
+				if !credited(meeting) {
+					continue
+				}
+				item.Taught++
+				if meeting.lecturerUserID == 0 {
+					item.TaughtByAssistant++
+				} else {
+					item.TaughtByLecturer++
+				}
+				if !planned[date] {
+					item.Unplanned++
+				}
+				if meeting.delay != nil {
+					opened++
+					totalDelay += *meeting.delay
+					if *meeting.delay > lateThreshold {
+						item.LateOpened++
+					}
+					if *meeting.delay > item.MaxDelayMinutes {
+						item.MaxDelayMinutes = *meeting.delay
+					}
+				}
+			}
+			item.Missed = item.Planned - (item.Taught - item.Unplanned)
+			if opened > 0 {
+				item.AverageDelayMinutes = float64(totalDelay) / float64(opened)
+			}
+			item.CreditHoursPlanned = item.Planned * item.Credits
+			item.CreditHoursTaught = item.TaughtByLecturer * item.Credits
+			item.CreditHoursByAssistant = item.TaughtByAssistant * item.Credits
+
+			record.Planned += item.Planned
+			record.Taught += item.Taught
+			record.TaughtByLecturer += item.TaughtByLecturer
+			record.TaughtByAssistant += item.TaughtByAssistant
+			record.Missed += item.Missed
+			record.LateOpened += item.LateOpened
+			record.CreditHoursPlanned += item.CreditHoursPlanned
+			record.CreditHoursTaught += item.CreditHoursTaught
+			record.CreditHoursByAssistant += item.CreditHoursByAssistant
+			record.Schedules = append(record.Schedules, item)
+
+			total := delays[teacher]
+			delays[teacher] = [2]int{total[0] + totalDelay, total[1] + opened}
+		}
+	}
+
+	report := &models.LecturerTeachingReport{
+		AcademicYearID:   academicYear.ID,
+		AcademicYearName: academicYear.Name,
+		Semester:         academicYear.Semester,
+		Until:            until.Format("2006-01-02"),
+		LateThreshold:    lateThreshold,
+		Lecturers:        make([]models.LecturerTeachingRecord, 0, len(records)),
+	}
+	for userID, record := range records {
+		if total := delays[userID]; total[1] > 0 {
+			record.AverageDelayMinutes = float64(total[0]) / float64(total[1])
+		}
+		if record.Planned > 0 {
+			record.TeachingRate = float64(record.TaughtByLecturer) * 100 / float64(record.Planned)
+		}
+		sort.Slice(record.Schedules, func(i, j int) bool {
+			a, b := record.Schedules[i], record.Schedules[j]
+			if a.CourseCode != b.CourseCode {
+				return a.CourseCode < b.CourseCode
+			}
+			return a.StudentGroup < b.StudentGroup
+		})
+		report.Lecturers = append(report.Lecturers, *record)
+	}
+	sort.Slice(report.Lecturers, func(i, j int) bool {
+		return report.Lecturers[i].LecturerName < report.Lecturers[j].LecturerName
+	})
+
+	return report, nil
+}
+
+// scheduleTeachers returns the lecturers of a schedule: its coordinator, the team members assigned
+// to it and anyone else who taught one of its meetings
+func scheduleTeachers(schedule models.CourseSchedule, assignments []models.LecturerAssignment, meetings map[string]*teachingMeeting) []uint {
+	seen := map[uint]bool{schedule.UserID: true}
+	var others []uint
+	add := func(userID uint) {
+		if userID != 0 && !seen[userID] {
+			seen[userID] = true
+			others = append(others, userID)
+		}
+	}
+	for _, assignment := range assignments {
+		if assignment.CourseID == schedule.CourseID &&
+			(assignment.CourseScheduleID == nil || *assignment.CourseScheduleID == schedule.ID) {
+			add(uint(assignment.UserID))
+		}
+	}
+	for _, meeting := range meetings {
+		add(meeting.lecturerUserID)
+	}
+	sort.Slice(others, func(i, j int) bool { return others[i] < others[j] })
+	return append([]uint{schedule.UserID}, others...)
+}
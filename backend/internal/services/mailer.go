@@ -0,0 +1,205 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/delpresence/backend/internal/utils"
+)
+
+// EmailMessage is a plain-text email addressed to a single recipient
+type EmailMessage struct {
+	To      string
+	ToName  string
+	Subject string
+	Body    string
+}
+
+// Mailer sends email messages
+type Mailer interface {
+	Send(msg EmailMessage) error
+}
+
+// NewMailerFromEnv creates the mailer selected by MAIL_TRANSPORT ("smtp" or "file")
+func NewMailerFromEnv() Mailer {
+	from := utils.GetEnvWithDefault("MAIL_FROM", "DelPresence <no-reply@del.ac.id>")
+
+	switch strings.ToLower(utils.GetEnvWithDefault("MAIL_TRANSPORT", "file")) {
+	case "smtp":
+		return &SMTPMailer{
+			host:        utils.GetEnvWithDefault("SMTP_HOST", "localhost"),
+			port:        utils.GetEnvAsInt("SMTP_PORT", 587),
+			username:    utils.GetEnvWithDefault("SMTP_USERNAME", ""),
+			password:    utils.GetEnvWithDefault("SMTP_PASSWORD", ""),
+			implicitTLS: utils.GetEnvAsBool("SMTP_IMPLICIT_TLS", false),
+			from:        from,
+		}
+	default:
+		return &FileMailer{
+			dir:  utils.GetEnvWithDefault("MAIL_OUTBOX_DIR", "./mailbox"),
+			from: from,
+		}
+	}
+}
+
+// SMTPMailer sends email through an SMTP server. STARTTLS is used when the
+// server offers it; set implicitTLS for servers that expect TLS from the start (port 465).
+type SMTPMailer struct {
+	host        string
+	port        int
+	username    string
+	password    string
+	implicitTLS bool
+	from        string
+}
+
+// Send delivers a message through the SMTP server
+func (m *SMTPMailer) Send(msg EmailMessage) error {
+	data, err := buildEmail(m.from, msg)
+	if err != nil {
+		return err
+	}
+
+	sender, err := mail.ParseAddress(m.from)
+	if err != nil {
+		return fmt.Errorf("invalid MAIL_FROM address: %w", err)
+	}
+
+	addr := net.JoinHostPort(m.host, fmt.Sprintf("%d", m.port))
+
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+
+	if !m.implicitTLS {
+		return smtp.SendMail(addr, auth, sender.Address, []string{msg.To}, data)
+	}
+
+	conn, err := tls.Dial("tcp", addr, &tls.Config{ServerName: m.host})
+	if err != nil {
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(sender.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// FileMailer writes each message as an .eml file into a directory instead of sending it.
+// It is meant for development and testing.
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+// Send writes the message to the outbox directory
+func (m *FileMailer) Send(msg EmailMessage) error {
+	data, err := buildEmail(m.from, msg)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return err
+	}
+
+	suffix, err := randomHex(4)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102-150405"), suffix)
+	return os.WriteFile(filepath.Join(m.dir, name), data, 0644)
+}
+
+// buildEmail renders a message in RFC 5322 format with a quoted-printable UTF-8 body
+func buildEmail(from string, msg EmailMessage) ([]byte, error) {
+	if msg.To == "" {
+		return nil, fmt.Errorf("recipient address is required")
+	}
+
+	to := (&mail.Address{Name: msg.ToName, Address: msg.To}).String()
+
+	messageID, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+
+	domain := "delpresence.local"
+	if sender, err := mail.ParseAddress(from); err == nil {
+		if at := strings.LastIndex(sender.Address, "@"); at >= 0 {
+			domain = sender.Address[at+1:]
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", to)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: <%s@%s>\r\n", messageID, domain)
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
+	buf.WriteString("\r\n")
+
+	qp := quotedprintable.NewWriter(&buf)
+	if _, err := qp.Write([]byte(strings.ReplaceAll(msg.Body, "\n", "\r\n"))); err != nil {
+		return nil, err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// randomHex returns n random bytes encoded as hex
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,472 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/delpresence/backend/internal/models"
+	"github.com/delpresence/backend/internal/repositories"
+	"github.com/delpresence/backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+// Notification job names accepted by RunJob
+const (
+	NotificationJobAbsenceSummary   = "absence-summary"
+	NotificationJobThresholdWarning = "threshold-warning"
+	NotificationJobLecturerDigest   = "lecturer-digest"
+)
+
+// notificationSchedulerInterval is how often the scheduler checks whether jobs are due
+const notificationSchedulerInterval = 10 * time.Minute
+
+// NotificationPreferenceUpdate is the set of preference fields a user can change.
+// Nil fields are left unchanged.
+type NotificationPreferenceUpdate struct {
+	Language         *string `json:"language"`
+	EmailEnabled     *bool   `json:"email_enabled"`
+	AbsenceSummary   *bool   `json:"absence_summary"`
+	ThresholdWarning *bool   `json:"threshold_warning"`
+	LecturerDigest   *bool   `json:"lecturer_digest"`
+	PushEnabled      *bool   `json:"push_enabled"`
+}
+
+// absenceSummaryData is the template data of a weekly absence summary
+type absenceSummaryData struct {
+	Name    string
+	From    time.Time
+	To      time.Time
+	Courses []repositories.StudentCourseAttendance
+}
+
+// thresholdWarningData is the template data of an eligibility threshold warning
+type thresholdWarningData struct {
+	Name       string
+	CourseCode string
+	CourseName string
+	Rate       float64
+	Threshold  float64
+	Attended   int
+	Held       int
+	Eligible   bool
+}
+
+// lecturerDigestData is the template data of a lecturer's daily digest
+type lecturerDigestData struct {
+	Name     string
+	Date     time.Time
+	Unopened []repositories.LecturerScheduleItem
+	Unclosed []repositories.LecturerScheduleItem
+}
+
+// NotificationService sends email notifications about attendance
+type NotificationService struct {
+	repo             *repositories.NotificationRepository
+	academicYearRepo *repositories.AcademicYearRepository
+	mailer           Mailer
+	policy           EligibilityPolicy
+	defaultLanguage  string
+}
+
+// NewNotificationService creates a new notification service
+func NewNotificationService() *NotificationService {
+	return &NotificationService{
+		repo:             repositories.NewNotificationRepository(),
+		academicYearRepo: repositories.NewAcademicYearRepository(),
+		mailer:           NewMailerFromEnv(),
+		policy:           LoadEligibilityPolicy(),
+		defaultLanguage:  normalizeLanguage(utils.GetEnvWithDefault("MAIL_DEFAULT_LANGUAGE", "id")),
+	}
+}
+
+// GetPreference returns a user's notification preference, or the defaults if none was saved
+func (s *NotificationService) GetPreference(userID int) (*models.NotificationPreference, error) {
+	preference, err := s.repo.GetPreference(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return s.defaultPreference(userID), nil
+		}
+		return nil, err
+	}
+	return preference, nil
+}
+
+// UpdatePreference applies the given changes to a user's notification preference
+func (s *NotificationService) UpdatePreference(userID int, update NotificationPreferenceUpdate) (*models.NotificationPreference, error) {
+	preference, err := s.GetPreference(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Language != nil {
+		language := strings.ToLower(strings.TrimSpace(*update.Language))
+		if language != "id" && language != "en" {
+			return nil, errors.New("language must be 'id' or 'en'")
+		}
+		preference.Language = language
+	}
+	if update.EmailEnabled != nil {
+		preference.EmailEnabled = *update.EmailEnabled
+	}
+	if update.AbsenceSummary != nil {
+		preference.AbsenceSummary = *update.AbsenceSummary
+	}
+	if update.ThresholdWarning != nil {
+		preference.ThresholdWarning = *update.ThresholdWarning
+	}
+	if update.LecturerDigest != nil {
+		preference.LecturerDigest = *update.LecturerDigest
+	}
+	if update.PushEnabled != nil {
+		preference.PushEnabled = *update.PushEnabled
+	}
+
+	if err := s.repo.SavePreference(preference); err != nil {
+		return nil, err
+	}
+	return preference, nil
+}
+
+// ListLogs lists the most recent notification logs, optionally filtered by kind
+func (s *NotificationService) ListLogs(kind string, limit int) ([]models.NotificationLog, error) {
+	return s.repo.ListLogs(strings.ToUpper(kind), limit)
+}
+
+// RunJob runs a notification job immediately and returns the number of emails sent
+func (s *NotificationService) RunJob(job string) (int, error) {
+	now := GetIndonesiaTime()
+
+	switch job {
+	case NotificationJobAbsenceSummary:
+		return s.SendAbsenceSummaries(now)
+	case NotificationJobThresholdWarning:
+		return s.SendThresholdWarnings(now)
+	case NotificationJobLecturerDigest:
+		return s.SendLecturerDigests(now)
+	default:
+		return 0, fmt.Errorf("unknown notification job: %s", job)
+	}
+}
+
+// StartScheduler starts a background worker that runs the daily jobs once a day after
+// NOTIFICATION_HOUR (WIB) and the weekly summary on NOTIFICATION_WEEKLY_DAY (0 = Sunday).
+// Each email is reserved in the notification log first, so running the scheduler on
+// several replicas does not send duplicates.
+func (s *NotificationService) StartScheduler() {
+	hour := utils.GetEnvAsInt("NOTIFICATION_HOUR", 7)
+	weeklyDay := time.Weekday(utils.GetEnvAsInt("NOTIFICATION_WEEKLY_DAY", int(time.Monday)))
+
+	var mu sync.Mutex
+	var lastRun string
+
+	runDue := func() {
+		now := GetIndonesiaTime()
+		today := now.Format("2006-01-02")
+
+		mu.Lock()
+		defer mu.Unlock()
+		if now.Hour() < hour || lastRun == today {
+			return
+		}
+		lastRun = today
+
+		if sent, err := s.SendLecturerDigests(now); err != nil {
+			log.Printf("Lecturer digest notifications failed: %v", err)
+		} else if sent > 0 {
+			log.Printf("Sent %d lecturer digest notifications", sent)
+		}
+
+		if sent, err := s.SendThresholdWarnings(now); err != nil {
+			log.Printf("Threshold warning notifications failed: %v", err)
+		} else if sent > 0 {
+			log.Printf("Sent %d threshold warning notifications", sent)
+		}
+
+		if now.Weekday() == weeklyDay {
+			if sent, err := s.SendAbsenceSummaries(now); err != nil {
+				log.Printf("Absence summary notifications failed: %v", err)
+			} else if sent > 0 {
+				log.Printf("Sent %d absence summary notifications", sent)
+			}
+		}
+	}
+
+	go func() {
+		runDue()
+
+		ticker := time.NewTicker(notificationSchedulerInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			runDue()
+		}
+	}()
+
+	log.Printf("Notification scheduler started (daily at %02d:00 WIB, weekly on %s)", hour, weeklyDay)
+}
+
+// SendAbsenceSummaries emails each student who missed or was late to a meeting
+// a summary of the seven days before now
+func (s *NotificationService) SendAbsenceSummaries(now time.Time) (int, error) {
+	to := truncateToDate(now).AddDate(0, 0, -1)
+	from := to.AddDate(0, 0, -6)
+
+	rows, err := s.repo.GetStudentCourseAttendance(from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	// Group rows by student, keeping only students with absences or late arrivals
+	byStudent := make(map[int][]repositories.StudentCourseAttendance)
+	var userIDs []int
+	for _, row := range rows {
+		if row.Absent == 0 && row.Late == 0 {
+			continue
+		}
+		if _, ok := byStudent[row.UserID]; !ok {
+			userIDs = append(userIDs, row.UserID)
+		}
+		byStudent[row.UserID] = append(byStudent[row.UserID], row)
+	}
+
+	preferences, err := s.repo.GetPreferencesByUserIDs(userIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	dedupeKey := "week:" + from.Format("2006-01-02")
+	for _, userID := range userIDs {
+		courses := byStudent[userID]
+		preference := s.preferenceFor(preferences, userID)
+		if !preference.EmailEnabled || !preference.AbsenceSummary {
+			continue
+		}
+
+		data := absenceSummaryData{Name: courses[0].FullName, From: from, To: to, Courses: courses}
+		if s.send(userID, courses[0].Email, courses[0].FullName, models.NotificationKindAbsenceSummary, dedupeKey, preference.Language, data) {
+			sent++
+		}
+	}
+
+	return sent, nil
+}
+
+// SendThresholdWarnings emails students whose attendance in a course of the active
+// academic year is below or close to the eligibility threshold. Each student is
+// warned at most once per course per week.
+func (s *NotificationService) SendThresholdWarnings(now time.Time) (int, error) {
+	academicYear, err := s.academicYearRepo.GetActiveAcademicYear()
+	if err != nil {
+		return 0, err
+	}
+	if academicYear == nil {
+		return 0, nil
+	}
+
+	rows, err := s.repo.GetStudentCourseAttendance(academicYear.StartDate, truncateToDate(now))
+	if err != nil {
+		return 0, err
+	}
+
+	minMeetings := utils.GetEnvAsInt("NOTIFICATION_WARNING_MIN_MEETINGS", 3)
+
+	var candidates []repositories.StudentCourseAttendance
+	var userIDs []int
+	for _, row := range rows {
+		if row.Held() < minMeetings {
+			continue
+		}
+		if !s.policy.IsNearThreshold(s.policy.Rate(row.Present, row.Late, row.Excused, row.Held())) {
+			continue
+		}
+		candidates = append(candidates, row)
+		userIDs = append(userIDs, row.UserID)
+	}
+
+	preferences, err := s.repo.GetPreferencesByUserIDs(userIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	year, week := now.ISOWeek()
+	sent := 0
+	for _, row := range candidates {
+		preference := s.preferenceFor(preferences, row.UserID)
+		if !preference.EmailEnabled || !preference.ThresholdWarning {
+			continue
+		}
+
+		rate := s.policy.Rate(row.Present, row.Late, row.Excused, row.Held())
+		attended := row.Present + row.Late
+		if s.policy.ExcusedCountsAsPresent {
+			attended += row.Excused
+		}
+
+		data := thresholdWarningData{
+			Name:       row.FullName,
+			CourseCode: row.CourseCode,
+			CourseName: row.CourseName,
+			Rate:       rate,
+			Threshold:  s.policy.ThresholdPercent,
+			Attended:   attended,
+			Held:       row.Held(),
+			Eligible:   s.policy.IsEligible(rate),
+		}
+
+		dedupeKey := fmt.Sprintf("schedule:%d:%d-W%02d", row.CourseScheduleID, year, week)
+		if s.send(row.UserID, row.Email, row.FullName, models.NotificationKindThresholdWarning, dedupeKey, preference.Language, data) {
+			sent++
+		}
+	}
+
+	return sent, nil
+}
+
+// SendLecturerDigests emails lecturers and assistants the schedules that had no
+// session yesterday and the sessions they left open before today
+func (s *NotificationService) SendLecturerDigests(now time.Time) (int, error) {
+	today := truncateToDate(now)
+	yesterday := today.AddDate(0, 0, -1)
+
+	unopened, err := s.repo.GetUnopenedSchedules(yesterday, indonesianDayName(yesterday.Weekday()))
+	if err != nil {
+		return 0, err
+	}
+
+	unclosed, err := s.repo.GetUnclosedSessions(today)
+	if err != nil {
+		return 0, err
+	}
+
+	digests := make(map[int]*lecturerDigestData)
+	for _, item := range unopened {
+		userID := int(item.LecturerUserID)
+		if digests[userID] == nil {
+			digests[userID] = &lecturerDigestData{Date: yesterday}
+		}
+		digests[userID].Unopened = append(digests[userID].Unopened, item)
+	}
+	for _, item := range unclosed {
+		userID := int(item.LecturerUserID)
+		if digests[userID] == nil {
+			digests[userID] = &lecturerDigestData{Date: yesterday}
+		}
+		digests[userID].Unclosed = append(digests[userID].Unclosed, item)
+	}
+
+	userIDs := make([]int, 0, len(digests))
+	for userID := range digests {
+		userIDs = append(userIDs, userID)
+	}
+	sort.Ints(userIDs)
+
+	recipients, err := s.repo.GetStaffRecipients(userIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	preferences, err := s.repo.GetPreferencesByUserIDs(userIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	dedupeKey := "date:" + today.Format("2006-01-02")
+	for _, userID := range userIDs {
+		preference := s.preferenceFor(preferences, userID)
+		if !preference.EmailEnabled || !preference.LecturerDigest {
+			continue
+		}
+
+		recipient := recipients[userID]
+		data := digests[userID]
+		data.Name = recipient.FullName
+		if s.send(userID, recipient.Email, recipient.FullName, models.NotificationKindLecturerDigest, dedupeKey, preference.Language, data) {
+			sent++
+		}
+	}
+
+	return sent, nil
+}
+
+// send reserves a log entry for the notification and emails it. It returns true
+// only when the email was sent by this call.
+func (s *NotificationService) send(userID int, email, name string, kind models.NotificationKind, dedupeKey, language string, data interface{}) bool {
+	entry := &models.NotificationLog{
+		UserID:    userID,
+		Kind:      kind,
+		DedupeKey: dedupeKey,
+		Channel:   models.NotificationChannelEmail,
+		Recipient: email,
+		Status:    models.NotificationLogStatusPending,
+	}
+
+	reserved, err := s.repo.ReserveLog(entry)
+	if err != nil {
+		log.Printf("Failed to reserve %s notification for user %d: %v", kind, userID, err)
+		return false
+	}
+	if !reserved {
+		return false
+	}
+
+	subject, body, err := renderEmail(kind, language, data)
+	if err == nil {
+		entry.Subject = subject
+		if email == "" {
+			entry.Status = models.NotificationLogStatusSkipped
+			entry.Error = "user has no email address"
+		} else {
+			err = s.mailer.Send(EmailMessage{To: email, ToName: name, Subject: subject, Body: body})
+		}
+	}
+
+	if err != nil {
+		entry.Status = models.NotificationLogStatusFailed
+		entry.Error = err.Error()
+		log.Printf("Failed to send %s notification to user %d: %v", kind, userID, err)
+	} else if entry.Status == models.NotificationLogStatusPending {
+		entry.Status = models.NotificationLogStatusSent
+	}
+
+	if err := s.repo.UpdateLog(entry); err != nil {
+		log.Printf("Failed to update notification log %d: %v", entry.ID, err)
+	}
+
+	return entry.Status == models.NotificationLogStatusSent
+}
+
+// preferenceFor returns a user's preference from the map, or the defaults
+func (s *NotificationService) preferenceFor(preferences map[int]models.NotificationPreference, userID int) *models.NotificationPreference {
+	if preference, ok := preferences[userID]; ok {
+		return &preference
+	}
+	return s.defaultPreference(userID)
+}
+
+// defaultPreference returns the preference used for users who never saved one
+func (s *NotificationService) defaultPreference(userID int) *models.NotificationPreference {
+	return &models.NotificationPreference{
+		UserID:           userID,
+		Language:         s.defaultLanguage,
+		EmailEnabled:     true,
+		AbsenceSummary:   true,
+		ThresholdWarning: true,
+		LecturerDigest:   true,
+		PushEnabled:      true,
+	}
+}
+
+// normalizeLanguage returns "en" for English and "id" for anything else
+func normalizeLanguage(language string) string {
+	if strings.ToLower(strings.TrimSpace(language)) == "en" {
+		return "en"
+	}
+	return "id"
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/delpresence/backend/internal/models"
+)
+
+// emailTemplate is the subject and body template of one notification kind in one language
+type emailTemplate struct {
+	Subject string
+	Body    string
+}
+
+// emailTemplates holds the notification templates keyed by kind and language
+var emailTemplates = map[models.NotificationKind]map[string]emailTemplate{
+	models.NotificationKindAbsenceSummary: {
+		"id": {
+			Subject: "Ringkasan kehadiran mingguan {{date .From}} - {{date .To}}",
+			Body: `Halo {{.Name}},
+
+Berikut ringkasan kehadiran Anda untuk periode {{date .From}} sampai {{date .To}}:
+{{range .Courses}}
+- {{.CourseCode}} {{.CourseName}}: hadir {{.Present}}, terlambat {{.Late}}, tidak hadir {{.Absent}}, izin {{.Excused}}
+{{- end}}
+
+Jika ada data yang tidak sesuai, silakan hubungi dosen pengampu mata kuliah.
+
+Salam,
+DelPresence
+`,
+		},
+		"en": {
+			Subject: "Weekly attendance summary {{date .From}} - {{date .To}}",
+			Body: `Hello {{.Name}},
+
+Here is your attendance summary for {{date .From}} to {{date .To}}:
+{{range .Courses}}
+- {{.CourseCode}} {{.CourseName}}: present {{.Present}}, late {{.Late}}, absent {{.Absent}}, excused {{.Excused}}
+{{- end}}
+
+If any record is incorrect, please contact the lecturer of the course.
+
+Regards,
+DelPresence
+`,
+		},
+	},
+	models.NotificationKindThresholdWarning: {
+		"id": {
+			Subject: "Peringatan kehadiran {{.CourseCode}} {{.CourseName}}",
+			Body: `Halo {{.Name}},
+
+Tingkat kehadiran Anda pada mata kuliah {{.CourseCode}} {{.CourseName}} saat ini {{pct .Rate}} ({{.Attended}} dari {{.Held}} pertemuan).
+{{- if .Eligible}}
+Batas minimum kehadiran untuk mengikuti ujian adalah {{pct .Threshold}}. Anda sudah mendekati batas tersebut.
+{{- else}}
+Batas minimum kehadiran untuk mengikuti ujian adalah {{pct .Threshold}}. Kehadiran Anda saat ini berada di bawah batas tersebut.
+{{- end}}
+
+Mohon perhatikan kehadiran Anda pada pertemuan berikutnya.
+
+Salam,
+DelPresence
+`,
+		},
+		"en": {
+			Subject: "Attendance warning for {{.CourseCode}} {{.CourseName}}",
+			Body: `Hello {{.Name}},
+
+Your attendance rate in {{.CourseCode}} {{.CourseName}} is currently {{pct .Rate}} ({{.Attended}} of {{.Held}} meetings).
+{{- if .Eligible}}
+The minimum attendance required to sit the exam is {{pct .Threshold}}. You are getting close to that limit.
+{{- else}}
+The minimum attendance required to sit the exam is {{pct .Threshold}}. Your attendance is currently below that limit.
+{{- end}}
+
+Please make sure to attend the upcoming meetings.
+
+Regards,
+DelPresence
+`,
+		},
+	},
+	models.NotificationKindLecturerDigest: {
+		"id": {
+			Subject: "Sesi absensi yang perlu ditindaklanjuti ({{date .Date}})",
+			Body: `Halo {{.Name}},
+{{if .Unopened}}
+Jadwal berikut tidak memiliki sesi absensi pada {{date .Date}}:
+{{range .Unopened}}
+- {{.CourseCode}} {{.CourseName}} ({{.StudentGroupName}}), {{.StartTime}}-{{.EndTime}}
+{{- end}}
+{{end}}
+{{- if .Unclosed}}
+Sesi absensi berikut masih aktif dan belum ditutup:
+{{range .Unclosed}}
+- {{.CourseCode}} {{.CourseName}} ({{.StudentGroupName}}), {{date .Date}} {{.StartTime}}-{{.EndTime}}
+{{- end}}
+{{end}}
+Silakan buka atau tutup sesi tersebut melalui DelPresence.
+
+Salam,
+DelPresence
+`,
+		},
+		"en": {
+			Subject: "Attendance sessions that need your attention ({{date .Date}})",
+			Body: `Hello {{.Name}},
+{{if .Unopened}}
+The following schedules had no attendance session on {{date .Date}}:
+{{range .Unopened}}
+- {{.CourseCode}} {{.CourseName}} ({{.StudentGroupName}}), {{.StartTime}}-{{.EndTime}}
+{{- end}}
+{{end}}
+{{- if .Unclosed}}
+The following attendance sessions are still active and were never closed:
+{{range .Unclosed}}
+- {{.CourseCode}} {{.CourseName}} ({{.StudentGroupName}}), {{date .Date}} {{.StartTime}}-{{.EndTime}}
+{{- end}}
+{{end}}
+Please open or close these sessions in DelPresence.
+
+Regards,
+DelPresence
+`,
+		},
+	},
+}
+
+// emailTemplateFuncs are the helper functions available in email templates
+var emailTemplateFuncs = template.FuncMap{
+	"date": func(t time.Time) string { return t.Format("02-01-2006") },
+	"pct":  func(v float64) string { return fmt.Sprintf("%.1f%%", v) },
+}
+
+// renderEmail renders the subject and body of a notification in the given language,
+// falling back to Indonesian when the language has no template
+func renderEmail(kind models.NotificationKind, language string, data interface{}) (string, string, error) {
+	byLanguage, ok := emailTemplates[kind]
+	if !ok {
+		return "", "", fmt.Errorf("no email template for %s", kind)
+	}
+
+	tmpl, ok := byLanguage[language]
+	if !ok {
+		tmpl = byLanguage["id"]
+	}
+
+	subject, err := executeEmailTemplate(string(kind)+"-subject", tmpl.Subject, data)
+	if err != nil {
+		return "", "", err
+	}
+
+	body, err := executeEmailTemplate(string(kind)+"-body", tmpl.Body, data)
+	if err != nil {
+		return "", "", err
+	}
+
+	return strings.TrimSpace(subject), body, nil
+}
+
+// executeEmailTemplate parses and executes a single template
+func executeEmailTemplate(name, text string, data interface{}) (string, error) {
+	t, err := template.New(name).Funcs(emailTemplateFuncs).Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
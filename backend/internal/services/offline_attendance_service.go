@@ -0,0 +1,419 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/delpresence/backend/internal/database"
+	"github.com/delpresence/backend/internal/models"
+	"github.com/delpresence/backend/internal/repositories"
+	"github.com/delpresence/backend/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// offlineVerificationMethod is recorded on attendance captured offline
+const offlineVerificationMethod = "QR_CODE_OFFLINE"
+
+// maxOfflineBatchSize limits the number of check-ins in one batch
+const maxOfflineBatchSize = 100
+
+// OfflineCheckInSubmission is a check-in captured by the app while offline.
+// Signature is the hex HMAC-SHA256 of OfflineCheckInSigningString with the device's signing key.
+type OfflineCheckInSubmission struct {
+	ClientID  string `json:"client_id"`
+	SessionID uint   `json:"session_id"`
+	QRData    string `json:"qr_data"`
+	ScannedAt string `json:"scanned_at"` // RFC 3339, as read from the device clock
+	DeviceID  string `json:"device_id"`
+	Signature string `json:"signature"`
+}
+
+// OfflineCheckInResult is the outcome of one submission in a batch
+type OfflineCheckInResult struct {
+	ClientID         string                       `json:"client_id"`
+	SubmissionID     uint                         `json:"submission_id,omitempty"`
+	Outcome          models.OfflineCheckInOutcome `json:"outcome"`
+	Reason           string                       `json:"reason,omitempty"`
+	AttendanceStatus string                       `json:"attendance_status,omitempty"`
+	Duplicate        bool                         `json:"duplicate"`
+}
+
+// OfflineAttendanceService validates and records check-ins captured offline
+type OfflineAttendanceService struct {
+	repo           *repositories.OfflineCheckInRepository
+	attendanceRepo *repositories.AttendanceRepository
+	sessions       *AttendanceSessionService
+	db             *gorm.DB
+	secret         []byte
+	maxDelay       time.Duration
+	flagGrace      time.Duration
+	clockSkew      time.Duration
+}
+
+// NewOfflineAttendanceService creates a new offline attendance service. Reviews of flagged
+// check-ins are authorized with the actor's attendance policy.
+func NewOfflineAttendanceService(actor AttendanceActor) *OfflineAttendanceService {
+	secret := utils.GetEnvWithDefault("OFFLINE_CHECKIN_SECRET", "")
+	if secret == "" {
+		secret = utils.GetEnvWithDefault("JWT_SECRET", "")
+	}
+
+	return &OfflineAttendanceService{
+		repo:           repositories.NewOfflineCheckInRepository(),
+		attendanceRepo: repositories.NewAttendanceRepository(),
+		sessions:       NewAttendanceSessionService(actor),
+		db:             database.GetDB(),
+		secret:         []byte(secret),
+		maxDelay:       time.Duration(utils.GetEnvAsInt("OFFLINE_CHECKIN_MAX_DELAY_HOURS", 24)) * time.Hour,
+		flagGrace:      time.Duration(utils.GetEnvAsInt("OFFLINE_CHECKIN_FLAG_GRACE_MINUTES", 10)) * time.Minute,
+		clockSkew:      2 * time.Minute,
+	}
+}
+
+// IssueSigningKey returns the key a device uses to sign offline check-ins. The key is
+// derived from the server secret, the user and the device, so nothing needs to be stored.
+func (s *OfflineAttendanceService) IssueSigningKey(userID uint, deviceID string) (string, error) {
+	deviceID = strings.TrimSpace(deviceID)
+	if deviceID == "" {
+		return "", errors.New("device_id is required")
+	}
+	if len(s.secret) == 0 {
+		return "", errors.New("offline check-in is not configured")
+	}
+	return s.signingKey(userID, deviceID), nil
+}
+
+// OfflineCheckInSigningString returns the string the app signs for a submission
+func OfflineCheckInSigningString(sub OfflineCheckInSubmission) string {
+	return fmt.Sprintf("%s|%d|%s|%s|%s", sub.ClientID, sub.SessionID, sub.QRData, sub.ScannedAt, sub.DeviceID)
+}
+
+// SubmitBatch validates and records a batch of offline check-ins for a student. When the student's
+// registered device is given, check-ins captured on other devices are rejected.
+// Resubmitting a client ID returns the stored result without changing attendance.
+func (s *OfflineAttendanceService) SubmitBatch(userID uint, registeredDevice string, submissions []OfflineCheckInSubmission) ([]OfflineCheckInResult, error) {
+	if len(submissions) == 0 {
+		return nil, errors.New("no submissions")
+	}
+	if len(submissions) > maxOfflineBatchSize {
+		return nil, fmt.Errorf("a batch can contain at most %d submissions", maxOfflineBatchSize)
+	}
+
+	var student models.Student
+	if err := s.db.Where("user_id = ?", userID).First(&student).Error; err != nil {
+		return nil, errors.New("student record not found")
+	}
+
+	receivedAt := time.Now()
+	results := make([]OfflineCheckInResult, 0, len(submissions))
+	for _, sub := range submissions {
+		result, err := s.processSubmission(&student, registeredDevice, sub, receivedAt)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// ListSubmissions lists the offline check-ins of a session the user has access to
+func (s *OfflineAttendanceService) ListSubmissions(sessionID uint, userID uint, outcome string) ([]models.OfflineCheckIn, error) {
+	if _, err := s.sessions.authorize(AttendanceActionView, sessionID, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListBySession(sessionID, strings.ToUpper(outcome))
+}
+
+// ReviewSubmission accepts or rejects a flagged check-in. Accepting records the
+// attendance with the original scan time.
+func (s *OfflineAttendanceService) ReviewSubmission(submissionID uint, reviewerID uint, accept bool, notes string) (*models.OfflineCheckIn, error) {
+	checkIn, err := s.repo.GetByID(submissionID)
+	if err != nil {
+		return nil, errors.New("offline check-in not found")
+	}
+
+	// Accepting a check-in sets the student's status, as marking them by hand would
+	session, err := s.sessions.authorize(AttendanceActionMark, checkIn.AttendanceSessionID, reviewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if checkIn.Outcome != models.OfflineCheckInFlagged {
+		return nil, errors.New("only flagged check-ins can be reviewed")
+	}
+
+	now := time.Now()
+	checkIn.ReviewedByID = &reviewerID
+	checkIn.ReviewedAt = &now
+	if notes != "" {
+		checkIn.Reason = strings.TrimSpace(checkIn.Reason + "; " + notes)
+	}
+
+	var attendance *models.StudentAttendance
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if accept {
+			checkIn.Outcome = models.OfflineCheckInAccepted
+			attendance, err = s.applyCheckIn(tx, session, checkIn.StudentID, checkIn.ScannedAt, CheckInMetadata{DeviceID: checkIn.DeviceID})
+			if err != nil {
+				return err
+			}
+			checkIn.AttendanceStatus = string(attendance.Status)
+		} else {
+			checkIn.Outcome = models.OfflineCheckInRejected
+		}
+		return tx.Omit("Student").Save(checkIn).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if attendance != nil {
+		publishStudentAttendanceEvent(models.AttendanceEventStudentCheckedIn, session, &checkIn.Student, attendance)
+	}
+
+	return checkIn, nil
+}
+
+// processSubmission validates one submission and stores it with its outcome
+func (s *OfflineAttendanceService) processSubmission(student *models.Student, registeredDevice string, sub OfflineCheckInSubmission, receivedAt time.Time) (OfflineCheckInResult, error) {
+	sub.ClientID = strings.TrimSpace(sub.ClientID)
+	result := OfflineCheckInResult{ClientID: sub.ClientID}
+
+	if sub.ClientID == "" || len(sub.ClientID) > 64 {
+		result.Outcome = models.OfflineCheckInRejected
+		result.Reason = "client_id is required and must be at most 64 characters"
+		return result, nil
+	}
+
+	// A resubmitted client ID returns the stored result
+	if existing, err := s.repo.GetByClientID(student.UserID, sub.ClientID); err == nil {
+		return offlineResultFromRecord(existing, true), nil
+	}
+
+	scannedAt, err := time.Parse(time.RFC3339, sub.ScannedAt)
+	if err != nil {
+		result.Outcome = models.OfflineCheckInRejected
+		result.Reason = "scanned_at must be an RFC 3339 timestamp"
+		return result, nil
+	}
+
+	var session *models.AttendanceSession
+	var codeExpiredAt *time.Time
+	outcome, reason := s.validate(student, registeredDevice, sub, scannedAt, receivedAt, &session, &codeExpiredAt)
+
+	record := &models.OfflineCheckIn{
+		ClientID:            sub.ClientID,
+		UserID:              student.UserID,
+		StudentID:           student.ID,
+		AttendanceSessionID: sub.SessionID,
+		DeviceID:            sub.DeviceID,
+		QRData:              sub.QRData,
+		ScannedAt:           scannedAt,
+		ReceivedAt:          receivedAt,
+		Outcome:             outcome,
+		Reason:              reason,
+	}
+
+	var attendance *models.StudentAttendance
+	inserted := false
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Omit("Student").Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			// Another request stored the same client ID concurrently
+			return nil
+		}
+		inserted = true
+
+		if outcome != models.OfflineCheckInAccepted {
+			return nil
+		}
+
+		attendance, err = s.applyCheckIn(tx, session, student.ID, scannedAt, CheckInMetadata{
+			DeviceID:      sub.DeviceID,
+			CodeExpiredAt: codeExpiredAt,
+		})
+		if err != nil {
+			return err
+		}
+		record.AttendanceStatus = string(attendance.Status)
+		return tx.Model(record).Update("attendance_status", record.AttendanceStatus).Error
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if !inserted {
+		existing, err := s.repo.GetByClientID(student.UserID, sub.ClientID)
+		if err != nil {
+			return result, err
+		}
+		return offlineResultFromRecord(existing, true), nil
+	}
+
+	if attendance != nil {
+		publishStudentAttendanceEvent(models.AttendanceEventStudentCheckedIn, session, student, attendance)
+	}
+
+	return offlineResultFromRecord(record, false), nil
+}
+
+// validate decides whether a submission is accepted, rejected or flagged for review.
+// The session is returned through sessionOut when it exists, and when a rotating QR code was
+// scanned after it rotated, the rotation time through codeExpiredAtOut.
+func (s *OfflineAttendanceService) validate(student *models.Student, registeredDevice string, sub OfflineCheckInSubmission, scannedAt, receivedAt time.Time, sessionOut **models.AttendanceSession, codeExpiredAtOut **time.Time) (models.OfflineCheckInOutcome, string) {
+	if len(s.secret) == 0 {
+		return models.OfflineCheckInRejected, "offline check-in is not configured"
+	}
+
+	if registeredDevice != "" && sub.DeviceID != registeredDevice {
+		return models.OfflineCheckInRejected, "captured on a device that is not registered for this account"
+	}
+
+	expected := hmac.New(sha256.New, []byte(s.signingKey(uint(student.UserID), sub.DeviceID)))
+	expected.Write([]byte(OfflineCheckInSigningString(sub)))
+	signature, err := hex.DecodeString(sub.Signature)
+	if err != nil || !hmac.Equal(signature, expected.Sum(nil)) {
+		return models.OfflineCheckInRejected, "invalid signature"
+	}
+
+	session, err := s.attendanceRepo.GetAttendanceSessionByID(sub.SessionID)
+	if err != nil {
+		return models.OfflineCheckInRejected, "attendance session not found"
+	}
+	*sessionOut = session
+
+	if session.Status == models.AttendanceStatusCanceled {
+		return models.OfflineCheckInRejected, "attendance session was canceled"
+	}
+
+	if session.Type != models.AttendanceTypeQRCode && session.Type != models.AttendanceTypeBoth {
+		return models.OfflineCheckInRejected, "this attendance session does not support QR code verification"
+	}
+
+	// Only the random code the session shows proves the student saw it; a rotating code is checked
+	// against the code shown at capture time
+	valid, codeExpiredAt := verifyIssuedQRCode(session, sub.QRData, scannedAt)
+	if !valid {
+		return models.OfflineCheckInRejected, "invalid QR code data"
+	}
+	*codeExpiredAtOut = codeExpiredAt
+
+	var isEnrolled bool
+	if err := s.db.Raw(`
+		SELECT EXISTS (
+			SELECT 1 FROM student_to_groups
+			WHERE student_group_id = ? AND student_id = ?
+		) as is_enrolled`,
+		session.CourseSchedule.StudentGroupID, student.ID).Scan(&isEnrolled).Error; err != nil || !isEnrolled {
+		return models.OfflineCheckInRejected, "student is not enrolled in this course"
+	}
+
+	if receivedAt.Sub(scannedAt) > s.maxDelay {
+		return models.OfflineCheckInRejected, fmt.Sprintf("submitted more than %d hours after scanning", int(s.maxDelay.Hours()))
+	}
+
+	opensAt, closesAt := offlineSessionWindow(session, receivedAt)
+
+	if scannedAt.After(receivedAt.Add(s.clockSkew)) {
+		return models.OfflineCheckInFlagged, "scan time is ahead of the server clock"
+	}
+
+	if scannedAt.Before(opensAt) || scannedAt.After(closesAt) {
+		if scannedAt.Before(opensAt.Add(-s.flagGrace)) || scannedAt.After(closesAt.Add(s.flagGrace)) {
+			return models.OfflineCheckInRejected, "scan time is outside the session window"
+		}
+		return models.OfflineCheckInFlagged, fmt.Sprintf("scan time is within %d minutes outside the session window", int(s.flagGrace.Minutes()))
+	}
+
+	// The scan time comes from the device. A rotating code ties it to when the code was shown, but a
+	// fixed code does not, so once the session has closed the lecturer decides.
+	if session.Status == models.AttendanceStatusClosed && session.QRRotationSeconds <= 0 {
+		return models.OfflineCheckInFlagged, "submitted after the session closed, the scan time cannot be verified"
+	}
+
+	return models.OfflineCheckInAccepted, ""
+}
+
+// applyCheckIn records attendance at the scan time. Students already marked present,
+// late or excused (e.g. by an online scan or the lecturer) are left unchanged.
+func (s *OfflineAttendanceService) applyCheckIn(tx *gorm.DB, session *models.AttendanceSession, studentID uint, scannedAt time.Time, meta CheckInMetadata) (*models.StudentAttendance, error) {
+	status := models.StudentAttendanceStatusPresent
+	if session.AllowLate && scannedAt.Sub(session.StartTime).Minutes() > float64(session.LateThreshold) {
+		status = models.StudentAttendanceStatusLate
+	}
+
+	checkInTime := scannedAt.In(getIndonesiaLocation())
+
+	var attendance models.StudentAttendance
+	err := tx.Where("attendance_session_id = ? AND student_id = ?", session.ID, studentID).First(&attendance).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		attendance = models.StudentAttendance{
+			AttendanceSessionID: session.ID,
+			StudentID:           studentID,
+			Status:              status,
+			CheckInTime:         &checkInTime,
+			VerificationMethod:  offlineVerificationMethod,
+			DeviceID:            meta.DeviceID,
+			CodeExpiredAt:       meta.CodeExpiredAt,
+		}
+		if err := tx.Create(&attendance).Error; err != nil {
+			return nil, err
+		}
+		return &attendance, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if attendance.Status != models.StudentAttendanceStatusAbsent {
+		return &attendance, nil
+	}
+
+	attendance.Status = status
+	attendance.CheckInTime = &checkInTime
+	attendance.VerificationMethod = offlineVerificationMethod
+	attendance.DeviceID = meta.DeviceID
+	attendance.CodeExpiredAt = meta.CodeExpiredAt
+	if err := tx.Save(&attendance).Error; err != nil {
+		return nil, err
+	}
+	return &attendance, nil
+}
+
+// signingKey derives the signing key of a user's device
+func (s *OfflineAttendanceService) signingKey(userID uint, deviceID string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(fmt.Sprintf("offline-checkin|%d|%s", userID, deviceID)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// offlineSessionWindow returns when a session accepted QR check-ins: from its start
+// until it was closed, or until now while it is still open
+func offlineSessionWindow(session *models.AttendanceSession, now time.Time) (time.Time, time.Time) {
+	if session.EndTime != nil {
+		return session.StartTime, *session.EndTime
+	}
+	return session.StartTime, now
+}
+
+// offlineResultFromRecord converts a stored check-in to a batch result
+func offlineResultFromRecord(record *models.OfflineCheckIn, duplicate bool) OfflineCheckInResult {
+	return OfflineCheckInResult{
+		ClientID:         record.ClientID,
+		SubmissionID:     record.ID,
+		Outcome:          record.Outcome,
+		Reason:           record.Reason,
+		AttendanceStatus: record.AttendanceStatus,
+		Duplicate:        duplicate,
+	}
+}
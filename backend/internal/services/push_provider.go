@@ -0,0 +1,217 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/delpresence/backend/internal/utils"
+	"github.com/dgrijalva/jwt-go"
+)
+
+// ErrInvalidPushToken is returned when the provider reports a token as unregistered,
+// so the token should be removed
+var ErrInvalidPushToken = errors.New("push token is no longer valid")
+
+// PushMessage is a notification shown on a device, with data for the app to act on
+type PushMessage struct {
+	Title string            `json:"title"`
+	Body  string            `json:"body"`
+	Data  map[string]string `json:"data,omitempty"`
+}
+
+// PushProvider sends push notifications to a single device token
+type PushProvider interface {
+	Send(token string, msg PushMessage) error
+}
+
+// NewPushProviderFromEnv creates the push provider selected by PUSH_PROVIDER ("fcm" or "stub")
+func NewPushProviderFromEnv() PushProvider {
+	if strings.ToLower(utils.GetEnvWithDefault("PUSH_PROVIDER", "stub")) == "fcm" {
+		provider, err := NewFCMProvider(
+			utils.GetEnvWithDefault("FCM_CREDENTIALS_FILE", ""),
+			utils.GetEnvWithDefault("FCM_ENDPOINT", "https://fcm.googleapis.com"),
+		)
+		if err == nil {
+			return provider
+		}
+		log.Printf("Failed to configure FCM push provider, falling back to stub: %v", err)
+	}
+	return &StubPushProvider{}
+}
+
+// StubPushProvider logs push notifications instead of sending them.
+// It is meant for development and testing.
+type StubPushProvider struct{}
+
+// Send logs the push notification
+func (p *StubPushProvider) Send(token string, msg PushMessage) error {
+	log.Printf("[push] to %s: %s - %s %v", shortenToken(token), msg.Title, msg.Body, msg.Data)
+	return nil
+}
+
+// fcmServiceAccount holds the fields of a Google service account key file used by FCM
+type fcmServiceAccount struct {
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// FCMProvider sends push notifications through the FCM HTTP v1 API, authenticating
+// with a service account. The endpoint can point to any FCM-compatible server.
+type FCMProvider struct {
+	account  fcmServiceAccount
+	endpoint string
+	client   *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// NewFCMProvider creates an FCM provider from a service account key file
+func NewFCMProvider(credentialsFile, endpoint string) (*FCMProvider, error) {
+	if credentialsFile == "" {
+		return nil, errors.New("FCM_CREDENTIALS_FILE is not set")
+	}
+
+	raw, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var account fcmServiceAccount
+	if err := json.Unmarshal(raw, &account); err != nil {
+		return nil, fmt.Errorf("invalid FCM credentials file: %w", err)
+	}
+	if account.ProjectID == "" || account.ClientEmail == "" || account.PrivateKey == "" {
+		return nil, errors.New("FCM credentials file is missing project_id, client_email or private_key")
+	}
+	if account.TokenURI == "" {
+		account.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+
+	return &FCMProvider{
+		account:  account,
+		endpoint: strings.TrimRight(endpoint, "/"),
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Send posts a message for one device token
+func (p *FCMProvider) Send(token string, msg PushMessage) error {
+	accessToken, err := p.getAccessToken()
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"message": map[string]interface{}{
+			"token": token,
+			"notification": map[string]string{
+				"title": msg.Title,
+				"body":  msg.Body,
+			},
+			"data": msg.Data,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost,
+		fmt.Sprintf("%s/v1/projects/%s/messages:send", p.endpoint, p.account.ProjectID),
+		bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode == http.StatusNotFound || strings.Contains(string(respBody), "UNREGISTERED") {
+		return ErrInvalidPushToken
+	}
+	return fmt.Errorf("FCM returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+}
+
+// getAccessToken returns a cached OAuth2 access token, exchanging a signed
+// service account assertion for a new one when it is about to expire
+func (p *FCMProvider) getAccessToken() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.accessToken != "" && time.Now().Before(p.expiresAt.Add(-time.Minute)) {
+		return p.accessToken, nil
+	}
+
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(p.account.PrivateKey))
+	if err != nil {
+		return "", fmt.Errorf("invalid FCM private key: %w", err)
+	}
+
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   p.account.ClientEmail,
+		"scope": "https://www.googleapis.com/auth/firebase.messaging",
+		"aud":   p.account.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(key)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := p.client.PostForm(p.account.TokenURI, url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return "", fmt.Errorf("FCM token exchange returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+
+	p.accessToken = result.AccessToken
+	p.expiresAt = now.Add(time.Duration(result.ExpiresIn) * time.Second)
+	return p.accessToken, nil
+}
+
+// shortenToken returns the start of a device token for logging
+func shortenToken(token string) string {
+	if len(token) <= 12 {
+		return token
+	}
+	return token[:12] + "..."
+}
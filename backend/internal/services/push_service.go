@@ -0,0 +1,292 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/delpresence/backend/internal/models"
+	"github.com/delpresence/backend/internal/repositories"
+	"github.com/delpresence/backend/internal/utils"
+)
+
+// pushReminderInterval is how often the reminder worker looks for sessions about to close
+const pushReminderInterval = 30 * time.Second
+
+var (
+	pushProvider     PushProvider
+	pushProviderOnce sync.Once
+)
+
+// getPushProvider returns the shared push provider, so access tokens are cached across services
+func getPushProvider() PushProvider {
+	pushProviderOnce.Do(func() {
+		pushProvider = NewPushProviderFromEnv()
+	})
+	return pushProvider
+}
+
+// pushTexts holds the title and body format of each push kind keyed by language
+var pushTexts = map[models.NotificationKind]map[string][2]string{
+	models.NotificationKindSessionOpened: {
+		"id": {"Absensi dibuka", "Sesi absensi %s %s sudah dibuka.%s"},
+		"en": {"Attendance open", "The attendance session for %s %s is now open.%s"},
+	},
+	models.NotificationKindSessionClosing: {
+		"id": {"Absensi segera ditutup", "Sesi absensi %s %s akan ditutup pukul %s. Anda belum tercatat hadir."},
+		"en": {"Attendance closing soon", "The attendance session for %s %s closes at %s. You have not checked in yet."},
+	},
+}
+
+// closesAtTexts is appended to the session opened body when the session closes automatically
+var closesAtTexts = map[string]string{
+	"id": " Lakukan absensi sebelum pukul %s.",
+	"en": " Check in before %s.",
+}
+
+// PushService registers devices and sends push notifications to the mobile app
+type PushService struct {
+	repo            *repositories.NotificationRepository
+	provider        PushProvider
+	defaultLanguage string
+}
+
+// NewPushService creates a new push service
+func NewPushService() *PushService {
+	return &PushService{
+		repo:            repositories.NewNotificationRepository(),
+		provider:        getPushProvider(),
+		defaultLanguage: normalizeLanguage(utils.GetEnvWithDefault("MAIL_DEFAULT_LANGUAGE", "id")),
+	}
+}
+
+// RegisterDevice stores a device token for a user
+func (s *PushService) RegisterDevice(userID int, role, token, platform string) (*models.DeviceToken, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil, errors.New("token is required")
+	}
+
+	platform = strings.ToLower(strings.TrimSpace(platform))
+	if platform != "" && platform != "android" && platform != "ios" && platform != "web" {
+		return nil, errors.New("platform must be 'android', 'ios' or 'web'")
+	}
+
+	device := &models.DeviceToken{
+		UserID:     userID,
+		Role:       role,
+		Token:      token,
+		Platform:   platform,
+		LastSeenAt: time.Now(),
+	}
+
+	if err := s.repo.SaveDeviceToken(device); err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+// UnregisterDevice removes a user's device token, e.g. on logout
+func (s *PushService) UnregisterDevice(userID int, token string) error {
+	deleted, err := s.repo.DeleteDeviceToken(userID, strings.TrimSpace(token))
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errors.New("device token not found")
+	}
+	return nil
+}
+
+// ListDevices lists a user's registered devices
+func (s *PushService) ListDevices(userID int) ([]models.DeviceToken, error) {
+	return s.repo.ListDeviceTokens(userID)
+}
+
+// NotifySessionOpened pushes a notification to every student of the session's group
+func (s *PushService) NotifySessionOpened(session *models.AttendanceSession, schedule *models.CourseSchedule) {
+	if schedule == nil || schedule.StudentGroupID == 0 {
+		return
+	}
+
+	userIDs, err := s.repo.GetStudentUserIDsInGroup(schedule.StudentGroupID)
+	if err != nil {
+		log.Printf("Failed to load students for session %d push: %v", session.ID, err)
+		return
+	}
+
+	closesAt := ""
+	if session.AutoClose {
+		closesAt = session.StartTime.Add(time.Duration(session.Duration) * time.Minute).In(getIndonesiaLocation()).Format("15:04")
+	}
+
+	s.sendToUsers(userIDs, models.NotificationKindSessionOpened, fmt.Sprintf("session:%d", session.ID), func(language string) PushMessage {
+		texts := pushTexts[models.NotificationKindSessionOpened][language]
+		suffix := ""
+		if closesAt != "" {
+			suffix = fmt.Sprintf(closesAtTexts[language], closesAt)
+		}
+		return PushMessage{
+			Title: texts[0],
+			Body:  fmt.Sprintf(texts[1], schedule.Course.Code, schedule.Course.Name, suffix),
+			Data: map[string]string{
+				"type":       string(models.NotificationKindSessionOpened),
+				"session_id": fmt.Sprintf("%d", session.ID),
+			},
+		}
+	})
+}
+
+// StartReminderWorker starts a background worker that reminds students who have not
+// checked in that an auto-closing session ends within PUSH_CLOSING_REMINDER_MINUTES
+func (s *PushService) StartReminderWorker() {
+	lead := time.Duration(utils.GetEnvAsInt("PUSH_CLOSING_REMINDER_MINUTES", 5)) * time.Minute
+
+	go func() {
+		ticker := time.NewTicker(pushReminderInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			s.sendClosingReminders(lead)
+		}
+	}()
+
+	log.Printf("Push reminder worker started (%s before auto-close)", lead)
+}
+
+// sendClosingReminders pushes a reminder for every session closing within the lead time
+func (s *PushService) sendClosingReminders(lead time.Duration) {
+	now := time.Now()
+	sessions, err := s.repo.GetSessionsClosingBetween(now, now.Add(lead))
+	if err != nil {
+		log.Printf("Failed to load sessions closing soon: %v", err)
+		return
+	}
+
+	for _, session := range sessions {
+		userIDs, err := s.repo.GetAbsentStudentUserIDs(session.SessionID)
+		if err != nil {
+			log.Printf("Failed to load absent students for session %d: %v", session.SessionID, err)
+			continue
+		}
+
+		session := session
+		closesAt := session.ClosesAt.In(getIndonesiaLocation()).Format("15:04")
+		s.sendToUsers(userIDs, models.NotificationKindSessionClosing, fmt.Sprintf("session:%d", session.SessionID), func(language string) PushMessage {
+			texts := pushTexts[models.NotificationKindSessionClosing][language]
+			return PushMessage{
+				Title: texts[0],
+				Body:  fmt.Sprintf(texts[1], session.CourseCode, session.CourseName, closesAt),
+				Data: map[string]string{
+					"type":       string(models.NotificationKindSessionClosing),
+					"session_id": fmt.Sprintf("%d", session.SessionID),
+				},
+			}
+		})
+	}
+}
+
+// sendToUsers pushes a message to every device of the given users who allow push
+// notifications. Each user is reserved in the notification log first, so the same
+// kind and key is pushed at most once per user across replicas.
+func (s *PushService) sendToUsers(userIDs []int, kind models.NotificationKind, dedupeKey string, build func(language string) PushMessage) int {
+	tokens, err := s.repo.GetDeviceTokensByUserIDs(userIDs)
+	if err != nil {
+		log.Printf("Failed to load device tokens for %s push: %v", kind, err)
+		return 0
+	}
+	if len(tokens) == 0 {
+		return 0
+	}
+
+	recipients := make([]int, 0, len(tokens))
+	for userID := range tokens {
+		recipients = append(recipients, userID)
+	}
+
+	preferences, err := s.repo.GetPreferencesByUserIDs(recipients)
+	if err != nil {
+		log.Printf("Failed to load notification preferences for %s push: %v", kind, err)
+		return 0
+	}
+
+	var invalid []string
+	sent := 0
+	for _, userID := range recipients {
+		language := s.defaultLanguage
+		if preference, ok := preferences[userID]; ok {
+			if !preference.PushEnabled {
+				continue
+			}
+			language = normalizeLanguage(preference.Language)
+		}
+
+		entry := &models.NotificationLog{
+			UserID:    userID,
+			Kind:      kind,
+			DedupeKey: dedupeKey,
+			Channel:   models.NotificationChannelPush,
+			Status:    models.NotificationLogStatusPending,
+		}
+		reserved, err := s.repo.ReserveLog(entry)
+		if err != nil {
+			log.Printf("Failed to reserve %s push for user %d: %v", kind, userID, err)
+			continue
+		}
+		if !reserved {
+			continue
+		}
+
+		msg := build(language)
+		entry.Subject = msg.Title
+		entry.Status = models.NotificationLogStatusFailed
+
+		var errs []string
+		for _, token := range tokens[userID] {
+			err := s.provider.Send(token, msg)
+			switch {
+			case err == nil:
+				entry.Status = models.NotificationLogStatusSent
+			case errors.Is(err, ErrInvalidPushToken):
+				invalid = append(invalid, token)
+			default:
+				errs = append(errs, err.Error())
+			}
+		}
+
+		if entry.Status == models.NotificationLogStatusSent {
+			sent++
+		} else if len(errs) == 0 {
+			entry.Status = models.NotificationLogStatusSkipped
+			entry.Error = "no valid device tokens"
+		} else {
+			entry.Error = strings.Join(errs, "; ")
+		}
+
+		if err := s.repo.UpdateLog(entry); err != nil {
+			log.Printf("Failed to update notification log %d: %v", entry.ID, err)
+		}
+	}
+
+	if err := s.repo.DeleteDeviceTokens(invalid); err != nil {
+		log.Printf("Failed to remove invalid device tokens: %v", err)
+	}
+
+	return sent
+}
+
+// dispatchSessionOpenedPush notifies the session's students in the background
+// so creating the session is not slowed down by the push provider
+func dispatchSessionOpenedPush(session *models.AttendanceSession, schedule *models.CourseSchedule) {
+	sessionCopy := *session
+	var scheduleCopy *models.CourseSchedule
+	if schedule != nil {
+		copied := *schedule
+		scheduleCopy = &copied
+	}
+
+	go NewPushService().NotifySessionOpened(&sessionCopy, scheduleCopy)
+}
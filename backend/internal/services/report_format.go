@@ -0,0 +1,64 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/delpresence/backend/internal/utils"
+)
+
+// indonesianMonths are the month names used in printed reports
+var indonesianMonths = [...]string{
+	"Januari", "Februari", "Maret", "April", "Mei", "Juni",
+	"Juli", "Agustus", "September", "Oktober", "November", "Desember",
+}
+
+// filenameUnsafe matches characters that are not allowed in report file names
+var filenameUnsafe = regexp.MustCompile(`[^\w\-]`)
+
+// formatIndonesianDate formats a date as e.g. "5 Agustus 2025"
+func formatIndonesianDate(t time.Time) string {
+	return fmt.Sprintf("%d %s %d", t.Day(), indonesianMonths[t.Month()-1], t.Year())
+}
+
+// reportFilename joins parts into a file name safe for Content-Disposition headers
+func reportFilename(extension string, parts ...string) string {
+	cleaned := make([]string, 0, len(parts))
+	for _, part := range parts {
+		part = strings.ReplaceAll(strings.TrimSpace(part), " ", "_")
+		part = strings.NewReplacer("/", "-", "\\", "-").Replace(part)
+		part = filenameUnsafe.ReplaceAllString(part, "")
+		if part != "" {
+			cleaned = append(cleaned, part)
+		}
+	}
+	return strings.Join(cleaned, "_") + "." + extension
+}
+
+// formatPercent formats a percentage with two decimals
+func formatPercent(value float64) string {
+	return fmt.Sprintf("%.2f%%", value)
+}
+
+// publicURL returns an absolute URL for a path on the public address of the backend
+func publicURL(path string) string {
+	base := utils.GetEnvWithDefault("PUBLIC_BASE_URL", "http://localhost:"+utils.GetEnvWithDefault("SERVER_PORT", "8080"))
+	return strings.TrimRight(base, "/") + path
+}
+
+// signReportValue signs a value embedded in a printed or shared link with the secret in the
+// given environment variable, falling back to JWT_SECRET
+func signReportValue(secretEnv, value string) string {
+	secret := utils.GetEnvWithDefault(secretEnv, "")
+	if secret == "" {
+		secret = utils.GetEnvWithDefault("JWT_SECRET", "")
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
@@ -0,0 +1,424 @@
+package services
+
+import (
+	"crypto/hmac"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/delpresence/backend/internal/models"
+	"github.com/delpresence/backend/internal/realtime"
+	"github.com/delpresence/backend/internal/repositories"
+	"github.com/delpresence/backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+const (
+	// reportJobClaimLease is how long a claimed job is hidden from other workers while it renders
+	reportJobClaimLease = 10 * time.Minute
+
+	// reportJobBatchSize is the number of jobs rendered per worker tick
+	reportJobBatchSize = 2
+
+	// reportJobMaxAttempts is the number of times a job is rendered before it is marked failed
+	reportJobMaxAttempts = 3
+
+	// reportJobRetryDelay is the delay before a failed job is retried, multiplied by the attempt count
+	reportJobRetryDelay = 30 * time.Second
+
+	// reportJobCleanupInterval is how often expired artifacts are removed from storage
+	reportJobCleanupInterval = 10 * time.Minute
+
+	// reportJobHistory is how long failed and expired jobs stay listed before they are deleted
+	reportJobHistory = 30 * 24 * time.Hour
+
+	// reportJobListLimit is the number of jobs returned when listing a user's jobs
+	reportJobListLimit = 50
+
+	// reportJobEvent is the Server-Sent Event name used for job status updates
+	reportJobEvent = "REPORT_JOB_UPDATED"
+)
+
+var (
+	// ErrInvalidReportJob is returned when a job request has an unknown type, format or missing parameters
+	ErrInvalidReportJob = errors.New("invalid report job")
+
+	// ErrReportJobNotFound is returned when a job does not exist or belongs to another user
+	ErrReportJobNotFound = errors.New("report job not found")
+
+	// ErrInvalidDownloadLink is returned when a download link has a bad signature or has expired
+	ErrInvalidDownloadLink = errors.New("download link is invalid or has expired")
+
+	// ErrReportArtifactUnavailable is returned when the job has no downloadable file
+	ErrReportArtifactUnavailable = errors.New("report file is not available")
+)
+
+// reportJobFormats lists the formats each job type can be rendered in
+var reportJobFormats = map[models.ReportJobType][]string{
+	models.ReportJobSessionReport:    {"xlsx"},
+	models.ReportJobAttendanceMatrix: {"xlsx", "csv", "pdf"},
+	models.ReportJobStudentRecap:     {"xlsx", "pdf"},
+}
+
+// reportContentTypes maps report formats to their MIME types
+var reportContentTypes = map[string]string{
+	"xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"csv":  "text/csv; charset=utf-8",
+	"pdf":  "application/pdf",
+}
+
+// ReportJobService queues report exports, renders them in a background worker and
+// serves the rendered files through signed, time-limited download links
+type ReportJobService struct {
+	repo       *repositories.ReportJobRepository
+	storageDir string
+	retention  time.Duration
+	linkTTL    time.Duration
+}
+
+// NewReportJobService creates a new report job service
+func NewReportJobService() *ReportJobService {
+	return &ReportJobService{
+		repo:       repositories.NewReportJobRepository(),
+		storageDir: utils.GetEnvWithDefault("REPORT_STORAGE_DIR", "./storage/reports"),
+		retention:  time.Duration(utils.GetEnvAsInt("REPORT_RETENTION_HOURS", 24)) * time.Hour,
+		linkTTL:    time.Duration(utils.GetEnvAsInt("REPORT_LINK_TTL_MINUTES", 15)) * time.Minute,
+	}
+}
+
+// Enqueue validates a report request and queues it for the worker. Access to the requested
+// data is checked when the job renders, with the role and user ID of the requester.
+func (s *ReportJobService) Enqueue(jobType models.ReportJobType, format string, params models.ReportJobParams, role string, userID uint) (*models.ReportJobResponse, error) {
+	formats, ok := reportJobFormats[jobType]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidReportJob, jobType)
+	}
+	if format == "" {
+		format = formats[0]
+	}
+	if !slices.Contains(formats, format) {
+		return nil, fmt.Errorf("%w: %s reports can be rendered as %v", ErrInvalidReportJob, jobType, formats)
+	}
+
+	switch jobType {
+	case models.ReportJobSessionReport:
+		if params.SessionID == 0 {
+			return nil, fmt.Errorf("%w: session_id is required", ErrInvalidReportJob)
+		}
+	case models.ReportJobAttendanceMatrix:
+		if params.CourseScheduleID == 0 && params.CourseID == 0 {
+			return nil, fmt.Errorf("%w: course_schedule_id or course_id is required", ErrInvalidReportJob)
+		}
+	case models.ReportJobStudentRecap:
+		if params.StudentID == 0 {
+			return nil, fmt.Errorf("%w: student_id is required", ErrInvalidReportJob)
+		}
+	}
+
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &models.ReportJob{
+		Type:            jobType,
+		Format:          format,
+		Params:          string(encoded),
+		Status:          models.ReportJobStatusPending,
+		RequestedByID:   userID,
+		RequestedByRole: role,
+		AvailableAt:     time.Now(),
+	}
+	if err := s.repo.Create(job); err != nil {
+		return nil, fmt.Errorf("failed to queue report job: %w", err)
+	}
+
+	return s.toResponse(job), nil
+}
+
+// GetJob returns a job requested by the user, with a fresh download link when it is completed.
+// User IDs are only unique within a role, so the role must match as well.
+func (s *ReportJobService) GetJob(id uint, role string, userID uint) (*models.ReportJobResponse, error) {
+	job, err := s.repo.GetByID(id)
+	if err != nil || job.RequestedByID != userID || job.RequestedByRole != role {
+		return nil, ErrReportJobNotFound
+	}
+	return s.toResponse(job), nil
+}
+
+// ListJobs returns the most recent jobs requested by the user in the role
+func (s *ReportJobService) ListJobs(role string, userID uint) ([]models.ReportJobResponse, error) {
+	jobs, err := s.repo.ListByRequester(role, userID, reportJobListLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.ReportJobResponse, 0, len(jobs))
+	for i := range jobs {
+		responses = append(responses, *s.toResponse(&jobs[i]))
+	}
+	return responses, nil
+}
+
+// OpenArtifact verifies a signed download link and opens the rendered file of the job.
+// The caller must close the returned file.
+func (s *ReportJobService) OpenArtifact(id uint, expires int64, signature string) (*models.ReportJob, *os.File, error) {
+	if time.Now().Unix() > expires || !hmac.Equal([]byte(signature), []byte(signReportDownload(id, expires))) {
+		return nil, nil, ErrInvalidDownloadLink
+	}
+
+	job, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, nil, ErrReportJobNotFound
+	}
+	if job.Status != models.ReportJobStatusCompleted || job.StoragePath == "" {
+		return nil, nil, ErrReportArtifactUnavailable
+	}
+
+	file, err := os.Open(job.StoragePath)
+	if err != nil {
+		log.Printf("Failed to open artifact of report job %d: %v", job.ID, err)
+		return nil, nil, ErrReportArtifactUnavailable
+	}
+	return job, file, nil
+}
+
+// StartWorker starts a background worker that renders queued jobs on an interval and removes
+// artifacts past their retention period. Jobs are claimed with SKIP LOCKED, so a worker can run
+// on every replica as long as REPORT_STORAGE_DIR is shared between them.
+func (s *ReportJobService) StartWorker() {
+	interval := time.Duration(utils.GetEnvAsInt("REPORT_WORKER_INTERVAL_SECONDS", 2)) * time.Second
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		cleanup := time.NewTicker(reportJobCleanupInterval)
+		defer cleanup.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.processDueJobs()
+			case <-cleanup.C:
+				s.purgeExpiredArtifacts()
+			}
+		}
+	}()
+
+	log.Printf("Report job worker started (interval %s, storage %s, retention %s)", interval, s.storageDir, s.retention)
+}
+
+// processDueJobs renders one batch of queued jobs
+func (s *ReportJobService) processDueJobs() {
+	jobs, err := s.repo.ClaimDueJobs(reportJobBatchSize, reportJobClaimLease)
+	if err != nil {
+		log.Printf("Failed to claim report jobs: %v", err)
+		return
+	}
+
+	for i := range jobs {
+		s.runJob(&jobs[i])
+	}
+}
+
+// runJob renders a claimed job, stores the artifact and records the outcome
+func (s *ReportJobService) runJob(job *models.ReportJob) {
+	now := time.Now()
+	job.Attempts++
+	job.StartedAt = &now
+	s.saveJob(job)
+
+	content, filename, err := s.render(job)
+	if err == nil {
+		err = s.storeArtifact(job, content)
+	}
+
+	if err != nil {
+		job.Error = err.Error()
+		if job.Attempts >= reportJobMaxAttempts || isPermanentReportError(err) {
+			job.Status = models.ReportJobStatusFailed
+			log.Printf("Report job %d failed after %d attempts: %v", job.ID, job.Attempts, err)
+		} else {
+			job.Status = models.ReportJobStatusPending
+			job.AvailableAt = time.Now().Add(time.Duration(job.Attempts) * reportJobRetryDelay)
+		}
+		s.saveJob(job)
+		return
+	}
+
+	completedAt := time.Now()
+	expiresAt := completedAt.Add(s.retention)
+	job.Status = models.ReportJobStatusCompleted
+	job.FileName = filename
+	job.ContentType = reportContentTypes[job.Format]
+	job.FileSize = int64(len(content))
+	job.Error = ""
+	job.CompletedAt = &completedAt
+	job.ExpiresAt = &expiresAt
+	s.saveJob(job)
+}
+
+// render produces the file of a job with the access rights of the user who requested it
+func (s *ReportJobService) render(job *models.ReportJob) ([]byte, string, error) {
+	var params models.ReportJobParams
+	if err := json.Unmarshal([]byte(job.Params), &params); err != nil {
+		return nil, "", fmt.Errorf("%w: malformed parameters", ErrInvalidReportJob)
+	}
+
+	switch job.Type {
+	case models.ReportJobSessionReport:
+		sessionService, err := NewAttendanceSessionServiceForRole(job.RequestedByRole)
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: %v", ErrInvalidReportJob, err)
+		}
+		return sessionService.RenderSessionReport(params.SessionID, job.RequestedByID)
+
+	case models.ReportJobAttendanceMatrix:
+		matrixService := NewAttendanceMatrixService()
+		var matrices []models.AttendanceMatrix
+		if params.CourseScheduleID != 0 {
+			matrix, err := matrixService.GetScheduleMatrix(params.CourseScheduleID, job.RequestedByRole, job.RequestedByID)
+			if err != nil {
+				return nil, "", err
+			}
+			matrices = []models.AttendanceMatrix{*matrix}
+		} else {
+			var err error
+			matrices, err = matrixService.GetCourseMatrices(params.CourseID, params.AcademicYearID, job.RequestedByRole, job.RequestedByID)
+			if err != nil {
+				return nil, "", err
+			}
+		}
+
+		var content []byte
+		var err error
+		switch job.Format {
+		case "csv":
+			content, err = RenderMatrixCSV(matrices)
+		case "pdf":
+			content, err = RenderMatrixPDF(matrices)
+		default:
+			content, err = RenderMatrixXLSX(matrices)
+		}
+		return content, MatrixFilename(matrices, job.Format), err
+
+	case models.ReportJobStudentRecap:
+		recap, err := NewAttendanceRecapService().GetRecapForUser(params.StudentID, job.RequestedByRole, job.RequestedByID, params.AcademicYearID)
+		if err != nil {
+			return nil, "", err
+		}
+
+		var content []byte
+		if job.Format == "pdf" {
+			content, err = RenderRecapPDF(recap)
+		} else {
+			content, err = RenderRecapXLSX(recap)
+		}
+		return content, RecapFilename(recap, job.Format), err
+	}
+
+	return nil, "", fmt.Errorf("%w: unknown type %q", ErrInvalidReportJob, job.Type)
+}
+
+// storeArtifact writes the rendered file to storage. The file is written under a temporary
+// name and renamed, so a partially written file is never served.
+func (s *ReportJobService) storeArtifact(job *models.ReportJob, content []byte) error {
+	if err := os.MkdirAll(s.storageDir, 0o750); err != nil {
+		return fmt.Errorf("failed to create report storage: %w", err)
+	}
+
+	path := filepath.Join(s.storageDir, fmt.Sprintf("report-job-%d.%s", job.ID, job.Format))
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o640); err != nil {
+		return fmt.Errorf("failed to write report file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write report file: %w", err)
+	}
+
+	job.StoragePath = path
+	return nil
+}
+
+// purgeExpiredArtifacts removes artifacts past their retention period and deletes old job history
+func (s *ReportJobService) purgeExpiredArtifacts() {
+	jobs, err := s.repo.ListExpiredArtifacts(time.Now(), 100)
+	if err != nil {
+		log.Printf("Failed to list expired report jobs: %v", err)
+		return
+	}
+
+	for i := range jobs {
+		job := &jobs[i]
+		if job.StoragePath != "" {
+			if err := os.Remove(job.StoragePath); err != nil && !os.IsNotExist(err) {
+				log.Printf("Failed to remove artifact of report job %d: %v", job.ID, err)
+				continue
+			}
+		}
+		job.Status = models.ReportJobStatusExpired
+		job.StoragePath = ""
+		s.saveJob(job)
+	}
+
+	if deleted, err := s.repo.DeleteFinishedBefore(time.Now().Add(-reportJobHistory)); err != nil {
+		log.Printf("Failed to delete old report jobs: %v", err)
+	} else if deleted > 0 {
+		log.Printf("Deleted %d old report jobs", deleted)
+	}
+}
+
+// saveJob persists a job and notifies subscribers of its new status
+func (s *ReportJobService) saveJob(job *models.ReportJob) {
+	if err := s.repo.Update(job); err != nil {
+		log.Printf("Failed to update report job %d: %v", job.ID, err)
+		return
+	}
+
+	if err := realtime.Publish(realtime.ReportJobTopic(job.ID), reportJobEvent, s.toResponse(job)); err != nil {
+		log.Printf("Failed to publish update of report job %d: %v", job.ID, err)
+	}
+}
+
+// toResponse maps a job to its response, signing a download link for completed jobs.
+// The link never outlives the artifact.
+func (s *ReportJobService) toResponse(job *models.ReportJob) *models.ReportJobResponse {
+	response := &models.ReportJobResponse{ReportJob: *job}
+	json.Unmarshal([]byte(job.Params), &response.Params)
+
+	if job.Status == models.ReportJobStatusCompleted && job.ExpiresAt != nil {
+		expiresAt := time.Now().Add(s.linkTTL)
+		if job.ExpiresAt.Before(expiresAt) {
+			expiresAt = *job.ExpiresAt
+		}
+		expires := expiresAt.Unix()
+		response.DownloadURL = publicURL(fmt.Sprintf("/api/reports/jobs/%d/download?expires=%d&sig=%s",
+			job.ID, expires, signReportDownload(job.ID, expires)))
+		response.DownloadExpiresAt = &expiresAt
+	}
+
+	return response
+}
+
+// signReportDownload signs the job ID and expiry of a download link
+func signReportDownload(jobID uint, expires int64) string {
+	return signReportValue("REPORT_LINK_SECRET", "report-download|"+strconv.FormatUint(uint64(jobID), 10)+"|"+strconv.FormatInt(expires, 10))
+}
+
+// isPermanentReportError reports whether retrying a job cannot succeed
+func isPermanentReportError(err error) bool {
+	return errors.Is(err, ErrInvalidReportJob) ||
+		errors.Is(err, ErrScheduleForbidden) ||
+		errors.Is(err, ErrRecapForbidden) ||
+		errors.Is(err, ErrAttendanceActionNotAllowed) ||
+		errors.Is(err, ErrSessionAccessDenied) ||
+		errors.Is(err, gorm.ErrRecordNotFound)
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"strings"
+	"time"
+
+	"github.com/delpresence/backend/internal/models"
+)
+
+// indonesianDays maps the day names stored in CourseSchedule.Day to weekdays
+var indonesianDays = map[string]time.Weekday{
+	"minggu": time.Sunday,
+	"senin":  time.Monday,
+	"selasa": time.Tuesday,
+	"rabu":   time.Wednesday,
+	"kamis":  time.Thursday,
+	"jumat":  time.Friday,
+	"sabtu":  time.Saturday,
+}
+
+// scheduleWeekday converts a CourseSchedule day name (e.g. "Senin") to a weekday
+func scheduleWeekday(day string) (time.Weekday, bool) {
+	weekday, ok := indonesianDays[strings.ToLower(strings.TrimSpace(day))]
+	return weekday, ok
+}
+
+// indonesianDayName returns the CourseSchedule day name for a weekday
+func indonesianDayName(weekday time.Weekday) string {
+	names := [...]string{"Minggu", "Senin", "Selasa", "Rabu", "Kamis", "Jumat", "Sabtu"}
+	return names[weekday]
+}
+
+// plannedMeetingDates lists the dates between from and to (inclusive) on which a schedule meets
+func plannedMeetingDates(schedule models.CourseSchedule, from, to time.Time) []time.Time {
+	weekday, ok := scheduleWeekday(schedule.Day)
+	if !ok {
+		return nil
+	}
+
+	from = truncateToDate(from)
+	to = truncateToDate(to)
+
+	// Move to the first matching weekday
+	offset := (int(weekday) - int(from.Weekday()) + 7) % 7
+	var dates []time.Time
+	for d := from.AddDate(0, 0, offset); !d.After(to); d = d.AddDate(0, 0, 7) {
+		dates = append(dates, d)
+	}
+	return dates
+}
+
+// truncateToDate strips the time of day while keeping the location
+func truncateToDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,253 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/delpresence/backend/internal/models"
+	"github.com/delpresence/backend/internal/utils"
+	"github.com/tealeg/xlsx/v3"
+)
+
+// SessionReportFilename returns the download filename of a session attendance report
+func SessionReportFilename(session *models.AttendanceSessionResponse) string {
+	return fmt.Sprintf("Presensi_%s_%s_%s.xlsx",
+		session.CourseCode,
+		formatSessionReportFilename(session.CourseName),
+		formatSessionReportFilename(session.Date))
+}
+
+// attendanceReportHeader is what the summary sheet of an attendance report shows about what was
+// attended: a course session or a campus event
+type attendanceReportHeader struct {
+	SubjectLabel  string
+	Subject       string
+	Date          string
+	StartTime     string
+	EndTime       string
+	Room          string
+	TotalStudents int
+	AttendedCount int
+	LateCount     int
+	AbsentCount   int
+	ExcusedCount  int
+}
+
+// RenderSessionReportXLSX renders the attendance report of a single session as an xlsx workbook
+// with a summary sheet and the list of students
+func RenderSessionReportXLSX(session *models.AttendanceSessionResponse, attendances []models.StudentAttendanceResponse) ([]byte, error) {
+	return renderAttendanceReportXLSX(attendanceReportHeader{
+		SubjectLabel:  "Mata Kuliah",
+		Subject:       fmt.Sprintf("%s - %s", session.CourseCode, session.CourseName),
+		Date:          session.Date,
+		StartTime:     session.StartTime,
+		EndTime:       session.EndTime,
+		Room:          session.Room,
+		TotalStudents: session.TotalStudents,
+		AttendedCount: session.AttendedCount,
+		LateCount:     session.LateCount,
+		AbsentCount:   session.AbsentCount,
+		ExcusedCount:  session.ExcusedCount,
+	}, attendances)
+}
+
+// renderAttendanceReportXLSX renders an attendance report with a summary sheet and the list of students
+func renderAttendanceReportXLSX(session attendanceReportHeader, attendances []models.StudentAttendanceResponse) ([]byte, error) {
+	// Create Excel file
+	file := xlsx.NewFile()
+
+	// Create main summary sheet
+	summarySheet, err := file.AddSheet("Summary")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Excel sheet: %w", err)
+	}
+
+	// Add title and styling
+	titleRow := summarySheet.AddRow()
+	titleCell := titleRow.AddCell()
+	titleCell.Value = fmt.Sprintf("LAPORAN PRESENSI MAHASISWA")
+	titleCell.SetStyle(utils.NewXLSXTitleStyle())
+
+	// Add course information
+	summarySheet.AddRow() // Empty row for spacing
+
+	courseRow := summarySheet.AddRow()
+	courseNameLabel := courseRow.AddCell()
+	courseNameLabel.Value = session.SubjectLabel
+	courseNameValue := courseRow.AddCell()
+	courseNameValue.Value = session.Subject
+
+	dateRow := summarySheet.AddRow()
+	dateLabel := dateRow.AddCell()
+	dateLabel.Value = "Tanggal"
+	dateValue := dateRow.AddCell()
+	dateValue.Value = session.Date
+
+	timeRow := summarySheet.AddRow()
+	timeLabel := timeRow.AddCell()
+	timeLabel.Value = "Waktu"
+	timeValue := timeRow.AddCell()
+	timeValue.Value = fmt.Sprintf("%s - %s", session.StartTime, session.EndTime)
+
+	roomRow := summarySheet.AddRow()
+	roomLabel := roomRow.AddCell()
+	roomLabel.Value = "Ruangan"
+	roomValue := roomRow.AddCell()
+	roomValue.Value = session.Room
+
+	// Add statistics
+	summarySheet.AddRow() // Empty row for spacing
+
+	statsRow := summarySheet.AddRow()
+	statsLabel := statsRow.AddCell()
+	statsLabel.Value = "Statistik Kehadiran"
+	statsLabel.SetStyle(utils.NewXLSXBoldStyle())
+
+	totalRow := summarySheet.AddRow()
+	totalLabel := totalRow.AddCell()
+	totalLabel.Value = "Total Mahasiswa"
+	totalValue := totalRow.AddCell()
+	totalValue.Value = fmt.Sprintf("%d", session.TotalStudents)
+
+	presentRow := summarySheet.AddRow()
+	presentLabel := presentRow.AddCell()
+	presentLabel.Value = "Hadir"
+	presentValue := presentRow.AddCell()
+	presentValue.Value = fmt.Sprintf("%d", session.AttendedCount)
+
+	lateRow := summarySheet.AddRow()
+	lateLabel := lateRow.AddCell()
+	lateLabel.Value = "Terlambat"
+	lateValue := lateRow.AddCell()
+	lateValue.Value = fmt.Sprintf("%d", session.LateCount)
+
+	absentRow := summarySheet.AddRow()
+	absentLabel := absentRow.AddCell()
+	absentLabel.Value = "Tidak Hadir"
+	absentValue := absentRow.AddCell()
+	absentValue.Value = fmt.Sprintf("%d", session.AbsentCount)
+
+	excusedRow := summarySheet.AddRow()
+	excusedLabel := excusedRow.AddCell()
+	excusedLabel.Value = "Izin"
+	excusedValue := excusedRow.AddCell()
+	excusedValue.Value = fmt.Sprintf("%d", session.ExcusedCount)
+
+	percentRow := summarySheet.AddRow()
+	percentLabel := percentRow.AddCell()
+	percentLabel.Value = "Persentase Kehadiran"
+	percentValue := percentRow.AddCell()
+
+	// Calculate attendance percentage
+	totalStudents := session.TotalStudents
+	if totalStudents > 0 {
+		attendancePercent := float64(session.AttendedCount+session.LateCount) / float64(totalStudents) * 100
+		percentValue.Value = fmt.Sprintf("%.2f%%", attendancePercent)
+	} else {
+		percentValue.Value = "N/A"
+	}
+
+	// Create detailed attendance sheet
+	detailSheet, err := file.AddSheet("Daftar Hadir")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create detail sheet: %w", err)
+	}
+
+	// Add table header with styling
+	headerRow := detailSheet.AddRow()
+
+	headerStyle := utils.NewXLSXHeaderStyle()
+
+	headerCells := []string{"No", "NIM", "Nama Mahasiswa", "Status", "Waktu Presensi", "Metode Verifikasi", "Keterangan"}
+	for _, header := range headerCells {
+		cell := headerRow.AddCell()
+		cell.Value = header
+		cell.SetStyle(headerStyle)
+	}
+
+	// Add data rows
+	for i, attendance := range attendances {
+		row := detailSheet.AddRow()
+
+		// Style for data cells, with the status cell colored by status
+		dataStyle := utils.NewXLSXCellStyle()
+		statusStyle := utils.NewXLSXStatusStyle(attendance.Status)
+
+		// Add data cells
+		numCell := row.AddCell()
+		numCell.SetInt(i + 1)
+		numCell.SetStyle(dataStyle)
+
+		nimCell := row.AddCell()
+		nimCell.Value = attendance.StudentNIM
+		nimCell.SetStyle(dataStyle)
+
+		nameCell := row.AddCell()
+		nameCell.Value = attendance.StudentName
+		nameCell.SetStyle(dataStyle)
+
+		statusCell := row.AddCell()
+
+		// Translate status to Indonesian
+		statusCell.Value = utils.AttendanceStatusLabel(attendance.Status)
+		statusCell.SetStyle(statusStyle)
+
+		timeCell := row.AddCell()
+		timeCell.Value = attendance.CheckInTime
+		timeCell.SetStyle(dataStyle)
+
+		methodCell := row.AddCell()
+
+		// Translate verification method to Indonesian
+		methodText := ""
+		switch attendance.VerificationMethod {
+		case "QR_CODE":
+			methodText = "Kode QR"
+		case "FACE_RECOGNITION":
+			methodText = "Pengenalan Wajah"
+		case "MANUAL":
+			methodText = "Manual"
+		default:
+			methodText = attendance.VerificationMethod
+		}
+
+		methodCell.Value = methodText
+		methodCell.SetStyle(dataStyle)
+
+		noteCell := row.AddCell()
+		noteCell.Value = attendance.Notes
+		noteCell.SetStyle(dataStyle)
+	}
+
+	// Auto-size columns for better readability
+	detailSheet.SetColWidth(1, 1, 5)  // No
+	detailSheet.SetColWidth(2, 2, 15) // NIM
+	detailSheet.SetColWidth(3, 3, 30) // Name
+	detailSheet.SetColWidth(4, 4, 15) // Status
+	detailSheet.SetColWidth(5, 5, 20) // Check-in time
+	detailSheet.SetColWidth(6, 6, 20) // Verification method
+	detailSheet.SetColWidth(7, 7, 30) // Notes
+
+	var buf bytes.Buffer
+	if err := file.Write(&buf); err != nil {
+		return nil, fmt.Errorf("failed to generate Excel file: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// formatSessionReportFilename makes a string safe for use in a report filename
+func formatSessionReportFilename(s string) string {
+	// Replace spaces with underscores
+	s = strings.ReplaceAll(s, " ", "_")
+
+	// Replace slashes with hyphens
+	s = strings.ReplaceAll(s, "/", "-")
+	s = strings.ReplaceAll(s, "\\", "-")
+
+	// Remove other special characters
+	s = filenameUnsafe.ReplaceAllString(s, "")
+
+	// Convert to lowercase for consistency
+	return strings.ToLower(s)
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/delpresence/backend/internal/models"
+	"github.com/delpresence/backend/internal/repositories"
+	"github.com/delpresence/backend/internal/utils"
+)
+
+var (
+	// ErrDeviceRequired is returned when a check-in does not say which device sent it
+	ErrDeviceRequired = errors.New("a registered device is required to check in, send its ID in the X-Device-ID header")
+
+	// ErrDeviceNotRegistered is returned when a check-in comes from a device other than the student's active one
+	ErrDeviceNotRegistered = errors.New("this device is not registered for your account")
+
+	// ErrInvalidDevice is returned when a device ID is malformed
+	ErrInvalidDevice = errors.New("invalid device")
+
+	// ErrDeviceTaken is returned when a device is already the active device of another student
+	ErrDeviceTaken = errors.New("this device is registered to another student")
+
+	// ErrDeviceChangePending is returned when a student already waits for approval of another device
+	ErrDeviceChangePending = errors.New("a device change is already waiting for approval")
+)
+
+// StudentDeviceService binds students' check-ins to one registered phone each
+type StudentDeviceService struct {
+	repo             *repositories.StudentDeviceRepository
+	studentRepo      *repositories.StudentRepository
+	academicYearRepo *repositories.AcademicYearRepository
+}
+
+// NewStudentDeviceService creates a new student device service
+func NewStudentDeviceService() *StudentDeviceService {
+	return &StudentDeviceService{
+		repo:             repositories.NewStudentDeviceRepository(),
+		studentRepo:      repositories.NewStudentRepository(),
+		academicYearRepo: repositories.NewAcademicYearRepository(),
+	}
+}
+
+// VerifyDevice checks that a check-in comes from the student's active device. It accepts any
+// device when DEVICE_BINDING_REQUIRED is false.
+func (s *StudentDeviceService) VerifyDevice(userID uint, deviceID string) error {
+	if !utils.GetEnvAsBool("DEVICE_BINDING_REQUIRED", true) {
+		return nil
+	}
+
+	deviceID = strings.TrimSpace(deviceID)
+	if deviceID == "" {
+		return ErrDeviceRequired
+	}
+	active, err := s.repo.FindByStatus(int(userID), models.StudentDeviceActive)
+	if err != nil {
+		return err
+	}
+	if active == nil || active.DeviceID != deviceID {
+		return ErrDeviceNotRegistered
+	}
+	return nil
+}
+
+// GetOverview returns a student's active device, pending change and changes left this semester
+func (s *StudentDeviceService) GetOverview(userID uint) (*models.StudentDeviceOverview, error) {
+	active, err := s.repo.FindByStatus(int(userID), models.StudentDeviceActive)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := s.repo.FindByStatus(int(userID), models.StudentDevicePending)
+	if err != nil {
+		return nil, err
+	}
+	academicYearID, used, err := s.changesThisSemester(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.StudentDeviceOverview{
+		Active:         active,
+		Pending:        pending,
+		AcademicYearID: academicYearID,
+		ChangesUsed:    used,
+		ChangesAllowed: deviceChangesAllowed(),
+	}, nil
+}
+
+// RegisterDevice registers the device a student checks in with. The first device is activated at
+// once, and so are changes while the student has changes left this semester
+// (DEVICE_CHANGES_PER_SEMESTER, default 1). Beyond that the change waits for an admin.
+func (s *StudentDeviceService) RegisterDevice(userID uint, deviceID, deviceName, reason string) (*models.StudentDevice, error) {
+	deviceID = strings.TrimSpace(deviceID)
+	if len(deviceID) < 8 || len(deviceID) > 128 {
+		return nil, fmt.Errorf("%w: device_id must be 8 to 128 characters", ErrInvalidDevice)
+	}
+
+	student, err := s.studentRepo.FindByUserID(int(userID))
+	if err != nil {
+		return nil, err
+	}
+	if student == nil {
+		return nil, errors.New("student record not found")
+	}
+
+	owner, err := s.repo.FindActiveByDeviceID(deviceID)
+	if err != nil {
+		return nil, err
+	}
+	if owner != nil && owner.StudentID != student.ID {
+		return nil, ErrDeviceTaken
+	}
+
+	active, err := s.repo.FindByStatus(student.UserID, models.StudentDeviceActive)
+	if err != nil {
+		return nil, err
+	}
+	if active != nil && active.DeviceID == deviceID {
+		return active, nil
+	}
+
+	pending, err := s.repo.FindByStatus(student.UserID, models.StudentDevicePending)
+	if err != nil {
+		return nil, err
+	}
+	if pending != nil {
+		if pending.DeviceID == deviceID {
+			return pending, nil
+		}
+		return nil, ErrDeviceChangePending
+	}
+
+	academicYearID, used, err := s.changesThisSemester(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	device := &models.StudentDevice{
+		UserID:         student.UserID,
+		StudentID:      student.ID,
+		DeviceID:       deviceID,
+		DeviceName:     strings.TrimSpace(deviceName),
+		AcademicYearID: academicYearID,
+		IsChange:       active != nil,
+		Reason:         strings.TrimSpace(reason),
+	}
+
+	if active != nil && used >= deviceChangesAllowed() {
+		device.Status = models.StudentDevicePending
+		if err := s.repo.Create(device); err != nil {
+			return nil, err
+		}
+		return device, nil
+	}
+
+	if err := s.repo.Activate(device, active, GetIndonesiaTime()); err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+// ListRequests lists device registrations for admins, optionally filtered by status and student
+func (s *StudentDeviceService) ListRequests(status string, studentID uint) ([]models.StudentDevice, error) {
+	return s.repo.List(strings.ToUpper(status), studentID)
+}
+
+// ReviewRequest approves or rejects a pending device change. Approving replaces the student's
+// active device regardless of the changes left this semester.
+func (s *StudentDeviceService) ReviewRequest(id, reviewerID uint, approve bool, notes string) (*models.StudentDevice, error) {
+	device, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if device.Status != models.StudentDevicePending {
+		return nil, fmt.Errorf("%w: only pending device changes can be reviewed", ErrInvalidDevice)
+	}
+
+	now := GetIndonesiaTime()
+	device.ReviewedByID = &reviewerID
+	device.ReviewedAt = &now
+	device.ReviewNotes = strings.TrimSpace(notes)
+
+	if !approve {
+		device.Status = models.StudentDeviceRejected
+		if err := s.repo.Save(device); err != nil {
+			return nil, err
+		}
+		return device, nil
+	}
+
+	owner, err := s.repo.FindActiveByDeviceID(device.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+	if owner != nil && owner.StudentID != device.StudentID {
+		return nil, ErrDeviceTaken
+	}
+	active, err := s.repo.FindByStatus(device.UserID, models.StudentDeviceActive)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Activate(device, active, now); err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+// changesThisSemester returns the current academic year and how many device changes the student
+// made since it started. Without a current academic year the last six months count.
+func (s *StudentDeviceService) changesThisSemester(userID uint) (*uint, int, error) {
+	since := GetIndonesiaTime().AddDate(0, -6, 0)
+	var academicYearID *uint
+	academicYear, err := s.academicYearRepo.GetActiveAcademicYear()
+	if err != nil {
+		return nil, 0, err
+	}
+	if academicYear != nil {
+		since = academicYear.StartDate
+		academicYearID = &academicYear.ID
+	}
+
+	used, err := s.repo.CountChangesSince(int(userID), since)
+	if err != nil {
+		return nil, 0, err
+	}
+	return academicYearID, int(used), nil
+}
+
+// deviceChangesAllowed returns how many device changes a student may make per semester without
+// an admin's approval
+func deviceChangesAllowed() int {
+	return utils.GetEnvAsInt("DEVICE_CHANGES_PER_SEMESTER", 1)
+}
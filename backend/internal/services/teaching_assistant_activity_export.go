@@ -0,0 +1,89 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/delpresence/backend/internal/models"
+	"github.com/delpresence/backend/internal/utils"
+	"github.com/tealeg/xlsx/v3"
+)
+
+// TeachingAssistantActivityFilename returns the download file name of a teaching assistant activity report
+func TeachingAssistantActivityFilename(report *models.TeachingAssistantActivityReport) string {
+	return reportFilename("xlsx", "Aktivitas_Asisten_Dosen", report.AcademicYearName, report.Semester)
+}
+
+// RenderTeachingAssistantActivityXLSX renders a teaching assistant activity report as an Excel
+// workbook with one row per assistant and course
+func RenderTeachingAssistantActivityXLSX(report *models.TeachingAssistantActivityReport) ([]byte, error) {
+	file := xlsx.NewFile()
+	sheet, err := file.AddSheet("Aktivitas Asisten")
+	if err != nil {
+		return nil, err
+	}
+
+	titleCell := sheet.AddRow().AddCell()
+	titleCell.Value = "REKAP AKTIVITAS ASISTEN DOSEN"
+	titleCell.SetStyle(utils.NewXLSXTitleStyle())
+	sheet.AddRow()
+	row := sheet.AddRow()
+	row.AddCell().Value = "Tahun Akademik"
+	row.AddCell().Value = fmt.Sprintf("%s %s", report.AcademicYearName, report.Semester)
+	sheet.AddRow()
+
+	headerStyle := utils.NewXLSXHeaderStyle()
+	header := sheet.AddRow()
+	for _, title := range []string{
+		"No", "Nama Asisten", "Kode MK", "Mata Kuliah", "Sesi Dibuka", "Sesi Ditutup", "Presensi Manual",
+		"Jam Mengajar", "Total Sesi MK", "Sesi oleh Dosen", "Porsi Asisten",
+	} {
+		addStyledString(header, title, headerStyle)
+	}
+
+	dataStyle := utils.NewXLSXCellStyle()
+	totalStyle := utils.NewXLSXHeaderStyle()
+	number := 0
+	for _, assistant := range report.Assistants {
+		for _, course := range assistant.Courses {
+			number++
+			row := sheet.AddRow()
+			addStyledInt(row, number, dataStyle)
+			addStyledString(row, assistant.AssistantName, dataStyle)
+			addStyledString(row, course.CourseCode, dataStyle)
+			addStyledString(row, course.CourseName, dataStyle)
+			addStyledInt(row, course.SessionsCreated, dataStyle)
+			addStyledInt(row, course.SessionsClosed, dataStyle)
+			addStyledInt(row, course.ManualMarks, dataStyle)
+			addStyledString(row, fmt.Sprintf("%.1f", course.HoursCovered), dataStyle)
+			addStyledInt(row, course.CourseSessions, dataStyle)
+			addStyledInt(row, course.LecturerRunSessions, dataStyle)
+			addStyledString(row, formatPercent(course.AssistantShare), dataStyle)
+		}
+
+		row := sheet.AddRow()
+		addStyledString(row, "", totalStyle)
+		addStyledString(row, "Total "+assistant.AssistantName, totalStyle)
+		addStyledString(row, "", totalStyle)
+		addStyledString(row, "", totalStyle)
+		addStyledInt(row, assistant.SessionsCreated, totalStyle)
+		addStyledInt(row, assistant.SessionsClosed, totalStyle)
+		addStyledInt(row, assistant.ManualMarks, totalStyle)
+		addStyledString(row, fmt.Sprintf("%.1f", assistant.HoursCovered), totalStyle)
+		for i := 0; i < 3; i++ {
+			addStyledString(row, "", totalStyle)
+		}
+	}
+
+	sheet.SetColWidth(1, 1, 5)
+	sheet.SetColWidth(2, 2, 30)
+	sheet.SetColWidth(3, 3, 12)
+	sheet.SetColWidth(4, 4, 30)
+	sheet.SetColWidth(5, 11, 14)
+
+	buf := &bytes.Buffer{}
+	if err := file.Write(buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/delpresence/backend/internal/models"
+	"github.com/delpresence/backend/internal/repositories"
+)
+
+// ErrAssistantActivityForbidden is returned when a role may not see teaching assistant activity
+var ErrAssistantActivityForbidden = errors.New("you are not allowed to view teaching assistant activity")
+
+// TeachingAssistantActivityFilter narrows a teaching assistant activity report
+type TeachingAssistantActivityFilter struct {
+	AssistantUserID uint
+	CourseID        uint
+}
+
+// TeachingAssistantActivityService reports what teaching assistants did in their assigned courses
+type TeachingAssistantActivityService struct {
+	statsRepo        *repositories.AttendanceStatisticsRepository
+	assignmentRepo   *repositories.TeachingAssistantAssignmentRepository
+	academicYearRepo *repositories.AcademicYearRepository
+}
+
+// NewTeachingAssistantActivityService creates a new teaching assistant activity service
+func NewTeachingAssistantActivityService() *TeachingAssistantActivityService {
+	return &TeachingAssistantActivityService{
+		statsRepo:        repositories.NewAttendanceStatisticsRepository(),
+		assignmentRepo:   repositories.NewTeachingAssistantAssignmentRepository(),
+		academicYearRepo: repositories.NewAcademicYearRepository(),
+	}
+}
+
+// ResolveAcademicYear returns the given academic year, or the active one when the ID is zero
+func (s *TeachingAssistantActivityService) ResolveAcademicYear(academicYearID uint) (*models.AcademicYear, error) {
+	return resolveAcademicYear(s.academicYearRepo, academicYearID)
+}
+
+// GetReport builds the activity of teaching assistants in an academic year. Admins see every
+// assignment; lecturers see the assignments they made. A session counts as the assistant's when
+// they opened it in the ASSISTANT role, and its hours are the scheduled hours of its schedule.
+func (s *TeachingAssistantActivityService) GetReport(academicYear *models.AcademicYear, role string, userID uint, filter TeachingAssistantActivityFilter) (*models.TeachingAssistantActivityReport, error) {
+	var assignments []models.TeachingAssistantAssignment
+	var err error
+	switch strings.ToLower(role) {
+	case "admin":
+		assignments, err = s.assignmentRepo.GetAll(academicYear.ID)
+	case "dosen":
+		assignments, err = s.assignmentRepo.GetByLecturerID(userID, academicYear.ID)
+	default:
+		return nil, ErrAssistantActivityForbidden
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	courseSet := make(map[uint]bool)
+	assistantSet := make(map[uint]bool)
+	var selected []models.TeachingAssistantAssignment
+	for _, assignment := range assignments {
+		if filter.AssistantUserID > 0 && uint(assignment.UserID) != filter.AssistantUserID {
+			continue
+		}
+		if filter.CourseID > 0 && assignment.CourseID != filter.CourseID {
+			continue
+		}
+		selected = append(selected, assignment)
+		courseSet[assignment.CourseID] = true
+		assistantSet[uint(assignment.UserID)] = true
+	}
+	courseIDs := make([]uint, 0, len(courseSet))
+	for id := range courseSet {
+		courseIDs = append(courseIDs, id)
+	}
+	assistantIDs := make([]uint, 0, len(assistantSet))
+	for id := range assistantSet {
+		assistantIDs = append(assistantIDs, id)
+	}
+
+	sessions, err := s.statsRepo.GetCourseSessions(academicYear.ID, courseIDs)
+	if err != nil {
+		return nil, err
+	}
+	marks, err := s.statsRepo.GetManualMarkCounts(academicYear.ID, courseIDs, assistantIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	type courseUser struct{ courseID, userID uint }
+	manualMarks := make(map[courseUser]int, len(marks))
+	for _, mark := range marks {
+		manualMarks[courseUser{mark.CourseID, mark.VerifiedByID}] = mark.Count
+	}
+	sessionsByCourse := make(map[uint][]repositories.CourseSession)
+	for _, session := range sessions {
+		sessionsByCourse[session.CourseID] = append(sessionsByCourse[session.CourseID], session)
+	}
+
+	report := &models.TeachingAssistantActivityReport{
+		AcademicYearID:   academicYear.ID,
+		AcademicYearName: academicYear.Name,
+		Semester:         academicYear.Semester,
+		Assistants:       []models.TeachingAssistantActivity{},
+	}
+
+	byAssistant := make(map[uint]*models.TeachingAssistantActivity)
+	for _, assignment := range selected {
+		assistantID := uint(assignment.UserID)
+		activity, ok := byAssistant[assistantID]
+		if !ok {
+			activity = &models.TeachingAssistantActivity{AssistantUserID: assistantID}
+			if assignment.Employee != nil {
+				activity.AssistantName = assignment.Employee.FullName
+			}
+			byAssistant[assistantID] = activity
+		}
+
+		course := models.TeachingAssistantCourseActivity{
+			AssignmentID: assignment.ID,
+			CourseID:     assignment.CourseID,
+			CourseCode:   assignment.Course.Code,
+			CourseName:   assignment.Course.Name,
+			AssignedByID: assignment.AssignedByID,
+			ManualMarks:  manualMarks[courseUser{assignment.CourseID, assistantID}],
+		}
+		for _, session := range sessionsByCourse[assignment.CourseID] {
+			course.CourseSessions++
+			switch {
+			case session.CreatorRole == "LECTURER":
+				course.LecturerRunSessions++
+			case session.CreatorRole == "ASSISTANT" && session.CreatorID == assistantID:
+				course.SessionsCreated++
+				course.HoursCovered += scheduledHours(session.ScheduleStartTime, session.ScheduleEndTime)
+			}
+			if session.ClosedByID != nil && *session.ClosedByID == assistantID {
+				course.SessionsClosed++
+			}
+		}
+		if course.CourseSessions > 0 {
+			course.AssistantShare = float64(course.SessionsCreated) * 100 / float64(course.CourseSessions)
+		}
+		if course.LecturerRunSessions > 0 {
+			course.AssistantToLecturer = float64(course.SessionsCreated) / float64(course.LecturerRunSessions)
+		}
+
+		activity.SessionsCreated += course.SessionsCreated
+		activity.SessionsClosed += course.SessionsClosed
+		activity.ManualMarks += course.ManualMarks
+		activity.HoursCovered += course.HoursCovered
+		activity.Courses = append(activity.Courses, course)
+	}
+
+	for _, activity := range byAssistant {
+		sort.Slice(activity.Courses, func(i, j int) bool {
+			return activity.Courses[i].CourseCode < activity.Courses[j].CourseCode
+		})
+		report.Assistants = append(report.Assistants, *activity)
+	}
+	sort.Slice(report.Assistants, func(i, j int) bool {
+		return report.Assistants[i].AssistantName < report.Assistants[j].AssistantName
+	})
+
+	return report, nil
+}
+
+// scheduledHours returns the length of a schedule's HH:MM time slot in hours, or 0 when a time
+// cannot be parsed
+func scheduledHours(start, end string) float64 {
+	var times [2]time.Time
+	for i, value := range []string{start, end} {
+		parsed := false
+		for _, layout := range []string{"15:04", "15:04:05"} {
+			if t, err := time.Parse(layout, value); err == nil {
+				times[i] = t
+				parsed = true
+				break
+			}
+		}
+		if !parsed {
+			return 0
+		}
+	}
+	if hours := times[1].Sub(times[0]).Hours(); hours > 0 {
+		return hours
+	}
+	return 0
+}
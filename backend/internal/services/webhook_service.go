@@ -0,0 +1,479 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/delpresence/backend/internal/models"
+	"github.com/delpresence/backend/internal/repositories"
+	"github.com/delpresence/backend/internal/utils"
+)
+
+const (
+	// webhookBatchSize is the number of deliveries sent per worker tick
+	webhookBatchSize = 10
+
+	// webhookLeaseMargin is added to the time a batch can take when hiding it from other workers
+	webhookLeaseMargin = time.Minute
+
+	// webhookBaseBackoff is the delay before the first retry, doubled on every attempt
+	webhookBaseBackoff = 30 * time.Second
+
+	// webhookMaxBackoff caps the delay between retries
+	webhookMaxBackoff = 6 * time.Hour
+)
+
+// WebhookService manages webhook endpoints and delivers queued events to them
+type WebhookService struct {
+	repo        *repositories.WebhookRepository
+	client      *http.Client
+	maxAttempts int
+	claimLease  time.Duration
+}
+
+// NewWebhookService creates a new webhook service
+func NewWebhookService() *WebhookService {
+	timeout := time.Duration(utils.GetEnvAsInt("WEBHOOK_TIMEOUT_SECONDS", 10)) * time.Second
+
+	return &WebhookService{
+		repo: repositories.NewWebhookRepository(),
+		client: &http.Client{
+			Timeout: timeout,
+		},
+		maxAttempts: utils.GetEnvAsInt("WEBHOOK_MAX_ATTEMPTS", 8),
+		// A claimed batch stays hidden until every delivery in it could have timed out
+		claimLease: webhookBatchSize*timeout + webhookLeaseMargin,
+	}
+}
+
+// CreateEndpoint registers a new webhook endpoint, generating a signing secret if none is given
+func (s *WebhookService) CreateEndpoint(name, rawURL, secret string, eventTypes []string, createdByID uint) (*models.WebhookEndpointResponse, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, errors.New("name is required")
+	}
+	if err := validateWebhookURL(rawURL); err != nil {
+		return nil, err
+	}
+
+	types, err := normalizeWebhookEventTypes(eventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	if secret == "" {
+		secret, err = generateWebhookSecret()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	endpoint := &models.WebhookEndpoint{
+		Name:        name,
+		URL:         rawURL,
+		Secret:      secret,
+		EventTypes:  strings.Join(types, ","),
+		Active:      true,
+		CreatedByID: createdByID,
+	}
+
+	if err := s.repo.CreateEndpoint(endpoint); err != nil {
+		return nil, err
+	}
+
+	response := mapWebhookEndpointToResponse(endpoint)
+	response.Secret = endpoint.Secret
+	return &response, nil
+}
+
+// UpdateEndpoint updates an endpoint's name, URL, event filter and active flag
+func (s *WebhookService) UpdateEndpoint(id uint, name, rawURL string, eventTypes []string, active bool) (*models.WebhookEndpointResponse, error) {
+	endpoint, err := s.repo.GetEndpointByID(id)
+	if err != nil {
+		return nil, errors.New("webhook endpoint not found")
+	}
+
+	if strings.TrimSpace(name) == "" {
+		return nil, errors.New("name is required")
+	}
+	if err := validateWebhookURL(rawURL); err != nil {
+		return nil, err
+	}
+
+	types, err := normalizeWebhookEventTypes(eventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint.Name = name
+	endpoint.URL = rawURL
+	endpoint.EventTypes = strings.Join(types, ",")
+	endpoint.Active = active
+
+	if err := s.repo.UpdateEndpoint(endpoint); err != nil {
+		return nil, err
+	}
+
+	response := mapWebhookEndpointToResponse(endpoint)
+	return &response, nil
+}
+
+// DeleteEndpoint removes a webhook endpoint
+func (s *WebhookService) DeleteEndpoint(id uint) error {
+	if _, err := s.repo.GetEndpointByID(id); err != nil {
+		return errors.New("webhook endpoint not found")
+	}
+	return s.repo.DeleteEndpoint(id)
+}
+
+// GetEndpoint gets a webhook endpoint by ID
+func (s *WebhookService) GetEndpoint(id uint) (*models.WebhookEndpointResponse, error) {
+	endpoint, err := s.repo.GetEndpointByID(id)
+	if err != nil {
+		return nil, errors.New("webhook endpoint not found")
+	}
+
+	response := mapWebhookEndpointToResponse(endpoint)
+	return &response, nil
+}
+
+// ListEndpoints lists all webhook endpoints
+func (s *WebhookService) ListEndpoints() ([]models.WebhookEndpointResponse, error) {
+	endpoints, err := s.repo.ListEndpoints()
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.WebhookEndpointResponse, 0, len(endpoints))
+	for i := range endpoints {
+		responses = append(responses, mapWebhookEndpointToResponse(&endpoints[i]))
+	}
+	return responses, nil
+}
+
+// ListDeliveries lists the most recent deliveries for an endpoint
+func (s *WebhookService) ListDeliveries(endpointID uint, limit int) ([]models.WebhookDelivery, error) {
+	if _, err := s.repo.GetEndpointByID(endpointID); err != nil {
+		return nil, errors.New("webhook endpoint not found")
+	}
+	return s.repo.ListDeliveriesByEndpoint(endpointID, limit)
+}
+
+// ListDeadLetters lists deliveries that exhausted their retries
+func (s *WebhookService) ListDeadLetters(limit int) ([]models.WebhookDelivery, error) {
+	return s.repo.ListDeliveriesByStatus(models.WebhookDeliveryStatusDead, limit)
+}
+
+// Redeliver puts a dead delivery back on the queue with a fresh retry budget. A delivered one
+// keeps its record and is queued again as a new delivery of the same event.
+func (s *WebhookService) Redeliver(deliveryID uint) (*models.WebhookDelivery, error) {
+	delivery, err := s.repo.GetDeliveryByID(deliveryID)
+	if err != nil {
+		return nil, errors.New("webhook delivery not found")
+	}
+
+	switch delivery.Status {
+	case models.WebhookDeliveryStatusPending:
+		return nil, errors.New("webhook delivery is already queued")
+	case models.WebhookDeliveryStatusDelivered:
+		copied := []models.WebhookDelivery{{
+			EndpointID:    delivery.EndpointID,
+			EventID:       delivery.EventID,
+			EventType:     delivery.EventType,
+			Payload:       delivery.Payload,
+			Status:        models.WebhookDeliveryStatusPending,
+			NextAttemptAt: time.Now(),
+		}}
+		if err := s.repo.CreateDeliveries(copied); err != nil {
+			return nil, err
+		}
+		return &copied[0], nil
+	}
+
+	delivery.Status = models.WebhookDeliveryStatusPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	delivery.LastError = ""
+
+	if err := s.repo.UpdateDelivery(delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// Enqueue queues an event for every active endpoint subscribed to its type
+func (s *WebhookService) Enqueue(eventType string, data interface{}) error {
+	endpoints, err := s.repo.ListActiveEndpoints()
+	if err != nil {
+		return err
+	}
+
+	var subscribed []models.WebhookEndpoint
+	for _, endpoint := range endpoints {
+		if webhookEndpointAccepts(&endpoint, eventType) {
+			subscribed = append(subscribed, endpoint)
+		}
+	}
+
+	if len(subscribed) == 0 {
+		return nil
+	}
+
+	eventID, err := generateWebhookEventID()
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(models.WebhookPayload{
+		ID:        eventID,
+		Type:      eventType,
+		CreatedAt: GetIndonesiaTime(),
+		Data:      data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	now := time.Now()
+	deliveries := make([]models.WebhookDelivery, 0, len(subscribed))
+	for _, endpoint := range subscribed {
+		deliveries = append(deliveries, models.WebhookDelivery{
+			EndpointID:    endpoint.ID,
+			EventID:       eventID,
+			EventType:     eventType,
+			Payload:       string(payload),
+			Status:        models.WebhookDeliveryStatusPending,
+			NextAttemptAt: now,
+		})
+	}
+
+	return s.repo.CreateDeliveries(deliveries)
+}
+
+// StartDeliveryWorker starts a background worker that sends due deliveries on an interval.
+// Deliveries are claimed with SKIP LOCKED, so a worker can run on every replica.
+func (s *WebhookService) StartDeliveryWorker() {
+	interval := time.Duration(utils.GetEnvAsInt("WEBHOOK_WORKER_INTERVAL_SECONDS", 5)) * time.Second
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			s.processDueDeliveries()
+		}
+	}()
+
+	log.Printf("Webhook delivery worker started (interval %s, max attempts %d)", interval, s.maxAttempts)
+}
+
+// processDueDeliveries sends one batch of due deliveries
+func (s *WebhookService) processDueDeliveries() {
+	deliveries, err := s.repo.ClaimDueDeliveries(webhookBatchSize, s.claimLease)
+	if err != nil {
+		log.Printf("Failed to claim webhook deliveries: %v", err)
+		return
+	}
+
+	for i := range deliveries {
+		s.attemptDelivery(&deliveries[i])
+	}
+}
+
+// attemptDelivery posts a delivery to its endpoint and records the outcome
+func (s *WebhookService) attemptDelivery(delivery *models.WebhookDelivery) {
+	endpoint := delivery.Endpoint
+	delivery.Attempts++
+
+	if endpoint.ID == 0 || endpoint.DeletedAt.Valid || !endpoint.Active {
+		delivery.Status = models.WebhookDeliveryStatusDead
+		delivery.LastError = "endpoint was deleted or disabled"
+		s.saveDelivery(delivery)
+		return
+	}
+
+	statusCode, err := s.send(&endpoint, delivery)
+	delivery.LastStatusCode = statusCode
+
+	if err == nil {
+		now := time.Now()
+		delivery.Status = models.WebhookDeliveryStatusDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+		s.saveDelivery(delivery)
+		return
+	}
+
+	delivery.LastError = err.Error()
+
+	if delivery.Attempts >= s.maxAttempts {
+		delivery.Status = models.WebhookDeliveryStatusDead
+		log.Printf("Webhook delivery %d to endpoint %d moved to dead letters after %d attempts: %v",
+			delivery.ID, endpoint.ID, delivery.Attempts, err)
+	} else {
+		delivery.NextAttemptAt = time.Now().Add(webhookBackoff(delivery.Attempts))
+	}
+
+	s.saveDelivery(delivery)
+}
+
+// send posts the signed payload and treats any 2xx response as success
+func (s *WebhookService) send(endpoint *models.WebhookEndpoint, delivery *models.WebhookDelivery) (int, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequest(http.MethodPost, endpoint.URL, bytes.NewBufferString(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "DelPresence-Webhooks/1.0")
+	req.Header.Set("X-DelPresence-Event", delivery.EventType)
+	req.Header.Set("X-DelPresence-Event-ID", delivery.EventID)
+	req.Header.Set("X-DelPresence-Delivery", strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set("X-DelPresence-Timestamp", timestamp)
+	req.Header.Set("X-DelPresence-Signature", "sha256="+SignWebhookPayload(endpoint.Secret, timestamp, delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// Drain a bounded amount of the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// saveDelivery persists the outcome of a delivery attempt
+func (s *WebhookService) saveDelivery(delivery *models.WebhookDelivery) {
+	if err := s.repo.UpdateDelivery(delivery); err != nil {
+		log.Printf("Failed to update webhook delivery %d: %v", delivery.ID, err)
+	}
+}
+
+// SignWebhookPayload computes the hex HMAC-SHA256 of "<timestamp>.<payload>" with the endpoint secret.
+// Receivers recompute it to verify the X-DelPresence-Signature header.
+func SignWebhookPayload(secret, timestamp, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// dispatchWebhookEvent queues a webhook event without failing the calling operation
+func dispatchWebhookEvent(eventType string, data interface{}) {
+	if err := NewWebhookService().Enqueue(eventType, data); err != nil {
+		log.Printf("Failed to queue %s webhook event: %v", eventType, err)
+	}
+}
+
+// webhookBackoff returns the exponential delay before the next attempt
+func webhookBackoff(attempts int) time.Duration {
+	delay := webhookBaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= webhookMaxBackoff {
+			return webhookMaxBackoff
+		}
+	}
+	return delay
+}
+
+// webhookEndpointAccepts reports whether an endpoint subscribes to the event type
+func webhookEndpointAccepts(endpoint *models.WebhookEndpoint, eventType string) bool {
+	if endpoint.EventTypes == "" {
+		return true
+	}
+	for _, t := range strings.Split(endpoint.EventTypes, ",") {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeWebhookEventTypes validates and de-duplicates an event type filter
+func normalizeWebhookEventTypes(eventTypes []string) ([]string, error) {
+	known := make(map[string]bool, len(models.WebhookEventTypes))
+	for _, t := range models.WebhookEventTypes {
+		known[t] = true
+	}
+
+	seen := make(map[string]bool)
+	var types []string
+	for _, t := range eventTypes {
+		t = strings.ToUpper(strings.TrimSpace(t))
+		if t == "" || seen[t] {
+			continue
+		}
+		if !known[t] {
+			return nil, fmt.Errorf("unknown event type %s", t)
+		}
+		seen[t] = true
+		types = append(types, t)
+	}
+	return types, nil
+}
+
+// validateWebhookURL ensures the endpoint URL is an absolute http(s) URL
+func validateWebhookURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return errors.New("url must be an absolute http or https URL")
+	}
+	return nil
+}
+
+// mapWebhookEndpointToResponse maps a WebhookEndpoint to its response format without the secret
+func mapWebhookEndpointToResponse(endpoint *models.WebhookEndpoint) models.WebhookEndpointResponse {
+	eventTypes := []string{}
+	if endpoint.EventTypes != "" {
+		eventTypes = strings.Split(endpoint.EventTypes, ",")
+	}
+
+	return models.WebhookEndpointResponse{
+		ID:          endpoint.ID,
+		Name:        endpoint.Name,
+		URL:         endpoint.URL,
+		EventTypes:  eventTypes,
+		Active:      endpoint.Active,
+		CreatedByID: endpoint.CreatedByID,
+		CreatedAt:   endpoint.CreatedAt,
+		UpdatedAt:   endpoint.UpdatedAt,
+	}
+}
+
+// generateWebhookSecret generates a random signing secret
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// generateWebhookEventID generates a random identifier shared by all deliveries of one event
+func generateWebhookEventID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "evt_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,82 @@
+package utils
+
+import "github.com/tealeg/xlsx/v3"
+
+// NewXLSXTitleStyle returns the style used for report titles
+func NewXLSXTitleStyle() *xlsx.Style {
+	style := xlsx.NewStyle()
+	style.Font.Bold = true
+	style.Font.Size = 16
+	return style
+}
+
+// NewXLSXBoldStyle returns the style used for section labels
+func NewXLSXBoldStyle() *xlsx.Style {
+	style := xlsx.NewStyle()
+	style.Font.Bold = true
+	return style
+}
+
+// NewXLSXHeaderStyle returns the style used for table headers
+func NewXLSXHeaderStyle() *xlsx.Style {
+	style := NewXLSXCellStyle()
+	style.Font.Bold = true
+	style.Fill.PatternType = "solid"
+	style.Fill.BgColor = "C6E0B4" // Light green background
+	style.Alignment.Horizontal = "center"
+	return style
+}
+
+// NewXLSXCellStyle returns the style used for bordered table cells
+func NewXLSXCellStyle() *xlsx.Style {
+	style := xlsx.NewStyle()
+	style.Border.Left = "thin"
+	style.Border.Right = "thin"
+	style.Border.Top = "thin"
+	style.Border.Bottom = "thin"
+	return style
+}
+
+// NewXLSXStatusStyle returns the bordered, colored style for an attendance status cell
+func NewXLSXStatusStyle(status string) *xlsx.Style {
+	style := NewXLSXCellStyle()
+	style.Font.Bold = true
+	style.Alignment.Horizontal = "center"
+
+	switch status {
+	case "PRESENT":
+		style.Fill.PatternType = "solid"
+		style.Fill.BgColor = "C6EFCE" // Light green
+		style.Font.Color = "006100"   // Dark green
+	case "LATE":
+		style.Fill.PatternType = "solid"
+		style.Fill.BgColor = "FFEB9C" // Light yellow
+		style.Font.Color = "9C5700"   // Dark orange
+	case "ABSENT":
+		style.Fill.PatternType = "solid"
+		style.Fill.BgColor = "FFC7CE" // Light red
+		style.Font.Color = "9C0006"   // Dark red
+	case "EXCUSED":
+		style.Fill.PatternType = "solid"
+		style.Fill.BgColor = "DDEBF7" // Light blue
+		style.Font.Color = "2F75B5"   // Dark blue
+	}
+
+	return style
+}
+
+// AttendanceStatusLabel translates an attendance status to Indonesian
+func AttendanceStatusLabel(status string) string {
+	switch status {
+	case "PRESENT":
+		return "Hadir"
+	case "LATE":
+		return "Terlambat"
+	case "ABSENT":
+		return "Tidak Hadir"
+	case "EXCUSED":
+		return "Izin"
+	default:
+		return status
+	}
+}